@@ -0,0 +1,190 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/dantech2000/kubelog/pkg/bench"
+	"github.com/dantech2000/kubelog/pkg/format"
+	"github.com/dantech2000/kubelog/pkg/logging"
+	"github.com/dantech2000/kubelog/pkg/session"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var benchCmd = &cobra.Command{
+	Use:   "bench [file]",
+	Short: "Measure how fast log lines are parsed and formatted",
+	Long: `Measure how many log lines per second kubelog parses and formats on this
+machine, and how much memory it allocates per line, without connecting to a
+cluster.
+
+Without a file, synthetic lines in a mix of the formats kubelog parses are
+used; --lines sets how many. A file of log lines, a .klog session recording or
+- for stdin can be given instead to measure lines of your own. The lines are
+processed again and again for --duration, first only parsed, then parsed and
+formatted in the --output format. Text is formatted with colors, as it is on
+terminals. The parsers of --parser and the fieldAliases, epochUnits, derivedFields and
+maskRules of the config file are applied as by kubelog logs.
+
+Example usage:
+  kubelog bench
+  kubelog bench --duration 10s -o json
+  kubelog bench session.klog --parser klog`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runBench(cmd, args); err != nil {
+			color.Red("Error running benchmark: %v", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(benchCmd)
+	benchCmd.Flags().Int("lines", 10000, "Number of synthetic lines to generate when no file is given")
+	benchCmd.Flags().Duration("duration", 3*time.Second, "How long to run each stage of the benchmark")
+	benchCmd.Flags().StringP("output", "o", "text", "Output format to measure: text, json, jsonl, logfmt, ecs, gelf, csv or custom-columns=HEADER:.Field,...")
+	benchCmd.Flags().StringSlice("parser", nil, "Parse lines with built-in parsers that aren't detected automatically: "+strings.Join(logging.ParserNames(), ", "))
+	_ = benchCmd.RegisterFlagCompletionFunc("output", completeOutputFormats("text", "json", "jsonl", "logfmt", "ecs", "gelf", "csv", format.CustomColumnsPrefix))
+}
+
+func runBench(cmd *cobra.Command, args []string) error {
+	n, err := cmd.Flags().GetInt("lines")
+	if err != nil {
+		return fmt.Errorf("error getting lines flag: %v", err)
+	}
+	if n < 1 {
+		return fmt.Errorf("--lines must be at least 1")
+	}
+
+	duration, err := cmd.Flags().GetDuration("duration")
+	if err != nil {
+		return fmt.Errorf("error getting duration flag: %v", err)
+	}
+
+	output, err := cmd.Flags().GetString("output")
+	if err != nil {
+		return fmt.Errorf("error getting output flag: %v", err)
+	}
+
+	parsers, err := cmd.Flags().GetStringSlice("parser")
+	if err != nil {
+		return fmt.Errorf("error getting parser flag: %v", err)
+	}
+
+	cfg, err := loadConfig(cmd)
+	if err != nil {
+		return err
+	}
+	options := &logOptions{level: "DEBUG", parsers: parsers, output: output}
+	pipeline, err := buildPipeline(options, cfg)
+	if err != nil {
+		return err
+	}
+	formatter, err := formatterFor(output, options)
+	if err != nil {
+		return err
+	}
+
+	source := "synthetic lines"
+	var lines []string
+	if len(args) == 0 {
+		lines = bench.SyntheticLines(n)
+	} else {
+		source = "lines of " + args[0]
+		if args[0] == "-" {
+			source = "lines of stdin"
+		}
+		if lines, err = readBenchLines(args[0]); err != nil {
+			return err
+		}
+		if len(lines) == 0 {
+			return fmt.Errorf("%s has no lines", args[0])
+		}
+	}
+
+	var size int
+	for _, line := range lines {
+		size += len(line)
+	}
+	fmt.Printf("Benchmarking %d %s (%s) for %s per stage on %s/%s, %s\n",
+		len(lines), source, logging.HumanizeBytes(float64(size)), duration, runtime.GOOS, runtime.GOARCH, runtime.Version())
+
+	parsed := bench.Run(lines, pipeline, nil, duration)
+	noColor := color.NoColor
+	color.NoColor = false
+	formatted := bench.Run(lines, pipeline, formatter, duration)
+	color.NoColor = noColor
+
+	if output == "" {
+		output = "text"
+	}
+	table := format.NewTable("STAGE", "LINES/S", "MB/S", "ALLOCS/LINE", "BYTES/LINE")
+	for _, stage := range []struct {
+		name   string
+		result bench.Result
+	}{
+		{"parse", parsed},
+		{"parse + format " + output, formatted},
+	} {
+		table.Append(stage.name,
+			fmt.Sprintf("%.0f", stage.result.LinesPerSecond()),
+			fmt.Sprintf("%.1f", stage.result.BytesPerSecond()/1e6),
+			fmt.Sprintf("%.1f", stage.result.AllocsPerLine()),
+			fmt.Sprintf("%.0f", stage.result.AllocBytesPerLine()))
+	}
+	fmt.Println(table.Render())
+	return nil
+}
+
+// readBenchLines reads the lines to benchmark from a file of log lines, a
+// session recording, or stdin for -
+func readBenchLines(path string) ([]string, error) {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %w", path, err)
+	}
+
+	var lines []string
+	reader, err := session.NewReader(bytes.NewReader(data))
+	switch {
+	case errors.Is(err, session.ErrNotSession):
+		scanner := bufio.NewScanner(bytes.NewReader(data))
+		scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+		for scanner.Scan() {
+			if line := scanner.Text(); line != "" {
+				lines = append(lines, line)
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("error reading %s: %w", path, err)
+		}
+		return lines, nil
+	case err != nil:
+		return nil, err
+	}
+	for {
+		entry, err := reader.Next()
+		if err == io.EOF {
+			return lines, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, entry.Line)
+	}
+}
@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/dantech2000/kubelog/pkg/logging"
+	"github.com/dantech2000/kubelog/pkg/pager"
+	"github.com/mattn/go-isatty"
+	"golang.org/x/term"
+)
+
+// builtinPagerWriter collects the output and shows it in the built-in pager
+// once all of it is read. Output that fits on the screen, or that can't be
+// paged because stdin isn't a terminal, is printed instead.
+type builtinPagerWriter struct {
+	mu    sync.Mutex
+	out   *os.File
+	pager *pager.Pager
+}
+
+// newBuiltinPager creates a builtinPagerWriter for the terminal out
+func newBuiltinPager(out *os.File) *builtinPagerWriter {
+	return &builtinPagerWriter{out: out, pager: pager.New(terminalSize(out))}
+}
+
+// terminalSize returns the width and height of a terminal, or 80x24 when
+// it can't be read
+func terminalSize(f *os.File) (int, int) {
+	width, height, err := term.GetSize(int(f.Fd()))
+	if err != nil || width < 1 || height < 2 {
+		return 80, 24
+	}
+	return width, height
+}
+
+// WriteEntry implements the EntryWriter interface, keeping the level and
+// timestamp of the entry for level toggling and jumping to a time
+func (w *builtinPagerWriter) WriteEntry(entry logging.LogEntry, line string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.pager.Add(pager.Line{Text: line, Leveled: true, Level: entry.Level, Time: entry.Timestamp})
+	return nil
+}
+
+// Write implements io.Writer for output without an entry, such as headers
+func (w *builtinPagerWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, line := range strings.Split(strings.TrimSuffix(string(p), "\n"), "\n") {
+		w.pager.Add(pager.Line{Text: line})
+	}
+	return len(p), nil
+}
+
+// Close shows the output and returns once the user quit the pager
+func (w *builtinPagerWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.pager.Fits() || !isatty.IsTerminal(os.Stdin.Fd()) {
+		for _, line := range w.pager.Lines() {
+			if _, err := fmt.Fprintln(w.out, line); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return w.run()
+}
+
+// run shows the pager on the alternate screen until the user quits
+func (w *builtinPagerWriter) run() error {
+	state, err := term.MakeRaw(int(os.Stdin.Fd()))
+	if err != nil {
+		return fmt.Errorf("error setting up the terminal for the pager: %w", err)
+	}
+	defer func() { _ = term.Restore(int(os.Stdin.Fd()), state) }()
+	fmt.Fprint(w.out, "\x1b[?1049h\x1b[?25l")
+	defer fmt.Fprint(w.out, "\x1b[?25h\x1b[?1049l")
+
+	buf := make([]byte, 64)
+	for {
+		// The size is read again for every key, so resizing needs no signal
+		w.pager.Resize(terminalSize(w.out))
+		if err := w.pager.Render(w.out); err != nil {
+			return err
+		}
+		n, err := os.Stdin.Read(buf)
+		if err != nil {
+			return nil
+		}
+		for _, key := range pager.ParseKeys(buf[:n]) {
+			if w.pager.Key(key) {
+				return nil
+			}
+		}
+	}
+}
@@ -0,0 +1,254 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/dantech2000/kubelog/pkg/checkpoint"
+	"github.com/dantech2000/kubelog/pkg/kubernetes"
+	"github.com/dantech2000/kubelog/pkg/logging"
+	"github.com/dantech2000/kubelog/pkg/sink"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// captureOptions holds the command options for the capture command
+type captureOptions struct {
+	namespace string
+	selector  string
+	container string
+	dir       string
+	interval  time.Duration
+	stateFile string
+	rotation  rotationOptions
+}
+
+var captureCmd = &cobra.Command{
+	Use:   "capture",
+	Short: "Continuously capture the logs of a workload to rotated files",
+	Long: `Capture the logs of every container of the pods matching a label selector to
+files until interrupted, as a lightweight alternative to a cluster log agent for a
+single workload.
+
+The pods are listed again every --interval, so new pods are picked up as they
+start, and streams ended by a container restart or a broken connection are
+reattached. The last line captured of every container is kept in
+checkpoints.json in the directory, so reattached streams, and a capture restarted
+later, continue where they left off without repeating lines.
+
+Every container is captured to files in <dir>/<namespace>/<pod>/, with the lines
+written as the container logged them. Every file starts with a "# " line of JSON
+metadata: the namespace, pod, container, node, image and labels, and when the
+capture attached to the stream. Files are rotated like the output files of
+kubelog logs and named after the container and the time they start, e.g.
+app-20240115-100000.log; by default a new file is started when one would grow
+beyond 100MB, and the newest 10 of every container are kept. The maskRules of
+the config file are applied.
+
+Example usage:
+  kubelog capture --selector app=api --dir /var/log/kubelog
+  kubelog capture -l app=api -n shop -c app --dir ./logs --rotate-every 1h --rotate-compress zstd`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runCapture(cmd); err != nil {
+			fmt.Fprintf(os.Stderr, "Error running capture command: %v\n", err)
+			if hint := errorHint(err); hint != "" {
+				fmt.Fprintln(os.Stderr, hint)
+			}
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(captureCmd)
+	captureCmd.Flags().StringP("namespace", "n", "", "Kubernetes namespace (defaults to current context's namespace)")
+	captureCmd.Flags().StringP("selector", "l", "", "Capture the logs of all pods matching this label selector (e.g. app=api)")
+	captureCmd.Flags().StringP("container", "c", "", "Only capture the containers of this name")
+	captureCmd.Flags().String("dir", "", "Directory the logs are captured to")
+	captureCmd.Flags().Duration("interval", kubernetes.DefaultCaptureInterval, "How often to look for new pods and ended streams")
+	captureCmd.Flags().String("state-file", "", "Where the last line captured per container is kept (default checkpoints.json in --dir)")
+	captureCmd.Flags().Duration("rotate-every", 0, "Start a new file every time window, e.g. 1h, named after the window's start time")
+	captureCmd.Flags().String("rotate-size", "100MB", "Start a new file when it would grow beyond this size (empty for no limit)")
+	captureCmd.Flags().String("rotate-compress", sink.CompressNone, "Compress rotated files that are done: none, gzip or zstd")
+	captureCmd.Flags().Int("rotate-keep", 10, "Only keep this many rotated files that are done per container, removing the oldest (0 keeps all)")
+
+	_ = captureCmd.RegisterFlagCompletionFunc("namespace", completeNamespaces)
+}
+
+func getCaptureOptions(cmd *cobra.Command) (*captureOptions, error) {
+	namespace, err := cmd.Flags().GetString("namespace")
+	if err != nil {
+		return nil, fmt.Errorf("error getting namespace flag: %v", err)
+	}
+
+	selector, err := cmd.Flags().GetString("selector")
+	if err != nil {
+		return nil, fmt.Errorf("error getting selector flag: %v", err)
+	}
+	if selector == "" {
+		return nil, fmt.Errorf("--selector is required")
+	}
+
+	container, err := cmd.Flags().GetString("container")
+	if err != nil {
+		return nil, fmt.Errorf("error getting container flag: %v", err)
+	}
+
+	dir, err := cmd.Flags().GetString("dir")
+	if err != nil {
+		return nil, fmt.Errorf("error getting dir flag: %v", err)
+	}
+	if dir == "" {
+		return nil, fmt.Errorf("--dir is required")
+	}
+
+	interval, err := cmd.Flags().GetDuration("interval")
+	if err != nil {
+		return nil, fmt.Errorf("error getting interval flag: %v", err)
+	}
+	if interval <= 0 {
+		return nil, fmt.Errorf("--interval must be positive")
+	}
+
+	stateFile, err := cmd.Flags().GetString("state-file")
+	if err != nil {
+		return nil, fmt.Errorf("error getting state-file flag: %v", err)
+	}
+	if stateFile == "" {
+		stateFile = filepath.Join(dir, "checkpoints.json")
+	}
+
+	rotation, err := getRotationOptions(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	return &captureOptions{
+		namespace: namespace,
+		selector:  selector,
+		container: container,
+		dir:       dir,
+		interval:  interval,
+		stateFile: stateFile,
+		rotation:  rotation,
+	}, nil
+}
+
+func runCapture(cmd *cobra.Command) error {
+	options, err := getCaptureOptions(cmd)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := loadConfig(cmd)
+	if err != nil {
+		return err
+	}
+	pipeline := logging.NewPipeline()
+	if len(cfg.MaskRules) > 0 {
+		mask, err := newMask(cfg)
+		if err != nil {
+			return err
+		}
+		pipeline.Transform(mask)
+	}
+
+	clientset, contextNamespace, err := newKubeClient(cmd, cfg)
+	if err != nil {
+		return fmt.Errorf("error getting kubernetes client: %v", err)
+	}
+	if options.namespace == "" {
+		options.namespace = contextNamespace
+	}
+
+	store, err := checkpoint.Load(options.stateFile)
+	if err != nil {
+		return err
+	}
+
+	// Stop capturing cleanly on Ctrl-C or SIGTERM
+	ctx := cmd.Context()
+
+	go saveCheckpoints(ctx, store, os.Stderr)
+	defer func() {
+		if err := store.Save(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error saving checkpoints: %v\n", err)
+		}
+	}()
+
+	capture := &kubernetes.Capture{
+		Clientset: clientset,
+		Namespace: options.namespace,
+		Selector:  options.selector,
+		Container: options.container,
+		Interval:  options.interval,
+		Open:      captureFileOpener(options),
+		Options: []kubernetes.Option{
+			kubernetes.WithPipeline(pipeline),
+			kubernetes.WithFormatter(logging.FormatterFunc(func(entry logging.LogEntry) string { return entry.RawLine })),
+			kubernetes.WithCheckpoints(store),
+		},
+		Events: kubernetes.NoticeHandler(os.Stderr),
+	}
+	fmt.Fprintf(os.Stderr, "Capturing the logs of pods matching %s in namespace %s to %s\n", options.selector, options.namespace, options.dir)
+	if err := capture.Run(ctx); err != nil && !errors.Is(err, context.Canceled) {
+		return err
+	}
+	return nil
+}
+
+// captureFileOpener returns the function opening the capture file of a container
+func captureFileOpener(options *captureOptions) func(pod *corev1.Pod, target kubernetes.Target) (io.WriteCloser, error) {
+	return func(pod *corev1.Pod, target kubernetes.Target) (io.WriteCloser, error) {
+		file, err := options.rotation.newFileWriter(filepath.Join(options.dir, target.Namespace, target.Pod, target.Container+".log"))
+		if err != nil {
+			return nil, err
+		}
+		header, err := captureHeader(pod, target, time.Now())
+		if err != nil {
+			return nil, err
+		}
+		file.Header = header
+		return file, nil
+	}
+}
+
+// captureMetadata describes the stream a capture file holds
+type captureMetadata struct {
+	Namespace string            `json:"namespace"`
+	Pod       string            `json:"pod"`
+	Container string            `json:"container"`
+	Node      string            `json:"node,omitempty"`
+	Image     string            `json:"image,omitempty"`
+	Labels    map[string]string `json:"labels,omitempty"`
+	Attached  time.Time         `json:"attached"`
+}
+
+// captureHeader renders the metadata line at the top of every capture file
+func captureHeader(pod *corev1.Pod, target kubernetes.Target, attached time.Time) (string, error) {
+	meta := captureMetadata{
+		Namespace: target.Namespace,
+		Pod:       target.Pod,
+		Container: target.Container,
+		Node:      pod.Spec.NodeName,
+		Labels:    pod.Labels,
+		Attached:  attached.UTC(),
+	}
+	for _, c := range pod.Spec.Containers {
+		if c.Name == target.Container {
+			meta.Image = c.Image
+		}
+	}
+	data, err := json.Marshal(meta)
+	if err != nil {
+		return "", fmt.Errorf("error encoding capture metadata: %w", err)
+	}
+	return "# " + string(data), nil
+}
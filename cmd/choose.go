@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/AlecAivazis/survey/v2/terminal"
+	"github.com/dantech2000/kubelog/pkg/kubernetes"
+)
+
+// chooseContainer prompts the user to choose one of the containers of a pod.
+// The prompt is written to stderr so it stays out of redirected log output.
+func chooseContainer(pod string, containers []kubernetes.ContainerInfo) (string, error) {
+	options := make([]string, len(containers))
+	for i, info := range containers {
+		options[i] = kubernetes.FormatContainerInfo(info)
+	}
+
+	var selectedIdx int
+	prompt := &survey.Select{
+		Message: "Choose a container:",
+		Options: options,
+		Filter: func(filter string, value string, index int) bool {
+			container := containers[index]
+			filter = strings.ToLower(filter)
+			return strings.Contains(strings.ToLower(container.Name), filter) ||
+				strings.Contains(strings.ToLower(container.Status), filter) ||
+				strings.Contains(strings.ToLower(container.Image), filter)
+		},
+	}
+
+	err := survey.AskOne(prompt, &selectedIdx, survey.WithPageSize(10), survey.WithStdio(os.Stdin, os.Stderr, os.Stderr))
+	if err != nil {
+		if errors.Is(err, terminal.InterruptErr) {
+			return "", fmt.Errorf("operation cancelled")
+		}
+		return "", fmt.Errorf("selection failed: %w", err)
+	}
+	return containers[selectedIdx].Name, nil
+}
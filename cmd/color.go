@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/fatih/color"
+	"github.com/mattn/go-isatty"
+)
+
+// setupColor makes sure the colors written to terminals show as colors.
+// Windows consoles only interpret them with virtual terminal processing,
+// which is enabled for both stdout and stderr; consoles without it, such as
+// those of Windows before Windows 10, get plain output rather than escape
+// sequences.
+func setupColor() {
+	if color.NoColor {
+		return
+	}
+	for _, f := range []*os.File{os.Stdout, os.Stderr} {
+		if isatty.IsTerminal(f.Fd()) && !enableVirtualTerminal(f) {
+			color.NoColor = true
+			return
+		}
+	}
+}
+
+// isTerminal reports whether f is a terminal, counting the Cygwin and MSYS2
+// terminals of Windows as the color package does
+func isTerminal(f *os.File) bool {
+	return isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd())
+}
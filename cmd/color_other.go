@@ -0,0 +1,11 @@
+//go:build !windows
+
+package cmd
+
+import "os"
+
+// enableVirtualTerminal reports whether the terminal of f interprets the
+// escape sequences of colors, which terminals outside of Windows all do
+func enableVirtualTerminal(f *os.File) bool {
+	return true
+}
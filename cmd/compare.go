@@ -0,0 +1,285 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dantech2000/kubelog/pkg/format"
+	"github.com/dantech2000/kubelog/pkg/kubernetes"
+	"github.com/dantech2000/kubelog/pkg/logging"
+	"github.com/spf13/cobra"
+)
+
+// defaultCompareInterval is how often the compare command reports
+const defaultCompareInterval = 10 * time.Second
+
+// compareOptions holds the command options for the compare command
+type compareOptions struct {
+	namespace string
+	selector  string
+	by        string
+	container string
+	interval  time.Duration
+	maxReqs   int
+	ignoreErr bool
+}
+
+var compareCmd = &cobra.Command{
+	Use:   "compare",
+	Short: "Compare the line and error rates of pods grouped by a label",
+	Long: `Follow the logs of every pod matching a label selector, group the pods by the
+value of the label given with --by, and report the line and error rates of every
+group side by side every --interval, e.g. to compare the versions of a workload
+during a canary release.
+
+Every report shows, per group, the number of pods, the lines and errors per
+second and the error rate since the previous report, and the totals since the
+comparison started. Pods without the label are left out, and pods started later
+are not picked up. Infrastructure sidecars (istio-proxy) are left out unless a
+container is given with -c. The line levels are detected like kubelog logs does.
+A last report is printed when interrupted.
+
+Example usage:
+  kubelog compare -l app=api --by version
+  kubelog compare -l app=api --by rollouts-pod-template-hash -c app --interval 30s`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runCompare(cmd); err != nil {
+			fmt.Fprintf(os.Stderr, "Error running compare command: %v\n", err)
+			if hint := errorHint(err); hint != "" {
+				fmt.Fprintln(os.Stderr, hint)
+			}
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(compareCmd)
+	compareCmd.Flags().StringP("namespace", "n", "", "Kubernetes namespace (defaults to current context's namespace)")
+	compareCmd.Flags().StringP("selector", "l", "", "Compare the pods matching this label selector (e.g. app=api)")
+	compareCmd.Flags().String("by", "", "Group the pods by the value of this label (e.g. version)")
+	compareCmd.Flags().StringP("container", "c", "", "Only compare the containers of this name")
+	compareCmd.Flags().Duration("interval", defaultCompareInterval, "How often to report the rates of every group")
+	compareCmd.Flags().Int("max-log-requests", kubernetes.DefaultMaxStreams, "Refuse to stream more than this many containers at once, to protect the client and the API server")
+	compareCmd.Flags().Bool("ignore-errors", false, "Report streams that fail to stderr and keep the others going")
+
+	_ = compareCmd.RegisterFlagCompletionFunc("namespace", completeNamespaces)
+}
+
+func getCompareOptions(cmd *cobra.Command) (*compareOptions, error) {
+	namespace, err := cmd.Flags().GetString("namespace")
+	if err != nil {
+		return nil, fmt.Errorf("error getting namespace flag: %v", err)
+	}
+
+	selector, err := cmd.Flags().GetString("selector")
+	if err != nil {
+		return nil, fmt.Errorf("error getting selector flag: %v", err)
+	}
+	if selector == "" {
+		return nil, fmt.Errorf("--selector is required")
+	}
+
+	by, err := cmd.Flags().GetString("by")
+	if err != nil {
+		return nil, fmt.Errorf("error getting by flag: %v", err)
+	}
+	if by == "" {
+		return nil, fmt.Errorf("--by is required")
+	}
+
+	container, err := cmd.Flags().GetString("container")
+	if err != nil {
+		return nil, fmt.Errorf("error getting container flag: %v", err)
+	}
+
+	interval, err := cmd.Flags().GetDuration("interval")
+	if err != nil {
+		return nil, fmt.Errorf("error getting interval flag: %v", err)
+	}
+	if interval <= 0 {
+		return nil, fmt.Errorf("--interval must be positive")
+	}
+
+	maxReqs, err := cmd.Flags().GetInt("max-log-requests")
+	if err != nil {
+		return nil, fmt.Errorf("error getting max-log-requests flag: %v", err)
+	}
+	if maxReqs < 1 {
+		return nil, fmt.Errorf("--max-log-requests must be at least 1")
+	}
+
+	ignoreErr, err := cmd.Flags().GetBool("ignore-errors")
+	if err != nil {
+		return nil, fmt.Errorf("error getting ignore-errors flag: %v", err)
+	}
+
+	return &compareOptions{
+		namespace: namespace,
+		selector:  selector,
+		by:        by,
+		container: container,
+		interval:  interval,
+		maxReqs:   maxReqs,
+		ignoreErr: ignoreErr,
+	}, nil
+}
+
+func runCompare(cmd *cobra.Command) error {
+	options, err := getCompareOptions(cmd)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := loadConfig(cmd)
+	if err != nil {
+		return err
+	}
+	clientset, contextNamespace, err := newKubeClient(cmd, cfg)
+	if err != nil {
+		return fmt.Errorf("error getting kubernetes client: %v", err)
+	}
+	if options.namespace == "" {
+		options.namespace = contextNamespace
+	}
+
+	// Stop comparing cleanly on Ctrl-C or SIGTERM
+	ctx := cmd.Context()
+
+	labelGroups, err := kubernetes.GroupTargetsByLabel(ctx, clientset, options.namespace, options.selector, options.container, options.by)
+	if err != nil {
+		return err
+	}
+
+	var targets []kubernetes.Target
+	groups := make([]*compareGroup, 0, len(labelGroups))
+	groupOf := make(map[kubernetes.Target]*compareGroup)
+	for _, labelGroup := range labelGroups {
+		groupTargets := labelGroup.Targets
+		if options.container == "" {
+			groupTargets = kubernetes.ExcludeContainers(groupTargets, kubernetes.DefaultSidecarContainers...)
+		}
+		if len(groupTargets) == 0 {
+			continue
+		}
+		group := newCompareGroup(labelGroup.Value, groupTargets)
+		for _, target := range groupTargets {
+			groupOf[target] = group
+		}
+		groups = append(groups, group)
+		targets = append(targets, groupTargets...)
+	}
+	if len(groups) == 0 {
+		return fmt.Errorf("%w: every container matching selector '%s' is excluded", kubernetes.ErrContainerNotFound, options.selector)
+	}
+	if err := kubernetes.CheckMaxStreams(targets, options.maxReqs); err != nil {
+		return err
+	}
+
+	// Only the rates are shown, so the lines themselves are discarded
+	multi := kubernetes.NewMultiLogFetcher(clientset, targets,
+		kubernetes.WithFollow(),
+		kubernetes.WithWriter(io.Discard),
+		kubernetes.WithFormatter(logging.FormatterFunc(func(entry logging.LogEntry) string { return "" })),
+		kubernetes.WithEvents(kubernetes.NoticeHandler(os.Stderr)),
+	)
+	multi.IgnoreErrors = options.ignoreErr
+	multi.TargetOptions = func(target kubernetes.Target) []kubernetes.Option {
+		return []kubernetes.Option{kubernetes.WithPipeline(groupOf[target].pipeline)}
+	}
+
+	fmt.Fprintf(os.Stderr, "Comparing %d containers matching %s in namespace %s by %s\n", len(targets), options.selector, options.namespace, options.by)
+	done := make(chan struct{})
+	var reports sync.WaitGroup
+	reports.Add(1)
+	go func() {
+		defer reports.Done()
+		ticker := time.NewTicker(options.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case now := <-ticker.C:
+				printCompareReport(os.Stdout, options.by, groups, now)
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	err = multi.GetLogs(ctx)
+	close(done)
+	reports.Wait()
+	printCompareReport(os.Stdout, options.by, groups, time.Now())
+	if err != nil && !errors.Is(err, context.Canceled) {
+		return err
+	}
+	return nil
+}
+
+// compareGroup counts the entries of the pods sharing a label value
+type compareGroup struct {
+	value    string
+	pods     int
+	pipeline *logging.Pipeline
+	counter  *logging.LevelCounter
+
+	// The counts and time of the previous report, to compute the rates since
+	lastTime   time.Time
+	lastLines  uint64
+	lastErrors uint64
+}
+
+// newCompareGroup creates the group of a label value streaming targets
+func newCompareGroup(value string, targets []kubernetes.Target) *compareGroup {
+	pods := make(map[string]bool)
+	for _, target := range targets {
+		pods[target.Pod] = true
+	}
+	counter := &logging.LevelCounter{}
+	pipeline := logging.NewPipeline()
+	pipeline.Transform(counter)
+	return &compareGroup{value: value, pods: len(pods), pipeline: pipeline, counter: counter, lastTime: time.Now()}
+}
+
+// report returns the cells of the group's row at now and starts the next
+// interval
+func (g *compareGroup) report(now time.Time) []string {
+	lines, errs := g.counter.Total(), g.counter.Count(logging.ERROR)
+	newLines, newErrors := lines-g.lastLines, errs-g.lastErrors
+	seconds := now.Sub(g.lastTime).Seconds()
+	g.lastTime, g.lastLines, g.lastErrors = now, lines, errs
+
+	var lineRate, errorRate, errorRatio float64
+	if seconds > 0 {
+		lineRate, errorRate = float64(newLines)/seconds, float64(newErrors)/seconds
+	}
+	if newLines > 0 {
+		errorRatio = float64(newErrors) / float64(newLines)
+	}
+	return []string{
+		g.value,
+		strconv.Itoa(g.pods),
+		fmt.Sprintf("%.1f", lineRate),
+		fmt.Sprintf("%.2f", errorRate),
+		fmt.Sprintf("%.1f%%", errorRatio*100),
+		strconv.FormatUint(lines, 10),
+		strconv.FormatUint(errs, 10),
+	}
+}
+
+// printCompareReport writes the rates of every group as a table to w
+func printCompareReport(w io.Writer, label string, groups []*compareGroup, now time.Time) {
+	table := format.NewTable(strings.ToUpper(label), "PODS", "LINES/S", "ERRORS/S", "ERROR RATE", "LINES", "ERRORS")
+	for _, group := range groups {
+		table.Append(group.report(now)...)
+	}
+	fmt.Fprintf(w, "%s\n%s\n\n", now.Format(time.TimeOnly), table.Render())
+}
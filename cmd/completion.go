@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"os"
+	"strings"
 
 	"github.com/spf13/cobra"
 )
@@ -43,3 +44,21 @@ PowerShell:
 		}
 	},
 }
+
+// completeOutputFormats returns a completion function for the --output
+// formats of a command. Formats taking a value, such as custom-columns=, are
+// completed without a trailing space.
+func completeOutputFormats(formats ...string) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		var matches []string
+		for _, f := range formats {
+			if strings.HasPrefix(f, toComplete) {
+				matches = append(matches, f)
+			}
+		}
+		if len(matches) == 1 && strings.HasSuffix(matches[0], "=") {
+			return matches, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace
+		}
+		return matches, cobra.ShellCompDirectiveNoFileComp
+	}
+}
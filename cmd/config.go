@@ -0,0 +1,261 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/dantech2000/kubelog/pkg/config"
+	"github.com/dantech2000/kubelog/pkg/kubernetes"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+	k8s "k8s.io/client-go/kubernetes"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect and edit the kubelog config file",
+	Long: `Inspect and edit the kubelog config file without opening an editor.
+
+Keys:
+  ` + strings.Join(config.Keys, "\n  ") + `
+
+Map entries are addressed with a dot after the section name, e.g.
+loggerLevels.io.netty; the section name alone refers to the whole section.
+Values are validated before the file is written, which does not preserve
+comments.
+
+Example usage:
+  kubelog config view
+  kubelog config get loggerLevels
+  kubelog config set loggerLevels.io.netty ERROR
+  kubelog config set derivedFields.slow 'duration_ms > 500'
+  kubelog config unset context`,
+}
+
+var configViewCmd = &cobra.Command{
+	Use:   "view",
+	Short: "Print the config file",
+	Args:  cobra.NoArgs,
+	Run:   runConfigView,
+}
+
+var configGetCmd = &cobra.Command{
+	Use:               "get <key>",
+	Short:             "Print the value of a setting",
+	Args:              cobra.ExactArgs(1),
+	Run:               runConfigGet,
+	ValidArgsFunction: completeConfigKeys,
+}
+
+var configSetCmd = &cobra.Command{
+	Use:               "set <key> <value>",
+	Short:             "Change the value of a setting",
+	Args:              cobra.ExactArgs(2),
+	Run:               runConfigSet,
+	ValidArgsFunction: completeConfigKeys,
+}
+
+var configUnsetCmd = &cobra.Command{
+	Use:               "unset <key>",
+	Short:             "Remove a setting",
+	Args:              cobra.ExactArgs(1),
+	Run:               runConfigUnset,
+	ValidArgsFunction: completeConfigKeys,
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configViewCmd, configGetCmd, configSetCmd, configUnsetCmd)
+}
+
+// loadConfig reads the config file given by --config, or the default one,
+// with the context overridden by --context
+func loadConfig(cmd *cobra.Command) (*config.Config, error) {
+	cfg, err := loadConfigFile(cmd)
+	if err != nil {
+		return nil, err
+	}
+	kubeContext, err := cmd.Flags().GetString("context")
+	if err != nil {
+		return nil, fmt.Errorf("error getting context flag: %v", err)
+	}
+	if kubeContext != "" {
+		cfg.Context = kubeContext
+	}
+	return cfg, nil
+}
+
+// loadConfigFile reads the config file given by --config, or the default one,
+// as it is saved
+func loadConfigFile(cmd *cobra.Command) (*config.Config, error) {
+	path, err := cmd.Flags().GetString("config")
+	if err != nil {
+		return nil, fmt.Errorf("error getting config flag: %v", err)
+	}
+	cfg, err := config.Load(path)
+	if err == nil {
+		slog.Debug("loaded config", "path", path, "context", cfg.Context)
+	}
+	return cfg, err
+}
+
+// saveConfig writes the config file given by --config, or the default one
+func saveConfig(cmd *cobra.Command, cfg *config.Config) error {
+	path, err := cmd.Flags().GetString("config")
+	if err != nil {
+		return fmt.Errorf("error getting config flag: %v", err)
+	}
+	return config.Save(path, cfg)
+}
+
+// kubeClient creates a Kubernetes client for the context given with --context
+// or chosen with 'kubelog contexts use', or the kubeconfig's current context. It also returns
+// the namespace of that context.
+func kubeClient(cmd *cobra.Command) (k8s.Interface, string, error) {
+	cfg, err := loadConfig(cmd)
+	if err != nil {
+		return nil, "", err
+	}
+	return newKubeClient(cmd, cfg)
+}
+
+// newKubeClient creates a Kubernetes client for the context of cfg, with the
+// timeout given with --request-timeout
+func newKubeClient(cmd *cobra.Command, cfg *config.Config) (*k8s.Clientset, string, error) {
+	timeout, err := cmd.Flags().GetDuration("request-timeout")
+	if err != nil {
+		return nil, "", fmt.Errorf("error getting request-timeout flag: %v", err)
+	}
+	if timeout < 0 {
+		return nil, "", fmt.Errorf("invalid --request-timeout %s (must not be negative)", timeout)
+	}
+	return kubernetes.GetKubernetesClientWithOptions(kubernetes.ClientOptions{
+		Context:        cfg.Context,
+		RequestTimeout: timeout,
+	})
+}
+
+func runConfigView(cmd *cobra.Command, args []string) {
+	path, err := cmd.Flags().GetString("config")
+	if err != nil {
+		color.Red("Error getting config flag: %v", err)
+		os.Exit(1)
+	}
+	if path == "" {
+		if path, err = config.DefaultPath(); err != nil {
+			color.Red("Error finding config file: %v", err)
+			os.Exit(1)
+		}
+	}
+
+	cfg, err := loadConfigFile(cmd)
+	if err != nil {
+		color.Red("Error loading config: %v", err)
+		os.Exit(1)
+	}
+
+	data, err := yaml.Marshal(cfg)
+	if err != nil {
+		color.Red("Error encoding config: %v", err)
+		os.Exit(1)
+	}
+	fmt.Printf("# %s\n%s", path, data)
+}
+
+func runConfigGet(cmd *cobra.Command, args []string) {
+	cfg, err := loadConfigFile(cmd)
+	if err != nil {
+		color.Red("Error loading config: %v", err)
+		os.Exit(1)
+	}
+
+	value, err := cfg.Get(args[0])
+	if err != nil {
+		color.Red("Error getting %s: %v", args[0], err)
+		os.Exit(1)
+	}
+	fmt.Println(value)
+}
+
+func runConfigSet(cmd *cobra.Command, args []string) {
+	key, value := args[0], args[1]
+	cfg, err := loadConfigFile(cmd)
+	if err != nil {
+		color.Red("Error loading config: %v", err)
+		os.Exit(1)
+	}
+
+	if key == "context" {
+		if err := validateContext(value); err != nil {
+			color.Red("Error setting %s: %v", key, err)
+			if hint := errorHint(err); hint != "" {
+				fmt.Println(hint)
+			}
+			os.Exit(1)
+		}
+	}
+
+	if err := cfg.Set(key, value); err != nil {
+		color.Red("Error setting %s: %v", key, err)
+		os.Exit(1)
+	}
+	if err := saveConfig(cmd, cfg); err != nil {
+		color.Red("Error saving config: %v", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Set %s to %s\n", color.CyanString(key), value)
+}
+
+func runConfigUnset(cmd *cobra.Command, args []string) {
+	cfg, err := loadConfigFile(cmd)
+	if err != nil {
+		color.Red("Error loading config: %v", err)
+		os.Exit(1)
+	}
+
+	if err := cfg.Unset(args[0]); err != nil {
+		if errors.Is(err, config.ErrNotSet) {
+			fmt.Printf("%s is not set\n", args[0])
+			return
+		}
+		color.Red("Error unsetting %s: %v", args[0], err)
+		os.Exit(1)
+	}
+	if err := saveConfig(cmd, cfg); err != nil {
+		color.Red("Error saving config: %v", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Unset %s\n", color.CyanString(args[0]))
+}
+
+// validateContext checks that the kubeconfig has a context with the given name
+func validateContext(name string) error {
+	contexts, err := kubernetes.ListContexts("")
+	if err != nil {
+		return err
+	}
+	_, err = kubernetes.FindContext(contexts, name)
+	return err
+}
+
+// completeConfigKeys completes the section names of the config file,
+// followed by a dot for sections holding several entries
+func completeConfigKeys(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	var keys []string
+	for _, key := range config.Keys {
+		section, _, hasEntries := strings.Cut(key, ".")
+		if hasEntries {
+			section += "."
+		}
+		keys = append(keys, section)
+	}
+	return keys, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace
+}
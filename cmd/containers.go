@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
 
@@ -8,6 +9,7 @@ import (
 	"github.com/dantech2000/kubelog/pkg/kubernetes"
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
+	k8s "k8s.io/client-go/kubernetes"
 )
 
 // containerOptions holds the command options for the containers command
@@ -15,31 +17,57 @@ type containerOptions struct {
 	namespace    string
 	podName      string
 	outputFormat string
+	noHeaders    bool
+	resources    bool
+	wide         bool
+	sortBy       string
+	borders      bool
+	selector     string
 }
 
 var containersCmd = &cobra.Command{
-	Use:   "containers [pod-name]",
+	Use:   "containers [pod-name | kind/name]",
 	Short: "List containers in a Kubernetes pod",
 	Long: `List all containers within a specified Kubernetes pod.
-This command provides a formatted output of container names for the given pod,
-including the total count of containers.
+Instead of a pod name, a workload such as deployment/api or a label selector
+given with -l lists the containers of every matching pod, grouped by pod.
+This command shows the containers of the given pod as an aligned table with their
+type, readiness, status and image. Init and ephemeral containers are included,
+and their logs can be read with kubelog logs -c. Every container shows its restart
+count and, once restarted, the reason, exit code and time its last instance
+terminated.
 
 Example usage:
   kubelog containers my-pod -n my-namespace
   kubelog containers my-pod -n my-namespace --output json
   kubelog containers my-pod -n my-namespace -o yaml
-  kubelog containers my-pod -n my-namespace -o posix`,
-	Args: cobra.ExactArgs(1),
+  kubelog containers my-pod -n my-namespace -o posix
+  kubelog containers my-pod --show-resources
+  kubelog containers my-pod --wide
+  kubelog containers my-pod --sort-by restarts
+  kubelog containers my-pod --borders
+  kubelog containers -l app=api
+  kubelog containers deployment/api -o custom-columns=POD:.Pod,NAME:.Name,STATUS:.Status
+  kubelog containers my-pod -o custom-columns=NAME:.Name,STATUS:.Status,IMAGE:.Image`,
+	Args: cobra.MaximumNArgs(1),
 	Run:  runContainers,
 }
 
 func init() {
 	rootCmd.AddCommand(containersCmd)
 	containersCmd.Flags().StringP("namespace", "n", "", "Kubernetes namespace (defaults to current context's namespace)")
-	containersCmd.Flags().StringP("output", "o", "", "Output format: json, yaml, or posix")
+	containersCmd.Flags().StringP("selector", "l", "", "List containers of all pods matching this label selector (e.g. app=api)")
+	containersCmd.Flags().String("sort-by", "", "Sort containers by name, restarts, status or image")
+	containersCmd.Flags().Bool("wide", false, "Show declared ports and environment variable names (values are never shown)")
+	containersCmd.Flags().Bool("show-resources", false, "Show CPU and memory requests and limits of every container")
+	containersCmd.Flags().Bool("borders", false, "Draw unicode borders around the table")
+	containersCmd.Flags().Bool("no-headers", false, "Don't print the pod header or column headers")
+	containersCmd.Flags().StringP("output", "o", "", "Output format: json, yaml, posix, or custom-columns=HEADER:.Field,... (fields: .Name, .Type, .Ready, .Status, .Image; .Pod and .Namespace with several pods)")
+	_ = containersCmd.RegisterFlagCompletionFunc("output", completeOutputFormats("json", "yaml", "posix", format.CustomColumnsPrefix))
 
 	// Add completion for pod names
 	containersCmd.ValidArgsFunction = completePodNames
+	_ = containersCmd.RegisterFlagCompletionFunc("namespace", completeNamespaces)
 }
 
 func getContainerOptions(cmd *cobra.Command, args []string, contextNamespace string) (*containerOptions, error) {
@@ -58,15 +86,59 @@ func getContainerOptions(cmd *cobra.Command, args []string, contextNamespace str
 		return nil, fmt.Errorf("error getting output format flag: %v", err)
 	}
 
+	noHeaders, err := cmd.Flags().GetBool("no-headers")
+	if err != nil {
+		return nil, fmt.Errorf("error getting no-headers flag: %v", err)
+	}
+
+	resources, err := cmd.Flags().GetBool("show-resources")
+	if err != nil {
+		return nil, fmt.Errorf("error getting show-resources flag: %v", err)
+	}
+
+	wide, err := cmd.Flags().GetBool("wide")
+	if err != nil {
+		return nil, fmt.Errorf("error getting wide flag: %v", err)
+	}
+
+	sortBy, err := cmd.Flags().GetString("sort-by")
+	if err != nil {
+		return nil, fmt.Errorf("error getting sort-by flag: %v", err)
+	}
+
+	borders, err := cmd.Flags().GetBool("borders")
+	if err != nil {
+		return nil, fmt.Errorf("error getting borders flag: %v", err)
+	}
+
+	selector, err := cmd.Flags().GetString("selector")
+	if err != nil {
+		return nil, fmt.Errorf("error getting selector flag: %v", err)
+	}
+
+	var podName string
+	if len(args) > 0 {
+		podName = args[0]
+	}
+	if (podName == "") == (selector == "") {
+		return nil, fmt.Errorf("specify either a pod name or --selector")
+	}
+
 	return &containerOptions{
 		namespace:    namespace,
-		podName:      args[0],
+		podName:      podName,
 		outputFormat: outputFormat,
+		noHeaders:    noHeaders,
+		resources:    resources,
+		wide:         wide,
+		sortBy:       sortBy,
+		borders:      borders,
+		selector:     selector,
 	}, nil
 }
 
 func runContainers(cmd *cobra.Command, args []string) {
-	clientset, contextNamespace, err := kubernetes.GetKubernetesClient()
+	clientset, contextNamespace, err := kubeClient(cmd)
 	if err != nil {
 		color.Red("Error creating Kubernetes client: %v", err)
 		os.Exit(1)
@@ -78,13 +150,85 @@ func runContainers(cmd *cobra.Command, args []string) {
 		os.Exit(1)
 	}
 
-	containers, err := kubernetes.ListContainers(clientset, opts.namespace, opts.podName)
+	listOpts := format.ContainerListOptions{
+		ShowResources: opts.resources,
+		Wide:          opts.wide,
+		Borders:       opts.borders,
+		NoHeaders:     opts.noHeaders,
+	}
+
+	selector, err := containerSelector(cmd.Context(), clientset, opts)
+	if err != nil {
+		color.Red("Error resolving workload: %v", err)
+		os.Exit(1)
+	}
+	if selector != "" {
+		runSelectorContainers(cmd.Context(), clientset, opts, selector, listOpts)
+		return
+	}
+
+	containers, err := kubernetes.ListContainers(cmd.Context(), clientset, opts.namespace, opts.podName)
 	if err != nil {
 		color.Red("Error listing containers: %v", err)
+		if hint := errorHint(err); hint != "" {
+			fmt.Println(hint)
+		}
 		os.Exit(1)
 	}
 
+	if opts.sortBy != "" {
+		if err := kubernetes.SortContainers(containers, opts.sortBy); err != nil {
+			color.Red("Error sorting containers: %v", err)
+			os.Exit(1)
+		}
+	}
+
 	formatter := format.NewOutputFormatter(opts.podName, opts.namespace, containers)
+	formatter.List = listOpts
+	output, err := formatter.FormatOutput(opts.outputFormat)
+	if err != nil {
+		color.Red("Error formatting output: %v", err)
+		os.Exit(1)
+	}
+
+	fmt.Println(output)
+}
+
+// containerSelector returns the label selector of --selector or of a workload
+// reference such as deployment/api; plain pod names resolve to an empty selector
+func containerSelector(ctx context.Context, clientset k8s.Interface, opts *containerOptions) (string, error) {
+	if opts.selector != "" {
+		return opts.selector, nil
+	}
+	workload, ok, err := kubernetes.ParseWorkload(opts.podName)
+	if err != nil || !ok {
+		return "", err
+	}
+	return workload.Selector(ctx, clientset, opts.namespace)
+}
+
+// runSelectorContainers lists the containers of every pod matching the selector, grouped by pod
+func runSelectorContainers(ctx context.Context, clientset k8s.Interface, opts *containerOptions, selector string, listOpts format.ContainerListOptions) {
+	pods, err := kubernetes.ListSelectorContainers(ctx, clientset, opts.namespace, selector)
+	if err != nil {
+		color.Red("Error listing containers: %v", err)
+		if hint := errorHint(err); hint != "" {
+			fmt.Println(hint)
+		}
+		os.Exit(1)
+	}
+
+	if opts.sortBy != "" {
+		for _, pod := range pods {
+			if err := kubernetes.SortContainers(pod.Containers, opts.sortBy); err != nil {
+				color.Red("Error sorting containers: %v", err)
+				os.Exit(1)
+			}
+		}
+	}
+
+	formatter := format.NewPodsOutputFormatter(pods)
+	formatter.List = listOpts
 	output, err := formatter.FormatOutput(opts.outputFormat)
 	if err != nil {
 		color.Red("Error formatting output: %v", err)
@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/dantech2000/kubelog/pkg/format"
+	"github.com/dantech2000/kubelog/pkg/kubernetes"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var contextsCmd = &cobra.Command{
+	Use:   "contexts",
+	Short: "List kubeconfig contexts",
+	Long: `List the contexts of your kubeconfig. The context kubelog uses is marked
+with an asterisk: the one chosen with 'kubelog contexts use', or else the
+kubeconfig's current context.
+
+Example usage:
+  kubelog contexts
+  kubelog contexts -o posix
+  kubelog contexts use staging`,
+	Args: cobra.NoArgs,
+	Run:  runContexts,
+}
+
+var contextsUseCmd = &cobra.Command{
+	Use:   "use <context>",
+	Short: "Switch the context kubelog uses",
+	Long: `Switch the kubeconfig context kubelog uses by default. The choice is saved
+in the kubelog config file and does not change the current context of kubectl
+or your kubeconfig.
+
+Example usage:
+  kubelog contexts use staging`,
+	Args:              cobra.ExactArgs(1),
+	Run:               runContextsUse,
+	ValidArgsFunction: completeContextNames,
+}
+
+func init() {
+	rootCmd.AddCommand(contextsCmd)
+	contextsCmd.AddCommand(contextsUseCmd)
+	contextsCmd.Flags().StringP("output", "o", "", "Output format: json, yaml or posix")
+	_ = contextsCmd.RegisterFlagCompletionFunc("output", completeOutputFormats("json", "yaml", "posix"))
+	contextsCmd.Flags().Bool("no-headers", false, "Don't print the column headers")
+}
+
+func runContexts(cmd *cobra.Command, args []string) {
+	outputFormat, err := cmd.Flags().GetString("output")
+	if err != nil {
+		color.Red("Error getting output format flag: %v", err)
+		os.Exit(1)
+	}
+
+	noHeaders, err := cmd.Flags().GetBool("no-headers")
+	if err != nil {
+		color.Red("Error getting no-headers flag: %v", err)
+		os.Exit(1)
+	}
+
+	cfg, err := loadConfig(cmd)
+	if err != nil {
+		color.Red("Error loading config: %v", err)
+		os.Exit(1)
+	}
+
+	contexts, err := kubernetes.ListContexts(cfg.Context)
+	if err != nil {
+		color.Red("Error listing contexts: %v", err)
+		os.Exit(1)
+	}
+
+	output, err := format.FormatContexts(contexts, outputFormat, noHeaders)
+	if err != nil {
+		color.Red("Error formatting output: %v", err)
+		os.Exit(1)
+	}
+
+	fmt.Println(output)
+}
+
+func runContextsUse(cmd *cobra.Command, args []string) {
+	cfg, err := loadConfigFile(cmd)
+	if err != nil {
+		color.Red("Error loading config: %v", err)
+		os.Exit(1)
+	}
+
+	if err := validateContext(args[0]); err != nil {
+		color.Red("Error switching context: %v", err)
+		if hint := errorHint(err); hint != "" {
+			fmt.Println(hint)
+		}
+		os.Exit(1)
+	}
+
+	cfg.Context = args[0]
+	if err := saveConfig(cmd, cfg); err != nil {
+		color.Red("Error saving config: %v", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Switched kubelog to context %s\n", color.CyanString(args[0]))
+}
+
+// completeContextNames provides completion for kubeconfig context names
+func completeContextNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return completeContextFlag(cmd, args, toComplete)
+}
+
+// completeContextFlag completes the --context flag with kubeconfig context names
+func completeContextFlag(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	contexts, err := kubernetes.ListContexts("")
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	var names []string
+	for _, ctx := range contexts {
+		names = append(names, ctx.Name+"\t"+ctx.Cluster)
+	}
+
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/dantech2000/kubelog/pkg/version"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/spf13/cobra/doc"
+)
+
+var docsCmd = &cobra.Command{
+	Use:    "docs",
+	Short:  "Generate documentation for packaging",
+	Hidden: true,
+	Long: `Generate the documentation of every kubelog command from its help, for
+packages to install alongside the binary.
+
+Example usage:
+  kubelog docs man --dir dist/man
+  kubelog docs markdown --dir docs`,
+}
+
+var docsManCmd = &cobra.Command{
+	Use:   "man",
+	Short: "Generate man pages",
+	Long: `Generate a man page in section 1 for every kubelog command, e.g.
+kubelog.1 and kubelog-logs.1, to install into e.g. /usr/share/man/man1.
+The pages are dated $SOURCE_DATE_EPOCH when it is set, or else today.`,
+	Args: cobra.NoArgs,
+	Run:  runDocsMan,
+}
+
+var docsMarkdownCmd = &cobra.Command{
+	Use:   "markdown",
+	Short: "Generate markdown docs",
+	Long: `Generate a markdown page for every kubelog command, e.g. kubelog.md and
+kubelog_logs.md, linked to each other.`,
+	Args: cobra.NoArgs,
+	Run:  runDocsMarkdown,
+}
+
+func init() {
+	rootCmd.AddCommand(docsCmd)
+	docsCmd.AddCommand(docsManCmd, docsMarkdownCmd)
+	docsManCmd.Flags().String("dir", "man", "Directory to write the man pages to")
+	docsMarkdownCmd.Flags().String("dir", "docs", "Directory to write the markdown pages to")
+}
+
+func runDocsMan(cmd *cobra.Command, args []string) {
+	dir := docsDir(cmd)
+	header := &doc.GenManHeader{
+		Title:   "KUBELOG",
+		Section: "1",
+		Source:  "kubelog " + version.CurrentVersion.String(),
+		Manual:  "Kubelog Manual",
+	}
+	if err := doc.GenManTree(rootCmd, header, dir); err != nil {
+		color.Red("Error generating man pages: %v", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Wrote man pages to %s\n", dir)
+}
+
+func runDocsMarkdown(cmd *cobra.Command, args []string) {
+	dir := docsDir(cmd)
+	if err := doc.GenMarkdownTree(rootCmd, dir); err != nil {
+		color.Red("Error generating markdown docs: %v", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Wrote markdown docs to %s\n", dir)
+}
+
+// docsDir creates the --dir directory the docs are written to. The pages
+// leave out the line telling when they were generated, and man pages are
+// dated $SOURCE_DATE_EPOCH when it is set, so that builds are reproducible.
+func docsDir(cmd *cobra.Command) string {
+	dir, err := cmd.Flags().GetString("dir")
+	if err != nil {
+		color.Red("Error getting dir flag: %v", err)
+		os.Exit(1)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		color.Red("Error creating %s: %v", dir, err)
+		os.Exit(1)
+	}
+	rootCmd.DisableAutoGenTag = true
+	return dir
+}
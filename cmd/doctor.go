@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/dantech2000/kubelog/pkg/doctor"
+	"github.com/dantech2000/kubelog/pkg/format"
+	"github.com/dantech2000/kubelog/pkg/glyph"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Diagnose problems reading logs from the cluster",
+	Long: `Check everything kubelog needs to read logs and suggest fixes for problems.
+This is the first thing to run when kubelog shows nothing. It checks:
+- that the kubeconfig is valid and its context refers to a cluster and user
+- that the auth plugin of the user (e.g. aws, gke-gcloud-auth-plugin) is installed
+- that the API server is reachable and accepts the credentials
+- that you may list pods and read pods/log in the namespace
+- that the local clock agrees with the API server, which --since relies on
+
+The command exits with a non-zero status when a check fails.
+
+Example usage:
+  kubelog doctor
+  kubelog doctor -n my-namespace`,
+	Args: cobra.NoArgs,
+	Run:  runDoctor,
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+	doctorCmd.Flags().StringP("namespace", "n", "", "Namespace to check permissions in (defaults to current context's namespace)")
+	doctorCmd.Flags().Duration("timeout", 5*time.Second, "Timeout for every request to the API server")
+}
+
+// doctorSymbol marks the status of a check
+func doctorSymbol(status doctor.Status) string {
+	switch status {
+	case doctor.OK:
+		return color.GreenString(glyph.Check.String())
+	case doctor.Warning:
+		return color.YellowString("!")
+	case doctor.Failed:
+		return color.RedString(glyph.Cross.String())
+	default:
+		return color.New(color.Faint).Sprint("-")
+	}
+}
+
+func runDoctor(cmd *cobra.Command, args []string) {
+	namespace, err := cmd.Flags().GetString("namespace")
+	if err != nil {
+		color.Red("Error getting namespace flag: %v", err)
+		os.Exit(1)
+	}
+
+	timeout, err := cmd.Flags().GetDuration("timeout")
+	if err != nil {
+		color.Red("Error getting timeout flag: %v", err)
+		os.Exit(1)
+	}
+
+	cfg, err := loadConfig(cmd)
+	if err != nil {
+		color.Red("Error loading config: %v", err)
+		os.Exit(1)
+	}
+
+	results := doctor.Run(cmd.Context(), doctor.Options{
+		Context:   cfg.Context,
+		Namespace: namespace,
+		Timeout:   timeout,
+	})
+
+	width := 0
+	for _, result := range results {
+		if len(result.Name) > width {
+			width = len(result.Name)
+		}
+	}
+
+	failed := false
+	for _, result := range results {
+		message := result.Message
+		if result.Status == doctor.Skipped {
+			message = "skipped: " + message
+		}
+		fmt.Printf("%s %s   %s\n", doctorSymbol(result.Status), format.Pad(result.Name, width), message)
+		if result.Fix != "" {
+			fmt.Printf("  %s %s\n", color.CyanString("Fix:"), result.Fix)
+		}
+		if result.Status == doctor.Failed {
+			failed = true
+		}
+	}
+
+	if failed {
+		os.Exit(1)
+	}
+}
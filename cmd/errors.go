@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"errors"
+
+	"github.com/dantech2000/kubelog/pkg/kubernetes"
+)
+
+// errorHint returns a suggestion for resolving well-known errors, or an empty string
+func errorHint(err error) string {
+	switch {
+	case errors.Is(err, kubernetes.ErrPodNotFound):
+		return "Hint: check the pod name and namespace (use -n to select a namespace)"
+	case errors.Is(err, kubernetes.ErrContainerNotFound):
+		return "Hint: run 'kubelog containers <pod>' to list the containers in the pod"
+	case errors.Is(err, kubernetes.ErrNoPreviousInstance):
+		return "Hint: omit -p to see the logs of the running container"
+	case errors.Is(err, kubernetes.ErrWorkloadNotFound):
+		return "Hint: check the resource name and namespace (use -n to select a namespace)"
+	case errors.Is(err, kubernetes.ErrRevisionNotFound):
+		return "Hint: run 'kubectl rollout history deployment/<name>' to list the available revisions"
+	case errors.Is(err, kubernetes.ErrContextNotFound):
+		return "Hint: run 'kubelog contexts' to list the contexts in your kubeconfig"
+	case errors.Is(err, kubernetes.ErrTooManyStreams):
+		return "Hint: narrow the selector, pick a container with -c, or raise --max-log-requests"
+	case errors.Is(err, kubernetes.ErrForbidden):
+		return "Hint: kubelog needs permission to get pods and pods/log in this namespace"
+	default:
+		return ""
+	}
+}
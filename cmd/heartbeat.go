@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/dantech2000/kubelog/pkg/glyph"
+	"github.com/dantech2000/kubelog/pkg/logging"
+	"github.com/fatih/color"
+)
+
+const (
+	// heartbeatAfter is how long a followed stream is quiet before the status line is shown
+	heartbeatAfter = 10 * time.Second
+	// heartbeatInterval is how often the status line is refreshed
+	heartbeatInterval = time.Second
+)
+
+// heartbeatColor keeps the status line subtle
+var heartbeatColor = color.New(color.Faint)
+
+// heartbeat shows a "waiting for logs" status line on the terminal while
+// nothing is written, so a quiet stream can be told apart from a broken one.
+// Output written through it first clears the status line.
+type heartbeat struct {
+	mu      sync.Mutex
+	out     io.Writer
+	status  io.Writer
+	start   time.Time
+	written time.Time
+	shown   bool
+}
+
+// newHeartbeat creates a heartbeat writing log output to out and the status
+// line to status, which must be a terminal
+func newHeartbeat(out, status io.Writer) *heartbeat {
+	now := time.Now()
+	return &heartbeat{out: out, status: status, start: now, written: now}
+}
+
+// Write implements io.Writer for the log output
+func (h *heartbeat) Write(p []byte) (int, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.clear()
+	h.written = time.Now()
+	return h.out.Write(p)
+}
+
+// Stderr returns a writer for other messages on the status stream, which
+// first clears the status line
+func (h *heartbeat) Stderr() io.Writer {
+	return heartbeatStderr{h}
+}
+
+// heartbeatStderr writes to the status stream of a heartbeat
+type heartbeatStderr struct {
+	h *heartbeat
+}
+
+// Write implements io.Writer
+func (w heartbeatStderr) Write(p []byte) (int, error) {
+	w.h.mu.Lock()
+	defer w.h.mu.Unlock()
+	w.h.clear()
+	return w.h.status.Write(p)
+}
+
+// run refreshes the status line until ctx is done, reporting when tracker
+// last saw a line; the status line is cleared before it returns
+func (h *heartbeat) run(ctx context.Context, tracker *logging.ActivityTracker) {
+	ticker := time.NewTicker(heartbeatInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			h.Clear()
+			return
+		case <-ticker.C:
+			h.refresh(tracker.LastSeen())
+		}
+	}
+}
+
+// refresh redraws the status line when nothing was written for heartbeatAfter
+func (h *heartbeat) refresh(lastLine time.Time) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if time.Since(h.written) < heartbeatAfter {
+		return
+	}
+
+	detail := "no lines yet"
+	if lastLine.After(h.start) {
+		detail = fmt.Sprintf("last line %s ago", time.Since(lastLine).Truncate(time.Second))
+	}
+	fmt.Fprint(h.status, "\r\033[K"+heartbeatColor.Sprintf("waiting for logs%s (%s)", glyph.Ellipsis, detail))
+	h.shown = true
+}
+
+// Clear removes the status line if it is shown
+func (h *heartbeat) Clear() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.clear()
+}
+
+// clear removes the status line; the caller holds h.mu
+func (h *heartbeat) clear() {
+	if h.shown {
+		fmt.Fprint(h.status, "\r\033[K")
+		h.shown = false
+	}
+}
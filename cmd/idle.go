@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/dantech2000/kubelog/pkg/logging"
+)
+
+// idleCheckInterval is how often a follow session is checked for inactivity
+const idleCheckInterval = time.Second
+
+// Actions taken when a follow session is idle for --idle-timeout
+const (
+	idleExit = "exit"
+	idleWarn = "warn"
+)
+
+// watchIdle ends the session with cancel, or writes a warning to w when action
+// is idleWarn, once no entry arrived for timeout. It returns when ctx is done.
+func watchIdle(ctx context.Context, cancel context.CancelFunc, tracker *logging.ActivityTracker, timeout time.Duration, action string, w io.Writer) {
+	ticker := time.NewTicker(idleCheckInterval)
+	defer ticker.Stop()
+
+	var warned time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if tracker.Idle() < timeout {
+				continue
+			}
+			if action == idleExit {
+				fmt.Fprintf(w, "No logs for %s, ending the session\n", timeout)
+				cancel()
+				return
+			}
+			// Warn once for every quiet period
+			if last := tracker.LastSeen(); !last.Equal(warned) {
+				fmt.Fprintf(w, "Warning: no logs for %s (last line at %s)\n", timeout, last.Format("15:04:05"))
+				warned = last
+			}
+		}
+	}
+}
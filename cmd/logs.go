@@ -2,34 +2,314 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"log/slog"
 	"os"
 	"strings"
+	"time"
 
+	"github.com/dantech2000/kubelog/pkg/checkpoint"
+	"github.com/dantech2000/kubelog/pkg/config"
+	"github.com/dantech2000/kubelog/pkg/format"
 	"github.com/dantech2000/kubelog/pkg/kubernetes"
+	"github.com/dantech2000/kubelog/pkg/logging"
+	"github.com/dantech2000/kubelog/pkg/sink"
+	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8s "k8s.io/client-go/kubernetes"
 )
 
 // logOptions holds the command options for the logs command
 type logOptions struct {
 	namespace string
+	allNs     bool
+	fallback  []string
 	container string
 	follow    bool
 	level     string
 	podName   string
+	selector  string
+	revision  int64
+	compare   bool
+	reorder   time.Duration
+	dedup     time.Duration
+	track     []string
+	skew      string
+	exclude   []string
+	sidecars  bool
+	maxReqs   int
+	ignoreErr bool
+	noMarkers bool
+	lastWords int64
+	tees      []string
+	noPager   bool
+	pager     string
+	lineNums  bool
 	previous  bool
+	since     time.Duration
+	resume    bool
+	stateFile string
+	idle      time.Duration
+	onIdle    string
+	heartbeat bool
+	session   bool
+	counts    bool
+	grep      []string
+	matchAll  bool
+	grepOpts  logging.GrepOptions
+	trace     string
+	loggers   []string
+	fields    []string
+	sample    uint64
+	suppress  []string
+	status    []string
+	summary   bool
+	strict    bool
+	strictLn  bool
+	checker   *logging.ParseChecker
+	log4j     []string
+	regexes   []string
+	parsers   []string
+	expand    bool
+	collapse  bool
+	pretty    bool
+	decode    []string
+	humanize  bool
+	allFields bool
+	icons     bool
+	timeFmt   logging.TimeFormat
+	keywords  []string
+	output    string
+	labels    []string
+	gelf      string
+	file      string
+	rotation  rotationOptions
+	record    string
+	columns   []string
+	noHeaders bool
+	jq        string
+	raw       bool
+	prefix    prefixOptions
+}
+
+// prefixOptions holds the settings for the per-stream prefix in multi-pod mode
+type prefixOptions struct {
+	template      string
+	shortPodNames bool
+	width         int
 }
 
 var logsCmd = &cobra.Command{
-	Use:   "logs [container_id]",
+	Use:   "logs [pod-name | type/name]",
 	Short: "Display logs for a specific container",
 	Long: `Display logs for a specific container. You can filter logs by level using the --level flag.
-Supported levels are DEBUG, INFO, WARN, and ERROR.`,
-	Args: cobra.ExactArgs(1),
+Supported levels are DEBUG, INFO, WARN, and ERROR.
+
+Instead of a pod name, a workload such as deployment/api, statefulset/db or
+job/migrate can be given, or --selector to stream the logs of every pod matching a
+label selector at once. For deployments, --revision N limits the stream to the
+pods of a single ReplicaSet revision, e.g. only the new pods during a rollout.
+With --compare-revisions, the stable and canary pods of a deployment or Argo
+Rollout (rollout/name) are streamed together in distinct colors, and the error
+rate of each revision is reported on stderr. Knative services are targeted with
+ksvc/name. Infrastructure sidecars (istio-proxy, and queue-proxy for Knative) are
+skipped when streaming multiple pods unless --include-sidecars is set. Each line
+is then prefixed with its source, which can be customized with --prefix-template,
+e.g. '{{.Namespace}}/{{.Pod | short}}[{{.Container}}]'. To protect the client and
+the API server, kubelog refuses to open more than 25 container streams at once and
+lists the ones that would be skipped; raise the limit with --max-log-requests. The
+first stream to fail, e.g. for lack of permission or a missing container, stops
+all of them; with --ignore-errors the failure is reported on stderr and the other
+streams continue.
+
+-A (--all-namespaces) streams the pods matching --selector in every namespace, prefixed
+with their namespace. Users who may not list pods cluster-wide get the pods of the
+namespaces listed under namespaces: in the config file instead, or of the namespaces
+they may list or else the current one; kubelog says on stderr which it searched, and
+leaves out those whose pods are forbidden too.
+
+--resume continues every container stream after the last line the previous
+--resume session saw, so restarting kubelog neither repeats nor misses lines. The
+position of every pod and container is kept in a state file, by default
+kubelog/checkpoints.json in the user cache directory, or the file given with
+--state-file. New pods, e.g. after a rollout, start from their first line.
+
+Lines of several pods are written in the order they arrive, so lines logged at the
+same time can show up out of order. --reorder-window 2s holds every line for two
+seconds and writes the lines held in timestamp order, trading latency for ordering.
+Lines arriving more than the window late are written as they come, and how many of
+them there were is printed on stderr at the end.
+
+While following a --selector, marker lines show when a pod starts being followed
+and when its container terminates or restarts, so gaps in the stream are explained
+inline: + pod api-xyz started, - pod api-abc terminated (OOMKilled). The stream of a
+restarted container is attached again. --no-markers leaves the markers out, and
+the stream of a container ends with it. When a container is OOMKilled, the last 20
+lines it logged, or --last-words, follow its marker in a highlighted block, as the
+kill often cuts its stream short of them.
+
+Replicas behind one selector often log the same message at the same time, e.g. when
+a shared dependency fails. --dedup-across-pods collapses identical messages that
+several pods log within two seconds, or the --dedup-window, into the line of the
+first pod, ending in the number of replicas that logged it, e.g. (×3 replicas).
+Every line is held for the window.
+
+--track FIELD watches a field, or a dotted path such as raft.leader, and writes a
+marker line before each entry whose value differs from the previous entry of the same
+pod's container: ~ status 200 → 503 (api-1). Flapping health checks and leader
+changes stand out without reading every line. Entries without the field keep the
+last value; --track may be repeated.
+
+Containers with a drifting clock or logging local time without a zone write
+timestamps that are systematically off. --clock-skew report compares the timestamp
+of every entry with the time the container runtime received its line and reports
+the pods off by more than a second on stderr at the end. --clock-skew correct also
+orders lines by timestamps corrected for the skew, with --trace or --reorder-window;
+the timestamps shown are left as logged.
+
+With --follow, --idle-timeout 10m ends the session once no lines arrived for ten
+minutes, so forgotten terminals don't hold streams open; --idle-action warn prints
+a warning on stderr instead and keeps following. While a followed stream is quiet
+for 10s, a "waiting for logs… (last line 2m ago)" status line is shown on the
+terminal; the last line counts lines hidden by filters. --heartbeat=false hides it.
+Pods that have completed, failed or been evicted log nothing more: their phase is
+noted on stderr and the logs they left are shown without following.
+When the session is interrupted with Ctrl-C or ends through --idle-timeout, a
+summary of its duration, lines per level and per pod, and the top 3 error messages
+(grouped by their text with numbers, IDs and addresses masked) is printed on
+stderr; --session-summary=false turns it off.
+
+Further filters can be combined and are applied in order:
+  --grep     keep lines matching a regular expression; -i ignores case, -F
+             matches a literal string, which is faster on busy streams, and -V
+             (--invert-match) keeps the lines not matching instead. Repeated, lines
+             matching any pattern are kept, or with --match-all only lines
+             matching every one, e.g. -g timeout -g checkout-service --match-all
+  --trace    keep lines of a single trace or request ID; without --follow the lines
+             of all pods are ordered by timestamp
+  --logger   keep entries of loggers matching a pattern such as 'pkg.foo.*', or drop
+             them with a ! prefix ('!io.netty'); may be repeated
+  --field    keep entries whose field matches an expression (key=value, key!=value,
+             key~regex, key>n, key>=n, key<n, key<=n); may be repeated. Keys of
+             nested JSON objects are reached with dots, e.g. http.request.method=POST
+  --sample   keep only one out of every N entries
+
+Envoy/Istio access logs, Spring Boot, Rails/Puma and common Log4j layouts are recognized
+automatically. Applications using a custom Log4j PatternLayout can be parsed with
+--log4j-pattern, e.g. '%d{ISO8601} %-5p [%t] %c - %m%n', and any other format with
+--parse-regex, whose named groups become fields; the groups level, time and
+message fill in the entry's level, timestamp and message. --parser klog parses the
+lines of the Kubernetes components, which kubelog system does by itself. Named
+groups of --grep patterns become fields of the lines they match as well, e.g.
+--grep 'order (?P<order>ord-\d+) took (?P<ms>\d+)ms' --field 'ms>500'. Python
+tracebacks and Java stack traces are folded into a single entry showing the
+exception; --expand-tracebacks shows the frames and --collapse-frames shortens
+Java traces by collapsing framework and repeated frames. The keys of JSON objects
+embedded in plain text messages can be used with --field, and --pretty-json
+indents them. Fields holding encoded payloads are decoded for display with
+--decode-field, e.g. payload=base64, and --humanize shows durations and byte
+counts as e.g. 1.2s and 4.3MB; filters still compare the raw values. JSON fields
+are shown in key order, leaving out the level, timestamp and message fields
+already shown at the front of the line; --show-all-fields shows every field.
+--icons starts every line with an icon of its level, taken from the color theme.
+--time-format shows timestamps in a Go layout, e.g. --time-format 15:04:05.000, or
+a preset: rfc3339, rfc3339nano, short (the time of day), kitchen, epoch or
+epoch-ms. Timestamps are shown to the second unless --time-precision ms, us or ns
+shows their fraction, e.g. 10:30:45.123 for ms; with epoch, it sets the unit
+counted.
+
+Messages of ERROR entries are shown in red, as are messages of entries without a
+level of their own that mention "error" or "failed" as a word; entries logged with
+another level are never. --error-keyword replaces these keywords with regular
+expressions of your own, e.g. --error-keyword 'timed? ?out', and
+--no-error-keywords only highlights ERROR entries.
+
+--status only shows access log entries (Envoy, NGINX, Rails and JSON logs with a
+status field) whose HTTP status code matches, e.g. --status 5xx or --status 400-499.
+--summary prints a table of 2xx, 3xx, 4xx and 5xx counts per pod to stderr, every
+5s while following and once more at the end, with the p50, p95 and p99 of the last
+1000 durations logged in fields such as duration_ms or latency. It counts every
+entry, including those hidden by filters. --counts prints how many lines each pod
+and container contributed in the same way, and their share of all lines, making it
+obvious which replica is misbehaving.
+
+--strict validates logging conventions: lines with malformed JSON, unknown or
+missing levels and unparsable timestamps, which are otherwise shown with the
+DEBUG level or without a time, are counted and reported on stderr at the end, and
+kubelog exits with status 1 if there were any. --strict-lines also prints every
+such line to stderr as it arrives, after its problems.
+
+--suppress hides built-in classes of noisy lines: probes (kube-probe user agents),
+health-checks (/healthz, /readyz, /actuator/health, ...), elb (AWS load balancer
+health checks) and istio-heartbeat (istio-proxy readiness checks and Prometheus
+scrapes), e.g. --suppress health-checks,probes.
+
+With --output json, jsonl or logfmt, entries are written as structured records
+carrying their namespace, pod, container and node instead of a line prefix;
+--add-labels attaches selected pod labels as well, e.g. --add-labels team,version.
+jsonl writes one compact JSON object per line with sorted keys, for piping into
+jq. --output ecs writes one Elastic Common Schema document per line (@timestamp,
+log.level, message, trace.id, kubernetes.*) for ingestion into Elasticsearch, and
+--output gelf writes GELF messages for Graylog. --output csv writes a CSV table of
+the --fields columns, e.g. --fields ts,level,pod,msg,status, and -o
+custom-columns=TIME:.Timestamp,MSG:.Message shows the given entry paths in aligned
+columns as kubectl does; .Fields.<name> and .Source.Pod refer to parsed fields and
+the entry's pod; dotted paths such as .Fields.kubernetes.labels.app, like those of
+--fields and --field, reach into nested objects. --no-headers leaves out the
+header row of both, for awk and cut.
+
+--gelf-address sends the messages of --output gelf to a Graylog GELF input over
+UDP or TCP instead of stdout, e.g. --gelf-address tcp://graylog:12201.
+
+--output-file captures the logs to a file without colors. For long captures,
+--rotate-every 1h starts a new file every hour, named after the start of its
+window, e.g. capture-20240115-100000.log for --output-file capture.log; every CSV
+file starts with the header row. --rotate-size 100MB also starts a new file, named
+after its start time, when the current one would grow beyond 100MB. Rotated files
+that are done can be compressed with --rotate-compress gzip or zstd, and
+--rotate-keep 10 removes all but the newest ten of them.
+
+--tee FORMAT=DESTINATION also writes the entries shown to a sink of its own, in
+any --output format and without colors, e.g. --tee json=app.jsonl saves JSON while
+the terminal shows text. --tee gelf=tcp://graylog:12201 sends GELF to Graylog;
+every other format is written to a file. --tee can be given several times.
+
+--record session.klog records the session alongside the output: the entries shown,
+as the containers logged them, with their timestamps, pods and containers, after
+a header describing the session. Unlike the output, the recording stays
+machine-readable whatever the output format, so it can be parsed and filtered again.
+
+--raw writes the lines exactly as the container logged them, skipping parsing,
+filters and coloring, with the pod prefix when streaming several pods. It is the
+fastest way to dump large amounts of history to a file:
+kubelog logs my-pod --raw > history.log.
+
+Without -f, logs longer than the terminal screen are shown in $PAGER, or less -R
+when it isn't set, keeping their colors; shorter ones are printed as usual.
+--no-pager, or PAGER set to cat or empty, always prints them. --pager runs
+another command instead of $PAGER, and --pager builtin uses kubelog's own pager
+once all lines are read: / searches, n and N go to the next and previous match,
+t jumps to a time such as 14:05, d, i, w and e hide or show the lines of a level,
+and q quits.
+
+--line-numbers numbers the lines shown, counting from 1 for the whole session, so
+they can be referred to as e.g. line 482 when sharing a screen or pasting an
+excerpt. The lines of a multi-line entry such as a traceback share its number.
+
+--jq runs a jq query over every entry with an embedded jq, keeping the colors of
+the output: --jq '.fields.request | {path, status}'. The query sees the entry as
+{timestamp, level, message, logger, fields, kubernetes}; string results are
+printed unquoted and entries without results, e.g. through select, are skipped.`,
+	Args: cobra.MaximumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		if err := runLogs(cmd, args); err != nil {
-			fmt.Printf("Error running logs command: %v\n", err)
+			fmt.Fprintf(os.Stderr, "Error running logs command: %v\n", err)
+			if hint := errorHint(err); hint != "" {
+				fmt.Fprintln(os.Stderr, hint)
+			}
 			os.Exit(1)
 		}
 	},
@@ -42,16 +322,94 @@ func init() {
 	logsCmd.Flags().BoolP("follow", "f", false, "Follow the log output in real-time")
 	logsCmd.Flags().StringP("level", "l", "DEBUG", "Filter logs by level (DEBUG, INFO, WARN, ERROR)")
 	logsCmd.Flags().BoolP("previous", "p", false, "Get previous terminated container logs")
+	logsCmd.Flags().String("selector", "", "Stream logs from all pods matching this label selector (e.g. app=api)")
+	logsCmd.Flags().BoolP("all-namespaces", "A", false, "Stream the pods matching --selector in all namespaces")
+	logsCmd.Flags().Int64("revision", 0, "Only stream pods of this ReplicaSet revision of a deployment")
+	logsCmd.Flags().Bool("compare-revisions", false, "Stream the stable and canary revisions of a deployment or rollout side by side and report their error rates")
+	logsCmd.Flags().Bool("no-markers", false, "Don't write marker lines when pods of a --selector start being followed, terminate or restart")
+	logsCmd.Flags().Int64("last-words", kubernetes.DefaultLastWords, "Last lines of an OOMKilled container to show in a block after its marker while following a --selector (0 shows none)")
+	logsCmd.Flags().Bool("dedup-across-pods", false, "Collapse identical messages that several pods log within --dedup-window into one line with the number of replicas")
+	logsCmd.Flags().Duration("dedup-window", kubernetes.DefaultDedupWindow, "How long --dedup-across-pods waits for other replicas to log the same message")
+	logsCmd.Flags().StringArray("track", nil, "Mark the entries where this field's value changes from the previous entry of the same pod, e.g. status or raft.leader (repeatable)")
+	logsCmd.Flags().Duration("reorder-window", 0, "Hold the lines of multiple pods this long to write them in timestamp order, e.g. 2s")
+	logsCmd.Flags().String("clock-skew", "", "Detect pods whose logged timestamps are off from the node's clock: report, or correct to also order lines by corrected timestamps")
+	logsCmd.Flags().Int("max-log-requests", kubernetes.DefaultMaxStreams, "Refuse to stream more than this many containers at once, to protect the client and the API server")
+	logsCmd.Flags().String("pager", "", "Pager for historical logs longer than a screen: builtin, or a command used instead of $PAGER")
+	logsCmd.Flags().Bool("line-numbers", false, "Number the lines shown, counting from 1 for the session, to refer to them when sharing a screen or excerpt")
+	logsCmd.Flags().Bool("no-pager", false, "Don't show logs longer than a screen in $PAGER (less -R by default) when not following")
+	logsCmd.Flags().Bool("ignore-errors", false, "When streaming multiple pods, report streams that fail to stderr and keep the others going")
+	logsCmd.Flags().StringArray("exclude-container", nil, "Skip containers with this name when streaming multiple pods (repeatable)")
+	logsCmd.Flags().Bool("include-sidecars", false, "Also stream infrastructure sidecars that are skipped by default (istio-proxy, Knative queue-proxy)")
+	logsCmd.Flags().String("prefix-template", format.DefaultPrefixTemplate, "Template for the per-line prefix in multi-pod mode (fields: .Namespace, .Pod, .Container; function: short)")
+	logsCmd.Flags().Bool("short-pod-names", false, "Strip replica hashes from pod names in prefixes")
+	logsCmd.Flags().Int("prefix-width", 0, "Pad prefixes to a fixed width for aligned output")
+	logsCmd.Flags().Duration("since", 0, "Only return logs newer than a relative duration like 5s, 2m, or 3h")
+	logsCmd.Flags().Bool("resume", false, "Continue every container stream after the last line seen by the previous --resume session")
+	logsCmd.Flags().String("state-file", "", "Where --resume keeps the last line seen per container (default kubelog/checkpoints.json in the user cache directory)")
+	logsCmd.Flags().Duration("idle-timeout", 0, "End a follow session when no lines arrive for this long, e.g. 10m")
+	logsCmd.Flags().String("idle-action", idleExit, "What to do when --idle-timeout passes: exit, or warn and keep following")
+	logsCmd.Flags().Bool("heartbeat", true, "Show a \"waiting for logs\" status line on the terminal while a followed stream is quiet")
+	logsCmd.Flags().Bool("session-summary", true, "When a follow session is interrupted, print its duration, lines per level and pod, and top errors to stderr")
+	logsCmd.Flags().StringArrayP("grep", "g", nil, "Only show lines matching this regular expression (repeatable; lines matching any of them are shown)")
+	logsCmd.Flags().BoolP("grep-ignore-case", "i", false, "Match --grep regardless of case")
+	logsCmd.Flags().BoolP("grep-fixed", "F", false, "Match --grep as a literal string instead of a regular expression; faster on busy streams")
+	logsCmd.Flags().BoolP("invert-match", "V", false, "Only show lines not matching --grep, like grep -v (-v is kubelog's --verbose)")
+	logsCmd.Flags().Bool("match-any", false, "Show lines matching any of the --grep patterns (the default)")
+	logsCmd.Flags().Bool("match-all", false, "Only show lines matching all of the --grep patterns")
+	logsCmd.Flags().String("trace", "", "Only show lines of this trace or request ID, ordered by timestamp across pods")
+	logsCmd.Flags().StringArray("logger", nil, "Only show entries of loggers matching this pattern, or hide them with a ! prefix (repeatable)")
+	logsCmd.Flags().StringArray("field", nil, "Only show entries whose field matches an expression (e.g. status>=500)")
+	logsCmd.Flags().StringSlice("status", nil, "Only show access log entries with these HTTP status codes, e.g. 5xx, 404 or 400-499")
+	logsCmd.Flags().Bool("summary", false, "Print a table of HTTP status code counts and latency percentiles per pod to stderr, every 5s while following")
+	logsCmd.Flags().Bool("counts", false, "Print how many lines each pod and container contributed to stderr, every 5s while following")
+	logsCmd.Flags().Bool("strict", false, "Count the lines with malformed JSON, unknown or missing levels and unparsable timestamps, report them on stderr and exit with status 1")
+	logsCmd.Flags().Bool("strict-lines", false, "With --strict, also print every line that didn't parse cleanly to stderr, with its problems")
+	logsCmd.Flags().StringSlice("suppress", nil, "Hide built-in classes of noisy lines: "+strings.Join(logging.SuppressPresetNames(), ", "))
+	logsCmd.Flags().Uint64("sample", 0, "Only show one out of every N entries")
+	logsCmd.Flags().StringArray("log4j-pattern", nil, "Parse lines written with this Log4j PatternLayout (repeatable)")
+	logsCmd.Flags().StringArray("parse-regex", nil, "Parse lines matching this regular expression, whose named groups become fields, e.g. '(?P<level>\\w+) (?P<message>.*)' (repeatable)")
+	logsCmd.Flags().StringSlice("parser", nil, "Parse lines with built-in parsers that aren't detected automatically: "+strings.Join(logging.ParserNames(), ", "))
+	logsCmd.Flags().Bool("expand-tracebacks", false, "Show the frames of folded tracebacks and stack traces instead of only the exception")
+	logsCmd.Flags().Bool("collapse-frames", false, "Collapse framework and repeated frames in expanded Java stack traces")
+	logsCmd.Flags().Bool("pretty-json", false, "Pretty-print JSON objects embedded in plain text messages")
+	logsCmd.Flags().StringArray("decode-field", nil, "Decode a field holding an encoded payload, e.g. payload=base64 (repeatable)")
+	logsCmd.Flags().Bool("humanize", false, "Show duration and byte count fields in human-readable units")
+	logsCmd.Flags().Bool("show-all-fields", false, "Show every JSON field in text output, including those the default view leaves out")
+	logsCmd.Flags().Bool("icons", false, "Start every line with an icon of its level, e.g. 🔥 for errors, for quicker scanning")
+	logsCmd.Flags().String("time-format", "", "Show timestamps in a Go layout, e.g. 15:04:05.000, or a preset: rfc3339, rfc3339nano, short, kitchen, epoch or epoch-ms")
+	logsCmd.Flags().String("time-precision", "", "Show timestamps to the second (s), millisecond (ms), microsecond (us) or nanosecond (ns)")
+	logsCmd.Flags().StringArray("error-keyword", nil, "Show messages of entries without a level in red when they match this case-insensitive regular expression, replacing the defaults (repeatable)")
+	logsCmd.Flags().Bool("no-error-keywords", false, "Only show messages of ERROR entries in red, never those of entries without a level that mention errors")
+	logsCmd.Flags().StringP("output", "o", "", "Output format: text (default), json, jsonl, logfmt, ecs, gelf, csv or custom-columns=HEADER:.Field,...")
+	logsCmd.Flags().StringSlice("fields", nil, "Columns of --output csv, e.g. ts,level,pod,msg,status (default ts,level,namespace,pod,container,msg)")
+	logsCmd.Flags().StringArray("tee", nil, "Also write the entries shown to a sink in a format of its own: FORMAT=FILE, or gelf=ADDRESS for Graylog, e.g. json=app.jsonl (repeatable)")
+	logsCmd.Flags().Bool("no-headers", false, "Don't print the header row of csv and custom-columns output")
+	logsCmd.Flags().String("jq", "", "Transform every entry with a jq query, e.g. '.fields.request | {path, status}'")
+	logsCmd.Flags().Bool("raw", false, "Write the lines as they are, without parsing, filtering or coloring them, for the fastest dumps")
+	logsCmd.Flags().String("output-file", "", "Write the logs to this file instead of stdout, without colors")
+	logsCmd.Flags().Duration("rotate-every", 0, "Start a new --output-file every time window, e.g. 1h, named after the window's start time")
+	logsCmd.Flags().String("rotate-size", "", "Start a new --output-file when it would grow beyond this size, e.g. 100MB")
+	logsCmd.Flags().String("rotate-compress", sink.CompressNone, "Compress rotated files that are done: none, gzip or zstd")
+	logsCmd.Flags().Int("rotate-keep", 0, "Only keep this many rotated files that are done, removing the oldest (0 keeps all)")
+	logsCmd.Flags().String("record", "", "Record the entries shown, with their pods and timestamps, to a .klog session file")
+	logsCmd.Flags().String("gelf-address", "", "Send entries in GELF to a Graylog input instead of stdout, e.g. udp://graylog:12201 or tcp://graylog:12201")
+	logsCmd.Flags().StringSlice("add-labels", nil, "Pod labels to attach to structured output (e.g. team,version)")
 
 	// Add completion for pod names
 	logsCmd.ValidArgsFunction = completePodNames
 	// Add completion for container names
 	_ = logsCmd.RegisterFlagCompletionFunc("container", completeContainerNames)
+	_ = logsCmd.RegisterFlagCompletionFunc("namespace", completeNamespaces)
+	_ = logsCmd.RegisterFlagCompletionFunc("suppress", completeSuppressPresets)
+	_ = logsCmd.RegisterFlagCompletionFunc("time-format", completeTimeFormats)
+	_ = logsCmd.RegisterFlagCompletionFunc("time-precision", cobra.FixedCompletions([]string{"s", "ms", "us", "ns"}, cobra.ShellCompDirectiveNoFileComp))
+	_ = logsCmd.RegisterFlagCompletionFunc("level", completeLevels)
+	_ = logsCmd.RegisterFlagCompletionFunc("output", completeOutputFormats("text", "json", "jsonl", "logfmt", "ecs", "gelf", "csv", format.CustomColumnsPrefix))
 }
 
 // completePodNames provides dynamic completion for pod names
 func completePodNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-	clientset, _, err := kubernetes.GetKubernetesClient()
+	clientset, _, err := kubeClient(cmd)
 	if err != nil {
 		return nil, cobra.ShellCompDirectiveError
 	}
@@ -62,7 +420,7 @@ func completePodNames(cmd *cobra.Command, args []string, toComplete string) ([]s
 	}
 
 	// Add field selector to filter pods by name prefix for faster results
-	pods, err := clientset.CoreV1().Pods(namespace).List(context.Background(), metav1.ListOptions{
+	pods, err := clientset.CoreV1().Pods(namespace).List(cmd.Context(), metav1.ListOptions{
 		FieldSelector: fmt.Sprintf("metadata.name=%s*", toComplete),
 		Limit:         50, // Limit results for faster response
 	})
@@ -86,7 +444,7 @@ func completeContainerNames(cmd *cobra.Command, args []string, toComplete string
 		return nil, cobra.ShellCompDirectiveError
 	}
 
-	clientset, _, err := kubernetes.GetKubernetesClient()
+	clientset, _, err := kubeClient(cmd)
 	if err != nil {
 		return nil, cobra.ShellCompDirectiveError
 	}
@@ -96,24 +454,58 @@ func completeContainerNames(cmd *cobra.Command, args []string, toComplete string
 		namespace = "default"
 	}
 
-	podName := args[0]
-	containers, err := clientset.CoreV1().Pods(namespace).List(context.Background(), metav1.ListOptions{
-		FieldSelector: fmt.Sprintf("metadata.name=%s", podName),
-	})
+	containers, err := kubernetes.ListContainers(cmd.Context(), clientset, namespace, args[0])
 	if err != nil {
 		return nil, cobra.ShellCompDirectiveError
 	}
 
 	var names []string
-	if len(containers.Items) > 0 {
-		for _, container := range containers.Items[0].Spec.Containers {
-			names = append(names, container.Name)
-		}
+	for _, container := range containers {
+		names = append(names, container.Name)
 	}
 
 	return names, cobra.ShellCompDirectiveNoFileComp
 }
 
+// completeLevels completes the log levels of --level
+func completeLevels(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return []string{
+		"DEBUG\tall entries",
+		"INFO\tINFO, WARN and ERROR entries",
+		"WARN\tWARN and ERROR entries",
+		"ERROR\tERROR entries only",
+	}, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeTimeFormats completes the time format presets
+func completeTimeFormats(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return logging.TimeFormatPresets(), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeSuppressPresets completes the names of the built-in suppress presets,
+// after any already listed before a comma
+func completeSuppressPresets(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	listed := ""
+	if i := strings.LastIndex(toComplete, ","); i >= 0 {
+		listed = toComplete[:i+1]
+	}
+
+	var names []string
+	for _, name := range logging.SuppressPresetNames() {
+		names = append(names, listed+name+"\t"+logging.SuppressPresets[name].Description)
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+// rawConflicts are the flags that need parsed entries, which --raw skips
+var rawConflicts = []string{
+	"level", "grep", "trace", "logger", "field", "status", "suppress", "sample",
+	"log4j-pattern", "parse-regex", "parser", "expand-tracebacks", "collapse-frames", "pretty-json", "decode-field",
+	"humanize", "show-all-fields", "icons", "time-format", "time-precision", "error-keyword", "no-error-keywords", "output", "fields",
+	"no-headers", "tee", "jq", "add-labels", "gelf-address", "summary", "counts", "strict", "strict-lines", "idle-timeout",
+	"resume", "dedup-across-pods", "dedup-window", "track", "reorder-window", "clock-skew", "compare-revisions", "record",
+}
+
 func getLogOptions(cmd *cobra.Command, args []string) (*logOptions, error) {
 	namespace, err := cmd.Flags().GetString("namespace")
 	if err != nil {
@@ -140,23 +532,804 @@ func getLogOptions(cmd *cobra.Command, args []string) (*logOptions, error) {
 		return nil, fmt.Errorf("error getting previous flag: %v", err)
 	}
 
+	since, err := cmd.Flags().GetDuration("since")
+	if err != nil {
+		return nil, fmt.Errorf("error getting since flag: %v", err)
+	}
+
+	resume, err := cmd.Flags().GetBool("resume")
+	if err != nil {
+		return nil, fmt.Errorf("error getting resume flag: %v", err)
+	}
+	if resume && previous {
+		return nil, fmt.Errorf("--resume cannot be combined with --previous")
+	}
+
+	stateFile, err := cmd.Flags().GetString("state-file")
+	if err != nil {
+		return nil, fmt.Errorf("error getting state-file flag: %v", err)
+	}
+	if stateFile != "" && !resume {
+		return nil, fmt.Errorf("--state-file requires --resume")
+	}
+
+	idle, err := cmd.Flags().GetDuration("idle-timeout")
+	if err != nil {
+		return nil, fmt.Errorf("error getting idle-timeout flag: %v", err)
+	}
+
+	onIdle, err := cmd.Flags().GetString("idle-action")
+	if err != nil {
+		return nil, fmt.Errorf("error getting idle-action flag: %v", err)
+	}
+	if onIdle != idleExit && onIdle != idleWarn {
+		return nil, fmt.Errorf("invalid --idle-action %q (expected %s or %s)", onIdle, idleExit, idleWarn)
+	}
+	heartbeat, err := cmd.Flags().GetBool("heartbeat")
+	if err != nil {
+		return nil, fmt.Errorf("error getting heartbeat flag: %v", err)
+	}
+	session, err := cmd.Flags().GetBool("session-summary")
+	if err != nil {
+		return nil, fmt.Errorf("error getting session-summary flag: %v", err)
+	}
+	if idle > 0 && !follow {
+		return nil, fmt.Errorf("--idle-timeout requires --follow")
+	}
+
+	grep, err := cmd.Flags().GetStringArray("grep")
+	if err != nil {
+		return nil, fmt.Errorf("error getting grep flag: %v", err)
+	}
+
+	ignoreCase, err := cmd.Flags().GetBool("grep-ignore-case")
+	if err != nil {
+		return nil, fmt.Errorf("error getting grep-ignore-case flag: %v", err)
+	}
+
+	fixed, err := cmd.Flags().GetBool("grep-fixed")
+	if err != nil {
+		return nil, fmt.Errorf("error getting grep-fixed flag: %v", err)
+	}
+
+	invert, err := cmd.Flags().GetBool("invert-match")
+	if err != nil {
+		return nil, fmt.Errorf("error getting invert-match flag: %v", err)
+	}
+
+	matchAny, err := cmd.Flags().GetBool("match-any")
+	if err != nil {
+		return nil, fmt.Errorf("error getting match-any flag: %v", err)
+	}
+
+	matchAll, err := cmd.Flags().GetBool("match-all")
+	if err != nil {
+		return nil, fmt.Errorf("error getting match-all flag: %v", err)
+	}
+	if matchAny && matchAll {
+		return nil, fmt.Errorf("--match-any cannot be combined with --match-all")
+	}
+	if (ignoreCase || fixed || invert || matchAny || matchAll) && len(grep) == 0 {
+		return nil, fmt.Errorf("--grep-ignore-case, --grep-fixed, --invert-match, --match-any and --match-all require --grep")
+	}
+
+	trace, err := cmd.Flags().GetString("trace")
+	if err != nil {
+		return nil, fmt.Errorf("error getting trace flag: %v", err)
+	}
+
+	loggers, err := cmd.Flags().GetStringArray("logger")
+	if err != nil {
+		return nil, fmt.Errorf("error getting logger flag: %v", err)
+	}
+
+	fields, err := cmd.Flags().GetStringArray("field")
+	if err != nil {
+		return nil, fmt.Errorf("error getting field flag: %v", err)
+	}
+
+	status, err := cmd.Flags().GetStringSlice("status")
+	if err != nil {
+		return nil, fmt.Errorf("error getting status flag: %v", err)
+	}
+	summary, err := cmd.Flags().GetBool("summary")
+	if err != nil {
+		return nil, fmt.Errorf("error getting summary flag: %v", err)
+	}
+	counts, err := cmd.Flags().GetBool("counts")
+	if err != nil {
+		return nil, fmt.Errorf("error getting counts flag: %v", err)
+	}
+
+	strict, err := cmd.Flags().GetBool("strict")
+	if err != nil {
+		return nil, fmt.Errorf("error getting strict flag: %v", err)
+	}
+
+	strictLn, err := cmd.Flags().GetBool("strict-lines")
+	if err != nil {
+		return nil, fmt.Errorf("error getting strict-lines flag: %v", err)
+	}
+	if strictLn && !strict {
+		return nil, fmt.Errorf("--strict-lines requires --strict")
+	}
+	suppress, err := cmd.Flags().GetStringSlice("suppress")
+	if err != nil {
+		return nil, fmt.Errorf("error getting suppress flag: %v", err)
+	}
+	sample, err := cmd.Flags().GetUint64("sample")
+	if err != nil {
+		return nil, fmt.Errorf("error getting sample flag: %v", err)
+	}
+
+	log4j, err := cmd.Flags().GetStringArray("log4j-pattern")
+	if err != nil {
+		return nil, fmt.Errorf("error getting log4j-pattern flag: %v", err)
+	}
+
+	regexes, err := cmd.Flags().GetStringArray("parse-regex")
+	if err != nil {
+		return nil, fmt.Errorf("error getting parse-regex flag: %v", err)
+	}
+
+	parsers, err := cmd.Flags().GetStringSlice("parser")
+	if err != nil {
+		return nil, fmt.Errorf("error getting parser flag: %v", err)
+	}
+
+	expand, err := cmd.Flags().GetBool("expand-tracebacks")
+	if err != nil {
+		return nil, fmt.Errorf("error getting expand-tracebacks flag: %v", err)
+	}
+
+	collapse, err := cmd.Flags().GetBool("collapse-frames")
+	if err != nil {
+		return nil, fmt.Errorf("error getting collapse-frames flag: %v", err)
+	}
+
+	pretty, err := cmd.Flags().GetBool("pretty-json")
+	if err != nil {
+		return nil, fmt.Errorf("error getting pretty-json flag: %v", err)
+	}
+
+	decode, err := cmd.Flags().GetStringArray("decode-field")
+	if err != nil {
+		return nil, fmt.Errorf("error getting decode-field flag: %v", err)
+	}
+
+	humanize, err := cmd.Flags().GetBool("humanize")
+	if err != nil {
+		return nil, fmt.Errorf("error getting humanize flag: %v", err)
+	}
+	allFields, err := cmd.Flags().GetBool("show-all-fields")
+	if err != nil {
+		return nil, fmt.Errorf("error getting show-all-fields flag: %v", err)
+	}
+
+	icons, err := cmd.Flags().GetBool("icons")
+	if err != nil {
+		return nil, fmt.Errorf("error getting icons flag: %v", err)
+	}
+
+	keywords, err := cmd.Flags().GetStringArray("error-keyword")
+	if err != nil {
+		return nil, fmt.Errorf("error getting error-keyword flag: %v", err)
+	}
+
+	noKeywords, err := cmd.Flags().GetBool("no-error-keywords")
+	if err != nil {
+		return nil, fmt.Errorf("error getting no-error-keywords flag: %v", err)
+	}
+	if noKeywords {
+		if len(keywords) > 0 {
+			return nil, fmt.Errorf("--error-keyword cannot be combined with --no-error-keywords")
+		}
+		// An empty list disables the keywords, while nil keeps the defaults
+		keywords = []string{}
+	}
+
+	output, err := cmd.Flags().GetString("output")
+	if err != nil {
+		return nil, fmt.Errorf("error getting output flag: %v", err)
+	}
+
+	labels, err := cmd.Flags().GetStringSlice("add-labels")
+	if err != nil {
+		return nil, fmt.Errorf("error getting add-labels flag: %v", err)
+	}
+
+	columns, err := cmd.Flags().GetStringSlice("fields")
+	if err != nil {
+		return nil, fmt.Errorf("error getting fields flag: %v", err)
+	}
+
+	noHeaders, err := cmd.Flags().GetBool("no-headers")
+	if err != nil {
+		return nil, fmt.Errorf("error getting no-headers flag: %v", err)
+	}
+
+	jq, err := cmd.Flags().GetString("jq")
+	if err != nil {
+		return nil, fmt.Errorf("error getting jq flag: %v", err)
+	}
+
+	timeFormat, err := cmd.Flags().GetString("time-format")
+	if err != nil {
+		return nil, fmt.Errorf("error getting time-format flag: %v", err)
+	}
+	var timeFmt logging.TimeFormat
+	if timeFormat != "" {
+		if jq != "" || (output != "" && output != "text") {
+			return nil, fmt.Errorf("--time-format only applies to text output")
+		}
+		if timeFmt, err = logging.ParseTimeFormat(timeFormat); err != nil {
+			return nil, err
+		}
+	}
+
+	precision, err := cmd.Flags().GetString("time-precision")
+	if err != nil {
+		return nil, fmt.Errorf("error getting time-precision flag: %v", err)
+	}
+	if precision != "" {
+		if jq != "" || (output != "" && output != "text") {
+			return nil, fmt.Errorf("--time-precision only applies to text output")
+		}
+		if timeFmt, err = timeFmt.WithPrecision(precision); err != nil {
+			return nil, err
+		}
+	}
+
+	raw, err := cmd.Flags().GetBool("raw")
+	if err != nil {
+		return nil, fmt.Errorf("error getting raw flag: %v", err)
+	}
+	if raw {
+		for _, name := range rawConflicts {
+			if cmd.Flags().Changed(name) {
+				return nil, fmt.Errorf("--raw cannot be combined with --%s", name)
+			}
+		}
+	}
+
+	gelf, err := cmd.Flags().GetString("gelf-address")
+	if err != nil {
+		return nil, fmt.Errorf("error getting gelf-address flag: %v", err)
+	}
+	if gelf != "" {
+		if output != "" && output != "gelf" {
+			return nil, fmt.Errorf("--gelf-address cannot be combined with --output %s", output)
+		}
+		output = "gelf"
+	}
+
+	file, err := cmd.Flags().GetString("output-file")
+	if err != nil {
+		return nil, fmt.Errorf("error getting output-file flag: %v", err)
+	}
+	if file != "" && gelf != "" {
+		return nil, fmt.Errorf("--output-file cannot be combined with --gelf-address")
+	}
+
+	tees, err := cmd.Flags().GetStringArray("tee")
+	if err != nil {
+		return nil, fmt.Errorf("error getting tee flag: %v", err)
+	}
+
+	rotation, err := getRotationOptions(cmd)
+	if err != nil {
+		return nil, err
+	}
+	if rotation.changed && file == "" {
+		return nil, fmt.Errorf("--rotate-every, --rotate-size, --rotate-compress and --rotate-keep require --output-file")
+	}
+
+	record, err := cmd.Flags().GetString("record")
+	if err != nil {
+		return nil, fmt.Errorf("error getting record flag: %v", err)
+	}
+
+	selector, err := cmd.Flags().GetString("selector")
+	if err != nil {
+		return nil, fmt.Errorf("error getting selector flag: %v", err)
+	}
+
+	allNs, err := cmd.Flags().GetBool("all-namespaces")
+	if err != nil {
+		return nil, fmt.Errorf("error getting all-namespaces flag: %v", err)
+	}
+	switch {
+	case allNs && selector == "":
+		return nil, fmt.Errorf("--all-namespaces requires --selector")
+	case allNs && namespace != "":
+		return nil, fmt.Errorf("--all-namespaces cannot be combined with --namespace")
+	}
+
+	maxReqs, err := cmd.Flags().GetInt("max-log-requests")
+	if err != nil {
+		return nil, fmt.Errorf("error getting max-log-requests flag: %v", err)
+	}
+	if maxReqs < 1 {
+		return nil, fmt.Errorf("--max-log-requests must be at least 1")
+	}
+
+	ignoreErr, err := cmd.Flags().GetBool("ignore-errors")
+	if err != nil {
+		return nil, fmt.Errorf("error getting ignore-errors flag: %v", err)
+	}
+
+	noMarkers, err := cmd.Flags().GetBool("no-markers")
+	if err != nil {
+		return nil, fmt.Errorf("error getting no-markers flag: %v", err)
+	}
+
+	lastWords, err := cmd.Flags().GetInt64("last-words")
+	if err != nil {
+		return nil, fmt.Errorf("error getting last-words flag: %v", err)
+	}
+	if lastWords < 0 {
+		return nil, fmt.Errorf("--last-words must not be negative")
+	}
+
+	noPager, err := cmd.Flags().GetBool("no-pager")
+	if err != nil {
+		return nil, fmt.Errorf("error getting no-pager flag: %v", err)
+	}
+
+	pager, err := cmd.Flags().GetString("pager")
+	if err != nil {
+		return nil, fmt.Errorf("error getting pager flag: %v", err)
+	}
+
+	lineNums, err := cmd.Flags().GetBool("line-numbers")
+	if err != nil {
+		return nil, fmt.Errorf("error getting line-numbers flag: %v", err)
+	}
+	if lineNums && (jq != "" || (output != "" && output != "text")) {
+		return nil, fmt.Errorf("--line-numbers only applies to text output")
+	}
+
+	revision, err := cmd.Flags().GetInt64("revision")
+	if err != nil {
+		return nil, fmt.Errorf("error getting revision flag: %v", err)
+	}
+
+	compare, err := cmd.Flags().GetBool("compare-revisions")
+	if err != nil {
+		return nil, fmt.Errorf("error getting compare-revisions flag: %v", err)
+	}
+
+	dedup, err := getDedupWindow(cmd, jq, output)
+	if err != nil {
+		return nil, err
+	}
+
+	track, err := cmd.Flags().GetStringArray("track")
+	if err != nil {
+		return nil, fmt.Errorf("error getting track flag: %v", err)
+	}
+	if len(track) > 0 && (jq != "" || (output != "" && output != "text")) {
+		return nil, fmt.Errorf("--track only applies to text output")
+	}
+
+	reorder, err := cmd.Flags().GetDuration("reorder-window")
+	if err != nil {
+		return nil, fmt.Errorf("error getting reorder-window flag: %v", err)
+	}
+	if reorder < 0 {
+		return nil, fmt.Errorf("--reorder-window must not be negative")
+	}
+
+	skew, err := cmd.Flags().GetString("clock-skew")
+	if err != nil {
+		return nil, fmt.Errorf("error getting clock-skew flag: %v", err)
+	}
+	if skew != "" && skew != skewReport && skew != skewCorrect {
+		return nil, fmt.Errorf("invalid --clock-skew %q (expected %s or %s)", skew, skewReport, skewCorrect)
+	}
+
+	exclude, err := cmd.Flags().GetStringArray("exclude-container")
+	if err != nil {
+		return nil, fmt.Errorf("error getting exclude-container flag: %v", err)
+	}
+
+	sidecars, err := cmd.Flags().GetBool("include-sidecars")
+	if err != nil {
+		return nil, fmt.Errorf("error getting include-sidecars flag: %v", err)
+	}
+	if !sidecars {
+		exclude = append(exclude, kubernetes.DefaultSidecarContainers...)
+	}
+
+	var podName string
+	if len(args) > 0 {
+		podName = args[0]
+	}
+	if (podName == "") == (selector == "") {
+		return nil, fmt.Errorf("specify either a pod name or --selector")
+	}
+
+	prefix, err := getPrefixOptions(cmd)
+	if err != nil {
+		return nil, err
+	}
+	// Pods of different namespaces may share a name
+	if allNs && !cmd.Flags().Changed("prefix-template") {
+		prefix.template = "{{.Namespace}}/" + prefix.template
+	}
+
 	return &logOptions{
 		namespace: namespace,
+		allNs:     allNs,
 		container: container,
 		follow:    follow,
 		level:     level,
-		podName:   args[0],
+		podName:   podName,
+		selector:  selector,
+		revision:  revision,
+		compare:   compare,
+		reorder:   reorder,
+		dedup:     dedup,
+		track:     track,
+		skew:      skew,
+		exclude:   exclude,
+		sidecars:  sidecars,
+		maxReqs:   maxReqs,
+		ignoreErr: ignoreErr,
+		noMarkers: noMarkers,
+		lastWords: lastWords,
+		tees:      tees,
+		noPager:   noPager,
+		pager:     pager,
+		lineNums:  lineNums,
 		previous:  previous,
+		since:     since,
+		resume:    resume,
+		stateFile: stateFile,
+		idle:      idle,
+		onIdle:    onIdle,
+		heartbeat: heartbeat,
+		session:   session,
+		counts:    counts,
+		grep:      grep,
+		grepOpts:  logging.GrepOptions{IgnoreCase: ignoreCase, Fixed: fixed, Invert: invert},
+		matchAll:  matchAll,
+		trace:     trace,
+		loggers:   loggers,
+		fields:    fields,
+		sample:    sample,
+		suppress:  suppress,
+		status:    status,
+		summary:   summary,
+		strict:    strict,
+		strictLn:  strictLn,
+		log4j:     log4j,
+		regexes:   regexes,
+		parsers:   parsers,
+		expand:    expand,
+		collapse:  collapse,
+		pretty:    pretty,
+		decode:    decode,
+		humanize:  humanize,
+		allFields: allFields,
+		icons:     icons,
+		timeFmt:   timeFmt,
+		keywords:  keywords,
+		output:    output,
+		labels:    labels,
+		gelf:      gelf,
+		file:      file,
+		rotation:  rotation,
+		record:    record,
+		columns:   columns,
+		noHeaders: noHeaders,
+		jq:        jq,
+		raw:       raw,
+		prefix:    prefix,
 	}, nil
 }
 
+func getPrefixOptions(cmd *cobra.Command) (prefixOptions, error) {
+	tmpl, err := cmd.Flags().GetString("prefix-template")
+	if err != nil {
+		return prefixOptions{}, fmt.Errorf("error getting prefix-template flag: %v", err)
+	}
+
+	short, err := cmd.Flags().GetBool("short-pod-names")
+	if err != nil {
+		return prefixOptions{}, fmt.Errorf("error getting short-pod-names flag: %v", err)
+	}
+
+	width, err := cmd.Flags().GetInt("prefix-width")
+	if err != nil {
+		return prefixOptions{}, fmt.Errorf("error getting prefix-width flag: %v", err)
+	}
+
+	return prefixOptions{template: tmpl, shortPodNames: short, width: width}, nil
+}
+
+// buildPipeline assembles the filter chain described by the command options
+// and the config file
+func buildPipeline(options *logOptions, cfg *config.Config) (*logging.Pipeline, error) {
+	level, err := logging.ParseLogLevel(options.level)
+	if err != nil {
+		return nil, err
+	}
+	loggerLevels := make(map[string]logging.LogLevel, len(cfg.LoggerLevels))
+	for logger, name := range cfg.LoggerLevels {
+		l, err := logging.ParseLogLevel(name)
+		if err != nil {
+			return nil, fmt.Errorf("error in level of logger %q: %w", logger, err)
+		}
+		loggerLevels[logger] = l
+	}
+	pipeline := logging.NewPipeline(logging.LevelFilter{Min: level, Loggers: loggerLevels})
+	pipeline.Alias(cfg.FieldAliases)
+	epochUnits := make(map[string]time.Duration, len(cfg.EpochUnits))
+	for field, name := range cfg.EpochUnits {
+		unit, err := logging.ParseEpochUnit(name)
+		if err != nil {
+			return nil, fmt.Errorf("error in epoch unit of field %q: %w", field, err)
+		}
+		epochUnits[field] = unit
+	}
+	pipeline.EpochUnits(epochUnits)
+	// The checker sees entries as they were parsed, before any transform
+	if options.checker != nil {
+		pipeline.Transform(options.checker)
+	}
+
+	for _, field := range cfg.DerivedFields {
+		expr, err := logging.CompileExpr(field.Expr)
+		if err != nil {
+			return nil, fmt.Errorf("error in derived field %q: %w", field.Name, err)
+		}
+		pipeline.Transform(logging.DeriveField(field.Name, expr))
+	}
+
+	for _, pattern := range options.log4j {
+		parser, err := logging.NewLog4jParser(pattern)
+		if err != nil {
+			return nil, err
+		}
+		pipeline.Parse(parser)
+	}
+	for _, name := range options.parsers {
+		parser, err := logging.LookupParser(name)
+		if err != nil {
+			return nil, err
+		}
+		pipeline.Parse(parser)
+	}
+	for _, pattern := range options.regexes {
+		parser, err := logging.NewRegexParser(pattern)
+		if err != nil {
+			return nil, err
+		}
+		pipeline.Parse(parser)
+	}
+	pipeline.Fold(
+		func() logging.Folder { return logging.NewPythonTracebackFolder(options.expand) },
+		func() logging.Folder { return logging.NewJavaStackTraceFolder(options.expand, options.collapse) },
+	)
+	for _, expr := range options.decode {
+		decode, err := logging.ParseDecodeField(expr)
+		if err != nil {
+			return nil, err
+		}
+		pipeline.Transform(decode)
+	}
+	if options.pretty {
+		pipeline.Transform(logging.PrettyEmbeddedJSON)
+	}
+
+	if len(options.grep) > 0 {
+		grep, err := newGrepFilter(options, pipeline)
+		if err != nil {
+			return nil, err
+		}
+		pipeline.Use(grep)
+	}
+
+	if options.trace != "" {
+		pipeline.Use(logging.TraceFilter{ID: options.trace})
+	}
+
+	if len(options.loggers) > 0 {
+		loggers, err := logging.NewLoggerFilter(options.loggers)
+		if err != nil {
+			return nil, err
+		}
+		pipeline.Use(loggers)
+	}
+
+	for _, expr := range options.fields {
+		field, err := logging.ParseFieldFilter(expr)
+		if err != nil {
+			return nil, err
+		}
+		pipeline.Use(field)
+	}
+
+	if len(options.status) > 0 {
+		status, err := logging.NewStatusFilter(options.status)
+		if err != nil {
+			return nil, err
+		}
+		pipeline.Use(status)
+	}
+
+	if len(options.suppress) > 0 {
+		suppress, err := logging.NewSuppressFilter(options.suppress)
+		if err != nil {
+			return nil, err
+		}
+		pipeline.Use(suppress)
+	}
+
+	if options.sample > 1 {
+		pipeline.Use(logging.NewSampleFilter(options.sample))
+	}
+
+	// Masking comes last, so fields added by other transforms are masked too
+	if len(cfg.MaskRules) > 0 {
+		if options.raw {
+			return nil, fmt.Errorf("--raw cannot apply the maskRules of the config file")
+		}
+		mask, err := newMask(cfg)
+		if err != nil {
+			return nil, err
+		}
+		pipeline.Transform(mask)
+	}
+
+	return pipeline, nil
+}
+
+// newMask compiles the masking rules of the config file into a transform
+func newMask(cfg *config.Config) (logging.Transform, error) {
+	rules := make([]logging.MaskRule, len(cfg.MaskRules))
+	for i, rule := range cfg.MaskRules {
+		var err error
+		if rules[i], err = logging.NewMaskRule(rule.Pattern, rule.Replacement); err != nil {
+			return nil, fmt.Errorf("error in mask rule %d: %w", i+1, err)
+		}
+	}
+	return logging.Mask(rules), nil
+}
+
+// newGrepFilter combines the --grep patterns: lines matching any of them are
+// kept, or only those matching all of them with --match-all. --invert-match
+// inverts the combination, so no pattern may match, or not all of them. The
+// named groups of the patterns are added to pipeline as transforms, so they
+// become fields of the entries they match.
+func newGrepFilter(options *logOptions, pipeline *logging.Pipeline) (logging.Filter, error) {
+	opts := options.grepOpts
+	opts.Invert = false
+	filters := make([]logging.Filter, len(options.grep))
+	for i, pattern := range options.grep {
+		f, err := logging.NewGrepFilterWithOptions(pattern, opts)
+		if err != nil {
+			return nil, err
+		}
+		if f.Pattern != nil && logging.HasNamedGroups(f.Pattern) {
+			pipeline.Transform(logging.CaptureFields(f.Pattern))
+		}
+		filters[i] = f
+	}
+
+	var grep logging.Filter = logging.AnyFilter(filters)
+	if options.matchAll {
+		grep = logging.FilterChain(filters)
+	}
+	if options.grepOpts.Invert {
+		grep = logging.Not(grep)
+	}
+	return grep, nil
+}
+
+// newFormatter returns the formatter for the output format of the command options
+func newFormatter(options *logOptions) (logging.Formatter, error) {
+	if options.jq != "" {
+		if options.output != "" && options.output != "text" {
+			return nil, fmt.Errorf("--jq cannot be combined with --output %s", options.output)
+		}
+		return logging.NewJQFormatter(options.jq)
+	}
+	return formatterFor(options.output, options)
+}
+
+// formatterFor returns the formatter of an output format, set up with the
+// text options of the command
+func formatterFor(output string, options *logOptions) (logging.Formatter, error) {
+	if strings.HasPrefix(output, format.CustomColumnsPrefix) {
+		return format.ParseCustomColumns(output)
+	}
+	switch output {
+	case "", "text":
+		formatter := logging.TextFormatter{Humanize: options.humanize, AllFields: options.allFields, Icons: options.icons, TimeFormat: options.timeFmt}
+		if options.keywords != nil {
+			highlighter, err := logging.NewHighlighter(options.keywords)
+			if err != nil {
+				return nil, err
+			}
+			formatter.Highlighter = highlighter
+		}
+		return formatter, nil
+	case "json":
+		return logging.JSONFormatter{}, nil
+	case "jsonl":
+		return logging.JSONFormatter{Compact: true}, nil
+	case "logfmt":
+		return logging.LogfmtFormatter{}, nil
+	case "ecs":
+		return logging.ECSFormatter{}, nil
+	case "gelf":
+		return logging.NewGELFFormatter(), nil
+	case "csv":
+		return logging.NewCSVFormatter(options.columns), nil
+	default:
+		return nil, fmt.Errorf("unsupported output format %q (expected text, json, jsonl, logfmt, ecs, gelf, csv or custom-columns=...)", output)
+	}
+}
+
+// getDedupWindow returns how long lines are held to collapse the identical
+// messages of replicas, or 0 without --dedup-across-pods
+func getDedupWindow(cmd *cobra.Command, jq, output string) (time.Duration, error) {
+	dedup, err := cmd.Flags().GetBool("dedup-across-pods")
+	if err != nil {
+		return 0, fmt.Errorf("error getting dedup-across-pods flag: %v", err)
+	}
+
+	window, err := cmd.Flags().GetDuration("dedup-window")
+	if err != nil {
+		return 0, fmt.Errorf("error getting dedup-window flag: %v", err)
+	}
+
+	switch {
+	case !dedup && cmd.Flags().Changed("dedup-window"):
+		return 0, fmt.Errorf("--dedup-window requires --dedup-across-pods")
+	case !dedup:
+		return 0, nil
+	case window <= 0:
+		return 0, fmt.Errorf("--dedup-window must be positive")
+	case jq != "" || (output != "" && output != "text"):
+		return 0, fmt.Errorf("--dedup-across-pods only applies to text output")
+	}
+	return window, nil
+}
+
+// structuredOutput reports whether entries are written as records carrying
+// their source, which replace the per-line prefix
+func structuredOutput(options *logOptions) bool {
+	return options.jq != "" || (options.output != "" && options.output != "text")
+}
+
 func runLogs(cmd *cobra.Command, args []string) error {
 	options, err := getLogOptions(cmd, args)
 	if err != nil {
 		return err
 	}
 
-	clientset, contextNamespace, err := kubernetes.GetKubernetesClient()
+	cfg, err := loadConfig(cmd)
+	if err != nil {
+		return err
+	}
+
+	if options.strict {
+		options.checker = &logging.ParseChecker{}
+	}
+	pipeline, err := buildPipeline(options, cfg)
+	if err != nil {
+		return err
+	}
+
+	formatter, err := newFormatter(options)
+	if err != nil {
+		return err
+	}
+
+	clientset, contextNamespace, err := newKubeClient(cmd, cfg)
 	if err != nil {
 		return fmt.Errorf("error getting kubernetes client: %v", err)
 	}
@@ -165,22 +1338,384 @@ func runLogs(cmd *cobra.Command, args []string) error {
 	if options.namespace == "" {
 		options.namespace = contextNamespace
 	}
+	options.fallback = cfg.Namespaces
 
-	// Create log fetcher with the new interface
-	logFetcher := kubernetes.NewLogFetcher(
-		clientset,
-		options.namespace,
-		options.podName,
-		options.follow,
-		options.previous,
-		os.Stdout,
-	)
+	var out io.Writer = os.Stdout
+	if options.gelf != "" {
+		gelf, err := sink.NewGELFWriter(options.gelf)
+		if err != nil {
+			return err
+		}
+		defer gelf.Close()
+		out = gelf
+	}
 
-	// Get logs using the new method
-	err = logFetcher.GetLogs()
-	if err != nil {
-		return fmt.Errorf("error fetching logs: %v", err)
+	var file *sink.FileWriter
+	if options.file != "" {
+		file, err = options.rotation.newFileWriter(options.file)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if err := file.Close(); err != nil {
+				fmt.Fprintf(os.Stderr, "Error closing capture file: %v\n", err)
+			}
+		}()
+		out = file
+		color.NoColor = true
+	}
+
+	if err := setupTheme(cmd, cfg); err != nil {
+		return err
+	}
+
+	// Historical logs longer than a screen are shown in a pager
+	if pager := pagerCommand(options.noPager, options.pager); pager != "" && !options.follow && out == io.Writer(os.Stdout) {
+		var pw io.WriteCloser
+		if pager == builtinPager {
+			pw = newBuiltinPager(os.Stdout)
+		} else {
+			pw = newPagerWriter(pager, os.Stdout)
+		}
+		defer func() {
+			if err := pw.Close(); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			}
+		}()
+		out = pw
+	}
+
+	// Lines are numbered as shown, after any writer below orders them
+	if options.lineNums {
+		out = kubernetes.NewLineNumberWriter(out)
+	}
+
+	if header, ok := formatter.(logging.HeaderFormatter); ok && !options.noHeaders {
+		// Every rotated file starts with the header
+		if file != nil {
+			file.Header = header.Header()
+		} else if _, err := fmt.Fprintln(out, header.Header()); err != nil {
+			return fmt.Errorf("error writing header: %w", err)
+		}
+	}
+
+	// Changes are marked between entries in the order they are shown
+	if len(options.track) > 0 {
+		out = kubernetes.NewTrackWriter(out, options.track)
+	}
+
+	// Lines of a trace are ordered by timestamp across pods once all are read
+	var sorted *kubernetes.SortedWriter
+	if options.trace != "" && !options.follow {
+		sorted = kubernetes.NewSortedWriter(out)
+		out = sorted
+	}
+
+	// A quiet followed stream shows a status line on the terminal, which other
+	// messages on stderr clear first
+	var stderr io.Writer = os.Stderr
+	var hb *heartbeat
+	if options.follow && options.heartbeat && !options.raw && options.gelf == "" && isTerminal(os.Stderr) {
+		hb = newHeartbeat(out, os.Stderr)
+		out = hb
+		stderr = hb.Stderr()
+	}
+	if options.strictLn {
+		options.checker.OnProblem = printParseProblems(stderr)
+	}
+
+	// Lines of concurrent streams are held for the window to order them by
+	// timestamp; a trace read in full is already ordered
+	var reorder *kubernetes.ReorderWriter
+	if options.reorder > 0 && sorted == nil {
+		reorder = kubernetes.NewReorderWriter(out, options.reorder)
+		out = reorder
+	}
+
+	// Sinks get the entries shown, after identical lines of replicas are
+	// collapsed and before they are held back to be ordered
+	if len(options.tees) > 0 {
+		tee, err := newTee(options, out)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if err := tee.Close(); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			}
+		}()
+		out = tee
+	}
+
+	// Identical lines of replicas are collapsed before they are ordered
+	var dedup *kubernetes.DedupWriter
+	if options.dedup > 0 {
+		dedup = kubernetes.NewDedupWriter(out, options.dedup)
+		out = dedup
+	}
+
+	// The recording sees every entry written, before any writer holds it back
+	if options.record != "" {
+		recorder, closeRecording, err := newRecorder(options, cfg, out)
+		if err != nil {
+			return err
+		}
+		defer closeRecording()
+		out = recorder
+	}
+
+	fetcherOpts := []kubernetes.Option{
+		kubernetes.WithSince(options.since),
+		kubernetes.WithPipeline(pipeline),
+		kubernetes.WithWriter(out),
+		kubernetes.WithFormatter(formatter),
+		kubernetes.WithLabels(options.labels...),
+		kubernetes.WithEvents(kubernetes.NoticeHandler(stderr)),
+		kubernetes.WithContainerChooser(chooseContainer),
+	}
+	if options.follow {
+		fetcherOpts = append(fetcherOpts, kubernetes.WithFollow())
+	}
+	if options.previous {
+		fetcherOpts = append(fetcherOpts, kubernetes.WithPrevious())
+	}
+	if options.raw {
+		fetcherOpts = append(fetcherOpts, kubernetes.WithRaw())
+	}
+	var skew *kubernetes.ClockSkew
+	if options.skew != "" {
+		skew = kubernetes.NewClockSkew(options.skew == skewCorrect)
+		fetcherOpts = append(fetcherOpts, kubernetes.WithClockSkew(skew))
+	}
+
+	// Stop streaming cleanly on Ctrl-C or SIGTERM
+	ctx := cmd.Context()
+
+	if options.resume {
+		store, err := checkpoint.Load(options.stateFile)
+		if err != nil {
+			return err
+		}
+		fetcherOpts = append(fetcherOpts, kubernetes.WithCheckpoints(store))
+		if options.follow {
+			go saveCheckpoints(ctx, store, stderr)
+		}
+		defer func() {
+			if err := store.Save(); err != nil {
+				fmt.Fprintf(stderr, "Error saving checkpoints: %v\n", err)
+			}
+		}()
+	}
+
+	if options.idle > 0 || hb != nil {
+		tracker := logging.NewActivityTracker()
+		pipeline.Transform(tracker)
+		if options.idle > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithCancel(ctx)
+			defer cancel()
+			go watchIdle(ctx, cancel, tracker, options.idle, options.onIdle, stderr)
+		}
+		if hb != nil {
+			hbCtx, stopHeartbeat := context.WithCancel(ctx)
+			done := make(chan struct{})
+			go func() {
+				hb.run(hbCtx, tracker)
+				close(done)
+			}()
+			defer func() {
+				stopHeartbeat()
+				<-done
+			}()
+		}
+	}
+
+	err = streamLogs(ctx, clientset, options, pipeline, fetcherOpts, stderr)
+	if dedup != nil {
+		if closeErr := dedup.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}
+	if sorted != nil {
+		if flushErr := sorted.Flush(); flushErr != nil && err == nil {
+			err = flushErr
+		}
+	}
+	if reorder != nil {
+		if closeErr := reorder.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+		if late, lag := reorder.Late(); late > 0 {
+			fmt.Fprintf(stderr, "%d lines arrived after the %s --reorder-window and were written out of order (up to %s late)\n",
+				late, options.reorder, lag.Round(time.Millisecond))
+		}
+	}
+	if skew != nil {
+		printClockSkew(stderr, skew)
+	}
+	if err != nil && !errors.Is(err, context.Canceled) {
+		return fmt.Errorf("error fetching logs: %w", err)
+	}
+	if options.checker != nil {
+		return reportParseProblems(stderr, options.checker)
 	}
 
 	return nil
 }
+
+// streamLogs streams the logs in the mode selected by the command options:
+// revision comparison, multiple pods, or a single pod. Reports are written to stderr.
+func streamLogs(ctx context.Context, clientset k8s.Interface, options *logOptions, pipeline *logging.Pipeline, fetcherOpts []kubernetes.Option, stderr io.Writer) error {
+	if options.compare {
+		if options.summary || options.counts {
+			return fmt.Errorf("--summary and --counts cannot be combined with --compare-revisions")
+		}
+		return streamRevisions(ctx, clientset, options, pipeline, fetcherOpts, stderr)
+	}
+
+	summary := newPodSummary(pipeline)
+	if options.follow && options.session && !options.raw {
+		// Only a session ended by Ctrl-C or --idle-timeout is reported
+		defer func() {
+			if ctx.Err() != nil {
+				summary.printSession(stderr)
+			}
+		}()
+	}
+	if options.summary {
+		defer summary.report(stderr, options.follow, summary.print)()
+	}
+	if options.counts {
+		defer summary.report(stderr, options.follow, summary.printCounts)()
+	}
+
+	if options.selector == "" {
+		selector, err := resolveSelector(ctx, clientset, options)
+		if err != nil {
+			return err
+		}
+		options.selector = selector
+	}
+	if options.selector != "" {
+		return streamSelector(ctx, clientset, options, fetcherOpts, summary)
+	}
+
+	fetcherOpts = append(fetcherOpts,
+		kubernetes.WithContainer(options.container),
+		kubernetes.WithPipeline(summary.pipelineFor(kubernetes.Target{
+			Namespace: options.namespace,
+			Pod:       options.podName,
+			Container: options.container,
+		})))
+	return kubernetes.NewLogFetcher(clientset, options.namespace, options.podName, fetcherOpts...).GetLogs(ctx)
+}
+
+// resolveSelector turns a workload reference such as deployment/api into a label
+// selector, honoring --revision. Plain pod names resolve to an empty selector.
+func resolveSelector(ctx context.Context, clientset k8s.Interface, options *logOptions) (string, error) {
+	workload, ok, err := kubernetes.ParseWorkload(options.podName)
+	if err != nil {
+		return "", err
+	}
+
+	if options.revision > 0 {
+		if !ok || workload.Kind != "deployment" {
+			return "", fmt.Errorf("--revision can only be used with a deployment, e.g. deployment/api")
+		}
+		return kubernetes.RevisionSelector(ctx, clientset, options.namespace, workload.Name, options.revision)
+	}
+
+	if !ok {
+		return "", nil
+	}
+	if !options.sidecars {
+		options.exclude = append(options.exclude, workload.SidecarContainers()...)
+	}
+	return workload.Selector(ctx, clientset, options.namespace)
+}
+
+// selectTargets resolves the container streams for a label selector, skipping
+// excluded containers unless a specific container was requested
+func selectTargets(ctx context.Context, clientset k8s.Interface, options *logOptions, selector string) ([]kubernetes.Target, error) {
+	var targets []kubernetes.Target
+	var err error
+	if options.allNs {
+		targets, err = selectAllNamespaces(ctx, clientset, options, selector)
+	} else {
+		targets, err = kubernetes.ResolveTargets(ctx, clientset, options.namespace, selector, options.container)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if options.container != "" {
+		return targets, nil
+	}
+
+	targets = kubernetes.ExcludeContainers(targets, options.exclude...)
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("%w: every container matching selector '%s' is excluded", kubernetes.ErrContainerNotFound, selector)
+	}
+	slog.Info("resolved containers", "selector", selector, "count", len(targets))
+	return targets, nil
+}
+
+// selectAllNamespaces resolves the container streams for a label selector in
+// all namespaces, telling users who may not list pods cluster-wide which
+// namespaces are searched instead
+func selectAllNamespaces(ctx context.Context, clientset k8s.Interface, options *logOptions, selector string) ([]kubernetes.Target, error) {
+	targets, searched, err := kubernetes.ResolveAllTargets(ctx, clientset, selector, options.container, options.fallback, options.namespace)
+	if err != nil {
+		if errors.Is(err, kubernetes.ErrForbidden) && len(options.fallback) == 0 {
+			return nil, fmt.Errorf("%w\nList the namespaces you may access under namespaces: in the config file to search them instead", err)
+		}
+		return nil, err
+	}
+	if searched != nil {
+		from := "you may access"
+		if len(options.fallback) > 0 {
+			from = "of the config file"
+		}
+		fmt.Fprintf(os.Stderr, "Not allowed to list pods in all namespaces; searching the namespaces %s instead: %s\n", from, strings.Join(searched, ", "))
+	}
+	return targets, nil
+}
+
+// streamSelector streams the logs of every container matching the label selector,
+// tallying the entries of each pod in summary
+func streamSelector(ctx context.Context, clientset k8s.Interface, options *logOptions, fetcherOpts []kubernetes.Option, summary *podSummary) error {
+	prefix, err := newPrefixTemplate(options.prefix)
+	if err != nil {
+		return err
+	}
+
+	targets, err := selectTargets(ctx, clientset, options, options.selector)
+	if err != nil {
+		return err
+	}
+	if err := kubernetes.CheckMaxStreams(targets, options.maxReqs); err != nil {
+		return err
+	}
+
+	multi := kubernetes.NewMultiLogFetcher(clientset, targets, fetcherOpts...)
+	multi.IgnoreErrors = options.ignoreErr
+	if !structuredOutput(options) {
+		multi.Prefix = prefix.Colorize
+		multi.Lifecycle = options.follow && !options.noMarkers
+		multi.LastWords = options.lastWords
+	}
+	multi.TargetOptions = func(target kubernetes.Target) []kubernetes.Option {
+		return []kubernetes.Option{kubernetes.WithPipeline(summary.pipelineFor(target))}
+	}
+	return multi.GetLogs(ctx)
+}
+
+// newPrefixTemplate builds the prefix template from the command options
+func newPrefixTemplate(options prefixOptions) (*format.PrefixTemplate, error) {
+	prefix, err := format.NewPrefixTemplate(options.template)
+	if err != nil {
+		return nil, err
+	}
+	prefix.ShortPodNames = options.shortPodNames
+	prefix.Width = options.width
+	return prefix, nil
+}
@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/dantech2000/kubelog/pkg/format"
+	"github.com/dantech2000/kubelog/pkg/kubernetes"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+// namespaceOptions holds the command options for the namespaces command
+type namespaceOptions struct {
+	outputFormat string
+	noHeaders    bool
+}
+
+var namespacesCmd = &cobra.Command{
+	Use:     "namespaces",
+	Aliases: []string{"ns"},
+	Short:   "List accessible Kubernetes namespaces",
+	Long: `List the namespaces whose pods you can access, with their pod counts.
+The namespace of the current kubeconfig context, which kubelog uses when -n is
+not given, is marked with an asterisk. If you may not list namespaces, only the
+current one is shown.
+
+Example usage:
+  kubelog namespaces
+  kubelog ns -o posix
+  kubelog namespaces --output json`,
+	Args: cobra.NoArgs,
+	Run:  runNamespaces,
+}
+
+func init() {
+	rootCmd.AddCommand(namespacesCmd)
+	namespacesCmd.Flags().StringP("output", "o", "", "Output format: json, yaml or posix")
+	_ = namespacesCmd.RegisterFlagCompletionFunc("output", completeOutputFormats("json", "yaml", "posix"))
+	namespacesCmd.Flags().Bool("no-headers", false, "Don't print the column headers")
+}
+
+func getNamespaceOptions(cmd *cobra.Command) (*namespaceOptions, error) {
+	outputFormat, err := cmd.Flags().GetString("output")
+	if err != nil {
+		return nil, fmt.Errorf("error getting output format flag: %v", err)
+	}
+
+	noHeaders, err := cmd.Flags().GetBool("no-headers")
+	if err != nil {
+		return nil, fmt.Errorf("error getting no-headers flag: %v", err)
+	}
+
+	return &namespaceOptions{
+		outputFormat: outputFormat,
+		noHeaders:    noHeaders,
+	}, nil
+}
+
+func runNamespaces(cmd *cobra.Command, args []string) {
+	clientset, contextNamespace, err := kubeClient(cmd)
+	if err != nil {
+		color.Red("Error creating Kubernetes client: %v", err)
+		os.Exit(1)
+	}
+
+	opts, err := getNamespaceOptions(cmd)
+	if err != nil {
+		color.Red("Error getting command options: %v", err)
+		os.Exit(1)
+	}
+
+	namespaces, err := kubernetes.ListNamespaces(cmd.Context(), clientset, contextNamespace)
+	if err != nil {
+		color.Red("Error listing namespaces: %v", err)
+		if hint := errorHint(err); hint != "" {
+			fmt.Println(hint)
+		}
+		os.Exit(1)
+	}
+
+	output, err := format.FormatNamespaces(namespaces, opts.outputFormat, opts.noHeaders)
+	if err != nil {
+		color.Red("Error formatting output: %v", err)
+		os.Exit(1)
+	}
+
+	fmt.Println(output)
+}
+
+// completeNamespaces provides dynamic completion for the --namespace flag,
+// describing every namespace with its pod count
+func completeNamespaces(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	clientset, contextNamespace, err := kubeClient(cmd)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	namespaces, err := kubernetes.ListNamespaces(cmd.Context(), clientset, contextNamespace)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	var names []string
+	for _, ns := range namespaces {
+		if !strings.HasPrefix(ns.Name, toComplete) {
+			continue
+		}
+		description := fmt.Sprintf("%d pods", ns.Pods)
+		if ns.Current {
+			description += ", current"
+		}
+		names = append(names, ns.Name+"\t"+description)
+	}
+
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
@@ -0,0 +1,157 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/term"
+)
+
+// defaultPager shows colored output when $PAGER isn't set
+const defaultPager = "less -R"
+
+// builtinPager selects the built-in pager instead of a command
+const builtinPager = "builtin"
+
+// pagerCommand returns the pager to page the output with, or "" when the
+// output isn't paged: with --no-pager, with PAGER set to "" or cat, or when
+// stdout isn't a terminal. The pager given with --pager is used instead of
+// $PAGER.
+func pagerCommand(disabled bool, pager string) string {
+	if disabled || !isTerminal(os.Stdout) {
+		return ""
+	}
+	command, ok := pager, pager != ""
+	if !ok {
+		command, ok = os.LookupEnv("PAGER")
+	}
+	if !ok {
+		command = defaultPager
+	}
+	if command = strings.TrimSpace(command); command == "cat" {
+		return ""
+	}
+	return command
+}
+
+// pagerWriter holds output back until it fills the terminal screen, then
+// starts the pager and pipes all output to it. Output that fits on the
+// screen is written to the terminal when the writer is closed.
+type pagerWriter struct {
+	command string
+	out     io.Writer
+	rows    int
+	cols    int
+
+	buf   bytes.Buffer
+	used  int
+	pager *exec.Cmd
+	pipe  io.WriteCloser
+	// quit is set when the pager exited before all output was written
+	quit bool
+}
+
+// newPagerWriter creates a pagerWriter paging with command, writing to the
+// terminal out when the output fits on it
+func newPagerWriter(command string, out *os.File) *pagerWriter {
+	cols, rows, err := term.GetSize(int(out.Fd()))
+	if err != nil || rows < 2 {
+		cols, rows = 80, 24
+	}
+	return &pagerWriter{command: command, out: out, rows: rows, cols: cols}
+}
+
+// Write implements io.Writer
+func (p *pagerWriter) Write(data []byte) (int, error) {
+	switch {
+	case p.quit:
+		// The rest isn't shown once the user quit the pager
+		return len(data), nil
+	case p.pipe != nil:
+		if _, err := p.pipe.Write(data); err != nil {
+			p.quit = true
+		}
+		return len(data), nil
+	}
+
+	p.buf.Write(data)
+	p.used += p.screenRows(data)
+	// The prompt of the shell needs a row once kubelog exits
+	if p.used >= p.rows-1 {
+		p.start()
+	}
+	return len(data), nil
+}
+
+// screenRows returns how many terminal rows the lines of data take up
+func (p *pagerWriter) screenRows(data []byte) int {
+	rows := 0
+	for _, line := range bytes.SplitAfter(data, []byte("\n")) {
+		if len(line) == 0 {
+			continue
+		}
+		rows += 1 + (utf8.RuneCount(bytes.TrimSuffix(line, []byte("\n")))-1)/p.cols
+	}
+	return rows
+}
+
+// start starts the pager with the output held back so far. If it can't be
+// started, output is written to the terminal instead.
+func (p *pagerWriter) start() {
+	args := strings.Fields(p.command)
+	pager := exec.Command(args[0], args[1:]...)
+	pager.Stdout, pager.Stderr = p.out, os.Stderr
+	// Like git, let less keep colors and quit on output that fits a screen
+	if _, ok := os.LookupEnv("LESS"); !ok {
+		pager.Env = append(os.Environ(), "LESS=FRX")
+	}
+	pipe, err := pager.StdinPipe()
+	if err == nil {
+		err = pager.Start()
+	}
+	if err != nil {
+		slog.Debug("error starting pager, writing to the terminal", "pager", p.command, "error", err)
+		_, _ = p.out.Write(p.buf.Bytes())
+		p.buf.Reset()
+		p.pipe = nopWriteCloser{p.out}
+		return
+	}
+	p.pager, p.pipe = pager, pipe
+	if _, err := pipe.Write(p.buf.Bytes()); err != nil {
+		p.quit = true
+	}
+	p.buf.Reset()
+}
+
+// Close writes output that fit on the screen to the terminal, or waits for
+// the user to quit the pager
+func (p *pagerWriter) Close() error {
+	if p.pipe == nil {
+		_, err := p.out.Write(p.buf.Bytes())
+		return err
+	}
+	p.pipe.Close()
+	if p.pager == nil {
+		return nil
+	}
+	if err := p.pager.Wait(); err != nil && !p.quit {
+		return fmt.Errorf("error running pager %q: %w", p.command, err)
+	}
+	return nil
+}
+
+// nopWriteCloser adds a Close method that does nothing to a writer
+type nopWriteCloser struct {
+	io.Writer
+}
+
+// Close implements io.Closer
+func (nopWriteCloser) Close() error {
+	return nil
+}
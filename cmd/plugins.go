@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dantech2000/kubelog/pkg/format"
+	"github.com/dantech2000/kubelog/pkg/plugin"
+	"github.com/spf13/cobra"
+)
+
+var pluginsCmd = &cobra.Command{
+	Use:   "plugins",
+	Short: "List plugins found on the PATH",
+	Long: `List the kubelog plugins found on your PATH. Like kubectl plugins, an
+executable named kubelog-foo runs as "kubelog foo", and kubelog-foo-bar as
+"kubelog foo bar", with the remaining arguments passed through. Built-in
+commands take precedence over plugins of the same name.
+
+Example usage:
+  kubelog plugins`,
+	Args: cobra.NoArgs,
+	Run:  runPlugins,
+}
+
+func init() {
+	rootCmd.AddCommand(pluginsCmd)
+}
+
+func runPlugins(cmd *cobra.Command, args []string) {
+	plugins := plugin.List()
+	if len(plugins) == 0 {
+		fmt.Println("No plugins found. Install an executable named kubelog-<name> on your PATH to add one.")
+		return
+	}
+
+	table := format.NewTable("COMMAND", "PATH", "NOTE")
+	for _, p := range plugins {
+		note := ""
+		if c, _, err := rootCmd.Find(strings.Fields(p.Name)); err == nil && c != rootCmd {
+			note = "shadowed by the built-in command"
+		}
+		table.Append("kubelog "+p.Name, p.Path, note)
+	}
+	fmt.Println(table.Render())
+}
@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/dantech2000/kubelog/pkg/config"
+	"github.com/dantech2000/kubelog/pkg/session"
+	"github.com/dantech2000/kubelog/pkg/version"
+)
+
+// newRecorder creates the --record session file and a writer recording the
+// entries written to out. The returned function flushes and closes the file,
+// reporting errors on stderr.
+func newRecorder(options *logOptions, cfg *config.Config, out io.Writer) (*session.Recorder, func(), error) {
+	file, err := os.Create(options.record)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error creating session recording: %w", err)
+	}
+	w, err := session.NewWriter(file, session.Header{
+		Kubelog:   version.CurrentVersion.String(),
+		Started:   time.Now().UTC(),
+		Context:   cfg.Context,
+		Namespace: options.namespace,
+		Args:      os.Args[1:],
+	})
+	if err != nil {
+		file.Close()
+		return nil, nil, err
+	}
+
+	closeRecording := func() {
+		err := w.Flush()
+		if closeErr := file.Close(); err == nil && closeErr != nil {
+			err = fmt.Errorf("error closing session recording: %w", closeErr)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error recording session: %v\n", err)
+		}
+	}
+	return session.NewRecorder(w, out), closeRecording, nil
+}
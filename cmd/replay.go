@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/dantech2000/kubelog/pkg/format"
+	"github.com/dantech2000/kubelog/pkg/kubernetes"
+	"github.com/dantech2000/kubelog/pkg/logging"
+	"github.com/dantech2000/kubelog/pkg/session"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var replayCmd = &cobra.Command{
+	Use:   "replay <file.klog>",
+	Short: "Show the entries of a session recording again",
+	Long: `Show the entries of a .klog session recording made with kubelog logs --record,
+without connecting to a cluster.
+
+The recorded lines are parsed again, so --level and --grep search a recording
+like a live stream, and --output exports it in any format kubelog logs writes,
+e.g. -o jsonl for jq or -o csv for a spreadsheet. Text lines are prefixed with
+the pod and container they were read from. The fieldAliases, epochUnits,
+derivedFields and maskRules of the config file apply as they do to kubelog logs.
+
+Example usage:
+  kubelog replay incident.klog
+  kubelog replay incident.klog --level ERROR --grep timeout
+  kubelog replay incident.klog -o jsonl > incident.jsonl`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runReplay(cmd, args); err != nil {
+			color.Red("Error replaying session: %v", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(replayCmd)
+	replayCmd.Flags().StringP("level", "l", "DEBUG", "Only show entries of this level or above (DEBUG, INFO, WARN, ERROR)")
+	replayCmd.Flags().StringArrayP("grep", "g", nil, "Only show lines matching this regular expression (repeatable; lines matching any of them are shown)")
+	replayCmd.Flags().StringP("output", "o", "text", "Output format: text, json, jsonl, logfmt, ecs, gelf, csv or custom-columns=HEADER:.Field,...")
+	replayCmd.Flags().StringSlice("parser", nil, "Parse lines with built-in parsers that aren't detected automatically: "+strings.Join(logging.ParserNames(), ", "))
+	_ = replayCmd.RegisterFlagCompletionFunc("level", completeLevels)
+	_ = replayCmd.RegisterFlagCompletionFunc("output", completeOutputFormats("text", "json", "jsonl", "logfmt", "ecs", "gelf", "csv", format.CustomColumnsPrefix))
+}
+
+func runReplay(cmd *cobra.Command, args []string) error {
+	level, err := cmd.Flags().GetString("level")
+	if err != nil {
+		return fmt.Errorf("error getting level flag: %v", err)
+	}
+
+	grep, err := cmd.Flags().GetStringArray("grep")
+	if err != nil {
+		return fmt.Errorf("error getting grep flag: %v", err)
+	}
+
+	output, err := cmd.Flags().GetString("output")
+	if err != nil {
+		return fmt.Errorf("error getting output flag: %v", err)
+	}
+
+	parsers, err := cmd.Flags().GetStringSlice("parser")
+	if err != nil {
+		return fmt.Errorf("error getting parser flag: %v", err)
+	}
+
+	cfg, err := loadConfig(cmd)
+	if err != nil {
+		return err
+	}
+	options := &logOptions{level: level, grep: grep, parsers: parsers, output: output}
+	pipeline, err := buildPipeline(options, cfg)
+	if err != nil {
+		return err
+	}
+	formatter, err := formatterFor(output, options)
+	if err != nil {
+		return err
+	}
+	prefix, err := format.NewPrefixTemplate(format.DefaultPrefixTemplate)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Open(args[0])
+	if err != nil {
+		return fmt.Errorf("error opening session recording: %w", err)
+	}
+	defer file.Close()
+	reader, err := session.NewReader(file)
+	if err != nil {
+		return fmt.Errorf("error reading %s: %w", args[0], err)
+	}
+
+	out := bufio.NewWriter(os.Stdout)
+	defer out.Flush()
+	if header, ok := formatter.(logging.HeaderFormatter); ok {
+		fmt.Fprintln(out, header.Header())
+	}
+	ctx := cmd.Context()
+	for ctx.Err() == nil {
+		recorded, err := reader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		entry, ok := pipeline.Process(recorded.Line)
+		if !ok {
+			continue
+		}
+		if entry.Timestamp.IsZero() && recorded.Time != nil {
+			entry.Timestamp = *recorded.Time
+		}
+		entry.Source = recorded.Source()
+
+		line := formatter.Format(entry)
+		if line == "" {
+			continue
+		}
+		if s := entry.Source; s != nil && (output == "" || output == "text") {
+			line = prefix.Colorize(kubernetes.Target{Namespace: s.Namespace, Pod: s.Pod, Container: s.Container}) + " " + line
+		}
+		if _, err := fmt.Fprintln(out, line); err != nil {
+			return err
+		}
+	}
+	return ctx.Err()
+}
@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/dantech2000/kubelog/pkg/checkpoint"
+)
+
+// checkpointInterval is how often the checkpoints of a follow session are saved,
+// so a killed session loses little of its progress
+const checkpointInterval = 5 * time.Second
+
+// saveCheckpoints saves the store every checkpointInterval until ctx is done,
+// writing errors to w
+func saveCheckpoints(ctx context.Context, store *checkpoint.Store, w io.Writer) {
+	ticker := time.NewTicker(checkpointInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := store.Save(); err != nil {
+				fmt.Fprintf(w, "Error saving checkpoints: %v\n", err)
+			}
+		}
+	}
+}
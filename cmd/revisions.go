@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/dantech2000/kubelog/pkg/kubernetes"
+	"github.com/dantech2000/kubelog/pkg/logging"
+	"github.com/fatih/color"
+	k8s "k8s.io/client-go/kubernetes"
+)
+
+// revisionReportInterval is how often error rates are reported while following
+const revisionReportInterval = 10 * time.Second
+
+// revisionColors distinguish the lines of the stable and canary revisions
+var revisionColors = map[string]*color.Color{
+	"stable": color.New(color.FgBlue),
+	"canary": color.New(color.FgYellow),
+}
+
+// streamRevisions streams the stable and canary revisions of a deployment or
+// rollout together and reports the error rate of each on stderr
+func streamRevisions(ctx context.Context, clientset k8s.Interface, options *logOptions, pipeline *logging.Pipeline, fetcherOpts []kubernetes.Option, stderr io.Writer) error {
+	workload, ok, err := kubernetes.ParseWorkload(options.podName)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("--compare-revisions requires a deployment or rollout, e.g. deployment/api")
+	}
+
+	prefix, err := newPrefixTemplate(options.prefix)
+	if err != nil {
+		return err
+	}
+
+	groups, err := kubernetes.CompareRevisionGroups(ctx, clientset, options.namespace, workload)
+	if err != nil {
+		return err
+	}
+
+	var targets []kubernetes.Target
+	groupOf := make(map[kubernetes.Target]int)
+	counters := make([]*logging.LevelCounter, len(groups))
+	pipelines := make([]*logging.Pipeline, len(groups))
+	for i, group := range groups {
+		groupTargets, err := selectTargets(ctx, clientset, options, group.Selector)
+		if err != nil {
+			return err
+		}
+		for _, target := range groupTargets {
+			groupOf[target] = i
+		}
+		targets = append(targets, groupTargets...)
+
+		// Count every entry of the revision, before the user's filters drop any
+		counters[i] = &logging.LevelCounter{}
+		pipelines[i] = pipeline.Clone()
+		pipelines[i].Transform(counters[i])
+	}
+
+	if err := kubernetes.CheckMaxStreams(targets, options.maxReqs); err != nil {
+		return err
+	}
+
+	multi := kubernetes.NewMultiLogFetcher(clientset, targets, fetcherOpts...)
+	multi.IgnoreErrors = options.ignoreErr
+	if !structuredOutput(options) {
+		multi.Prefix = func(target kubernetes.Target) string {
+			group := groups[groupOf[target]]
+			rendered, err := prefix.Render(target)
+			if err != nil {
+				rendered = target.String()
+			}
+			return revisionColors[group.Label].Sprintf("%s r%d %s", group.Label, group.Revision, rendered)
+		}
+	}
+	multi.TargetOptions = func(target kubernetes.Target) []kubernetes.Option {
+		return []kubernetes.Option{kubernetes.WithPipeline(pipelines[groupOf[target]])}
+	}
+
+	done := make(chan struct{})
+	if options.follow {
+		go func() {
+			ticker := time.NewTicker(revisionReportInterval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ticker.C:
+					printRevisionReport(stderr, groups, counters)
+				case <-done:
+					return
+				}
+			}
+		}()
+	}
+
+	err = multi.GetLogs(ctx)
+	close(done)
+	printRevisionReport(stderr, groups, counters)
+	return err
+}
+
+// printRevisionReport writes the per-revision line and error counts to w
+func printRevisionReport(w io.Writer, groups []kubernetes.RevisionGroup, counters []*logging.LevelCounter) {
+	parts := make([]string, len(groups))
+	for i, group := range groups {
+		parts[i] = revisionColors[group.Label].Sprintf("%s r%d: %s", group.Label, group.Revision, counters[i])
+	}
+	fmt.Fprintln(w, strings.Join(parts, " | "))
+}
@@ -1,9 +1,16 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"os/exec"
+	"os/signal"
+	"syscall"
 
+	"github.com/dantech2000/kubelog/pkg/glyph"
+	"github.com/dantech2000/kubelog/pkg/plugin"
+	"github.com/dantech2000/kubelog/pkg/selflog"
 	"github.com/spf13/cobra"
 )
 
@@ -19,20 +26,67 @@ It provides features such as:
 - Listing containers within a pod
 - Color-coded output for improved readability
 
+Any executable named kubelog-foo on your PATH runs as "kubelog foo", so
+subcommands can be added without changing kubelog; built-in commands take
+precedence. Run "kubelog plugins" to list them.
+
 Use "kubelog [command] --help" for more information about a command.`,
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		verbosity, _ := cmd.Flags().GetCount("verbose")
+		selflog.Setup(os.Stderr, verbosity)
+		glyph.ASCII, _ = cmd.Flags().GetBool("ascii")
+		setupColor()
+	},
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
-		fmt.Println(err)
+	if path, args, ok := findPlugin(os.Args[1:]); ok {
+		code, err := plugin.Run(path, args)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+		}
+		os.Exit(code)
+	}
+
+	// Commands run with a context cancelled by Ctrl-C or SIGTERM, so that they
+	// can stop cleanly; a second signal kills kubelog as usual
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
+	if err := rootCmd.ExecuteContext(ctx); err != nil {
+		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
 }
 
+// findPlugin returns the plugin handling args when they do not name a built-in command
+func findPlugin(args []string) (string, []string, bool) {
+	if len(args) == 0 {
+		return "", nil, false
+	}
+	rootCmd.InitDefaultHelpCmd()
+	rootCmd.InitDefaultCompletionCmd()
+	if cmd, _, err := rootCmd.Find(args); err == nil && cmd != rootCmd {
+		return "", nil, false
+	}
+	return plugin.Lookup(args, exec.LookPath)
+}
+
 func init() {
 	// Here you can define flags and configuration settings that are global to all commands.
 	// For example, setting a default namespace.
 	rootCmd.PersistentFlags().StringP("namespace", "n", "default", "Kubernetes namespace")
+	rootCmd.PersistentFlags().CountP("verbose", "v", "Log kubelog's own activity to stderr: -v for progress, -vv for debugging")
+	rootCmd.PersistentFlags().String("context", "", "Kubeconfig context to use, instead of the one chosen with 'kubelog contexts use' or the kubeconfig's current context")
+	_ = rootCmd.RegisterFlagCompletionFunc("context", completeContextFlag)
+	rootCmd.PersistentFlags().String("config", "", "Path to the kubelog config file (defaults to $KUBELOG_CONFIG or kubelog/config.yaml in the user config directory)")
+	rootCmd.PersistentFlags().String("theme", "", "Color theme: auto, dark, light or colorblind (defaults to the theme config key, or auto, which detects the terminal background)")
+	_ = rootCmd.RegisterFlagCompletionFunc("theme", completeThemes)
+	rootCmd.PersistentFlags().Duration("request-timeout", 0, "Give up on API requests that take longer than this, e.g. 30s (0 means no timeout); log streams are only limited until they open")
+	rootCmd.PersistentFlags().Bool("ascii", false, "Use ASCII instead of unicode symbols, e.g. + and x for check marks and crosses and plain table borders")
 }
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/dantech2000/kubelog/pkg/sink"
+	"github.com/spf13/cobra"
+)
+
+// rotationOptions holds the settings for splitting capture files
+type rotationOptions struct {
+	every    time.Duration
+	maxSize  int64
+	compress string
+	keep     int
+	// changed is set when any of the rotation flags was given
+	changed bool
+}
+
+// getRotationOptions reads the --rotate-* flags of a command
+func getRotationOptions(cmd *cobra.Command) (rotationOptions, error) {
+	every, err := cmd.Flags().GetDuration("rotate-every")
+	if err != nil {
+		return rotationOptions{}, fmt.Errorf("error getting rotate-every flag: %v", err)
+	}
+
+	size, err := cmd.Flags().GetString("rotate-size")
+	if err != nil {
+		return rotationOptions{}, fmt.Errorf("error getting rotate-size flag: %v", err)
+	}
+	var maxSize int64
+	if size != "" {
+		if maxSize, err = sink.ParseSize(size); err != nil {
+			return rotationOptions{}, err
+		}
+	}
+
+	compress, err := cmd.Flags().GetString("rotate-compress")
+	if err != nil {
+		return rotationOptions{}, fmt.Errorf("error getting rotate-compress flag: %v", err)
+	}
+	if compress != sink.CompressNone && compress != sink.CompressGzip && compress != sink.CompressZstd {
+		return rotationOptions{}, fmt.Errorf("invalid --rotate-compress %q (expected none, gzip or zstd)", compress)
+	}
+
+	keep, err := cmd.Flags().GetInt("rotate-keep")
+	if err != nil {
+		return rotationOptions{}, fmt.Errorf("error getting rotate-keep flag: %v", err)
+	}
+	if keep < 0 {
+		return rotationOptions{}, fmt.Errorf("--rotate-keep must not be negative")
+	}
+
+	changed := cmd.Flags().Changed("rotate-every") || cmd.Flags().Changed("rotate-size") ||
+		cmd.Flags().Changed("rotate-compress") || cmd.Flags().Changed("rotate-keep")
+	return rotationOptions{every: every, maxSize: maxSize, compress: compress, keep: keep, changed: changed}, nil
+}
+
+// newFileWriter creates a capture file at path rotated with these settings
+func (o rotationOptions) newFileWriter(path string) (*sink.FileWriter, error) {
+	file, err := sink.NewFileWriter(path, o.every)
+	if err != nil {
+		return nil, err
+	}
+	file.MaxSize, file.Compress, file.Keep = o.maxSize, o.compress, o.keep
+	return file, nil
+}
@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/dantech2000/kubelog/pkg/kubernetes"
+)
+
+// Modes of --clock-skew
+const (
+	skewReport  = "report"
+	skewCorrect = "correct"
+)
+
+// skewThreshold is the smallest skew reported; smaller offsets are the delay
+// of delivering lines and the precision of logged timestamps
+const skewThreshold = time.Second
+
+// printClockSkew writes the streams whose logged timestamps are systematically
+// off from the time their lines were received
+func printClockSkew(w io.Writer, skew *kubernetes.ClockSkew) {
+	skews := skew.Skews()
+	targets := make([]kubernetes.Target, 0, len(skews))
+	for target, d := range skews {
+		if d >= skewThreshold || d <= -skewThreshold {
+			targets = append(targets, target)
+		}
+	}
+	if len(targets) == 0 {
+		return
+	}
+	sort.Slice(targets, func(i, j int) bool { return targets[i].String() < targets[j].String() })
+
+	fmt.Fprintln(w, "\nClock skew of logged timestamps (compared to the time lines were received):")
+	for _, target := range targets {
+		d := skews[target].Round(time.Millisecond)
+		direction := "ahead"
+		if d < 0 {
+			d, direction = -d, "behind"
+		}
+		fmt.Fprintf(w, "  %s %s %s\n", target, d, direction)
+	}
+	if skew.Correct {
+		fmt.Fprintln(w, "Lines were ordered by the corrected timestamps.")
+	}
+}
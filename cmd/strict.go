@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/dantech2000/kubelog/pkg/logging"
+	"github.com/fatih/color"
+)
+
+var parseProblemColor = color.New(color.FgYellow)
+
+// printParseProblems returns the --strict-lines callback, which writes every
+// line that didn't parse cleanly to w after its problems
+func printParseProblems(w io.Writer) func(logging.LogEntry, []logging.ParseProblem) {
+	return func(entry logging.LogEntry, problems []logging.ParseProblem) {
+		descriptions := make([]string, len(problems))
+		for i, p := range problems {
+			descriptions[i] = p.String()
+		}
+		fmt.Fprintf(w, "%s %s\n", parseProblemColor.Sprintf("[%s]", strings.Join(descriptions, "; ")), entry.RawLine)
+	}
+}
+
+// reportParseProblems writes the --strict counts to w, and returns an error
+// when any line didn't parse cleanly, so that kubelog exits with status 1
+func reportParseProblems(w io.Writer, checker *logging.ParseChecker) error {
+	fmt.Fprintf(w, "\n%s\n", checker)
+	if failed := checker.Failed(); failed > 0 {
+		return fmt.Errorf("--strict: %d lines didn't parse cleanly", failed)
+	}
+	return nil
+}
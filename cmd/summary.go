@@ -0,0 +1,238 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/dantech2000/kubelog/pkg/format"
+	"github.com/dantech2000/kubelog/pkg/glyph"
+	"github.com/dantech2000/kubelog/pkg/kubernetes"
+	"github.com/dantech2000/kubelog/pkg/logging"
+	"github.com/fatih/color"
+)
+
+// summaryInterval is how often the summary and counts are printed while following
+const summaryInterval = 5 * time.Second
+
+// sessionTopErrors is the number of error fingerprints in the session report
+const sessionTopErrors = 3
+
+// podSummary tallies the entries of every pod: their levels, the HTTP status
+// codes of access log entries and rolling latency percentiles of the entries
+// logging a duration, along with the lines of every container stream and the
+// most frequent errors of all pods
+type podSummary struct {
+	pipeline *logging.Pipeline
+	start    time.Time
+	errors   *logging.ErrorCounter
+
+	mu      sync.Mutex
+	streams map[kubernetes.Target]*streamStats
+	stats   map[string]*podStats
+}
+
+// podStats are the statistics of a single pod
+type podStats struct {
+	levels  *logging.LevelCounter
+	status  *logging.StatusCounter
+	latency *logging.LatencyRecorder
+}
+
+// streamStats are the pipeline and line counts of a single container stream
+type streamStats struct {
+	pipeline *logging.Pipeline
+	lines    *logging.LevelCounter
+}
+
+// newPodSummary creates a summary of the entries of pipeline
+func newPodSummary(pipeline *logging.Pipeline) *podSummary {
+	return &podSummary{
+		pipeline: pipeline,
+		start:    time.Now(),
+		errors:   &logging.ErrorCounter{},
+		streams:  make(map[kubernetes.Target]*streamStats),
+		stats:    make(map[string]*podStats),
+	}
+}
+
+// pipelineFor returns the pipeline for a container stream, which sees every
+// entry before the user's filters drop any
+func (s *podSummary) pipelineFor(target kubernetes.Target) *logging.Pipeline {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if stream, ok := s.streams[target]; ok {
+		return stream.pipeline
+	}
+	stats, ok := s.stats[target.Pod]
+	if !ok {
+		stats = &podStats{
+			levels:  &logging.LevelCounter{},
+			status:  &logging.StatusCounter{},
+			latency: &logging.LatencyRecorder{},
+		}
+		s.stats[target.Pod] = stats
+	}
+	stream := &streamStats{pipeline: s.pipeline.Clone(), lines: &logging.LevelCounter{}}
+	stream.pipeline.Transform(stream.lines, stats.levels, stats.status, stats.latency, s.errors)
+	s.streams[target] = stream
+	return stream.pipeline
+}
+
+// report calls print every summaryInterval while following, and once more
+// when the returned function is called
+func (s *podSummary) report(w io.Writer, follow bool, print func(io.Writer)) func() {
+	done := make(chan struct{})
+	if follow {
+		go func() {
+			ticker := time.NewTicker(summaryInterval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ticker.C:
+					print(w)
+				case <-done:
+					return
+				}
+			}
+		}()
+	}
+	return func() {
+		close(done)
+		print(w)
+	}
+}
+
+// pods returns the names of the pods seen so far in sorted order; the caller holds s.mu
+func (s *podSummary) pods() []string {
+	pods := make([]string, 0, len(s.stats))
+	for pod := range s.stats {
+		pods = append(pods, pod)
+	}
+	sort.Strings(pods)
+	return pods
+}
+
+// print writes a table of the status code counts and latency percentiles of every pod
+func (s *podSummary) print(w io.Writer) {
+	s.mu.Lock()
+	pods := s.pods()
+	table := format.NewTable("POD", "2XX", "3XX", "4XX", "5XX", "TOTAL", "P50", "P95", "P99")
+	for _, pod := range pods {
+		c, l := s.stats[pod].status, s.stats[pod].latency
+		table.Append(pod,
+			strconv.FormatUint(c.Count(2), 10),
+			strconv.FormatUint(c.Count(3), 10),
+			countCell(c.Count(4), color.FgYellow),
+			countCell(c.Count(5), color.FgRed),
+			strconv.FormatUint(c.Total(), 10),
+			percentileCell(l, 50),
+			percentileCell(l, 95),
+			percentileCell(l, 99))
+	}
+	s.mu.Unlock()
+
+	fmt.Fprintf(w, "\nSummary at %s\n%s\n\n", time.Now().Format("15:04:05"), table.Render())
+}
+
+// printCounts writes a table of the lines every container stream contributed
+// and their share of all lines, with the errors among them
+func (s *podSummary) printCounts(w io.Writer) {
+	s.mu.Lock()
+	targets := make([]kubernetes.Target, 0, len(s.streams))
+	var total uint64
+	for target, stream := range s.streams {
+		targets = append(targets, target)
+		total += stream.lines.Total()
+	}
+	sort.Slice(targets, func(i, j int) bool { return targets[i].String() < targets[j].String() })
+
+	table := format.NewTable("POD", "CONTAINER", "LINES", "SHARE", "ERRORS")
+	for _, target := range targets {
+		lines := s.streams[target].lines
+		share := 0.0
+		if total > 0 {
+			share = float64(lines.Total()) / float64(total) * 100
+		}
+		container := target.Container
+		if container == "" {
+			container = "-"
+		}
+		table.Append(target.Pod, container,
+			strconv.FormatUint(lines.Total(), 10),
+			fmt.Sprintf("%.1f%%", share),
+			countCell(lines.Count(logging.ERROR), color.FgRed))
+	}
+	s.mu.Unlock()
+
+	fmt.Fprintf(w, "\nLines per container at %s\n%s\n\n", time.Now().Format("15:04:05"), table.Render())
+}
+
+// printSession writes a report of the whole session: its duration, the lines
+// per level and per pod, and the most frequent errors
+func (s *podSummary) printSession(w io.Writer) {
+	s.mu.Lock()
+	pods := s.pods()
+	var levels [logging.ERROR + 1]uint64
+	var total uint64
+	podCounts := make([]string, len(pods))
+	for i, pod := range pods {
+		c := s.stats[pod].levels
+		for level := logging.DEBUG; level <= logging.ERROR; level++ {
+			levels[level] += c.Count(level)
+		}
+		total += c.Total()
+		podCounts[i] = fmt.Sprintf("%s %d", pod, c.Total())
+	}
+	s.mu.Unlock()
+
+	levelCounts := make([]string, 0, len(levels))
+	for level := logging.DEBUG; level <= logging.ERROR; level++ {
+		levelCounts = append(levelCounts, fmt.Sprintf("%s %d", level, levels[level]))
+	}
+
+	fmt.Fprintf(w, "\nSession summary: %s, %d lines\n", time.Since(s.start).Truncate(time.Second), total)
+	fmt.Fprintf(w, "  Levels: %s\n", strings.Join(levelCounts, ", "))
+	if len(pods) > 1 {
+		fmt.Fprintf(w, "  Pods:   %s\n", strings.Join(podCounts, ", "))
+	}
+	if top := s.errors.Top(sessionTopErrors); len(top) > 0 {
+		fmt.Fprintln(w, "  Top errors:")
+		for _, e := range top {
+			example, _, _ := strings.Cut(e.Example, "\n")
+			fmt.Fprintf(w, "    %5d%s %s\n", e.Count, glyph.Times, color.RedString(truncate(example, 100)))
+		}
+	}
+}
+
+// truncate shortens s to at most n runes, marking the cut with an ellipsis
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	ellipsis := glyph.Ellipsis.String()
+	return string(runes[:n-utf8.RuneCountInString(ellipsis)]) + ellipsis
+}
+
+// countCell renders a count, colored when it is not zero
+func countCell(n uint64, attr color.Attribute) string {
+	if n == 0 {
+		return "0"
+	}
+	return color.New(attr).Sprint(n)
+}
+
+// percentileCell renders a latency percentile, or - when no durations were logged
+func percentileCell(l *logging.LatencyRecorder, p float64) string {
+	d, ok := l.Percentile(p)
+	if !ok {
+		return "-"
+	}
+	return logging.HumanizeDuration(d)
+}
@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/dantech2000/kubelog/pkg/kubernetes"
+	"github.com/spf13/cobra"
+)
+
+var systemCmd = &cobra.Command{
+	Use:   "system <component>",
+	Short: "Stream the logs of a cluster component such as coredns or the API server",
+	Long: `Stream the logs of a cluster component without looking up its namespace, labels
+and log format. The pods of the component are selected in the kube-system
+namespace and its lines are parsed with the right parser, e.g. klog for the
+control plane components and kube-proxy.
+
+Components:
+` + systemComponentList() + `
+
+The control plane components are found on clusters that run them as pods, such
+as kubeadm clusters; managed clusters usually don't. Every flag of kubelog logs
+can be used: -n overrides the namespace, -c the container, and a --selector is
+added to the component's one.
+
+Example usage:
+  kubelog system coredns -f
+  kubelog system apiserver --since 10m -l error
+  kubelog system kube-proxy --grep 'iptables' -f`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeSystemComponents,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runSystem(cmd, args[0]); err != nil {
+			fmt.Fprintf(os.Stderr, "Error running system command: %v\n", err)
+			if hint := errorHint(err); hint != "" {
+				fmt.Fprintln(os.Stderr, hint)
+			}
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(systemCmd)
+	// The flags of the logs command, registered by its init as logs.go sorts first
+	systemCmd.Flags().AddFlagSet(logsCmd.Flags())
+
+	_ = systemCmd.RegisterFlagCompletionFunc("namespace", completeNamespaces)
+	_ = systemCmd.RegisterFlagCompletionFunc("suppress", completeSuppressPresets)
+}
+
+// runSystem streams the logs of a system component with the logs command,
+// its flags preset for the component
+func runSystem(cmd *cobra.Command, name string) error {
+	component, err := kubernetes.LookupSystemComponent(name)
+	if err != nil {
+		return err
+	}
+
+	flags := cmd.Flags()
+	if !flags.Changed("namespace") {
+		if err := flags.Set("namespace", kubernetes.SystemNamespace); err != nil {
+			return err
+		}
+	}
+	selector := component.Selector
+	if extra, _ := flags.GetString("selector"); extra != "" {
+		selector += "," + extra
+	}
+	if err := flags.Set("selector", selector); err != nil {
+		return err
+	}
+	if component.Container != "" && !flags.Changed("container") {
+		if err := flags.Set("container", component.Container); err != nil {
+			return err
+		}
+	}
+	// Raw lines aren't parsed at all
+	if raw, _ := flags.GetBool("raw"); component.Parser != "" && !raw {
+		if err := flags.Set("parser", component.Parser); err != nil {
+			return err
+		}
+	}
+	return runLogs(cmd, nil)
+}
+
+// systemComponentList lists the known components with their descriptions
+func systemComponentList() string {
+	var lines []string
+	for _, name := range kubernetes.SystemComponentNames() {
+		lines = append(lines, fmt.Sprintf("  %-20s %s", name, kubernetes.SystemComponents[name].Description))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// completeSystemComponents completes the names of the known components
+func completeSystemComponents(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	var names []string
+	for _, name := range kubernetes.SystemComponentNames() {
+		names = append(names, name+"\t"+kubernetes.SystemComponents[name].Description)
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/dantech2000/kubelog/pkg/logging"
+	"github.com/dantech2000/kubelog/pkg/sink"
+)
+
+// parseTee splits a --tee value such as json=app.jsonl into the output format
+// and the destination. The destination follows the last =, as custom-columns
+// formats contain one themselves.
+func parseTee(spec string) (string, string, error) {
+	i := strings.LastIndex(spec, "=")
+	if i <= 0 || i == len(spec)-1 {
+		return "", "", fmt.Errorf("invalid --tee %q (expected FORMAT=DESTINATION, e.g. json=app.jsonl)", spec)
+	}
+	return spec[:i], spec[i+1:], nil
+}
+
+// newTee opens the sinks given with --tee, writing every entry shown to them
+// as well as to out. GELF is sent to a Graylog address; every other format is
+// written to a file.
+func newTee(options *logOptions, out io.Writer) (*sink.Tee, error) {
+	var sinks []sink.Sink
+	closeSinks := func() {
+		for _, s := range sinks {
+			s.Writer.Close()
+		}
+	}
+
+	for _, spec := range options.tees {
+		output, dest, err := parseTee(spec)
+		if err != nil {
+			closeSinks()
+			return nil, err
+		}
+		formatter, err := formatterFor(output, options)
+		if err != nil {
+			closeSinks()
+			return nil, fmt.Errorf("invalid --tee %q: %w", spec, err)
+		}
+
+		var w io.WriteCloser
+		if output == "gelf" {
+			w, err = sink.NewGELFWriter(dest)
+		} else {
+			var file *sink.FileWriter
+			file, err = sink.NewFileWriter(dest, 0)
+			if header, ok := formatter.(logging.HeaderFormatter); ok && err == nil && !options.noHeaders {
+				file.Header = header.Header()
+			}
+			w = file
+		}
+		if err != nil {
+			closeSinks()
+			return nil, fmt.Errorf("error opening --tee %q: %w", spec, err)
+		}
+		sinks = append(sinks, sink.Sink{Name: spec, Formatter: formatter, Writer: w})
+	}
+	return sink.NewTee(out, sinks...), nil
+}
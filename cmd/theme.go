@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"os"
+	"regexp"
+	"time"
+
+	"github.com/dantech2000/kubelog/pkg/config"
+	"github.com/dantech2000/kubelog/pkg/logging"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// backgroundQueryTimeout is how long the terminal has to report its background
+const backgroundQueryTimeout = 200 * time.Millisecond
+
+// deviceAttributesReply matches the reply to the DA1 query, which terminals
+// send after the reply to the background query, if any
+var deviceAttributesReply = regexp.MustCompile(`\x1b\[\?[0-9;]*c`)
+
+// setupTheme applies the color theme chosen with --theme, or in the config
+// file, detecting the terminal's background for auto, the default
+func setupTheme(cmd *cobra.Command, cfg *config.Config) error {
+	name, err := cmd.Flags().GetString("theme")
+	if err != nil {
+		return err
+	}
+	if name == "" {
+		name = cfg.Theme
+	}
+	if name == "" || name == logging.ThemeAuto {
+		if color.NoColor || !isTerminal(os.Stdout) {
+			return nil
+		}
+		name = detectTheme()
+	}
+	theme, err := logging.LookupTheme(name)
+	if err != nil {
+		return err
+	}
+	logging.SetTheme(theme)
+	return nil
+}
+
+// detectTheme returns the theme readable on the terminal's background, from
+// $COLORFGBG or by asking the terminal, and dark when it can't be told
+func detectTheme() string {
+	if name, ok := logging.ParseColorFGBG(os.Getenv("COLORFGBG")); ok {
+		return name
+	}
+	if name, ok := queryBackground(); ok {
+		return name
+	}
+	return "dark"
+}
+
+// queryBackground asks the terminal for its background color with OSC 11
+func queryBackground() (string, bool) {
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		return "", false
+	}
+	defer tty.Close()
+
+	// Fd would make the terminal blocking, which rules out a read deadline
+	conn, err := tty.SyscallConn()
+	if err != nil {
+		return "", false
+	}
+	var state *term.State
+	if err := conn.Control(func(fd uintptr) { state, err = term.MakeRaw(int(fd)) }); err != nil || state == nil {
+		return "", false
+	}
+	defer func() {
+		_ = conn.Control(func(fd uintptr) { _ = term.Restore(int(fd), state) })
+	}()
+
+	if err := tty.SetReadDeadline(time.Now().Add(backgroundQueryTimeout)); err != nil {
+		return "", false
+	}
+	// Every terminal answers DA1, so terminals ignoring OSC 11 don't make
+	// kubelog wait for the timeout
+	if _, err := tty.WriteString("\x1b]11;?\x1b\\\x1b[c"); err != nil {
+		return "", false
+	}
+	var reply []byte
+	buf := make([]byte, 64)
+	for !deviceAttributesReply.Match(reply) {
+		n, err := tty.Read(buf)
+		reply = append(reply, buf[:n]...)
+		if err != nil {
+			break
+		}
+	}
+	return logging.ParseBackgroundReply(string(reply))
+}
+
+// completeThemes completes the names of the themes
+func completeThemes(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return append([]string{logging.ThemeAuto}, logging.ThemeNames()...), cobra.ShellCompDirectiveNoFileComp
+}
@@ -1,16 +1,24 @@
 package cmd
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"net/http"
 	"os"
 	"runtime"
+	"time"
 
 	"github.com/dantech2000/kubelog/pkg/version"
+	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v2"
 )
 
+// updateCheckTimeout bounds the request to the GitHub releases API, so the
+// check does not hang when offline
+const updateCheckTimeout = 3 * time.Second
+
 // versionData represents the structured version information
 type versionData struct {
 	Version   string `json:"version" yaml:"version"`
@@ -19,6 +27,16 @@ type versionData struct {
 	GoVersion string `json:"goVersion" yaml:"goVersion"`
 	OS        string `json:"os" yaml:"os"`
 	Arch      string `json:"arch" yaml:"arch"`
+	// Update is only set with --check
+	Update *updateData `json:"update,omitempty" yaml:"update,omitempty"`
+}
+
+// updateData represents the result of checking for a newer release
+type updateData struct {
+	LatestVersion string `json:"latestVersion,omitempty" yaml:"latestVersion,omitempty"`
+	Available     bool   `json:"available" yaml:"available"`
+	URL           string `json:"url,omitempty" yaml:"url,omitempty"`
+	Error         string `json:"error,omitempty" yaml:"error,omitempty"`
 }
 
 var versionCmd = &cobra.Command{
@@ -34,7 +52,9 @@ This command shows detailed information about the build, including:
 - Operating system and architecture
 
 You can use the --short flag to show only the version number,
-or specify an output format using the --output flag.`,
+or specify an output format using the --output flag. With --check the latest
+release on GitHub is looked up to tell whether an update is available; when
+offline the check gives up after a few seconds without failing.`,
 	Example: `  # Show full version information
   kubelog version
   
@@ -45,7 +65,10 @@ or specify an output format using the --output flag.`,
   kubelog version --output json
   
   # Get version info in YAML format
-  kubelog version --output yaml`,
+  kubelog version --output yaml
+
+  # Check whether a newer version is available
+  kubelog version --check`,
 	Run: runVersion,
 }
 
@@ -53,9 +76,11 @@ func init() {
 	rootCmd.AddCommand(versionCmd)
 	versionCmd.Flags().BoolP("short", "s", false, "Print just the version number")
 	versionCmd.Flags().StringP("output", "o", "", "Output format (json or yaml)")
+	_ = versionCmd.RegisterFlagCompletionFunc("output", completeOutputFormats("json", "yaml"))
+	versionCmd.Flags().Bool("check", false, "Check GitHub for a newer release")
 }
 
-func getVersionData(version version.Version) versionData {
+func getVersionData(version version.Version, update *updateData) versionData {
 	return versionData{
 		Version:   version.String(),
 		Commit:    version.CommitHash,
@@ -63,35 +88,79 @@ func getVersionData(version version.Version) versionData {
 		GoVersion: runtime.Version(),
 		OS:        runtime.GOOS,
 		Arch:      runtime.GOARCH,
+		Update:    update,
+	}
+}
+
+// checkForUpdate looks up the latest release. Errors, e.g. when offline, are
+// reported in the result rather than failing the command.
+func checkForUpdate(ctx context.Context, current version.Version) *updateData {
+	ctx, cancel := context.WithTimeout(ctx, updateCheckTimeout)
+	defer cancel()
+
+	info, err := version.CheckForUpdate(ctx, http.DefaultClient, version.ReleasesURL, current)
+	if err != nil {
+		return &updateData{Error: err.Error()}
+	}
+	return &updateData{
+		LatestVersion: info.Latest.String(),
+		Available:     info.Available,
+		URL:           info.URL,
+	}
+}
+
+// printUpdate describes the result of the update check
+func printUpdate(current version.Version, update *updateData) {
+	switch {
+	case update.Error != "":
+		fmt.Fprintf(os.Stderr, "Could not check for updates: %s\n", update.Error)
+	case update.Available:
+		fmt.Printf("A new version of kubelog is available: %s (current %s)\n", color.GreenString(update.LatestVersion), current.String())
+		fmt.Printf("Upgrade with 'brew upgrade kubelog' or download it from %s\n", update.URL)
+	default:
+		fmt.Printf("kubelog %s is up to date\n", current.String())
 	}
 }
 
 func runVersion(cmd *cobra.Command, args []string) {
 	short, _ := cmd.Flags().GetBool("short")
 	output, _ := cmd.Flags().GetString("output")
+	check, _ := cmd.Flags().GetBool("check")
 
 	version := version.CurrentVersion
 
+	var update *updateData
+	if check {
+		update = checkForUpdate(cmd.Context(), version)
+	}
+
 	if short {
 		fmt.Println(version.String())
+		if update != nil {
+			printUpdate(version, update)
+		}
 		return
 	}
 
 	switch output {
 	case "json":
-		printJSON(version)
+		printJSON(version, update)
 	case "yaml":
-		printYAML(version)
+		printYAML(version, update)
 	case "":
 		fmt.Println(version.FullString())
+		if update != nil {
+			fmt.Println()
+			printUpdate(version, update)
+		}
 	default:
 		fmt.Printf("Error: unsupported output format %q\n", output)
 		os.Exit(1)
 	}
 }
 
-func printJSON(version version.Version) {
-	data := getVersionData(version)
+func printJSON(version version.Version, update *updateData) {
+	data := getVersionData(version, update)
 	jsonData, err := json.MarshalIndent(data, "", "  ")
 	if err != nil {
 		fmt.Printf("Error creating JSON output: %v\n", err)
@@ -100,8 +169,8 @@ func printJSON(version version.Version) {
 	fmt.Println(string(jsonData))
 }
 
-func printYAML(version version.Version) {
-	data := getVersionData(version)
+func printYAML(version version.Version, update *updateData) {
+	data := getVersionData(version, update)
 	yamlData, err := yaml.Marshal(data)
 	if err != nil {
 		fmt.Printf("Error creating YAML output: %v\n", err)
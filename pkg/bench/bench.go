@@ -0,0 +1,135 @@
+// Package bench measures how fast log lines are parsed and formatted, to
+// compare hardware and catch performance regressions.
+package bench
+
+import (
+	"fmt"
+	"math/rand"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/dantech2000/kubelog/pkg/logging"
+)
+
+// Result is the throughput and allocations of a benchmark run
+type Result struct {
+	// Lines is how many lines were processed
+	Lines int64
+	// Bytes is the size of the lines processed
+	Bytes int64
+	// Elapsed is how long processing the lines took
+	Elapsed time.Duration
+	// Allocs is how many heap allocations processing the lines made
+	Allocs uint64
+	// AllocBytes is how many bytes those allocations took
+	AllocBytes uint64
+}
+
+// LinesPerSecond returns how many lines were processed per second
+func (r Result) LinesPerSecond() float64 {
+	if r.Elapsed <= 0 {
+		return 0
+	}
+	return float64(r.Lines) / r.Elapsed.Seconds()
+}
+
+// BytesPerSecond returns how many bytes of lines were processed per second
+func (r Result) BytesPerSecond() float64 {
+	if r.Elapsed <= 0 {
+		return 0
+	}
+	return float64(r.Bytes) / r.Elapsed.Seconds()
+}
+
+// AllocsPerLine returns the average number of allocations per line
+func (r Result) AllocsPerLine() float64 {
+	if r.Lines == 0 {
+		return 0
+	}
+	return float64(r.Allocs) / float64(r.Lines)
+}
+
+// AllocBytesPerLine returns the average number of bytes allocated per line
+func (r Result) AllocBytesPerLine() float64 {
+	if r.Lines == 0 {
+		return 0
+	}
+	return float64(r.AllocBytes) / float64(r.Lines)
+}
+
+// Run parses the lines with the pipeline, and formats the entries with the
+// formatter unless it is nil, going through the lines again and again until
+// the duration has passed, and at least once. Filtered out lines count as
+// processed.
+func Run(lines []string, pipeline *logging.Pipeline, formatter logging.Formatter, duration time.Duration) Result {
+	var result Result
+	if len(lines) == 0 {
+		return result
+	}
+	var size int64
+	for _, line := range lines {
+		size += int64(len(line))
+	}
+
+	runtime.GC()
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	start := time.Now()
+	for {
+		for _, line := range lines {
+			entry, ok := pipeline.Process(line)
+			if ok && formatter != nil {
+				_ = formatter.Format(entry)
+			}
+		}
+		result.Lines += int64(len(lines))
+		result.Bytes += size
+		if result.Elapsed = time.Since(start); result.Elapsed >= duration {
+			break
+		}
+	}
+	runtime.ReadMemStats(&after)
+	result.Allocs = after.Mallocs - before.Mallocs
+	result.AllocBytes = after.TotalAlloc - before.TotalAlloc
+	return result
+}
+
+// syntheticServices are the services synthetic lines are logged by
+var syntheticServices = []string{"checkout", "cart", "payments", "inventory", "auth"}
+
+// syntheticLevels are the levels of synthetic lines, weighted like those of
+// a typical service
+var syntheticLevels = []string{"debug", "info", "info", "info", "info", "info", "warn", "error"}
+
+// SyntheticLines returns n log lines in a mix of the formats kubelog parses:
+// mostly JSON, with klog, Spring Boot, Envoy access log and plain text lines.
+// The lines are the same for every call with the same n.
+func SyntheticLines(n int) []string {
+	r := rand.New(rand.NewSource(1))
+	ts := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	lines := make([]string, n)
+	for i := range lines {
+		ts = ts.Add(time.Duration(r.Intn(50)) * time.Millisecond)
+		level := syntheticLevels[r.Intn(len(syntheticLevels))]
+		service := syntheticServices[r.Intn(len(syntheticServices))]
+		switch r.Intn(10) {
+		case 0:
+			lines[i] = fmt.Sprintf(`I%s    %d controller.go:%d] "Reconciled object" kind="Deployment" name=%q attempt=%d`,
+				ts.Format("0102 15:04:05.000000"), 1000+r.Intn(9000), 100+r.Intn(900), service, 1+r.Intn(3))
+		case 1:
+			lines[i] = fmt.Sprintf("%s  %5s %d --- [nio-8080-exec-%d] c.e.%s.OrderController : Processed order %d in %d ms",
+				ts.Format("2006-01-02 15:04:05.000"), strings.ToUpper(level), 1+r.Intn(99999), 1+r.Intn(10), service, r.Intn(1000000), r.Intn(2000))
+		case 2:
+			lines[i] = fmt.Sprintf(`[%s] "GET /api/v1/%s/%d HTTP/1.1" %d - 0 %d %d %d "10.0.%d.%d" "curl/8.0" "%08x-1f7a-4f4e-9d2b-1c2d3e4f5a6b" "%s:8080" "10.1.2.3:8080"`,
+				ts.Format("2006-01-02T15:04:05.000Z"), service, r.Intn(10000), []int{200, 200, 200, 201, 404, 503}[r.Intn(6)],
+				r.Intn(50000), r.Intn(500), r.Intn(500), r.Intn(256), r.Intn(256), r.Uint32(), service)
+		case 3:
+			lines[i] = fmt.Sprintf("%s %s %s worker %d finished batch %d", ts.Format("2006-01-02 15:04:05"), strings.ToUpper(level), service, r.Intn(16), r.Intn(100000))
+		default:
+			lines[i] = fmt.Sprintf(`{"time":%q,"level":%q,"msg":"request handled","service":%q,"method":"GET","path":"/api/%s","status":%d,"duration_ms":%d,"trace_id":"%016x","user":{"id":%d,"plan":"pro"}}`,
+				ts.Format(time.RFC3339Nano), level, service, service, []int{200, 200, 200, 204, 400, 500}[r.Intn(6)], r.Intn(1500), r.Uint64(), r.Intn(100000))
+		}
+	}
+	return lines
+}
@@ -0,0 +1,50 @@
+package bench
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dantech2000/kubelog/pkg/logging"
+)
+
+func TestSyntheticLines(t *testing.T) {
+	lines := SyntheticLines(200)
+	if len(lines) != 200 {
+		t.Fatalf("got %d lines, want 200", len(lines))
+	}
+	if again := SyntheticLines(200); again[199] != lines[199] {
+		t.Errorf("lines differ between calls: %q and %q", lines[199], again[199])
+	}
+
+	// Most lines are in formats whose level is parsed
+	levels := make(map[logging.LogLevel]int)
+	for _, line := range lines {
+		levels[logging.ParseLogEntry(line).Level]++
+	}
+	if levels[logging.INFO] < 50 || levels[logging.ERROR] == 0 {
+		t.Errorf("unexpected mix of levels: %v", levels)
+	}
+}
+
+func TestRun(t *testing.T) {
+	lines := SyntheticLines(100)
+	result := Run(lines, logging.NewPipeline(), logging.TextFormatter{}, 0)
+	if result.Lines != 100 {
+		t.Errorf("Lines = %d, want 100 for a single pass", result.Lines)
+	}
+	if result.Bytes == 0 || result.Allocs == 0 || result.LinesPerSecond() <= 0 {
+		t.Errorf("incomplete result: %+v", result)
+	}
+
+	result = Run(lines, logging.NewPipeline(), nil, 20*time.Millisecond)
+	if result.Elapsed < 20*time.Millisecond || result.Lines%100 != 0 {
+		t.Errorf("Run for 20ms: %+v", result)
+	}
+}
+
+func TestResult_Empty(t *testing.T) {
+	var r Result
+	if r.LinesPerSecond() != 0 || r.AllocsPerLine() != 0 {
+		t.Errorf("empty result: %v lines/s, %v allocs/line", r.LinesPerSecond(), r.AllocsPerLine())
+	}
+}
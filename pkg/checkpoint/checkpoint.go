@@ -0,0 +1,134 @@
+// Package checkpoint persists how far kubelog got in every container log
+// stream, so a later session can resume where the previous one stopped
+package checkpoint
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// fileVersion is the version of the state file format
+const fileVersion = 1
+
+// state is the layout of the state file
+type state struct {
+	Version int                  `json:"version"`
+	Cursors map[string]time.Time `json:"cursors"`
+}
+
+// Store holds the cursor of every container stream: the timestamp of the last
+// line seen, keyed by namespace/pod/container. It is safe for concurrent use.
+type Store struct {
+	path string
+
+	mu      sync.Mutex
+	cursors map[string]time.Time
+	dirty   bool
+}
+
+// DefaultPath returns the state file location: kubelog/checkpoints.json in the
+// user's cache directory
+func DefaultPath() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("error finding the user cache directory: %w", err)
+	}
+	return filepath.Join(dir, "kubelog", "checkpoints.json"), nil
+}
+
+// Load reads the state file at path, or the default location when path is
+// empty. A missing file yields an empty store, which Save creates.
+func Load(path string) (*Store, error) {
+	if path == "" {
+		var err error
+		if path, err = DefaultPath(); err != nil {
+			return nil, err
+		}
+	}
+
+	s := &Store{path: path, cursors: make(map[string]time.Time)}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading checkpoint file: %w", err)
+	}
+
+	var st state
+	if err := json.Unmarshal(data, &st); err != nil {
+		return nil, fmt.Errorf("error parsing checkpoint file %s: %w", path, err)
+	}
+	if st.Version != fileVersion {
+		return nil, fmt.Errorf("unsupported checkpoint file version %d in %s", st.Version, path)
+	}
+	for key, cursor := range st.Cursors {
+		s.cursors[key] = cursor
+	}
+	return s, nil
+}
+
+// Path returns the location of the state file
+func (s *Store) Path() string {
+	return s.path
+}
+
+// Cursor returns the timestamp of the last line seen in the stream
+func (s *Store) Cursor(key string) (time.Time, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cursor, ok := s.cursors[key]
+	return cursor, ok
+}
+
+// Advance moves the cursor of the stream to t; cursors never move backwards
+func (s *Store) Advance(key string, t time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if t.After(s.cursors[key]) {
+		s.cursors[key] = t
+		s.dirty = true
+	}
+}
+
+// Save writes the cursors to the state file if any moved since it was loaded
+// or last saved. The file is replaced atomically, so an interrupted save
+// leaves the previous checkpoints intact.
+func (s *Store) Save() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.dirty {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(state{Version: fileVersion, Cursors: s.cursors}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding checkpoints: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return fmt.Errorf("error creating checkpoint directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), ".checkpoints-*")
+	if err != nil {
+		return fmt.Errorf("error writing checkpoint file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return fmt.Errorf("error writing checkpoint file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("error writing checkpoint file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("error writing checkpoint file: %w", err)
+	}
+	s.dirty = false
+	return nil
+}
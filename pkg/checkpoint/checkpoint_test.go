@@ -0,0 +1,84 @@
+package checkpoint
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStore_SaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "checkpoints.json")
+	first := time.Date(2024, 1, 15, 10, 0, 0, 123456789, time.UTC)
+
+	s, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if _, ok := s.Cursor("default/api-0/app"); ok {
+		t.Fatal("Cursor() of a new store is set")
+	}
+	s.Advance("default/api-0/app", first.Add(time.Second))
+	s.Advance("default/api-0/app", first) // never moves backwards
+	s.Advance("default/api-1/app", first)
+	if err := s.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	tests := []struct {
+		key  string
+		want time.Time
+	}{
+		{"default/api-0/app", first.Add(time.Second)},
+		{"default/api-1/app", first},
+	}
+	for _, tt := range tests {
+		got, ok := loaded.Cursor(tt.key)
+		if !ok || !got.Equal(tt.want) {
+			t.Errorf("Cursor(%q) = %v, %v, want %v", tt.key, got, ok, tt.want)
+		}
+	}
+}
+
+func TestStore_SaveUnchanged(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkpoints.json")
+	s, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if err := s.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("Save() without changes created the state file: %v", err)
+	}
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{"not JSON", "cursors", "error parsing checkpoint file"},
+		{"unknown version", `{"version": 2, "cursors": {}}`, "unsupported checkpoint file version 2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "checkpoints.json")
+			if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			_, err := Load(path)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Load() error = %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
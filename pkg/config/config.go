@@ -0,0 +1,120 @@
+// Package config loads the kubelog configuration file
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v2"
+)
+
+// EnvPath is the environment variable overriding the configuration file location
+const EnvPath = "KUBELOG_CONFIG"
+
+// Config holds the settings read from the configuration file
+type Config struct {
+	// Context is the kubeconfig context kubelog uses instead of the
+	// kubeconfig's current context
+	Context string `yaml:"context,omitempty"`
+	// Theme is the color theme of the output: dark, light, or auto to pick
+	// the one matching the terminal's background
+	Theme string `yaml:"theme,omitempty"`
+	// FieldAliases maps field names used by in-house logging conventions to
+	// the names kubelog understands, e.g. lvl: level, "@m": message, reqId: trace_id
+	FieldAliases map[string]string `yaml:"fieldAliases,omitempty"`
+	// EpochUnits gives the unit of fields logged as numbers since the epoch,
+	// s, ms, us or ns, when it can't be told from their size, e.g. ts: ms
+	EpochUnits map[string]string `yaml:"epochUnits,omitempty"`
+	// Namespaces are searched one by one by --all-namespaces for users who
+	// may not list pods cluster-wide
+	Namespaces []string `yaml:"namespaces,omitempty"`
+	// DerivedFields are computed for every entry, in order, so later ones
+	// can use earlier ones
+	DerivedFields []DerivedField `yaml:"derivedFields,omitempty"`
+	// LoggerLevels overrides the minimum level for a logger and its children,
+	// e.g. io.netty: ERROR, myapp.db: DEBUG
+	LoggerLevels map[string]string `yaml:"loggerLevels,omitempty"`
+	// MaskRules replace sensitive text in messages and field values before
+	// entries are shown or exported, in order
+	MaskRules []MaskRule `yaml:"maskRules,omitempty"`
+}
+
+// DerivedField is a field computed from an expression over other fields,
+// e.g. latency_bucket = ceil(duration_ms/100)*100
+type DerivedField struct {
+	Name string `yaml:"name"`
+	Expr string `yaml:"expr"`
+}
+
+// MaskRule replaces the text matching a regular expression, e.g.
+// '\b\d{3}-\d{2}-\d{4}\b' with '***-**-****'
+type MaskRule struct {
+	Pattern     string `yaml:"pattern"`
+	Replacement string `yaml:"replacement"`
+}
+
+// DefaultPath returns the configuration file location: $KUBELOG_CONFIG if set,
+// otherwise kubelog/config.yaml in the user's configuration directory
+func DefaultPath() (string, error) {
+	if path := os.Getenv(EnvPath); path != "" {
+		return path, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("error finding the user config directory: %w", err)
+	}
+	return filepath.Join(dir, "kubelog", "config.yaml"), nil
+}
+
+// Load reads the configuration file at path. With an empty path the default
+// location is used, and a missing file there yields an empty configuration.
+func Load(path string) (*Config, error) {
+	optional := path == ""
+	if optional {
+		var err error
+		if path, err = DefaultPath(); err != nil {
+			return nil, err
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if optional && errors.Is(err, fs.ErrNotExist) {
+		return &Config{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading config file: %w", err)
+	}
+
+	var cfg Config
+	if err := yaml.UnmarshalStrict(data, &cfg); err != nil {
+		return nil, fmt.Errorf("error parsing config file %s: %w", path, err)
+	}
+	return &cfg, nil
+}
+
+// Save writes the configuration to the file at path, or to the default
+// location when path is empty, creating its directory if needed. Comments in
+// an existing file are not preserved.
+func Save(path string, cfg *Config) error {
+	if path == "" {
+		var err error
+		if path, err = DefaultPath(); err != nil {
+			return err
+		}
+	}
+
+	data, err := yaml.Marshal(cfg)
+	if err != nil {
+		return fmt.Errorf("error encoding config: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("error creating config directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("error writing config file: %w", err)
+	}
+	return nil
+}
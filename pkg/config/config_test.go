@@ -0,0 +1,108 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		env     string
+		want    *Config
+		wantErr bool
+	}{
+		{
+			name: "Field aliases",
+			path: write("aliases.yaml", "fieldAliases:\n  lvl: level\n  \"@m\": message\n  reqId: trace_id\n"),
+			want: &Config{FieldAliases: map[string]string{"lvl": "level", "@m": "message", "reqId": "trace_id"}},
+		},
+		{
+			name: "Derived fields",
+			path: write("derived.yaml", "derivedFields:\n  - name: latency_bucket\n    expr: ceil(duration_ms/100)*100\n"),
+			want: &Config{DerivedFields: []DerivedField{{Name: "latency_bucket", Expr: "ceil(duration_ms/100)*100"}}},
+		},
+		{
+			name: "Logger levels",
+			path: write("levels.yaml", "loggerLevels:\n  io.netty: ERROR\n  myapp.db: DEBUG\n"),
+			want: &Config{LoggerLevels: map[string]string{"io.netty": "ERROR", "myapp.db": "DEBUG"}},
+		},
+		{
+			name: "Namespaces",
+			path: write("namespaces.yaml", "namespaces:\n  - shop\n  - payments\n"),
+			want: &Config{Namespaces: []string{"shop", "payments"}},
+		},
+		{
+			name: "Mask rules",
+			path: write("mask.yaml", "maskRules:\n  - pattern: '\\b\\d{3}-\\d{2}-\\d{4}\\b'\n    replacement: '***-**-****'\n"),
+			want: &Config{MaskRules: []MaskRule{{Pattern: `\b\d{3}-\d{2}-\d{4}\b`, Replacement: "***-**-****"}}},
+		},
+		{
+			name:    "Unknown key",
+			path:    write("unknown.yaml", "fieldAlias:\n  lvl: level\n"),
+			wantErr: true,
+		},
+		{
+			name:    "Explicit path that does not exist",
+			path:    filepath.Join(dir, "missing.yaml"),
+			wantErr: true,
+		},
+		{
+			name: "Default path that does not exist",
+			env:  filepath.Join(dir, "missing.yaml"),
+			want: &Config{},
+		},
+		{
+			name: "Default path from the environment",
+			env:  write("env.yaml", "fieldAliases:\n  severity_text: level\n"),
+			want: &Config{FieldAliases: map[string]string{"severity_text": "level"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(EnvPath, tt.env)
+			got, err := Load(tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Load() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Load() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSave(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv(EnvPath, filepath.Join(dir, "kubelog", "config.yaml"))
+
+	want := &Config{
+		Context:      "staging",
+		FieldAliases: map[string]string{"lvl": "level"},
+		LoggerLevels: map[string]string{"io.netty": "ERROR"},
+	}
+	if err := Save("", want); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	got, err := Load("")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Load() after Save() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,218 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/dantech2000/kubelog/pkg/logging"
+	"gopkg.in/yaml.v2"
+)
+
+var (
+	// ErrUnknownKey is returned for keys that are not settings of the config file
+	ErrUnknownKey = errors.New("unknown config key")
+	// ErrNotSet is returned when getting or unsetting a setting that is not set
+	ErrNotSet = errors.New("config key not set")
+)
+
+// Keys lists the settings that can be read and changed by key. Map entries
+// are addressed with a dot, e.g. loggerLevels.io.netty or fieldAliases.lvl;
+// the section name alone refers to the whole section.
+var Keys = []string{
+	"context",
+	"theme",
+	"fieldAliases.<field>",
+	"epochUnits.<field>",
+	"derivedFields.<name>",
+	"loggerLevels.<logger>",
+}
+
+// splitKey splits a key into its section and the name of an entry within
+// the section, which may itself contain dots
+func splitKey(key string) (section, name string, err error) {
+	section, name, _ = strings.Cut(key, ".")
+	switch section {
+	case "context", "theme":
+		if name != "" {
+			return "", "", fmt.Errorf("%w: %q", ErrUnknownKey, key)
+		}
+	case "fieldAliases", "epochUnits", "derivedFields", "loggerLevels":
+	default:
+		return "", "", fmt.Errorf("%w: %q (expected one of %s)", ErrUnknownKey, key, strings.Join(Keys, ", "))
+	}
+	return section, name, nil
+}
+
+// Get returns the value of a setting. Whole sections are returned as YAML.
+func (c *Config) Get(key string) (string, error) {
+	section, name, err := splitKey(key)
+	if err != nil {
+		return "", err
+	}
+
+	var value string
+	switch {
+	case section == "context":
+		value = c.Context
+	case section == "theme":
+		value = c.Theme
+	case name == "":
+		return c.section(section)
+	case section == "fieldAliases":
+		value = c.FieldAliases[name]
+	case section == "epochUnits":
+		value = c.EpochUnits[name]
+	case section == "loggerLevels":
+		value = c.LoggerLevels[name]
+	case section == "derivedFields":
+		if i := c.derivedField(name); i >= 0 {
+			value = c.DerivedFields[i].Expr
+		}
+	}
+	if value == "" {
+		return "", fmt.Errorf("%w: %q", ErrNotSet, key)
+	}
+	return value, nil
+}
+
+// section returns a whole section as YAML
+func (c *Config) section(section string) (string, error) {
+	var value interface{}
+	var entries int
+	switch section {
+	case "fieldAliases":
+		value, entries = c.FieldAliases, len(c.FieldAliases)
+	case "epochUnits":
+		value, entries = c.EpochUnits, len(c.EpochUnits)
+	case "loggerLevels":
+		value, entries = c.LoggerLevels, len(c.LoggerLevels)
+	case "derivedFields":
+		value, entries = c.DerivedFields, len(c.DerivedFields)
+	}
+	if entries == 0 {
+		return "", fmt.Errorf("%w: %q", ErrNotSet, section)
+	}
+	data, err := yaml.Marshal(value)
+	if err != nil {
+		return "", fmt.Errorf("error encoding %s: %w", section, err)
+	}
+	return strings.TrimSuffix(string(data), "\n"), nil
+}
+
+// Set validates and changes the value of a setting. Map entries are added or
+// replaced; a new derived field is evaluated after the existing ones.
+func (c *Config) Set(key, value string) error {
+	section, name, err := splitKey(key)
+	if err != nil {
+		return err
+	}
+	if section != "context" && section != "theme" && name == "" {
+		return fmt.Errorf("%s holds several entries; set one of them, e.g. %s", section, exampleKey(section))
+	}
+	if value == "" {
+		return fmt.Errorf("empty value for %q (use unset to remove it)", key)
+	}
+
+	switch section {
+	case "context":
+		c.Context = value
+	case "theme":
+		if value != logging.ThemeAuto {
+			if _, err := logging.LookupTheme(value); err != nil {
+				return err
+			}
+		}
+		c.Theme = value
+	case "fieldAliases":
+		if name == value {
+			return fmt.Errorf("field %q cannot be an alias of itself", name)
+		}
+		if c.FieldAliases == nil {
+			c.FieldAliases = make(map[string]string)
+		}
+		c.FieldAliases[name] = value
+	case "epochUnits":
+		if _, err := logging.ParseEpochUnit(value); err != nil {
+			return fmt.Errorf("invalid unit for %q: %w", key, err)
+		}
+		if c.EpochUnits == nil {
+			c.EpochUnits = make(map[string]string)
+		}
+		c.EpochUnits[name] = value
+	case "loggerLevels":
+		if _, err := logging.ParseLogLevel(value); err != nil {
+			return fmt.Errorf("invalid level for %q: %w", key, err)
+		}
+		if c.LoggerLevels == nil {
+			c.LoggerLevels = make(map[string]string)
+		}
+		c.LoggerLevels[name] = value
+	case "derivedFields":
+		if _, err := logging.CompileExpr(value); err != nil {
+			return fmt.Errorf("invalid expression for %q: %w", key, err)
+		}
+		if i := c.derivedField(name); i >= 0 {
+			c.DerivedFields[i].Expr = value
+		} else {
+			c.DerivedFields = append(c.DerivedFields, DerivedField{Name: name, Expr: value})
+		}
+	}
+	return nil
+}
+
+// Unset removes a setting, or a whole section when only its name is given
+func (c *Config) Unset(key string) error {
+	section, name, err := splitKey(key)
+	if err != nil {
+		return err
+	}
+	if _, err := c.Get(key); err != nil {
+		return err
+	}
+
+	switch {
+	case section == "context":
+		c.Context = ""
+	case section == "theme":
+		c.Theme = ""
+	case section == "fieldAliases" && name == "":
+		c.FieldAliases = nil
+	case section == "fieldAliases":
+		delete(c.FieldAliases, name)
+	case section == "epochUnits" && name == "":
+		c.EpochUnits = nil
+	case section == "epochUnits":
+		delete(c.EpochUnits, name)
+	case section == "loggerLevels" && name == "":
+		c.LoggerLevels = nil
+	case section == "loggerLevels":
+		delete(c.LoggerLevels, name)
+	case section == "derivedFields" && name == "":
+		c.DerivedFields = nil
+	case section == "derivedFields":
+		i := c.derivedField(name)
+		c.DerivedFields = append(c.DerivedFields[:i], c.DerivedFields[i+1:]...)
+	}
+	return nil
+}
+
+// derivedField returns the index of the derived field with the given name, or -1
+func (c *Config) derivedField(name string) int {
+	for i, field := range c.DerivedFields {
+		if field.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// exampleKey returns a key addressing an entry of the section
+func exampleKey(section string) string {
+	for _, key := range Keys {
+		if strings.HasPrefix(key, section+".") {
+			return key
+		}
+	}
+	return section
+}
@@ -0,0 +1,113 @@
+package config
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestConfig_Set(t *testing.T) {
+	tests := []struct {
+		name    string
+		key     string
+		value   string
+		want    *Config
+		wantErr bool
+	}{
+		{name: "Context", key: "context", value: "staging", want: &Config{Context: "staging"}},
+		{name: "Theme", key: "theme", value: "light", want: &Config{Theme: "light"}},
+		{name: "Automatic theme", key: "theme", value: "auto", want: &Config{Theme: "auto"}},
+		{name: "Unknown theme", key: "theme", value: "solarized", wantErr: true},
+		{
+			name:  "Field alias",
+			key:   "fieldAliases.lvl",
+			value: "level",
+			want:  &Config{FieldAliases: map[string]string{"lvl": "level"}},
+		},
+		{
+			name:  "Epoch unit",
+			key:   "epochUnits.ts",
+			value: "ms",
+			want:  &Config{EpochUnits: map[string]string{"ts": "ms"}},
+		},
+		{
+			name:  "Logger level with dots in the logger name",
+			key:   "loggerLevels.io.netty",
+			value: "ERROR",
+			want:  &Config{LoggerLevels: map[string]string{"io.netty": "ERROR"}},
+		},
+		{
+			name:  "Derived field",
+			key:   "derivedFields.slow",
+			value: "duration_ms > 500",
+			want:  &Config{DerivedFields: []DerivedField{{Name: "slow", Expr: "duration_ms > 500"}}},
+		},
+		{name: "Invalid level", key: "loggerLevels.io.netty", value: "LOUD", wantErr: true},
+		{name: "Invalid expression", key: "derivedFields.slow", value: "duration_ms >", wantErr: true},
+		{name: "Invalid epoch unit", key: "epochUnits.ts", value: "minutes", wantErr: true},
+		{name: "Alias of itself", key: "fieldAliases.level", value: "level", wantErr: true},
+		{name: "Whole section", key: "loggerLevels", value: "ERROR", wantErr: true},
+		{name: "Empty value", key: "context", value: "", wantErr: true},
+		{name: "Unknown key", key: "colour", value: "red", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := &Config{}
+			err := got.Set(tt.key, tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Set() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Set() config = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfig_SetReplacesDerivedField(t *testing.T) {
+	cfg := &Config{DerivedFields: []DerivedField{{Name: "a", Expr: "1"}, {Name: "b", Expr: "2"}}}
+	if err := cfg.Set("derivedFields.a", "3"); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	want := []DerivedField{{Name: "a", Expr: "3"}, {Name: "b", Expr: "2"}}
+	if !reflect.DeepEqual(cfg.DerivedFields, want) {
+		t.Errorf("DerivedFields = %+v, want %+v", cfg.DerivedFields, want)
+	}
+}
+
+func TestConfig_GetUnset(t *testing.T) {
+	cfg := &Config{
+		Context:       "prod",
+		LoggerLevels:  map[string]string{"io.netty": "ERROR", "myapp.db": "DEBUG"},
+		DerivedFields: []DerivedField{{Name: "a", Expr: "1"}, {Name: "b", Expr: "2"}},
+	}
+
+	if got, err := cfg.Get("loggerLevels.io.netty"); err != nil || got != "ERROR" {
+		t.Errorf("Get() = %q, %v, want ERROR", got, err)
+	}
+	if got, err := cfg.Get("loggerLevels"); err != nil || got != "io.netty: ERROR\nmyapp.db: DEBUG" {
+		t.Errorf("Get() section = %q, %v", got, err)
+	}
+	if _, err := cfg.Get("fieldAliases.lvl"); !errors.Is(err, ErrNotSet) {
+		t.Errorf("Get() error = %v, want ErrNotSet", err)
+	}
+	if _, err := cfg.Get("theme.dark"); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("Get() error = %v, want ErrUnknownKey", err)
+	}
+
+	for _, key := range []string{"context", "loggerLevels.io.netty", "derivedFields.a"} {
+		if err := cfg.Unset(key); err != nil {
+			t.Fatalf("Unset(%q) error = %v", key, err)
+		}
+	}
+	want := &Config{
+		LoggerLevels:  map[string]string{"myapp.db": "DEBUG"},
+		DerivedFields: []DerivedField{{Name: "b", Expr: "2"}},
+	}
+	if !reflect.DeepEqual(cfg, want) {
+		t.Errorf("Unset() config = %+v, want %+v", cfg, want)
+	}
+	if err := cfg.Unset("context"); !errors.Is(err, ErrNotSet) {
+		t.Errorf("Unset() error = %v, want ErrNotSet", err)
+	}
+}
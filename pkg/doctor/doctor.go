@@ -0,0 +1,337 @@
+// Package doctor diagnoses why kubelog cannot read logs: a broken kubeconfig,
+// an unreachable API server, a missing auth plugin, missing permissions or a
+// skewed clock
+package doctor
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os/exec"
+	"strings"
+	"time"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// MaxClockSkew is the largest difference between the local and the API
+// server's clock that is not reported; beyond it --since windows and
+// timestamps are off
+const MaxClockSkew = 10 * time.Second
+
+// Status is the outcome of a check
+type Status int
+
+const (
+	// OK means the check passed
+	OK Status = iota
+	// Warning means kubelog works but something may surprise the user
+	Warning
+	// Failed means kubelog cannot work until the problem is fixed
+	Failed
+	// Skipped means the check could not run because an earlier one failed
+	Skipped
+)
+
+// Result is the outcome of a single check with a suggested fix for problems
+type Result struct {
+	Name    string
+	Status  Status
+	Message string
+	Fix     string
+}
+
+// Options selects what is checked
+type Options struct {
+	// Context is the kubeconfig context to check; empty selects the current one
+	Context string
+	// Namespace to check permissions in; empty selects the context's namespace
+	Namespace string
+	// Timeout bounds every request to the API server
+	Timeout time.Duration
+}
+
+// checks are the names of all checks, in the order they run
+var checks = []string{"Kubeconfig", "Auth plugin", "API server", "Permissions", "Clock"}
+
+// Run performs all checks in order. Checks that depend on a failed one are
+// reported as skipped.
+func Run(ctx context.Context, opts Options) []Result {
+	var results []Result
+	skipRest := func(reason string) []Result {
+		for _, name := range checks[len(results):] {
+			results = append(results, Result{Name: name, Status: Skipped, Message: reason})
+		}
+		return results
+	}
+
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	kubeconfig, err := loadingRules.Load()
+	if err != nil {
+		results = append(results, Result{
+			Name:    "Kubeconfig",
+			Status:  Failed,
+			Message: err.Error(),
+			Fix:     "Fix the syntax of the kubeconfig file, or point $KUBECONFIG at a valid one",
+		})
+		return skipRest("kubeconfig could not be loaded")
+	}
+
+	contextName := opts.Context
+	if contextName == "" {
+		contextName = kubeconfig.CurrentContext
+	}
+	result := CheckKubeconfig(kubeconfig, contextName)
+	results = append(results, result)
+	if result.Status == Failed {
+		return skipRest("kubeconfig is invalid")
+	}
+
+	authInfo := kubeconfig.AuthInfos[kubeconfig.Contexts[contextName].AuthInfo]
+	result = CheckAuthPlugin(authInfo, exec.LookPath)
+	results = append(results, result)
+	if result.Status == Failed {
+		return skipRest("the auth plugin is not available")
+	}
+
+	clientConfig := clientcmd.NewDefaultClientConfig(*kubeconfig, &clientcmd.ConfigOverrides{CurrentContext: contextName})
+	config, err := clientConfig.ClientConfig()
+	if err != nil {
+		results[0] = Result{Name: "Kubeconfig", Status: Failed, Message: err.Error(), Fix: "Fix the context's cluster and user entries in the kubeconfig"}
+		return skipRest("kubeconfig is invalid")
+	}
+	config.Timeout = opts.Timeout
+
+	namespace := opts.Namespace
+	if namespace == "" {
+		if namespace, _, err = clientConfig.Namespace(); err != nil {
+			namespace = "default"
+		}
+	}
+
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		results = append(results, Result{Name: "API server", Status: Failed, Message: err.Error()})
+		return skipRest("no API client")
+	}
+
+	result = CheckAPIServer(clientset, config.Host)
+	results = append(results, result)
+	if result.Status == Failed {
+		return skipRest("the API server is not reachable")
+	}
+
+	results = append(results, CheckPermissions(ctx, clientset, namespace))
+	results = append(results, checkServerClock(ctx, config))
+	return results
+}
+
+// CheckKubeconfig verifies that the context exists and refers to a cluster
+// and user defined in the kubeconfig
+func CheckKubeconfig(kubeconfig *clientcmdapi.Config, contextName string) Result {
+	result := Result{Name: "Kubeconfig"}
+	if len(kubeconfig.Contexts) == 0 {
+		result.Status = Failed
+		result.Message = "no contexts found"
+		result.Fix = "Create a kubeconfig with your cloud provider's CLI (e.g. 'aws eks update-kubeconfig') or set $KUBECONFIG"
+		return result
+	}
+	if contextName == "" {
+		result.Status = Failed
+		result.Message = "no current context is set"
+		result.Fix = "Run 'kubelog contexts use <name>' or 'kubectl config use-context <name>'"
+		return result
+	}
+
+	ctx, ok := kubeconfig.Contexts[contextName]
+	if !ok {
+		result.Status = Failed
+		result.Message = fmt.Sprintf("context '%s' does not exist", contextName)
+		result.Fix = "Run 'kubelog contexts' to list the contexts and 'kubelog contexts use <name>' to pick one"
+		return result
+	}
+	if _, ok := kubeconfig.Clusters[ctx.Cluster]; !ok {
+		result.Status = Failed
+		result.Message = fmt.Sprintf("context '%s' refers to cluster '%s', which is not defined", contextName, ctx.Cluster)
+		result.Fix = "Add the cluster to the kubeconfig or recreate it with your cloud provider's CLI"
+		return result
+	}
+	if _, ok := kubeconfig.AuthInfos[ctx.AuthInfo]; !ok {
+		result.Status = Failed
+		result.Message = fmt.Sprintf("context '%s' refers to user '%s', which is not defined", contextName, ctx.AuthInfo)
+		result.Fix = "Add the user to the kubeconfig or recreate it with your cloud provider's CLI"
+		return result
+	}
+
+	result.Message = fmt.Sprintf("context '%s' (cluster '%s', user '%s')", contextName, ctx.Cluster, ctx.AuthInfo)
+	return result
+}
+
+// removedAuthProviders are in-tree auth providers that client-go no longer
+// supports, with the exec plugins replacing them
+var removedAuthProviders = map[string]string{
+	"gcp":   "gke-gcloud-auth-plugin",
+	"azure": "kubelogin",
+}
+
+// CheckAuthPlugin verifies that the exec credential plugin of the user, if
+// any, is installed. lookPath finds executables, like exec.LookPath.
+func CheckAuthPlugin(authInfo *clientcmdapi.AuthInfo, lookPath func(string) (string, error)) Result {
+	result := Result{Name: "Auth plugin"}
+	switch {
+	case authInfo == nil:
+		result.Message = "no credentials configured"
+	case authInfo.Exec != nil:
+		path, err := lookPath(authInfo.Exec.Command)
+		if err != nil {
+			result.Status = Failed
+			result.Message = fmt.Sprintf("'%s' was not found", authInfo.Exec.Command)
+			result.Fix = fmt.Sprintf("Install %s and make sure it is on your PATH", authInfo.Exec.Command)
+			if authInfo.Exec.InstallHint != "" {
+				result.Fix = strings.TrimSpace(authInfo.Exec.InstallHint)
+			}
+			return result
+		}
+		result.Message = fmt.Sprintf("exec plugin %s", path)
+	case authInfo.AuthProvider != nil:
+		result.Status = Failed
+		result.Message = fmt.Sprintf("the '%s' auth provider is no longer supported", authInfo.AuthProvider.Name)
+		result.Fix = "Switch the user to an exec credential plugin"
+		if plugin, ok := removedAuthProviders[authInfo.AuthProvider.Name]; ok {
+			result.Fix = fmt.Sprintf("Install %s and recreate the kubeconfig entry with your cloud provider's CLI", plugin)
+		}
+	default:
+		result.Message = "not needed (static credentials)"
+	}
+	return result
+}
+
+// CheckAPIServer verifies that the API server answers and credentials are accepted
+func CheckAPIServer(clientset kubernetes.Interface, host string) Result {
+	result := Result{Name: "API server"}
+	info, err := clientset.Discovery().ServerVersion()
+	if err != nil {
+		result.Status = Failed
+		result.Message = fmt.Sprintf("%s: %v", host, err)
+		result.Fix = connectionFix(err)
+		return result
+	}
+	result.Message = fmt.Sprintf("%s (Kubernetes %s)", host, info.GitVersion)
+	return result
+}
+
+// connectionFix suggests a fix for a failed connection to the API server
+func connectionFix(err error) string {
+	msg := err.Error()
+	switch {
+	case apierrors.IsUnauthorized(err):
+		return "Your credentials were rejected or have expired; log in again with your cloud provider's CLI"
+	case strings.Contains(msg, "getting credentials"):
+		return "The auth plugin could not get credentials; log in again with your cloud provider's CLI"
+	case strings.Contains(msg, "x509"):
+		return "The server certificate is not trusted; check certificate-authority-data in the kubeconfig"
+	case strings.Contains(msg, "no such host"):
+		return "The server name does not resolve; check the cluster address and your DNS or VPN"
+	case strings.Contains(msg, "connection refused"),
+		strings.Contains(msg, "timeout"),
+		strings.Contains(msg, "deadline exceeded"):
+		return "The server is unreachable; check that you are on the right network or VPN and that the cluster is running"
+	default:
+		return "Check the cluster address in the kubeconfig and run 'kubectl version' for details"
+	}
+}
+
+// requiredAccess lists the permissions kubelog needs to read logs
+var requiredAccess = []authorizationv1.ResourceAttributes{
+	{Verb: "list", Resource: "pods"},
+	{Verb: "get", Resource: "pods"},
+	{Verb: "get", Resource: "pods", Subresource: "log"},
+}
+
+// CheckPermissions verifies that the user may list pods and read their logs in the namespace
+func CheckPermissions(ctx context.Context, clientset kubernetes.Interface, namespace string) Result {
+	result := Result{Name: "Permissions"}
+	var denied []string
+	for _, attrs := range requiredAccess {
+		attrs.Namespace = namespace
+		review := &authorizationv1.SelfSubjectAccessReview{
+			Spec: authorizationv1.SelfSubjectAccessReviewSpec{ResourceAttributes: &attrs},
+		}
+		resp, err := clientset.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, review, metav1.CreateOptions{})
+		if err != nil {
+			result.Status = Warning
+			result.Message = fmt.Sprintf("could not check permissions: %v", err)
+			return result
+		}
+		if !resp.Status.Allowed {
+			resource := attrs.Resource
+			if attrs.Subresource != "" {
+				resource += "/" + attrs.Subresource
+			}
+			denied = append(denied, attrs.Verb+" "+resource)
+		}
+	}
+
+	if len(denied) > 0 {
+		result.Status = Failed
+		result.Message = fmt.Sprintf("not allowed to %s in namespace '%s'", strings.Join(denied, ", "), namespace)
+		result.Fix = "Ask your cluster administrator for a Role granting get and list on pods and get on pods/log, or try another namespace with -n"
+		return result
+	}
+	result.Message = fmt.Sprintf("can list pods and read logs in namespace '%s'", namespace)
+	return result
+}
+
+// checkServerClock compares the local clock with the Date header of the API server
+func checkServerClock(ctx context.Context, config *rest.Config) Result {
+	serverTime, err := serverDate(ctx, config)
+	if err != nil {
+		return Result{Name: "Clock", Status: Warning, Message: fmt.Sprintf("could not read the server time: %v", err)}
+	}
+	return CheckClockSkew(serverTime, time.Now())
+}
+
+// serverDate returns the time reported by the API server in its Date header
+func serverDate(ctx context.Context, config *rest.Config) (time.Time, error) {
+	client, err := rest.HTTPClientFor(config)
+	if err != nil {
+		return time.Time{}, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(config.Host, "/")+"/version", nil)
+	if err != nil {
+		return time.Time{}, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer resp.Body.Close()
+	return http.ParseTime(resp.Header.Get("Date"))
+}
+
+// CheckClockSkew reports a local clock that differs from the server's by more than MaxClockSkew
+func CheckClockSkew(serverTime, localTime time.Time) Result {
+	result := Result{Name: "Clock"}
+	skew := localTime.Sub(serverTime).Round(time.Second)
+	if skew < 0 {
+		skew = -skew
+	}
+	if skew > MaxClockSkew {
+		direction := "ahead of"
+		if localTime.Before(serverTime) {
+			direction = "behind"
+		}
+		result.Status = Warning
+		result.Message = fmt.Sprintf("local clock is %s %s the API server", skew, direction)
+		result.Fix = "Sync your clock (e.g. 'sudo timedatectl set-ntp true'); until then --since windows and timestamps are off"
+		return result
+	}
+	result.Message = fmt.Sprintf("in sync with the API server (%s skew)", skew)
+	return result
+}
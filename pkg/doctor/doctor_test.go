@@ -0,0 +1,152 @@
+package doctor
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+func TestCheckKubeconfig(t *testing.T) {
+	kubeconfig := &clientcmdapi.Config{
+		Clusters:  map[string]*clientcmdapi.Cluster{"prod-cluster": {Server: "https://prod"}},
+		AuthInfos: map[string]*clientcmdapi.AuthInfo{"admin": {}},
+		Contexts: map[string]*clientcmdapi.Context{
+			"prod":   {Cluster: "prod-cluster", AuthInfo: "admin"},
+			"broken": {Cluster: "gone", AuthInfo: "admin"},
+			"nouser": {Cluster: "prod-cluster", AuthInfo: "gone"},
+		},
+	}
+
+	tests := []struct {
+		context string
+		want    Status
+	}{
+		{"prod", OK},
+		{"", Failed},
+		{"staging", Failed},
+		{"broken", Failed},
+		{"nouser", Failed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.context, func(t *testing.T) {
+			got := CheckKubeconfig(kubeconfig, tt.context)
+			if got.Status != tt.want {
+				t.Errorf("CheckKubeconfig() = %+v, want status %v", got, tt.want)
+			}
+			if got.Status == Failed && got.Fix == "" {
+				t.Errorf("CheckKubeconfig() failed without a fix: %+v", got)
+			}
+		})
+	}
+}
+
+func TestCheckAuthPlugin(t *testing.T) {
+	lookPath := func(name string) (string, error) {
+		if name == "aws" {
+			return "/usr/local/bin/aws", nil
+		}
+		return "", errors.New("not found")
+	}
+
+	tests := []struct {
+		name     string
+		authInfo *clientcmdapi.AuthInfo
+		want     Status
+		wantFix  string
+	}{
+		{"Static credentials", &clientcmdapi.AuthInfo{Token: "secret"}, OK, ""},
+		{"Installed exec plugin", &clientcmdapi.AuthInfo{Exec: &clientcmdapi.ExecConfig{Command: "aws"}}, OK, ""},
+		{
+			name:     "Missing exec plugin",
+			authInfo: &clientcmdapi.AuthInfo{Exec: &clientcmdapi.ExecConfig{Command: "kubelogin"}},
+			want:     Failed,
+			wantFix:  "Install kubelogin and make sure it is on your PATH",
+		},
+		{
+			name:     "Missing exec plugin with install hint",
+			authInfo: &clientcmdapi.AuthInfo{Exec: &clientcmdapi.ExecConfig{Command: "gke-gcloud-auth-plugin", InstallHint: "Run gcloud components install gke-gcloud-auth-plugin\n"}},
+			want:     Failed,
+			wantFix:  "Run gcloud components install gke-gcloud-auth-plugin",
+		},
+		{
+			name:     "Removed auth provider",
+			authInfo: &clientcmdapi.AuthInfo{AuthProvider: &clientcmdapi.AuthProviderConfig{Name: "gcp"}},
+			want:     Failed,
+			wantFix:  "Install gke-gcloud-auth-plugin and recreate the kubeconfig entry with your cloud provider's CLI",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := CheckAuthPlugin(tt.authInfo, lookPath)
+			if got.Status != tt.want || got.Fix != tt.wantFix {
+				t.Errorf("CheckAuthPlugin() = %+v, want status %v and fix %q", got, tt.want, tt.wantFix)
+			}
+		})
+	}
+}
+
+func TestCheckPermissions(t *testing.T) {
+	tests := []struct {
+		name    string
+		allowed func(*authorizationv1.ResourceAttributes) bool
+		want    Status
+		wantMsg string
+	}{
+		{
+			name:    "Allowed",
+			allowed: func(*authorizationv1.ResourceAttributes) bool { return true },
+			want:    OK,
+			wantMsg: "can list pods and read logs in namespace 'shop'",
+		},
+		{
+			name:    "Logs denied",
+			allowed: func(attrs *authorizationv1.ResourceAttributes) bool { return attrs.Subresource != "log" },
+			want:    Failed,
+			wantMsg: "not allowed to get pods/log in namespace 'shop'",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clientset := fake.NewSimpleClientset()
+			clientset.PrependReactor("create", "selfsubjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+				review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectAccessReview)
+				review.Status.Allowed = tt.allowed(review.Spec.ResourceAttributes)
+				return true, review, nil
+			})
+
+			got := CheckPermissions(context.Background(), clientset, "shop")
+			if got.Status != tt.want || got.Message != tt.wantMsg {
+				t.Errorf("CheckPermissions() = %+v, want status %v and message %q", got, tt.want, tt.wantMsg)
+			}
+		})
+	}
+}
+
+func TestCheckClockSkew(t *testing.T) {
+	server := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		local   time.Time
+		want    Status
+		wantMsg string
+	}{
+		{"In sync", server.Add(2 * time.Second), OK, "in sync with the API server (2s skew)"},
+		{"Ahead", server.Add(5 * time.Minute), Warning, "local clock is 5m0s ahead of the API server"},
+		{"Behind", server.Add(-30 * time.Second), Warning, "local clock is 30s behind the API server"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := CheckClockSkew(server, tt.local)
+			if got.Status != tt.want || got.Message != tt.wantMsg {
+				t.Errorf("CheckClockSkew() = %+v, want status %v and message %q", got, tt.want, tt.wantMsg)
+			}
+		})
+	}
+}
@@ -0,0 +1,225 @@
+package format
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dantech2000/kubelog/pkg/logging"
+)
+
+// CustomColumnsPrefix introduces a custom-columns output format, e.g.
+// custom-columns=TIME:.Timestamp,LEVEL:.Level,MSG:.Message
+const CustomColumnsPrefix = "custom-columns="
+
+// noneValue is shown for paths that do not resolve, as kubectl does
+const noneValue = "<none>"
+
+// columnTimeFormat is how time values are rendered in columns
+const columnTimeFormat = "2006-01-02T15:04:05.000Z07:00"
+
+// Column is a single custom column: a header and the path of the value shown
+// under it, such as .Fields.status
+type Column struct {
+	Header string
+	Path   []string
+}
+
+// CustomColumns renders values as rows of aligned columns in the style of
+// kubectl's custom-columns output. When streaming, a column widens as longer
+// values are seen. It is safe for concurrent use.
+type CustomColumns struct {
+	Columns []Column
+	// NoHeaders leaves out the header row of tables
+	NoHeaders bool
+
+	mu     sync.Mutex
+	widths []int
+}
+
+// ParseCustomColumns parses a spec such as "NAME:.Name,STATUS:.Status",
+// with or without the custom-columns= prefix
+func ParseCustomColumns(spec string) (*CustomColumns, error) {
+	spec = strings.TrimPrefix(spec, CustomColumnsPrefix)
+	if spec == "" {
+		return nil, fmt.Errorf("custom-columns format requires at least one column, e.g. NAME:.Name")
+	}
+
+	c := &CustomColumns{}
+	for _, part := range strings.Split(spec, ",") {
+		header, path, ok := strings.Cut(part, ":")
+		if !ok || header == "" || !strings.HasPrefix(path, ".") {
+			return nil, fmt.Errorf("invalid custom column %q (expected HEADER:.Path)", part)
+		}
+		column := Column{Header: header}
+		if path != "." {
+			column.Path = strings.Split(strings.TrimPrefix(path, "."), ".")
+		}
+		c.Columns = append(c.Columns, column)
+	}
+	return c, nil
+}
+
+// Header returns the header row; it also implements logging.HeaderFormatter
+func (c *CustomColumns) Header() string {
+	headers := make([]string, len(c.Columns))
+	for i, column := range c.Columns {
+		headers[i] = column.Header
+	}
+	return c.row(headers)
+}
+
+// Row renders the columns of a single value
+func (c *CustomColumns) Row(obj interface{}) string {
+	return c.row(c.values(obj))
+}
+
+// Format implements the logging.Formatter interface
+func (c *CustomColumns) Format(entry logging.LogEntry) string {
+	return c.Row(entry)
+}
+
+// Table renders the header, unless NoHeaders is set, and a row for every value,
+// aligned to the widest value of each column
+func (c *CustomColumns) Table(objs []interface{}) string {
+	headers := make([]string, len(c.Columns))
+	for i, column := range c.Columns {
+		headers[i] = column.Header
+	}
+	table := NewTable(headers...)
+	table.NoHeaders = c.NoHeaders
+	for _, obj := range objs {
+		table.Append(c.values(obj)...)
+	}
+	return table.Render()
+}
+
+// values resolves the path of every column against obj
+func (c *CustomColumns) values(obj interface{}) []string {
+	values := make([]string, len(c.Columns))
+	for i, column := range c.Columns {
+		values[i] = resolveColumn(reflect.ValueOf(obj), column.Path)
+	}
+	return values
+}
+
+// widen grows the column widths to fit the given values
+func (c *CustomColumns) widen(values []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.widths == nil {
+		c.widths = make([]int, len(c.Columns))
+		for i, column := range c.Columns {
+			c.widths[i] = VisibleWidth(column.Header)
+		}
+	}
+	for i, value := range values {
+		if w := VisibleWidth(value); w > c.widths[i] {
+			c.widths[i] = w
+		}
+	}
+}
+
+// row pads every value but the last to its column width, separated by columnGap
+func (c *CustomColumns) row(values []string) string {
+	c.widen(values)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var sb strings.Builder
+	for i, value := range values {
+		if i == len(values)-1 {
+			sb.WriteString(value)
+			break
+		}
+		sb.WriteString(Pad(value, c.widths[i]))
+		sb.WriteString(columnGap)
+	}
+	return sb.String()
+}
+
+// resolveColumn follows a path of struct field names and map keys and renders
+// the value found, or noneValue if the path does not resolve. The rest of the
+// path into the fields of an entry is looked up as by logging.FieldValue, so
+// flat dotted fields and keys with dots in nested objects are found too.
+func resolveColumn(v reflect.Value, path []string) string {
+	for i, name := range path {
+		for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+			if v.IsNil() {
+				return noneValue
+			}
+			v = v.Elem()
+		}
+		if v.CanInterface() {
+			if fields, ok := v.Interface().(map[string]interface{}); ok {
+				value, ok := logging.FieldValue(fields, strings.Join(path[i:], "."))
+				if !ok {
+					return noneValue
+				}
+				return renderColumn(reflect.ValueOf(&value).Elem())
+			}
+		}
+		switch v.Kind() {
+		case reflect.Struct:
+			v = v.FieldByName(name)
+		case reflect.Map:
+			if v.Type().Key().Kind() != reflect.String {
+				return noneValue
+			}
+			v = v.MapIndex(reflect.ValueOf(name).Convert(v.Type().Key()))
+		default:
+			return noneValue
+		}
+		if !v.IsValid() {
+			return noneValue
+		}
+	}
+	return renderColumn(v)
+}
+
+// renderColumn formats a resolved value for display
+func renderColumn(v reflect.Value) string {
+	for v.IsValid() && (v.Kind() == reflect.Interface || v.Kind() == reflect.Pointer) && !v.IsNil() {
+		if _, ok := v.Interface().(fmt.Stringer); ok {
+			break
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() || !v.CanInterface() {
+		return noneValue
+	}
+	if (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface || v.Kind() == reflect.Map || v.Kind() == reflect.Slice) && v.IsNil() {
+		return noneValue
+	}
+
+	switch value := v.Interface().(type) {
+	case time.Time:
+		if value.IsZero() {
+			return noneValue
+		}
+		return value.Format(columnTimeFormat)
+	case string:
+		return value
+	case []string:
+		return strings.Join(value, ",")
+	case fmt.Stringer:
+		return value.String()
+	}
+
+	switch v.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 64)
+	case reflect.Map, reflect.Slice, reflect.Struct, reflect.Pointer, reflect.Interface:
+		data, err := json.Marshal(v.Interface())
+		if err != nil {
+			return fmt.Sprintf("%v", v.Interface())
+		}
+		return string(data)
+	default:
+		return fmt.Sprintf("%v", v.Interface())
+	}
+}
@@ -0,0 +1,100 @@
+package format
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dantech2000/kubelog/pkg/kubernetes"
+	"github.com/dantech2000/kubelog/pkg/logging"
+)
+
+func TestParseCustomColumns(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    int
+		wantErr bool
+	}{
+		{"custom-columns=TIME:.Timestamp,LEVEL:.Level,MSG:.Message", 3, false},
+		{"NAME:.Name", 1, false},
+		{"custom-columns=", 0, true},
+		{"NAME", 0, true},
+		{"NAME:Name", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			got, err := ParseCustomColumns(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseCustomColumns() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && len(got.Columns) != tt.want {
+				t.Errorf("ParseCustomColumns() returned %d columns, want %d", len(got.Columns), tt.want)
+			}
+		})
+	}
+}
+
+func TestCustomColumns_Format(t *testing.T) {
+	columns, err := ParseCustomColumns("custom-columns=TIME:.Timestamp,LEVEL:.Level,POD:.Source.Pod,STATUS:.Fields.status,MSG:.Message")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entry := logging.LogEntry{
+		Timestamp: time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC),
+		Level:     logging.ERROR,
+		Message:   "upstream reset",
+		Fields:    map[string]interface{}{"status": float64(503)},
+		Source:    &logging.Source{Pod: "api-1"},
+	}
+	if got, want := columns.Header(), "TIME   LEVEL   POD   STATUS   MSG"; got != want {
+		t.Errorf("Header() = %q, want %q", got, want)
+	}
+	if got, want := columns.Format(entry), "2024-01-15T10:30:00.000Z   ERROR   api-1   503      upstream reset"; got != want {
+		t.Errorf("Format() = %q, want %q", got, want)
+	}
+	if got, want := columns.Format(logging.LogEntry{Message: "plain"}), "<none>                     DEBUG   <none>   <none>   plain"; got != want {
+		t.Errorf("Format() = %q, want %q", got, want)
+	}
+}
+
+func TestCustomColumns_FormatNestedFields(t *testing.T) {
+	columns, err := ParseCustomColumns("APP:.Fields.kubernetes.labels.app,NAME:.Fields.kubernetes.labels.app.kubernetes.io/name,TEAM:.Fields.kubernetes.labels.team")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entry := logging.LogEntry{Fields: map[string]interface{}{
+		"kubernetes": map[string]interface{}{
+			"labels": map[string]interface{}{"app": "api", "app.kubernetes.io/name": "payments"},
+		},
+	}}
+	if got, want := columns.Format(entry), "api   payments   <none>"; got != want {
+		t.Errorf("Format() = %q, want %q", got, want)
+	}
+}
+
+func TestCustomColumns_Table(t *testing.T) {
+	columns, err := ParseCustomColumns("NAME:.Name,STATUS:.Status,READY:.Ready")
+	if err != nil {
+		t.Fatal(err)
+	}
+	containers := []interface{}{
+		kubernetes.ContainerInfo{Name: "app", Ready: true, Status: "Running"},
+		kubernetes.ContainerInfo{Name: "istio-proxy", Ready: false, Status: "Waiting (CrashLoopBackOff)"},
+	}
+
+	want := strings.Join([]string{
+		"NAME          STATUS                       READY",
+		"app           Running                      true",
+		"istio-proxy   Waiting (CrashLoopBackOff)   false",
+	}, "\n")
+	if got := columns.Table(containers); got != want {
+		t.Errorf("Table() =\n%s\nwant\n%s", got, want)
+	}
+
+	columns.NoHeaders = true
+	if got, want := columns.Table(containers), want[strings.Index(want, "\n")+1:]; got != want {
+		t.Errorf("Table() with NoHeaders =\n%s\nwant\n%s", got, want)
+	}
+}
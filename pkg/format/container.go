@@ -3,13 +3,33 @@ package format
 import (
 	"fmt"
 	"strings"
+	"time"
 
+	"github.com/dantech2000/kubelog/pkg/glyph"
 	"github.com/dantech2000/kubelog/pkg/kubernetes"
 	"github.com/fatih/color"
 )
 
-// FormatContainerList formats the container list in a uniform way
-func FormatContainerList(podName, namespace string, containers []kubernetes.ContainerInfo) string {
+// ContainerListOptions selects the details shown for every container
+type ContainerListOptions struct {
+	// ShowResources adds the CPU and memory requests and limits
+	ShowResources bool
+	// Wide adds the declared ports and the names of environment variables
+	Wide bool
+	// Borders frames the table with unicode borders
+	Borders bool
+	// NoHeaders leaves out the pod header and the column headers
+	NoHeaders bool
+}
+
+// FormatContainerList formats the containers of a pod as a table below a
+// header naming the pod and namespace
+func FormatContainerList(podName, namespace string, containers []kubernetes.ContainerInfo, opts ContainerListOptions) string {
+	table := ContainerTable(containers, opts)
+	if opts.NoHeaders {
+		return table.Render()
+	}
+
 	var sb strings.Builder
 
 	// Write header
@@ -18,23 +38,139 @@ func FormatContainerList(podName, namespace string, containers []kubernetes.Cont
 		color.CyanString(namespace)))
 
 	// Write containers
-	for _, container := range containers {
-		statusColor := color.New(color.FgRed)
-		if container.Ready {
-			statusColor = color.New(color.FgGreen)
+	sb.WriteString(table.Render())
+	sb.WriteString("\n")
+
+	return sb.String()
+}
+
+// FormatPodContainerList formats the containers of several pods, grouped by
+// pod. Without headers the containers of all pods share a single table whose
+// first column names the pod.
+func FormatPodContainerList(pods []kubernetes.PodContainerList, opts ContainerListOptions) string {
+	if !opts.NoHeaders {
+		var sb strings.Builder
+		for _, pod := range pods {
+			sb.WriteString(FormatContainerList(pod.Pod, pod.Namespace, pod.Containers, opts))
 		}
+		return sb.String()
+	}
 
-		readySymbol := "✗"
-		if container.Ready {
-			readySymbol = "✓"
+	var table *Table
+	for _, pod := range pods {
+		podTable := ContainerTable(pod.Containers, opts)
+		if table == nil {
+			table = NewTable(append([]string{"POD"}, podTable.Headers...)...)
+			table.Borders = opts.Borders
+			table.NoHeaders = true
+		}
+		for _, row := range podTable.Rows {
+			table.Append(append([]string{pod.Pod}, row...)...)
 		}
+	}
+	if table == nil {
+		return ""
+	}
+	return table.Render()
+}
 
-		sb.WriteString(fmt.Sprintf("%s %s [%s] (%s)\n",
-			statusColor.Sprint(readySymbol),
-			container.Name,
-			container.Status,
-			container.Image))
+// ContainerTable returns a table with a row per container and the columns
+// selected by opts
+func ContainerTable(containers []kubernetes.ContainerInfo, opts ContainerListOptions) *Table {
+	headers := []string{"NAME", "TYPE", "READY", "STATUS", "RESTARTS", "LAST TERMINATION", "IMAGE"}
+	if opts.Wide {
+		headers = append(headers, "PORTS", "ENV")
+	}
+	if opts.ShowResources {
+		headers = append(headers, "CPU REQUEST", "CPU LIMIT", "MEMORY REQUEST", "MEMORY LIMIT")
 	}
 
-	return sb.String()
+	table := NewTable(headers...)
+	table.Borders = opts.Borders
+	table.NoHeaders = opts.NoHeaders
+	for _, info := range containers {
+		row := []string{
+			info.Name,
+			string(info.Type),
+			readyCell(info.Ready),
+			info.Status,
+			restartsCell(info.RestartCount),
+			terminationCell(info.LastTermination),
+			info.Image,
+		}
+		if opts.Wide {
+			row = append(row, joinOrNone(info.Ports), joinOrNone(info.Env))
+		}
+		if opts.ShowResources {
+			r := info.Resources
+			row = append(row,
+				resourceValue(r.CPURequest), resourceValue(r.CPULimit),
+				resourceValue(r.MemoryRequest), resourceValue(r.MemoryLimit))
+		}
+		table.Append(row...)
+	}
+	return table
+}
+
+// readyCell shows the ready state as a colored check mark or cross
+func readyCell(ready bool) string {
+	if ready {
+		return color.GreenString(glyph.Check.String())
+	}
+	return color.RedString(glyph.Cross.String())
+}
+
+// restartsCell highlights containers that have been restarted
+func restartsCell(count int32) string {
+	if count > 0 {
+		return color.YellowString("%d", count)
+	}
+	return "0"
+}
+
+// terminationCell describes how the last instance of a container ended,
+// e.g. OOMKilled (exit code 137) 12m ago
+func terminationCell(t *kubernetes.Termination) string {
+	if t == nil {
+		return "-"
+	}
+	cell := fmt.Sprintf("%s (exit code %d)", color.RedString(t.Reason), t.ExitCode)
+	if !t.FinishedAt.IsZero() {
+		cell += " " + formatAge(time.Since(t.FinishedAt)) + " ago"
+	}
+	return cell
+}
+
+// formatAge formats a duration the way kubectl shows ages, e.g. 45s, 12m, 5h or 3d
+func formatAge(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 48*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	}
+}
+
+// joinOrNone joins values with commas, or returns "none" for an empty list
+func joinOrNone[T fmt.Stringer](values []T) string {
+	if len(values) == 0 {
+		return "none"
+	}
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = v.String()
+	}
+	return strings.Join(parts, ", ")
+}
+
+// resourceValue shows unset requests and limits as "none"
+func resourceValue(quantity string) string {
+	if quantity == "" {
+		return color.YellowString("none")
+	}
+	return quantity
 }
@@ -0,0 +1,172 @@
+package format
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dantech2000/kubelog/pkg/kubernetes"
+	"github.com/fatih/color"
+)
+
+func TestFormatContainerList(t *testing.T) {
+	color.NoColor = true
+	defer func() { color.NoColor = false }()
+
+	containers := []kubernetes.ContainerInfo{
+		{Name: "migrate", Type: kubernetes.InitContainer, Status: "Terminated (Completed)", Image: "api:1.2"},
+		{Name: "app", Type: kubernetes.AppContainer, Ready: true, Status: "Running", Image: "api:1.2", RestartCount: 2},
+		{Name: "debugger", Type: kubernetes.EphemeralContainer, Status: "Running", Image: "busybox"},
+	}
+
+	tests := []struct {
+		name string
+		opts ContainerListOptions
+		want string
+	}{
+		{
+			name: "Default",
+			want: strings.Join([]string{
+				"\nPod: api-1\nNamespace: shop\n",
+				"NAME       TYPE        READY   STATUS                   RESTARTS   LAST TERMINATION   IMAGE",
+				"migrate    init        ✗       Terminated (Completed)   0          -                  api:1.2",
+				"app        container   ✓       Running                  2          -                  api:1.2",
+				"debugger   ephemeral   ✗       Running                  0          -                  busybox",
+				"",
+			}, "\n"),
+		},
+		{
+			name: "No headers",
+			opts: ContainerListOptions{NoHeaders: true},
+			want: strings.Join([]string{
+				"migrate    init        ✗   Terminated (Completed)   0   -   api:1.2",
+				"app        container   ✓   Running                  2   -   api:1.2",
+				"debugger   ephemeral   ✗   Running                  0   -   busybox",
+			}, "\n"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FormatContainerList("api-1", "shop", containers, tt.opts); got != tt.want {
+				t.Errorf("FormatContainerList() =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatPodContainerList(t *testing.T) {
+	color.NoColor = true
+	defer func() { color.NoColor = false }()
+
+	pods := []kubernetes.PodContainerList{
+		{Pod: "api-1", Namespace: "shop", Containers: []kubernetes.ContainerInfo{
+			{Name: "app", Type: kubernetes.AppContainer, Ready: true, Status: "Running", Image: "api:1.2"},
+		}},
+		{Pod: "api-2", Namespace: "shop", Containers: []kubernetes.ContainerInfo{
+			{Name: "app", Type: kubernetes.AppContainer, Status: "Waiting (CrashLoopBackOff)", Image: "api:1.3", RestartCount: 4},
+		}},
+	}
+
+	tests := []struct {
+		name string
+		opts ContainerListOptions
+		want string
+	}{
+		{
+			name: "Grouped by pod",
+			want: strings.Join([]string{
+				"\nPod: api-1\nNamespace: shop\n",
+				"NAME   TYPE        READY   STATUS    RESTARTS   LAST TERMINATION   IMAGE",
+				"app    container   ✓       Running   0          -                  api:1.2",
+				"\nPod: api-2\nNamespace: shop\n",
+				"NAME   TYPE        READY   STATUS                       RESTARTS   LAST TERMINATION   IMAGE",
+				"app    container   ✗       Waiting (CrashLoopBackOff)   4          -                  api:1.3",
+				"",
+			}, "\n"),
+		},
+		{
+			name: "No headers",
+			opts: ContainerListOptions{NoHeaders: true},
+			want: strings.Join([]string{
+				"api-1   app   container   ✓   Running                      0   -   api:1.2",
+				"api-2   app   container   ✗   Waiting (CrashLoopBackOff)   4   -   api:1.3",
+			}, "\n"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FormatPodContainerList(pods, tt.opts); got != tt.want {
+				t.Errorf("FormatPodContainerList() =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestContainerTable(t *testing.T) {
+	color.NoColor = true
+	defer func() { color.NoColor = false }()
+
+	app := kubernetes.ContainerInfo{
+		Name:            "app",
+		Type:            kubernetes.AppContainer,
+		Ready:           true,
+		Status:          "Running",
+		Image:           "api:1.2",
+		Ports:           []kubernetes.Port{{Name: "http", ContainerPort: 8080, Protocol: "TCP"}, {ContainerPort: 9090, Protocol: "TCP"}},
+		Env:             []kubernetes.EnvVar{{Name: "LOG_LEVEL"}, {Name: "DB_PASSWORD", Source: "secret db-creds"}},
+		Resources:       kubernetes.Resources{CPURequest: "100m", MemoryRequest: "128Mi", MemoryLimit: "256Mi"},
+		RestartCount:    3,
+		LastTermination: &kubernetes.Termination{Reason: "OOMKilled", ExitCode: 137, FinishedAt: time.Now().Add(-12*time.Minute - time.Second)},
+	}
+
+	tests := []struct {
+		name string
+		opts ContainerListOptions
+		want []string
+	}{
+		{
+			name: "Last termination",
+			want: []string{"app", "container", "✓", "Running", "3", "OOMKilled (exit code 137) 12m ago", "api:1.2"},
+		},
+		{
+			name: "Wide",
+			opts: ContainerListOptions{Wide: true},
+			want: []string{"app", "container", "✓", "Running", "3", "OOMKilled (exit code 137) 12m ago", "api:1.2",
+				"http:8080/TCP, 9090/TCP", "LOG_LEVEL, DB_PASSWORD (secret db-creds)"},
+		},
+		{
+			name: "Resources",
+			opts: ContainerListOptions{ShowResources: true},
+			want: []string{"app", "container", "✓", "Running", "3", "OOMKilled (exit code 137) 12m ago", "api:1.2",
+				"100m", "none", "128Mi", "256Mi"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			table := ContainerTable([]kubernetes.ContainerInfo{app}, tt.opts)
+			if len(table.Headers) != len(tt.want) {
+				t.Fatalf("ContainerTable() headers = %v, want %d columns", table.Headers, len(tt.want))
+			}
+			if got := strings.Join(table.Rows[0], "|"); got != strings.Join(tt.want, "|") {
+				t.Errorf("ContainerTable() row = %q, want %q", got, strings.Join(tt.want, "|"))
+			}
+		})
+	}
+}
+
+func TestFormatAge(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{45 * time.Second, "45s"},
+		{12 * time.Minute, "12m"},
+		{5 * time.Hour, "5h"},
+		{72 * time.Hour, "3d"},
+	}
+	for _, tt := range tests {
+		if got := formatAge(tt.d); got != tt.want {
+			t.Errorf("formatAge(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package format
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/dantech2000/kubelog/pkg/kubernetes"
+	"github.com/fatih/color"
+	"gopkg.in/yaml.v2"
+)
+
+// FormatContexts formats kubeconfig contexts as json, yaml, posix (one name
+// per line) or, by default, a table highlighting the current context
+func FormatContexts(contexts []kubernetes.ContextInfo, format string, noHeaders bool) (string, error) {
+	switch format {
+	case "json":
+		jsonData, err := json.MarshalIndent(contexts, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("error marshalling to JSON: %w", err)
+		}
+		return string(jsonData), nil
+	case "yaml":
+		yamlData, err := yaml.Marshal(contexts)
+		if err != nil {
+			return "", fmt.Errorf("error marshalling to YAML: %w", err)
+		}
+		return string(yamlData), nil
+	case "posix":
+		names := make([]string, len(contexts))
+		for i, ctx := range contexts {
+			names[i] = ctx.Name
+		}
+		return strings.Join(names, "\n"), nil
+	case "":
+		return ContextTable(contexts, noHeaders).Render(), nil
+	default:
+		return "", fmt.Errorf("unsupported output format %q (expected json, yaml or posix)", format)
+	}
+}
+
+// ContextTable returns a table with a row per context; the current context is
+// marked with an asterisk
+func ContextTable(contexts []kubernetes.ContextInfo, noHeaders bool) *Table {
+	table := NewTable("CURRENT", "NAME", "CLUSTER", "USER", "NAMESPACE")
+	table.NoHeaders = noHeaders
+	for _, ctx := range contexts {
+		marker, name := "", ctx.Name
+		if ctx.Current {
+			marker, name = "*", color.New(color.FgGreen, color.Bold).Sprint(ctx.Name)
+		}
+		table.Append(marker, name, ctx.Cluster, ctx.User, ctx.Namespace)
+	}
+	return table
+}
@@ -0,0 +1,48 @@
+package format
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dantech2000/kubelog/pkg/kubernetes"
+	"github.com/fatih/color"
+)
+
+func TestFormatContexts(t *testing.T) {
+	color.NoColor = true
+	defer func() { color.NoColor = false }()
+
+	contexts := []kubernetes.ContextInfo{
+		{Name: "prod", Cluster: "prod-cluster", User: "admin", Namespace: "shop", Current: true},
+		{Name: "staging", Cluster: "staging-cluster", User: "dev", Namespace: "default"},
+	}
+
+	tests := []struct {
+		name    string
+		format  string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "Table",
+			want: strings.Join([]string{
+				"CURRENT   NAME      CLUSTER           USER    NAMESPACE",
+				"*         prod      prod-cluster      admin   shop",
+				"          staging   staging-cluster   dev     default",
+			}, "\n"),
+		},
+		{name: "Posix", format: "posix", want: "prod\nstaging"},
+		{name: "Unsupported", format: "csv", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FormatContexts(contexts, tt.format, false)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("FormatContexts() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("FormatContexts() =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package format
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/dantech2000/kubelog/pkg/kubernetes"
+	"github.com/fatih/color"
+	"gopkg.in/yaml.v2"
+)
+
+// FormatNamespaces formats namespaces as json, yaml, posix (one name per
+// line) or, by default, a table marking the current namespace
+func FormatNamespaces(namespaces []kubernetes.NamespaceInfo, format string, noHeaders bool) (string, error) {
+	switch format {
+	case "json":
+		jsonData, err := json.MarshalIndent(namespaces, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("error marshalling to JSON: %w", err)
+		}
+		return string(jsonData), nil
+	case "yaml":
+		yamlData, err := yaml.Marshal(namespaces)
+		if err != nil {
+			return "", fmt.Errorf("error marshalling to YAML: %w", err)
+		}
+		return string(yamlData), nil
+	case "posix":
+		names := make([]string, len(namespaces))
+		for i, ns := range namespaces {
+			names[i] = ns.Name
+		}
+		return strings.Join(names, "\n"), nil
+	case "":
+		return NamespaceTable(namespaces, noHeaders).Render(), nil
+	default:
+		return "", fmt.Errorf("unsupported output format %q (expected json, yaml or posix)", format)
+	}
+}
+
+// NamespaceTable returns a table with a row per namespace; the current
+// namespace is marked with an asterisk
+func NamespaceTable(namespaces []kubernetes.NamespaceInfo, noHeaders bool) *Table {
+	table := NewTable("CURRENT", "NAME", "STATUS", "PODS")
+	table.NoHeaders = noHeaders
+	for _, ns := range namespaces {
+		marker, name := "", ns.Name
+		if ns.Current {
+			marker, name = "*", color.CyanString(ns.Name)
+		}
+		table.Append(marker, name, ns.Status, strconv.Itoa(ns.Pods))
+	}
+	return table
+}
@@ -0,0 +1,54 @@
+package format
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dantech2000/kubelog/pkg/kubernetes"
+	"github.com/fatih/color"
+)
+
+func TestFormatNamespaces(t *testing.T) {
+	color.NoColor = true
+	defer func() { color.NoColor = false }()
+
+	namespaces := []kubernetes.NamespaceInfo{
+		{Name: "default", Status: "Active", Pods: 0},
+		{Name: "shop", Status: "Active", Pods: 12, Current: true},
+	}
+
+	tests := []struct {
+		name      string
+		format    string
+		noHeaders bool
+		want      string
+		wantErr   bool
+	}{
+		{
+			name: "Table",
+			want: strings.Join([]string{
+				"CURRENT   NAME      STATUS   PODS",
+				"          default   Active   0",
+				"*         shop      Active   12",
+			}, "\n"),
+		},
+		{
+			name:      "No headers",
+			noHeaders: true,
+			want:      "    default   Active   0\n*   shop      Active   12",
+		},
+		{name: "Posix", format: "posix", want: "default\nshop"},
+		{name: "Unsupported", format: "csv", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FormatNamespaces(namespaces, tt.format, tt.noHeaders)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("FormatNamespaces() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("FormatNamespaces() =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
@@ -13,6 +13,9 @@ type OutputFormatter struct {
 	PodName    string
 	Namespace  string
 	Containers []kubernetes.ContainerInfo
+	// List selects the container details and headers of the default output;
+	// NoHeaders also applies to custom-columns
+	List ContainerListOptions `json:"-" yaml:"-"`
 }
 
 // NewOutputFormatter creates a new OutputFormatter
@@ -26,6 +29,9 @@ func NewOutputFormatter(podName, namespace string, containers []kubernetes.Conta
 
 // FormatOutput formats the output based on the specified format
 func (of *OutputFormatter) FormatOutput(format string) (string, error) {
+	if strings.HasPrefix(format, CustomColumnsPrefix) {
+		return of.formatCustomColumns(format)
+	}
 	switch format {
 	case "json":
 		return of.formatJSON()
@@ -34,10 +40,77 @@ func (of *OutputFormatter) FormatOutput(format string) (string, error) {
 	case "posix":
 		return of.formatPOSIX()
 	default:
-		return FormatContainerList(of.PodName, of.Namespace, of.Containers), nil
+		return FormatContainerList(of.PodName, of.Namespace, of.Containers, of.List), nil
 	}
 }
 
+// PodsOutputFormatter formats the containers of several pods, grouped by pod
+type PodsOutputFormatter struct {
+	Pods []kubernetes.PodContainerList
+	// List selects the container details and headers of the default output;
+	// NoHeaders also applies to custom-columns
+	List ContainerListOptions `json:"-" yaml:"-"`
+}
+
+// podContainer is a container together with its pod, so custom columns can
+// refer to .Pod and .Namespace besides the container's fields
+type podContainer struct {
+	Pod       string
+	Namespace string
+	kubernetes.ContainerInfo
+}
+
+// NewPodsOutputFormatter creates a new PodsOutputFormatter
+func NewPodsOutputFormatter(pods []kubernetes.PodContainerList) *PodsOutputFormatter {
+	return &PodsOutputFormatter{Pods: pods}
+}
+
+// FormatOutput formats the output based on the specified format
+func (pf *PodsOutputFormatter) FormatOutput(format string) (string, error) {
+	if strings.HasPrefix(format, CustomColumnsPrefix) {
+		return pf.formatCustomColumns(format)
+	}
+	switch format {
+	case "json":
+		jsonData, err := json.MarshalIndent(pf, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("error marshalling to JSON: %w", err)
+		}
+		return string(jsonData), nil
+	case "yaml":
+		yamlData, err := yaml.Marshal(pf)
+		if err != nil {
+			return "", fmt.Errorf("error marshalling to YAML: %w", err)
+		}
+		return string(yamlData), nil
+	case "posix":
+		var lines []string
+		for _, pod := range pf.Pods {
+			for _, container := range pod.Containers {
+				lines = append(lines, pod.Pod+" "+container.Name)
+			}
+		}
+		return strings.Join(lines, "\n"), nil
+	default:
+		return FormatPodContainerList(pf.Pods, pf.List), nil
+	}
+}
+
+func (pf *PodsOutputFormatter) formatCustomColumns(spec string) (string, error) {
+	columns, err := ParseCustomColumns(spec)
+	if err != nil {
+		return "", err
+	}
+	columns.NoHeaders = pf.List.NoHeaders
+	var rows []interface{}
+	for _, pod := range pf.Pods {
+		for _, container := range pod.Containers {
+			rows = append(rows, podContainer{Pod: pod.Pod, Namespace: pod.Namespace, ContainerInfo: container})
+		}
+	}
+	return columns.Table(rows), nil
+}
+
 func (of *OutputFormatter) formatJSON() (string, error) {
 	jsonData, err := json.MarshalIndent(of, "", "  ")
 	if err != nil {
@@ -61,3 +134,16 @@ func (of *OutputFormatter) formatPOSIX() (string, error) {
 	}
 	return strings.Join(names, "\n"), nil
 }
+
+func (of *OutputFormatter) formatCustomColumns(spec string) (string, error) {
+	columns, err := ParseCustomColumns(spec)
+	if err != nil {
+		return "", err
+	}
+	columns.NoHeaders = of.List.NoHeaders
+	rows := make([]interface{}, len(of.Containers))
+	for i, container := range of.Containers {
+		rows[i] = container
+	}
+	return columns.Table(rows), nil
+}
@@ -0,0 +1,47 @@
+package format
+
+import (
+	"testing"
+
+	"github.com/dantech2000/kubelog/pkg/kubernetes"
+)
+
+func TestPodsOutputFormatter_FormatOutput(t *testing.T) {
+	pods := []kubernetes.PodContainerList{
+		{Pod: "api-1", Namespace: "shop", Containers: []kubernetes.ContainerInfo{
+			{Name: "app", Status: "Running"},
+			{Name: "istio-proxy", Status: "Running"},
+		}},
+		{Pod: "api-2", Namespace: "shop", Containers: []kubernetes.ContainerInfo{
+			{Name: "app", Status: "Waiting (CrashLoopBackOff)"},
+		}},
+	}
+
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{
+			format: "posix",
+			want:   "api-1 app\napi-1 istio-proxy\napi-2 app",
+		},
+		{
+			format: "custom-columns=POD:.Pod,NAME:.Name,STATUS:.Status",
+			want: "POD     NAME          STATUS\n" +
+				"api-1   app           Running\n" +
+				"api-1   istio-proxy   Running\n" +
+				"api-2   app           Waiting (CrashLoopBackOff)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			got, err := NewPodsOutputFormatter(pods).FormatOutput(tt.format)
+			if err != nil {
+				t.Fatalf("FormatOutput() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("FormatOutput() =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
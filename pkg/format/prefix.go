@@ -0,0 +1,90 @@
+package format
+
+import (
+	"fmt"
+	"hash/fnv"
+	"regexp"
+	"strings"
+	"text/template"
+	"unicode/utf8"
+
+	"github.com/dantech2000/kubelog/pkg/kubernetes"
+	"github.com/dantech2000/kubelog/pkg/logging"
+)
+
+// DefaultPrefixTemplate is the prefix used for multi-stream output when none is configured
+const DefaultPrefixTemplate = "{{.Pod}} {{.Container}}"
+
+// replicaHashRegex matches pod names generated by a ReplicaSet: <name>-<template hash>-<suffix>
+var replicaHashRegex = regexp.MustCompile(`^(.+)-[a-z0-9]{6,10}-([a-z0-9]{5})$`)
+
+// ShortPodName strips the ReplicaSet template hash from generated pod names,
+// e.g. "api-7d4b9c8f6-x2k9z" becomes "api-x2k9z". Other names are returned unchanged.
+func ShortPodName(name string) string {
+	return replicaHashRegex.ReplaceAllString(name, "$1-$2")
+}
+
+// PrefixTemplate renders the prefix written before each line of a log stream
+type PrefixTemplate struct {
+	tmpl *template.Template
+	// ShortPodNames strips replica hashes from .Pod before rendering
+	ShortPodNames bool
+	// Width pads the rendered prefix to a fixed number of characters (zero disables padding)
+	Width int
+}
+
+// NewPrefixTemplate parses a prefix template. The template has access to
+// .Namespace, .Pod and .Container, and to the "short" function which strips
+// replica hashes from pod names.
+func NewPrefixTemplate(text string) (*PrefixTemplate, error) {
+	if text == "" {
+		text = DefaultPrefixTemplate
+	}
+	tmpl, err := template.New("prefix").Funcs(template.FuncMap{
+		"short": ShortPodName,
+	}).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid prefix template: %w", err)
+	}
+
+	// Catch references to unknown fields before any stream is opened
+	p := &PrefixTemplate{tmpl: tmpl}
+	if _, err := p.Render(kubernetes.Target{}); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Render returns the plain prefix for the target
+func (p *PrefixTemplate) Render(target kubernetes.Target) (string, error) {
+	if p.ShortPodNames {
+		target.Pod = ShortPodName(target.Pod)
+	}
+
+	var sb strings.Builder
+	if err := p.tmpl.Execute(&sb, target); err != nil {
+		return "", fmt.Errorf("error rendering prefix template: %w", err)
+	}
+
+	prefix := sb.String()
+	if pad := p.Width - utf8.RuneCountInString(prefix); pad > 0 {
+		prefix += strings.Repeat(" ", pad)
+	}
+	return prefix, nil
+}
+
+// Colorize returns the rendered prefix in a color chosen from the pod name, so
+// lines from the same pod are always shown in the same color. Rendering errors
+// fall back to the target's own string form.
+func (p *PrefixTemplate) Colorize(target kubernetes.Target) string {
+	prefix, err := p.Render(target)
+	if err != nil {
+		prefix = target.String()
+	}
+
+	h := fnv.New32a()
+	h.Write([]byte(target.Pod))
+	// The colors of the theme are cycled through so every pod gets a stable color
+	colors := logging.CurrentTheme().Prefixes
+	return colors[h.Sum32()%uint32(len(colors))].Sprint(prefix)
+}
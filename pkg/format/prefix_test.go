@@ -0,0 +1,70 @@
+package format
+
+import (
+	"testing"
+
+	"github.com/dantech2000/kubelog/pkg/kubernetes"
+)
+
+func TestShortPodName(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"api-7d4b9c8f6-x2k9z", "api-x2k9z"},
+		{"checkout-service-5f6b7c8d9f-abcde", "checkout-service-abcde"},
+		{"db-0", "db-0"},
+		{"standalone", "standalone"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := ShortPodName(tt.input); got != tt.want {
+				t.Errorf("ShortPodName(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrefixTemplate_Render(t *testing.T) {
+	target := kubernetes.Target{Namespace: "shop", Pod: "api-7d4b9c8f6-x2k9z", Container: "app"}
+
+	tests := []struct {
+		name     string
+		template string
+		short    bool
+		width    int
+		want     string
+		wantErr  bool
+	}{
+		{"Default template", "", false, 0, "api-7d4b9c8f6-x2k9z app", false},
+		{"Short function", "{{.Namespace}}/{{.Pod | short}}[{{.Container}}]", false, 0, "shop/api-x2k9z[app]", false},
+		{"Short pod names option", "{{.Pod}}", true, 0, "api-x2k9z", false},
+		{"Fixed width padding", "{{.Container}}", false, 6, "app   ", false},
+		{"Padding counts characters", "{{.Container}}→", false, 6, "app→  ", false},
+		{"Unknown field", "{{.Node}}", false, 0, "", true},
+		{"Invalid syntax", "{{.Pod", false, 0, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := NewPrefixTemplate(tt.template)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewPrefixTemplate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			p.ShortPodNames = tt.short
+			p.Width = tt.width
+
+			got, err := p.Render(target)
+			if err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Render() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
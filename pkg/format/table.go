@@ -0,0 +1,124 @@
+package format
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/dantech2000/kubelog/pkg/glyph"
+)
+
+// ansiRegex matches the color escape sequences written by fatih/color
+var ansiRegex = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+
+// columnGap separates the columns of tables without borders
+const columnGap = "   "
+
+// Table renders rows of cells as aligned columns, optionally framed with
+// unicode borders, or ASCII ones with glyph.ASCII. Cells may contain color
+// codes; they do not count towards the column width.
+type Table struct {
+	Headers []string
+	Rows    [][]string
+	// Borders frames the table and its cells with unicode box-drawing lines
+	Borders bool
+	// NoHeaders leaves out the header row
+	NoHeaders bool
+}
+
+// NewTable creates a table with the given column headers
+func NewTable(headers ...string) *Table {
+	return &Table{Headers: headers}
+}
+
+// Append adds a row; missing cells are left empty and extra cells are dropped
+func (t *Table) Append(cells ...string) {
+	row := make([]string, len(t.Headers))
+	copy(row, cells)
+	t.Rows = append(t.Rows, row)
+}
+
+// Render returns the table as text, without a trailing newline
+func (t *Table) Render() string {
+	rows := t.Rows
+	if !t.NoHeaders {
+		rows = append([][]string{t.Headers}, rows...)
+	}
+	widths := t.widths(rows)
+
+	var lines []string
+	if t.Borders {
+		lines = append(lines, borderLine(widths, glyph.BoxTopLeft, glyph.BoxTop, glyph.BoxTopRight))
+	}
+	for i, row := range rows {
+		lines = append(lines, t.renderRow(row, widths))
+		if t.Borders && i == 0 && !t.NoHeaders && len(rows) > 1 {
+			lines = append(lines, borderLine(widths, glyph.BoxLeft, glyph.BoxCross, glyph.BoxRight))
+		}
+	}
+	if t.Borders {
+		lines = append(lines, borderLine(widths, glyph.BoxBottomLeft, glyph.BoxBottom, glyph.BoxBottomRight))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// widths returns the width of the widest cell of every column
+func (t *Table) widths(rows [][]string) []int {
+	widths := make([]int, len(t.Headers))
+	for _, row := range rows {
+		for i, cell := range row {
+			if w := VisibleWidth(cell); w > widths[i] {
+				widths[i] = w
+			}
+		}
+	}
+	return widths
+}
+
+// renderRow pads the cells of a row to the column widths
+func (t *Table) renderRow(row []string, widths []int) string {
+	var sb strings.Builder
+	if t.Borders {
+		sb.WriteString(glyph.BoxVertical.String() + " ")
+	}
+	for i, cell := range row {
+		last := i == len(row)-1
+		if last && !t.Borders {
+			sb.WriteString(cell)
+			break
+		}
+		sb.WriteString(Pad(cell, widths[i]))
+		switch {
+		case t.Borders && last:
+			sb.WriteString(" " + glyph.BoxVertical.String())
+		case t.Borders:
+			sb.WriteString(" " + glyph.BoxVertical.String() + " ")
+		default:
+			sb.WriteString(columnGap)
+		}
+	}
+	return sb.String()
+}
+
+// borderLine draws a horizontal border with the given corner and junction characters
+func borderLine(widths []int, left, middle, right glyph.Symbol) string {
+	segments := make([]string, len(widths))
+	for i, w := range widths {
+		segments[i] = strings.Repeat(glyph.BoxHorizontal.String(), w+2)
+	}
+	return left.String() + strings.Join(segments, middle.String()) + right.String()
+}
+
+// VisibleWidth returns the number of characters s takes up on a terminal,
+// ignoring color codes
+func VisibleWidth(s string) int {
+	return utf8.RuneCountInString(ansiRegex.ReplaceAllString(s, ""))
+}
+
+// Pad pads s with spaces to the given visible width
+func Pad(s string, width int) string {
+	if w := VisibleWidth(s); w < width {
+		return s + strings.Repeat(" ", width-w)
+	}
+	return s
+}
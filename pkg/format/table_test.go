@@ -0,0 +1,115 @@
+package format
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dantech2000/kubelog/pkg/glyph"
+	"github.com/fatih/color"
+)
+
+func TestTable_Render(t *testing.T) {
+	tests := []struct {
+		name      string
+		borders   bool
+		noHeaders bool
+		ascii     bool
+		want      []string
+	}{
+		{
+			name: "Aligned columns",
+			want: []string{
+				"NAME      STATUS",
+				"api       Running",
+				"sidecar   Waiting (CrashLoopBackOff)",
+			},
+		},
+		{
+			name:      "No headers",
+			noHeaders: true,
+			want: []string{
+				"api       Running",
+				"sidecar   Waiting (CrashLoopBackOff)",
+			},
+		},
+		{
+			name:    "Borders",
+			borders: true,
+			want: []string{
+				"┌─────────┬────────────────────────────┐",
+				"│ NAME    │ STATUS                     │",
+				"├─────────┼────────────────────────────┤",
+				"│ api     │ Running                    │",
+				"│ sidecar │ Waiting (CrashLoopBackOff) │",
+				"└─────────┴────────────────────────────┘",
+			},
+		},
+		{
+			name:    "ASCII borders",
+			borders: true,
+			ascii:   true,
+			want: []string{
+				"+---------+----------------------------+",
+				"| NAME    | STATUS                     |",
+				"+---------+----------------------------+",
+				"| api     | Running                    |",
+				"| sidecar | Waiting (CrashLoopBackOff) |",
+				"+---------+----------------------------+",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			glyph.ASCII = tt.ascii
+			defer func() { glyph.ASCII = false }()
+
+			table := NewTable("NAME", "STATUS")
+			table.Borders = tt.borders
+			table.NoHeaders = tt.noHeaders
+			table.Append("api", "Running")
+			table.Append("sidecar", "Waiting (CrashLoopBackOff)")
+			if got, want := table.Render(), strings.Join(tt.want, "\n"); got != want {
+				t.Errorf("Render() =\n%s\nwant\n%s", got, want)
+			}
+		})
+	}
+}
+
+func TestTable_RenderColors(t *testing.T) {
+	noColor := color.NoColor
+	color.NoColor = false
+	defer func() { color.NoColor = noColor }()
+
+	table := NewTable("READY", "NAME")
+	table.Append(color.GreenString("✓"), "api")
+	table.Append(color.RedString("✗"), "sidecar")
+
+	lines := strings.Split(table.Render(), "\n")
+	want := []string{"READY   NAME", "✓       api", "✗       sidecar"}
+	for i, line := range lines {
+		if plain := ansiRegex.ReplaceAllString(line, ""); plain != want[i] {
+			t.Errorf("line %d = %q, want %q", i, plain, want[i])
+		}
+	}
+}
+
+func TestVisibleWidth(t *testing.T) {
+	noColor := color.NoColor
+	color.NoColor = false
+	defer func() { color.NoColor = noColor }()
+
+	tests := []struct {
+		s    string
+		want int
+	}{
+		{"api", 3},
+		{"✓", 1},
+		{color.RedString("OOMKilled"), 9},
+		{"", 0},
+	}
+	for _, tt := range tests {
+		if got := VisibleWidth(tt.s); got != tt.want {
+			t.Errorf("VisibleWidth(%q) = %d, want %d", tt.s, got, tt.want)
+		}
+	}
+}
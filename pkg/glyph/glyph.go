@@ -0,0 +1,44 @@
+// Package glyph provides the unicode symbols of kubelog's output, with ASCII
+// equivalents for CI logs, terminals and fonts that render them poorly
+package glyph
+
+// ASCII replaces every symbol with its ASCII equivalent when set
+var ASCII bool
+
+// Symbol is a unicode glyph and its ASCII equivalent
+type Symbol struct {
+	Unicode string
+	ASCII   string
+}
+
+// String returns the glyph, or its ASCII equivalent when ASCII is set
+func (s Symbol) String() string {
+	if ASCII {
+		return s.ASCII
+	}
+	return s.Unicode
+}
+
+// Symbols of the status of checks and containers, of shortened text and of changes
+var (
+	Check    = Symbol{"✓", "+"}
+	Cross    = Symbol{"✗", "x"}
+	Ellipsis = Symbol{"…", "..."}
+	Times    = Symbol{"×", "x"}
+	Arrow    = Symbol{"→", "->"}
+)
+
+// Box-drawing lines the borders of tables are made of
+var (
+	BoxHorizontal  = Symbol{"─", "-"}
+	BoxVertical    = Symbol{"│", "|"}
+	BoxTopLeft     = Symbol{"┌", "+"}
+	BoxTop         = Symbol{"┬", "+"}
+	BoxTopRight    = Symbol{"┐", "+"}
+	BoxLeft        = Symbol{"├", "+"}
+	BoxCross       = Symbol{"┼", "+"}
+	BoxRight       = Symbol{"┤", "+"}
+	BoxBottomLeft  = Symbol{"└", "+"}
+	BoxBottom      = Symbol{"┴", "+"}
+	BoxBottomRight = Symbol{"┘", "+"}
+)
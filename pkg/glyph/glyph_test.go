@@ -0,0 +1,45 @@
+package glyph
+
+import "testing"
+
+func TestSymbol_String(t *testing.T) {
+	tests := []struct {
+		symbol Symbol
+		ascii  bool
+		want   string
+	}{
+		{symbol: Check, want: "✓"},
+		{symbol: Check, ascii: true, want: "+"},
+		{symbol: Cross, ascii: true, want: "x"},
+		{symbol: Ellipsis, ascii: true, want: "..."},
+		{symbol: BoxVertical, want: "│"},
+		{symbol: BoxVertical, ascii: true, want: "|"},
+		{symbol: Symbol{}, ascii: true, want: ""},
+	}
+	defer func() { ASCII = false }()
+
+	for _, tt := range tests {
+		ASCII = tt.ascii
+		if got := tt.symbol.String(); got != tt.want {
+			t.Errorf("%+v.String() with ASCII=%v = %q, want %q", tt.symbol, tt.ascii, got, tt.want)
+		}
+	}
+}
+
+func TestSymbols_ASCII(t *testing.T) {
+	symbols := []Symbol{
+		Check, Cross, Ellipsis, Times, Arrow,
+		BoxHorizontal, BoxVertical, BoxTopLeft, BoxTop, BoxTopRight, BoxLeft,
+		BoxCross, BoxRight, BoxBottomLeft, BoxBottom, BoxBottomRight,
+	}
+	for _, s := range symbols {
+		for _, r := range s.ASCII {
+			if r > 127 {
+				t.Errorf("ASCII equivalent %q of %q is not ASCII", s.ASCII, s.Unicode)
+			}
+		}
+		if s.ASCII == "" {
+			t.Errorf("%q has no ASCII equivalent", s.Unicode)
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package kubernetes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// DefaultCaptureInterval is how often a Capture lists the matching pods
+const DefaultCaptureInterval = 10 * time.Second
+
+// Capture follows the logs of every container of the pods matching a label
+// selector until its context is cancelled. Pods created later are picked up
+// when the pods are listed again, and streams ended by a container restart or
+// an API error are reattached; with checkpoints, a reattached stream continues
+// after the last line captured, also across runs.
+type Capture struct {
+	// Clientset is the Kubernetes client
+	Clientset kubernetes.Interface
+	// Namespace is the Kubernetes namespace of the pods
+	Namespace string
+	// Selector is the label selector of the pods
+	Selector string
+	// Container limits the capture to the containers of this name (optional)
+	Container string
+	// Interval is how often the pods are listed (defaults to DefaultCaptureInterval)
+	Interval time.Duration
+	// Open returns the writer a container stream is captured to, which is
+	// closed when the stream ends
+	Open func(pod *corev1.Pod, target Target) (io.WriteCloser, error)
+	// Options are applied to the LogFetcher of every stream; the stream is
+	// always followed and written to the writer returned by Open
+	Options []Option
+	// Events receives the streams starting, ending and failing, besides the
+	// events of every stream (optional)
+	Events EventHandler
+	// Monitor tracks the health of the streams (optional, Run creates one)
+	Monitor *StreamMonitor
+
+	mu sync.Mutex
+	// active are the targets being streamed
+	active map[Target]bool
+	// done are the targets of finished pods whose logs were captured
+	done map[Target]bool
+}
+
+// Run captures logs until ctx is cancelled and returns the context's error.
+// Failing to list the pods is reported and retried at the next interval.
+func (c *Capture) Run(ctx context.Context) error {
+	interval := c.Interval
+	if interval <= 0 {
+		interval = DefaultCaptureInterval
+	}
+	c.active = make(map[Target]bool)
+	c.done = make(map[Target]bool)
+	if c.Monitor == nil {
+		c.Monitor = NewStreamMonitor()
+	}
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := c.discover(ctx, &wg); err != nil && ctx.Err() == nil {
+			c.event(EventListFailed, Target{Namespace: c.Namespace}, err, "error listing pods for selector '%s': %v", c.Selector, err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// discover lists the matching pods and starts streaming the containers that
+// have started and aren't streamed yet
+func (c *Capture) discover(ctx context.Context, wg *sync.WaitGroup) error {
+	pods, err := c.Clientset.CoreV1().Pods(c.Namespace).List(ctx, metav1.ListOptions{LabelSelector: c.Selector})
+	if err != nil {
+		return wrapAPIError(err, c.Namespace, "")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	seen := make(map[Target]bool)
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		for _, target := range PodTargets([]corev1.Pod{*pod}, c.Container) {
+			seen[target] = true
+			if c.active[target] || c.done[target] || !containerStarted(pod, target.Container) {
+				continue
+			}
+			c.active[target] = true
+			wg.Add(1)
+			go func(pod *corev1.Pod, target Target) {
+				defer wg.Done()
+				err := c.stream(ctx, pod, target)
+
+				c.mu.Lock()
+				defer c.mu.Unlock()
+				delete(c.active, target)
+				switch {
+				case ctx.Err() != nil:
+				case podFinished(pod) && err == nil:
+					c.done[target] = true
+				default:
+					if err != nil {
+						c.event(EventStreamFailed, target, err, "error capturing %s, retrying: %v", target, err)
+					}
+					c.Monitor.status(target).setState(StreamReconnecting, nil)
+				}
+			}(pod, target)
+		}
+	}
+	// Deleted pods are forgotten
+	for target := range c.done {
+		if !seen[target] {
+			delete(c.done, target)
+		}
+	}
+	return nil
+}
+
+// stream captures the logs of a container until its stream ends
+func (c *Capture) stream(ctx context.Context, pod *corev1.Pod, target Target) (err error) {
+	w, err := c.Open(pod, target)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = errors.Join(err, w.Close())
+	}()
+
+	opts := append([]Option{}, c.Options...)
+	opts = append(opts, WithContainer(target.Container), WithWriter(w), WithFollow(), WithEvents(c.Events), WithMonitor(c.Monitor))
+	c.event(EventCaptureStarted, target, nil, "capturing %s", target)
+	if err := NewLogFetcher(c.Clientset, target.Namespace, target.Pod, opts...).GetLogs(ctx); err != nil {
+		return err
+	}
+	if ctx.Err() == nil {
+		c.event(EventCaptureEnded, target, nil, "stream of %s ended", target)
+	}
+	return nil
+}
+
+// Stats returns the health of every stream captured, ordered by target. A
+// stream that ended is StreamReconnecting until it is opened again.
+func (c *Capture) Stats() []StreamStats {
+	if c.Monitor == nil {
+		return nil
+	}
+	return c.Monitor.Stats()
+}
+
+// event reports something about the capture to c.Events
+func (c *Capture) event(kind EventKind, target Target, err error, format string, args ...interface{}) {
+	c.Events.emit(Event{Kind: kind, Target: target, Message: fmt.Sprintf(format, args...), Err: err})
+}
+
+// containerStarted reports whether a container is running or has run, so its
+// logs can be streamed
+func containerStarted(pod *corev1.Pod, container string) bool {
+	status, ok := findContainerStatus(pod, container)
+	if !ok {
+		return false
+	}
+	return status.State.Running != nil || status.State.Terminated != nil || status.LastTerminationState.Terminated != nil
+}
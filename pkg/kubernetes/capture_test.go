@@ -0,0 +1,98 @@
+package kubernetes
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// captureFiles collects what a Capture writes per target
+type captureFiles struct {
+	mu    sync.Mutex
+	files map[Target]*bytes.Buffer
+	opens map[Target]int
+}
+
+func (f *captureFiles) open(pod *corev1.Pod, target Target) (io.WriteCloser, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.files[target] == nil {
+		f.files[target] = &bytes.Buffer{}
+	}
+	f.opens[target]++
+	return nopCloser{&lockedWriter{mu: &f.mu, w: f.files[target]}}, nil
+}
+
+type lockedWriter struct {
+	mu *sync.Mutex
+	w  io.Writer
+}
+
+func (w *lockedWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.w.Write(p)
+}
+
+type nopCloser struct{ io.Writer }
+
+func (nopCloser) Close() error { return nil }
+
+func TestCapture_Run(t *testing.T) {
+	running := newTestPod("api-1", map[string]string{"app": "api"}, "app", "sidecar")
+	for i := range running.Status.ContainerStatuses {
+		running.Status.ContainerStatuses[i].State.Running = &corev1.ContainerStateRunning{}
+	}
+	finished := newTestPod("api-2", map[string]string{"app": "api"}, "app")
+	finished.Status.Phase = corev1.PodSucceeded
+	finished.Status.ContainerStatuses[0].State.Terminated = &corev1.ContainerStateTerminated{Reason: "Completed"}
+	pending := newTestPod("api-3", map[string]string{"app": "api"}, "app")
+	other := newTestPod("web-1", map[string]string{"app": "web"}, "app")
+	other.Status.ContainerStatuses[0].State.Running = &corev1.ContainerStateRunning{}
+	clientset := fake.NewSimpleClientset(running, finished, pending, other)
+
+	files := &captureFiles{files: make(map[Target]*bytes.Buffer), opens: make(map[Target]int)}
+	var notices bytes.Buffer
+	capture := &Capture{
+		Clientset: clientset,
+		Namespace: "default",
+		Selector:  "app=api",
+		Container: "app",
+		Interval:  10 * time.Millisecond,
+		Open:      files.open,
+		Options:   []Option{WithRaw()},
+		Events:    NoticeHandler(&lockedWriter{mu: &sync.Mutex{}, w: &notices}),
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if err := capture.Run(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("Run() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	files.mu.Lock()
+	defer files.mu.Unlock()
+	api1 := Target{Namespace: "default", Pod: "api-1", Container: "app"}
+	api2 := Target{Namespace: "default", Pod: "api-2", Container: "app"}
+	if len(files.files) != 2 || files.files[api1] == nil || files.files[api2] == nil {
+		t.Fatalf("captured %v, want only %s and %s", files.opens, api1, api2)
+	}
+	if !strings.HasPrefix(files.files[api1].String(), "fake logs\n") {
+		t.Errorf("capture of %s = %q", api1, files.files[api1])
+	}
+	// The stream of a running pod is reattached when it ends
+	if files.opens[api1] < 2 {
+		t.Errorf("%s was opened %d times, want it reattached", api1, files.opens[api1])
+	}
+	// A finished pod is captured once
+	if got, want := files.files[api2].String(), "fake logs\n"; got != want || files.opens[api2] != 1 {
+		t.Errorf("capture of %s = %q in %d streams, want %q in one", api2, got, files.opens[api2], want)
+	}
+}
@@ -3,17 +3,44 @@ package kubernetes
 
 import (
 	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
 
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/clientcmd"
 )
 
+// ClientOptions configures the client created by GetKubernetesClientWithOptions
+type ClientOptions struct {
+	// Context is the kubeconfig context to use; empty selects the current one
+	Context string
+	// RequestTimeout bounds API requests, so a hung API server can't block
+	// forever (0 means no timeout). Followed log streams and watches aren't
+	// bounded, and other log requests only until their headers arrive.
+	RequestTimeout time.Duration
+}
+
 // GetKubernetesClient creates a new Kubernetes client using the default kubeconfig.
 // It returns the clientset, the current namespace, and any error encountered.
 // The current namespace is determined from the kubeconfig context.
 func GetKubernetesClient() (*kubernetes.Clientset, string, error) {
+	return GetKubernetesClientForContext("")
+}
+
+// GetKubernetesClientForContext is like GetKubernetesClient but uses the named
+// kubeconfig context instead of the current one. An empty name selects the
+// current context.
+func GetKubernetesClientForContext(contextName string) (*kubernetes.Clientset, string, error) {
+	return GetKubernetesClientWithOptions(ClientOptions{Context: contextName})
+}
+
+// GetKubernetesClientWithOptions is like GetKubernetesClient with the given
+// context and request timeout
+func GetKubernetesClientWithOptions(opts ClientOptions) (*kubernetes.Clientset, string, error) {
+	contextName := opts.Context
 	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
-	configOverrides := &clientcmd.ConfigOverrides{}
+	configOverrides := &clientcmd.ConfigOverrides{CurrentContext: contextName}
 	kubeConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, configOverrides)
 
 	config, err := kubeConfig.ClientConfig()
@@ -26,10 +53,17 @@ func GetKubernetesClient() (*kubernetes.Clientset, string, error) {
 		return nil, "", fmt.Errorf("failed to get namespace from config: %w", err)
 	}
 
+	if opts.RequestTimeout > 0 {
+		config.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+			return &timeoutTransport{next: rt, timeout: opts.RequestTimeout}
+		})
+	}
+
 	clientset, err := kubernetes.NewForConfig(config)
 	if err != nil {
 		return nil, "", fmt.Errorf("failed to create kubernetes client: %w", err)
 	}
+	slog.Debug("created kubernetes client", "context", contextName, "server", config.Host, "namespace", namespace)
 
 	return clientset, namespace, nil
 }
@@ -4,23 +4,165 @@ package kubernetes
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strings"
+	"time"
 
+	"github.com/dantech2000/kubelog/pkg/glyph"
 	"github.com/fatih/color"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 )
 
+// ContainerType distinguishes the kinds of containers a pod can declare
+type ContainerType string
+
+const (
+	// AppContainer is a container from spec.containers
+	AppContainer ContainerType = "container"
+	// InitContainer is a container from spec.initContainers
+	InitContainer ContainerType = "init"
+	// EphemeralContainer is a debug container from spec.ephemeralContainers
+	EphemeralContainer ContainerType = "ephemeral"
+)
+
 // ContainerInfo holds information about a container in a pod
 type ContainerInfo struct {
 	// Name is the container name
 	Name string
+	// Type is the kind of container: app, init or ephemeral
+	Type ContainerType
 	// Ready indicates if the container is ready
 	Ready bool
 	// Status is the current state of the container (Running, Waiting, Terminated)
 	Status string
 	// Image is the container image
 	Image string
+	// Resources are the container's CPU and memory requests and limits
+	Resources Resources
+	// RestartCount is the number of times the container has been restarted
+	RestartCount int32
+	// LastTermination describes how the previous instance of the container
+	// ended (nil if it has not been restarted)
+	LastTermination *Termination
+	// Ports are the ports the container declares
+	Ports []Port
+	// Env lists the container's environment variables without their values
+	Env []EnvVar
+}
+
+// Port is a port declared by a container
+type Port struct {
+	Name          string
+	ContainerPort int32
+	Protocol      string
+}
+
+// String returns the port as e.g. http:8080/TCP, or 8080/TCP without a name
+func (p Port) String() string {
+	if p.Name == "" {
+		return fmt.Sprintf("%d/%s", p.ContainerPort, p.Protocol)
+	}
+	return fmt.Sprintf("%s:%d/%s", p.Name, p.ContainerPort, p.Protocol)
+}
+
+// EnvVar names an environment variable of a container and where its value
+// comes from; the value itself is never included
+type EnvVar struct {
+	// Name is the variable name, or empty for variables imported with envFrom
+	Name string
+	// Source is e.g. "secret db-creds", "configmap app-config" or "field
+	// metadata.name"; empty for literal values
+	Source string
+}
+
+// String returns the variable as e.g. DB_PASSWORD (secret db-creds)
+func (e EnvVar) String() string {
+	switch {
+	case e.Source == "":
+		return e.Name
+	case e.Name == "":
+		return "all of " + e.Source
+	default:
+		return fmt.Sprintf("%s (%s)", e.Name, e.Source)
+	}
+}
+
+// containerPorts lists the ports declared by a container
+func containerPorts(ports []corev1.ContainerPort) []Port {
+	var result []Port
+	for _, p := range ports {
+		protocol := string(p.Protocol)
+		if protocol == "" {
+			protocol = string(corev1.ProtocolTCP)
+		}
+		result = append(result, Port{Name: p.Name, ContainerPort: p.ContainerPort, Protocol: protocol})
+	}
+	return result
+}
+
+// containerEnv lists the environment variables of a container with the source
+// of their values, leaving the values out
+func containerEnv(container corev1.Container) []EnvVar {
+	var env []EnvVar
+	for _, from := range container.EnvFrom {
+		switch {
+		case from.ConfigMapRef != nil:
+			env = append(env, EnvVar{Source: "configmap " + from.ConfigMapRef.Name})
+		case from.SecretRef != nil:
+			env = append(env, EnvVar{Source: "secret " + from.SecretRef.Name})
+		}
+	}
+	for _, v := range container.Env {
+		e := EnvVar{Name: v.Name}
+		if ref := v.ValueFrom; ref != nil {
+			switch {
+			case ref.SecretKeyRef != nil:
+				e.Source = "secret " + ref.SecretKeyRef.Name
+			case ref.ConfigMapKeyRef != nil:
+				e.Source = "configmap " + ref.ConfigMapKeyRef.Name
+			case ref.FieldRef != nil:
+				e.Source = "field " + ref.FieldRef.FieldPath
+			case ref.ResourceFieldRef != nil:
+				e.Source = "resource " + ref.ResourceFieldRef.Resource
+			}
+		}
+		env = append(env, e)
+	}
+	return env
+}
+
+// Termination describes how a container instance ended
+type Termination struct {
+	Reason     string
+	ExitCode   int32
+	FinishedAt time.Time
+}
+
+// Resources holds the CPU and memory requests and limits of a container.
+// Values are Kubernetes quantities such as 500m or 256Mi; empty means unset.
+type Resources struct {
+	CPURequest    string
+	CPULimit      string
+	MemoryRequest string
+	MemoryLimit   string
+}
+
+// containerResources extracts the CPU and memory settings from a container's resource requirements
+func containerResources(req corev1.ResourceRequirements) Resources {
+	quantity := func(list corev1.ResourceList, name corev1.ResourceName) string {
+		if q, ok := list[name]; ok {
+			return q.String()
+		}
+		return ""
+	}
+	return Resources{
+		CPURequest:    quantity(req.Requests, corev1.ResourceCPU),
+		CPULimit:      quantity(req.Limits, corev1.ResourceCPU),
+		MemoryRequest: quantity(req.Requests, corev1.ResourceMemory),
+		MemoryLimit:   quantity(req.Limits, corev1.ResourceMemory),
+	}
 }
 
 // GetContainerState returns a string representation of the container state
@@ -39,14 +181,39 @@ func GetContainerState(state corev1.ContainerState) string {
 
 // GetContainerStatus returns the ready state and status string for a container
 func GetContainerStatus(pod *corev1.Pod, containerName string) (bool, string) {
-	for _, status := range pod.Status.ContainerStatuses {
-		if status.Name == containerName {
-			return status.Ready, GetContainerState(status.State)
-		}
+	if status, ok := findContainerStatus(pod, containerName); ok {
+		return status.Ready, GetContainerState(status.State)
 	}
 	return false, "Unknown"
 }
 
+// findContainerStatus returns the status of an app, init or ephemeral container
+func findContainerStatus(pod *corev1.Pod, containerName string) (corev1.ContainerStatus, bool) {
+	for _, statuses := range [][]corev1.ContainerStatus{
+		pod.Status.ContainerStatuses,
+		pod.Status.InitContainerStatuses,
+		pod.Status.EphemeralContainerStatuses,
+	} {
+		for _, status := range statuses {
+			if status.Name == containerName {
+				return status, true
+			}
+		}
+	}
+	return corev1.ContainerStatus{}, false
+}
+
+// hasContainer reports whether the pod declares an app, init or ephemeral
+// container with the given name
+func hasContainer(pod *corev1.Pod, containerName string) bool {
+	for _, info := range AllPodContainers(pod) {
+		if info.Name == containerName {
+			return true
+		}
+	}
+	return false
+}
+
 // FormatContainerInfo returns a formatted string representation of container information
 // with color-coded status indicators
 func FormatContainerInfo(info ContainerInfo) string {
@@ -55,36 +222,134 @@ func FormatContainerInfo(info ContainerInfo) string {
 		statusColor = color.New(color.FgGreen)
 	}
 
-	readySymbol := "✗"
+	readySymbol := glyph.Cross.String()
 	if info.Ready {
-		readySymbol = "✓"
+		readySymbol = glyph.Check.String()
 	}
 
-	return fmt.Sprintf("%s %s [%s] (%s)",
+	restarts := fmt.Sprintf("restarts: %d", info.RestartCount)
+	if info.RestartCount > 0 {
+		restarts = color.YellowString(restarts)
+	}
+
+	return fmt.Sprintf("%s %s [%s, %s] (%s)",
 		statusColor.Sprint(readySymbol),
 		info.Name,
 		info.Status,
+		restarts,
 		info.Image)
 }
 
 // ListContainers returns detailed information about containers in a pod
-func ListContainers(clientset *kubernetes.Clientset, namespace, podName string) ([]ContainerInfo, error) {
-	ctx := context.Background()
+func ListContainers(ctx context.Context, clientset kubernetes.Interface, namespace, podName string) ([]ContainerInfo, error) {
 	pod, err := clientset.CoreV1().Pods(namespace).Get(ctx, podName, metav1.GetOptions{})
 	if err != nil {
-		return nil, fmt.Errorf("error fetching pod details: %w", err)
+		return nil, fmt.Errorf("error fetching pod details: %w", wrapAPIError(err, namespace, podName))
 	}
 
+	return AllPodContainers(pod), nil
+}
+
+// PodContainerList holds the containers of a single pod
+type PodContainerList struct {
+	Pod        string
+	Namespace  string
+	Containers []ContainerInfo
+}
+
+// ListSelectorContainers returns the containers of every pod matching the
+// label selector, grouped by pod and ordered by pod name
+func ListSelectorContainers(ctx context.Context, clientset kubernetes.Interface, namespace, selector string) ([]PodContainerList, error) {
+	pods, err := clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, fmt.Errorf("error listing pods for selector '%s': %w", selector, wrapAPIError(err, namespace, ""))
+	}
+	if len(pods.Items) == 0 {
+		return nil, fmt.Errorf("%w: no pods match selector '%s' in namespace '%s'", ErrPodNotFound, selector, namespace)
+	}
+
+	lists := make([]PodContainerList, len(pods.Items))
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		lists[i] = PodContainerList{Pod: pod.Name, Namespace: pod.Namespace, Containers: AllPodContainers(pod)}
+	}
+	sort.Slice(lists, func(i, j int) bool {
+		return lists[i].Pod < lists[j].Pod
+	})
+	return lists, nil
+}
+
+// PodContainers returns information about every app container declared in the pod spec
+func PodContainers(pod *corev1.Pod) []ContainerInfo {
 	containers := make([]ContainerInfo, len(pod.Spec.Containers))
 	for i, container := range pod.Spec.Containers {
-		ready, status := GetContainerStatus(pod, container.Name)
-		containers[i] = ContainerInfo{
-			Name:   container.Name,
-			Ready:  ready,
-			Status: status,
-			Image:  container.Image,
+		containers[i] = containerInfo(pod, container, AppContainer)
+	}
+	return containers
+}
+
+// AllPodContainers returns information about the init, app and ephemeral
+// containers of the pod, in that order
+func AllPodContainers(pod *corev1.Pod) []ContainerInfo {
+	var containers []ContainerInfo
+	for _, container := range pod.Spec.InitContainers {
+		containers = append(containers, containerInfo(pod, container, InitContainer))
+	}
+	containers = append(containers, PodContainers(pod)...)
+	for _, container := range pod.Spec.EphemeralContainers {
+		// Ephemeral containers share the fields of regular containers
+		containers = append(containers, containerInfo(pod, corev1.Container(container.EphemeralContainerCommon), EphemeralContainer))
+	}
+	return containers
+}
+
+// containerInfo combines a container's spec with its status
+func containerInfo(pod *corev1.Pod, container corev1.Container, typ ContainerType) ContainerInfo {
+	ready, status := GetContainerStatus(pod, container.Name)
+	info := ContainerInfo{
+		Name:      container.Name,
+		Type:      typ,
+		Ready:     ready,
+		Status:    status,
+		Image:     container.Image,
+		Resources: containerResources(container.Resources),
+		Ports:     containerPorts(container.Ports),
+		Env:       containerEnv(container),
+	}
+	if s, ok := findContainerStatus(pod, container.Name); ok {
+		info.RestartCount = s.RestartCount
+		if t := s.LastTerminationState.Terminated; t != nil {
+			info.LastTermination = &Termination{
+				Reason:     t.Reason,
+				ExitCode:   t.ExitCode,
+				FinishedAt: t.FinishedAt.Time,
+			}
 		}
 	}
+	return info
+}
 
-	return containers, nil
+// ContainerSortKeys are the keys SortContainers accepts
+var ContainerSortKeys = []string{"name", "restarts", "status", "image"}
+
+// SortContainers orders containers by name, status or image, or by restart
+// count with the most restarted first. Ties keep their original order.
+func SortContainers(containers []ContainerInfo, key string) error {
+	var less func(a, b ContainerInfo) bool
+	switch key {
+	case "name":
+		less = func(a, b ContainerInfo) bool { return a.Name < b.Name }
+	case "restarts":
+		less = func(a, b ContainerInfo) bool { return a.RestartCount > b.RestartCount }
+	case "status":
+		less = func(a, b ContainerInfo) bool { return a.Status < b.Status }
+	case "image":
+		less = func(a, b ContainerInfo) bool { return a.Image < b.Image }
+	default:
+		return fmt.Errorf("invalid sort key %q (expected one of %s)", key, strings.Join(ContainerSortKeys, ", "))
+	}
+	sort.SliceStable(containers, func(i, j int) bool {
+		return less(containers[i], containers[j])
+	})
+	return nil
 }
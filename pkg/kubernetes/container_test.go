@@ -0,0 +1,155 @@
+package kubernetes
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestAllPodContainers(t *testing.T) {
+	pod := newTestPod("api-1", nil, "app")
+	finished := metav1.NewTime(time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC))
+	pod.Status.ContainerStatuses[0].RestartCount = 2
+	pod.Status.ContainerStatuses[0].LastTerminationState.Terminated = &corev1.ContainerStateTerminated{
+		Reason: "OOMKilled", ExitCode: 137, FinishedAt: finished,
+	}
+	pod.Spec.InitContainers = []corev1.Container{{Name: "migrate", Image: "api:1.2"}}
+	pod.Status.InitContainerStatuses = []corev1.ContainerStatus{{
+		Name:  "migrate",
+		State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Reason: "Completed"}},
+	}}
+	pod.Spec.EphemeralContainers = []corev1.EphemeralContainer{{
+		EphemeralContainerCommon: corev1.EphemeralContainerCommon{Name: "debugger", Image: "busybox"},
+	}}
+	pod.Status.EphemeralContainerStatuses = []corev1.ContainerStatus{{
+		Name:  "debugger",
+		State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}},
+	}}
+
+	want := []ContainerInfo{
+		{Name: "migrate", Type: InitContainer, Status: "Terminated (Completed)", Image: "api:1.2"},
+		{Name: "app", Type: AppContainer, Ready: true, Status: "Unknown", Image: "app-image", RestartCount: 2,
+			LastTermination: &Termination{Reason: "OOMKilled", ExitCode: 137, FinishedAt: finished.Time}},
+		{Name: "debugger", Type: EphemeralContainer, Status: "Running", Image: "busybox"},
+	}
+	if got := AllPodContainers(pod); !reflect.DeepEqual(got, want) {
+		t.Errorf("AllPodContainers() = %+v, want %+v", got, want)
+	}
+	if got := PodContainers(pod); len(got) != 1 || got[0].Name != "app" {
+		t.Errorf("PodContainers() = %+v, want only the app container", got)
+	}
+	if !hasContainer(pod, "migrate") || !hasContainer(pod, "debugger") || hasContainer(pod, "missing") {
+		t.Error("hasContainer() should find init and ephemeral containers only")
+	}
+}
+
+func TestContainerResources(t *testing.T) {
+	req := corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("100m"),
+			corev1.ResourceMemory: resource.MustParse("128Mi"),
+		},
+		Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("256Mi")},
+	}
+	want := Resources{CPURequest: "100m", MemoryRequest: "128Mi", MemoryLimit: "256Mi"}
+	if got := containerResources(req); got != want {
+		t.Errorf("containerResources() = %+v, want %+v", got, want)
+	}
+}
+
+func TestContainerEnv(t *testing.T) {
+	container := corev1.Container{
+		EnvFrom: []corev1.EnvFromSource{{ConfigMapRef: &corev1.ConfigMapEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "api-config"}}}},
+		Env: []corev1.EnvVar{
+			{Name: "LOG_LEVEL", Value: "debug"},
+			{Name: "DB_PASSWORD", ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "db-creds"}, Key: "password",
+			}}},
+			{Name: "POD_NAME", ValueFrom: &corev1.EnvVarSource{FieldRef: &corev1.ObjectFieldSelector{FieldPath: "metadata.name"}}},
+		},
+	}
+	want := []EnvVar{
+		{Source: "configmap api-config"},
+		{Name: "LOG_LEVEL"},
+		{Name: "DB_PASSWORD", Source: "secret db-creds"},
+		{Name: "POD_NAME", Source: "field metadata.name"},
+	}
+	if got := containerEnv(container); !reflect.DeepEqual(got, want) {
+		t.Errorf("containerEnv() = %+v, want %+v", got, want)
+	}
+}
+
+func TestSortContainers(t *testing.T) {
+	containers := func() []ContainerInfo {
+		return []ContainerInfo{
+			{Name: "app", Status: "Running", Image: "b", RestartCount: 1},
+			{Name: "istio-proxy", Status: "Running", Image: "a", RestartCount: 4},
+			{Name: "agent", Status: "Waiting (CrashLoopBackOff)", Image: "c", RestartCount: 0},
+		}
+	}
+	names := func(cs []ContainerInfo) []string {
+		var result []string
+		for _, c := range cs {
+			result = append(result, c.Name)
+		}
+		return result
+	}
+
+	tests := []struct {
+		key     string
+		want    []string
+		wantErr bool
+	}{
+		{"name", []string{"agent", "app", "istio-proxy"}, false},
+		{"restarts", []string{"istio-proxy", "app", "agent"}, false},
+		{"status", []string{"app", "istio-proxy", "agent"}, false},
+		{"image", []string{"istio-proxy", "app", "agent"}, false},
+		{"age", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			got := containers()
+			err := SortContainers(got, tt.key)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SortContainers() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(names(got), tt.want) {
+				t.Errorf("SortContainers() order = %v, want %v", names(got), tt.want)
+			}
+		})
+	}
+}
+
+func TestListSelectorContainers(t *testing.T) {
+	clientset := fake.NewSimpleClientset(
+		newTestPod("api-2", map[string]string{"app": "api"}, "app", "sidecar"),
+		newTestPod("api-1", map[string]string{"app": "api"}, "app"),
+		newTestPod("web-1", map[string]string{"app": "web"}, "app"),
+	)
+
+	got, err := ListSelectorContainers(context.Background(), clientset, "default", "app=api")
+	if err != nil {
+		t.Fatalf("ListSelectorContainers() error = %v", err)
+	}
+	var pods []string
+	for _, pod := range got {
+		pods = append(pods, pod.Pod)
+	}
+	if want := []string{"api-1", "api-2"}; !reflect.DeepEqual(pods, want) {
+		t.Errorf("ListSelectorContainers() pods = %v, want %v", pods, want)
+	}
+	if n := len(got[1].Containers); n != 2 {
+		t.Errorf("ListSelectorContainers() returned %d containers for api-2, want 2", n)
+	}
+
+	if _, err := ListSelectorContainers(context.Background(), clientset, "default", "app=db"); !errors.Is(err, ErrPodNotFound) {
+		t.Errorf("ListSelectorContainers() error = %v, want ErrPodNotFound", err)
+	}
+}
@@ -0,0 +1,67 @@
+package kubernetes
+
+import (
+	"fmt"
+	"sort"
+
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// ContextInfo holds information about a kubeconfig context
+type ContextInfo struct {
+	Name      string
+	Cluster   string
+	User      string
+	Namespace string
+	// Current marks the context kubelog uses
+	Current bool
+}
+
+// ListContexts returns the contexts of the default kubeconfig, ordered by name.
+// The context named current is marked as current; an empty name marks the
+// kubeconfig's current context.
+func ListContexts(current string) ([]ContextInfo, error) {
+	kubeconfig, err := clientcmd.NewDefaultClientConfigLoadingRules().Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load kubeconfig: %w", err)
+	}
+	return KubeconfigContexts(kubeconfig, current), nil
+}
+
+// KubeconfigContexts returns the contexts of a kubeconfig, ordered by name,
+// marking current or, when it is empty, the kubeconfig's current context
+func KubeconfigContexts(kubeconfig *clientcmdapi.Config, current string) []ContextInfo {
+	if current == "" {
+		current = kubeconfig.CurrentContext
+	}
+
+	contexts := make([]ContextInfo, 0, len(kubeconfig.Contexts))
+	for name, ctx := range kubeconfig.Contexts {
+		namespace := ctx.Namespace
+		if namespace == "" {
+			namespace = "default"
+		}
+		contexts = append(contexts, ContextInfo{
+			Name:      name,
+			Cluster:   ctx.Cluster,
+			User:      ctx.AuthInfo,
+			Namespace: namespace,
+			Current:   name == current,
+		})
+	}
+	sort.Slice(contexts, func(i, j int) bool {
+		return contexts[i].Name < contexts[j].Name
+	})
+	return contexts
+}
+
+// FindContext returns the context with the given name
+func FindContext(contexts []ContextInfo, name string) (ContextInfo, error) {
+	for _, ctx := range contexts {
+		if ctx.Name == name {
+			return ctx, nil
+		}
+	}
+	return ContextInfo{}, fmt.Errorf("%w: '%s' in kubeconfig", ErrContextNotFound, name)
+}
@@ -0,0 +1,60 @@
+package kubernetes
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+func newTestKubeconfig() *clientcmdapi.Config {
+	return &clientcmdapi.Config{
+		CurrentContext: "prod",
+		Contexts: map[string]*clientcmdapi.Context{
+			"prod":    {Cluster: "prod-cluster", AuthInfo: "admin", Namespace: "shop"},
+			"staging": {Cluster: "staging-cluster", AuthInfo: "dev"},
+		},
+	}
+}
+
+func TestKubeconfigContexts(t *testing.T) {
+	tests := []struct {
+		name    string
+		current string
+		want    []ContextInfo
+	}{
+		{
+			name: "Kubeconfig current context",
+			want: []ContextInfo{
+				{Name: "prod", Cluster: "prod-cluster", User: "admin", Namespace: "shop", Current: true},
+				{Name: "staging", Cluster: "staging-cluster", User: "dev", Namespace: "default"},
+			},
+		},
+		{
+			name:    "Context chosen for kubelog",
+			current: "staging",
+			want: []ContextInfo{
+				{Name: "prod", Cluster: "prod-cluster", User: "admin", Namespace: "shop"},
+				{Name: "staging", Cluster: "staging-cluster", User: "dev", Namespace: "default", Current: true},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := KubeconfigContexts(newTestKubeconfig(), tt.current); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("KubeconfigContexts() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindContext(t *testing.T) {
+	contexts := KubeconfigContexts(newTestKubeconfig(), "")
+	if got, err := FindContext(contexts, "staging"); err != nil || got.Cluster != "staging-cluster" {
+		t.Errorf("FindContext() = %+v, %v", got, err)
+	}
+	if _, err := FindContext(contexts, "dev"); !errors.Is(err, ErrContextNotFound) {
+		t.Errorf("FindContext() error = %v, want ErrContextNotFound", err)
+	}
+}
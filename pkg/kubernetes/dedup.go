@@ -0,0 +1,192 @@
+package kubernetes
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dantech2000/kubelog/pkg/glyph"
+	"github.com/dantech2000/kubelog/pkg/logging"
+	"github.com/fatih/color"
+)
+
+// DefaultDedupWindow is how long a DedupWriter waits for other replicas to
+// log the same message
+const DefaultDedupWindow = 2 * time.Second
+
+var replicaCountColor = color.New(color.Faint)
+
+// DedupWriter collapses the identical messages that replicas of one workload
+// log within a window into the line of the first replica, marked with the
+// number of replicas that logged it. Every line is held for the length of the
+// window, and lines are written in the order they arrived. Messages are
+// identical when their level, container name and text match; repeats from
+// the same pod are kept. It is safe for concurrent use.
+type DedupWriter struct {
+	w      io.Writer
+	window time.Duration
+	now    func() time.Time
+	stop   chan struct{}
+	done   chan struct{}
+
+	mu      sync.Mutex
+	pending []*dedupGroup
+	groups  map[dedupKey]*dedupGroup
+	err     error
+}
+
+// dedupKey identifies the messages collapsed into one line
+type dedupKey struct {
+	level     logging.LogLevel
+	container string
+	message   string
+}
+
+// dedupGroup is a line waiting in a DedupWriter and the pods that logged it
+type dedupGroup struct {
+	key     dedupKey
+	entry   *logging.LogEntry
+	line    string
+	pods    map[string]bool
+	arrived time.Time
+}
+
+// NewDedupWriter creates a DedupWriter writing to w, holding lines for window.
+// Close must be called to write the lines still buffered.
+func NewDedupWriter(w io.Writer, window time.Duration) *DedupWriter {
+	d := &DedupWriter{
+		w:      w,
+		window: window,
+		now:    time.Now,
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+		groups: make(map[dedupKey]*dedupGroup),
+	}
+	go d.run()
+	return d
+}
+
+// run releases the lines that waited for the window until Close is called
+func (d *DedupWriter) run() {
+	defer close(d.done)
+	ticker := time.NewTicker(max(d.window/4, 10*time.Millisecond))
+	defer ticker.Stop()
+	for {
+		select {
+		case <-d.stop:
+			return
+		case <-ticker.C:
+			d.mu.Lock()
+			d.release(d.now())
+			d.mu.Unlock()
+		}
+	}
+}
+
+// WriteEntry implements the EntryWriter interface
+func (d *DedupWriter) WriteEntry(entry logging.LogEntry, line string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.err != nil {
+		return d.err
+	}
+	if entry.Source == nil {
+		d.add(&dedupGroup{entry: &entry, line: line, arrived: d.now()})
+		return nil
+	}
+
+	message := entry.Message
+	if message == "" {
+		message = entry.RawLine
+	}
+	key := dedupKey{level: entry.Level, container: entry.Source.Container, message: message}
+	pod := entry.Source.Namespace + "/" + entry.Source.Pod
+	if group, ok := d.groups[key]; ok && !group.pods[pod] && d.sameTime(group.entry.Timestamp, entry.Timestamp) {
+		group.pods[pod] = true
+		return nil
+	}
+	group := &dedupGroup{key: key, entry: &entry, line: line, pods: map[string]bool{pod: true}, arrived: d.now()}
+	d.groups[key] = group
+	d.add(group)
+	return nil
+}
+
+// Write implements io.Writer interface for lines without an entry
+func (d *DedupWriter) Write(p []byte) (int, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.err != nil {
+		return 0, d.err
+	}
+	d.add(&dedupGroup{line: strings.TrimSuffix(string(p), "\n"), arrived: d.now()})
+	return len(p), nil
+}
+
+// sameTime reports whether two messages were logged within the window, which
+// they are taken to be when either has no timestamp
+func (d *DedupWriter) sameTime(first, ts time.Time) bool {
+	if first.IsZero() || ts.IsZero() {
+		return true
+	}
+	return ts.Sub(first).Abs() <= d.window
+}
+
+// add buffers a line; the caller holds d.mu
+func (d *DedupWriter) add(group *dedupGroup) {
+	d.pending = append(d.pending, group)
+}
+
+// release writes the lines that arrived at least a window before now; the
+// caller holds d.mu
+func (d *DedupWriter) release(now time.Time) {
+	n := 0
+	for n < len(d.pending) && now.Sub(d.pending[n].arrived) >= d.window {
+		n++
+	}
+	d.writeLines(n)
+}
+
+// writeLines writes the first n buffered lines; the caller holds d.mu
+func (d *DedupWriter) writeLines(n int) {
+	for _, group := range d.pending[:n] {
+		if d.groups[group.key] == group {
+			delete(d.groups, group.key)
+		}
+		d.writeLine(group)
+	}
+	d.pending = append(d.pending[:0], d.pending[n:]...)
+}
+
+// writeLine writes the line of a group, with the number of replicas that
+// logged it, keeping the first error; the caller holds d.mu
+func (d *DedupWriter) writeLine(group *dedupGroup) {
+	if d.err != nil {
+		return
+	}
+	line := group.line
+	if len(group.pods) > 1 {
+		line += " " + replicaCountColor.Sprintf("(%s%d replicas)", glyph.Times, len(group.pods))
+	}
+	var err error
+	if ew, ok := d.w.(EntryWriter); ok && group.entry != nil {
+		err = ew.WriteEntry(*group.entry, line)
+	} else {
+		_, err = fmt.Fprintln(d.w, line)
+	}
+	if err != nil {
+		d.err = err
+	}
+}
+
+// Close writes the lines still buffered and returns the first error writing any line
+func (d *DedupWriter) Close() error {
+	close(d.stop)
+	<-d.done
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.writeLines(len(d.pending))
+	return d.err
+}
@@ -0,0 +1,80 @@
+package kubernetes
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/dantech2000/kubelog/pkg/logging"
+	"github.com/fatih/color"
+)
+
+func TestDedupWriter(t *testing.T) {
+	defer func(noColor bool) { color.NoColor = noColor }(color.NoColor)
+	color.NoColor = true
+
+	base := time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC)
+	clock := base
+	var buf bytes.Buffer
+	// Without the release goroutine, so the test controls the clock
+	done := make(chan struct{})
+	close(done)
+	w := &DedupWriter{w: &buf, window: 2 * time.Second, now: func() time.Time { return clock },
+		stop: make(chan struct{}), done: done, groups: make(map[dedupKey]*dedupGroup)}
+
+	write := func(pod string, level logging.LogLevel, offset time.Duration, message string) {
+		t.Helper()
+		entry := logging.LogEntry{
+			Level:     level,
+			Message:   message,
+			Timestamp: base.Add(offset),
+			Source:    &logging.Source{Namespace: "default", Pod: pod, Container: "api"},
+		}
+		if err := w.WriteEntry(entry, pod+" "+message); err != nil {
+			t.Fatal(err)
+		}
+	}
+	expect := func(want string) {
+		t.Helper()
+		if got := buf.String(); got != want {
+			t.Errorf("output = %q, want %q", got, want)
+		}
+	}
+
+	write("api-1", logging.ERROR, 0, "database unreachable")
+	write("api-2", logging.INFO, 0, "request served")
+	clock = clock.Add(time.Second)
+	write("api-2", logging.ERROR, time.Second, "database unreachable")
+	write("api-3", logging.ERROR, time.Second, "database unreachable")
+	// A repeat from the same pod and another level are lines of their own
+	write("api-1", logging.ERROR, time.Second, "database unreachable")
+	write("api-3", logging.WARN, time.Second, "database unreachable")
+	// Logged too long after the first line to be the same event
+	write("api-4", logging.INFO, 5*time.Second, "request served")
+	_, _ = w.Write([]byte("no entry\n"))
+	expect("")
+
+	clock = clock.Add(time.Second)
+	w.release(clock)
+	expect("api-1 database unreachable (×3 replicas)\napi-2 request served\n")
+
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	expect("api-1 database unreachable (×3 replicas)\napi-2 request served\n" +
+		"api-1 database unreachable\napi-3 database unreachable\napi-4 request served\nno entry\n")
+}
+
+func TestDedupWriter_Entries(t *testing.T) {
+	var rec entryRecorder
+	w := NewDedupWriter(&rec, time.Hour)
+	source := &logging.Source{Pod: "api-1", Container: "api"}
+	_ = w.WriteEntry(logging.LogEntry{Level: logging.WARN, Message: "slow", Source: source}, "slow")
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(rec.entries) != 1 || rec.entries[0].Level != logging.WARN {
+		t.Errorf("DedupWriter wrote entries %v, want the WARN entry", rec.entries)
+	}
+}
@@ -0,0 +1,51 @@
+package kubernetes
+
+import (
+	"errors"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// Sentinel errors returned (wrapped) by this package. Use errors.Is to check for them.
+var (
+	// ErrPodNotFound is returned when the requested pod does not exist
+	ErrPodNotFound = errors.New("pod not found")
+	// ErrContainerNotFound is returned when the pod has no container with the requested name
+	ErrContainerNotFound = errors.New("container not found")
+	// ErrContainerRequired is returned when a pod has several containers and none was chosen
+	ErrContainerRequired = errors.New("container name required")
+	// ErrNoPreviousInstance is returned when previous logs are requested for a container that never restarted
+	ErrNoPreviousInstance = errors.New("no previous terminated container found")
+	// ErrForbidden is returned when the API server denies access to a resource
+	ErrForbidden = errors.New("access forbidden")
+	// ErrWorkloadNotFound is returned when the requested deployment, statefulset or other workload does not exist
+	ErrWorkloadNotFound = errors.New("workload not found")
+	// ErrRevisionNotFound is returned when a deployment has no ReplicaSet with the requested revision
+	ErrRevisionNotFound = errors.New("revision not found")
+	// ErrContextNotFound is returned when the kubeconfig has no context with the requested name
+	ErrContextNotFound = errors.New("context not found")
+	// ErrTooManyStreams is returned when more container streams are requested than allowed at once
+	ErrTooManyStreams = errors.New("too many log streams")
+)
+
+// wrapAPIError wraps errors returned by the Kubernetes API for the given pod
+// with the matching sentinel error, keeping the original error in the chain
+func wrapAPIError(err error, namespace, podName string) error {
+	switch {
+	case apierrors.IsNotFound(err):
+		return fmt.Errorf("%w: '%s' in namespace '%s'", ErrPodNotFound, podName, namespace)
+	case apierrors.IsForbidden(err):
+		return fmt.Errorf("%w: %w", ErrForbidden, err)
+	default:
+		return err
+	}
+}
+
+// wrapWorkloadError is like wrapAPIError for errors concerning a workload rather than a pod
+func wrapWorkloadError(err error, namespace string, w Workload) error {
+	if apierrors.IsNotFound(err) {
+		return fmt.Errorf("%w: %s in namespace '%s'", ErrWorkloadNotFound, w, namespace)
+	}
+	return wrapAPIError(err, namespace, w.Name)
+}
@@ -0,0 +1,90 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+)
+
+// EventKind is what an Event reports
+type EventKind int
+
+const (
+	// EventContainerChosen reports the container streamed from a pod
+	// without one being named, its only one or the one ChooseContainer picked
+	EventContainerChosen EventKind = iota
+	// EventStreamOpened reports that the log stream of a container was opened
+	EventStreamOpened
+	// EventStreamClosed reports that the log stream of a container was closed
+	EventStreamClosed
+	// EventStreamResumed reports that a stream continues after the last line
+	// of a previous session
+	EventStreamResumed
+	// EventPodFinished reports a pod that has finished, whose logs are
+	// fetched without following
+	EventPodFinished
+	// EventLogsGone reports a finished pod whose logs were removed with its containers
+	EventLogsGone
+	// EventStreamFailed reports a stream that failed while the others
+	// continue, or a part of one, such as the last lines of a killed container
+	EventStreamFailed
+	// EventCaptureStarted reports that Capture started capturing a container
+	EventCaptureStarted
+	// EventCaptureEnded reports that the captured stream of a container ended
+	EventCaptureEnded
+	// EventListFailed reports that Capture failed to list the pods, and retries
+	EventListFailed
+)
+
+// Event reports the progress of log streams, so that programs using this
+// package and other interfaces than the kubelog CLI can show it their way
+type Event struct {
+	Kind EventKind
+	// Target is the stream concerned, the zero Target if none
+	Target Target
+	// Message describes the event for people, e.g. "pod default/job-x is
+	// Succeeded; showing the logs it left"
+	Message string
+	// Err is the failure of EventLogsGone, EventStreamFailed and EventListFailed
+	Err error
+}
+
+// Notice reports whether the event is meant to be shown to the user, as a pod
+// that has finished or a failure is, rather than only when debugging
+func (e Event) Notice() bool {
+	switch e.Kind {
+	case EventContainerChosen, EventStreamOpened, EventStreamClosed, EventStreamResumed:
+		return false
+	}
+	return true
+}
+
+// EventHandler receives the events of fetchers and captures. It is called
+// from the goroutines of concurrent streams, and must not block them for long.
+// Without one, events are dropped and nothing is printed.
+type EventHandler func(Event)
+
+// emit passes an event to the handler, if any
+func (h EventHandler) emit(e Event) {
+	if h != nil {
+		h(e)
+	}
+}
+
+// NoticeHandler returns an EventHandler writing the message of every notice
+// to w, and logging the other events with slog, as the kubelog CLI does
+func NoticeHandler(w io.Writer) EventHandler {
+	return func(e Event) {
+		if e.Notice() {
+			fmt.Fprintln(w, e.Message)
+			return
+		}
+		level := slog.LevelInfo
+		if e.Kind == EventContainerChosen {
+			level = slog.LevelDebug
+		}
+		slog.Log(context.Background(), level, e.Message,
+			"namespace", e.Target.Namespace, "pod", e.Target.Pod, "container", e.Target.Container)
+	}
+}
@@ -0,0 +1,186 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/dantech2000/kubelog/pkg/glyph"
+	"github.com/fatih/color"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DefaultLastWords is how many of the last lines of an OOMKilled container
+// are shown by default
+const DefaultLastWords = 20
+
+// lifecyclePollInterval is how often the pod of an ended stream is checked
+// until its container restarts
+var lifecyclePollInterval = 2 * time.Second
+
+// Colors of the marker lines of pods starting, and terminating or going away
+var (
+	startedMarkerColor = color.New(color.FgGreen, color.Bold)
+	endedMarkerColor   = color.New(color.FgRed, color.Bold)
+)
+
+// followLifecycle streams the logs of a target, writing a marker line to w
+// when the stream starts and when its container terminates or restarts. The
+// stream of a restarted container is attached again, so following goes on
+// with the new instance.
+func (m *MultiLogFetcher) followLifecycle(ctx context.Context, w io.Writer, target Target, name string, newFetcher func() *LogFetcher) error {
+	restarts := m.restartCount(ctx, target)
+	writeMarker(w, startedMarkerColor, "+", name, "started")
+	for {
+		fetcher := newFetcher()
+		if err := fetcher.GetLogs(ctx); err != nil || ctx.Err() != nil || !fetcher.Follow {
+			return err
+		}
+		restarted, err := m.awaitRestart(ctx, w, target, name, &restarts)
+		if err != nil || !restarted {
+			return err
+		}
+	}
+}
+
+// restartCount returns how often the container of a target has restarted,
+// or 0 when its pod can't be fetched; GetLogs reports that
+func (m *MultiLogFetcher) restartCount(ctx context.Context, target Target) int32 {
+	pod, err := m.Clientset.CoreV1().Pods(target.Namespace).Get(ctx, target.Pod, metav1.GetOptions{})
+	if err != nil {
+		return 0
+	}
+	status, _ := findContainerStatus(pod, target.Container)
+	return status.RestartCount
+}
+
+// awaitRestart waits for the container of a target whose stream ended to run
+// again, writing the markers of its termination and restart, and reports
+// whether it restarted. It doesn't wait when the container won't run again:
+// its pod finished or was deleted, or its restart policy keeps it stopped.
+func (m *MultiLogFetcher) awaitRestart(ctx context.Context, w io.Writer, target Target, name string, restarts *int32) (bool, error) {
+	ticker := time.NewTicker(lifecyclePollInterval)
+	defer ticker.Stop()
+
+	terminated := false
+	for checks := 1; ; checks++ {
+		pod, err := m.Clientset.CoreV1().Pods(target.Namespace).Get(ctx, target.Pod, metav1.GetOptions{})
+		switch {
+		case ctx.Err() != nil:
+			return false, nil
+		case apierrors.IsNotFound(err):
+			writeMarker(w, endedMarkerColor, "-", name, "deleted")
+			return false, nil
+		case err != nil:
+			return false, fmt.Errorf("error fetching pod details: %w", wrapAPIError(err, target.Namespace, target.Pod))
+		}
+
+		status, _ := findContainerStatus(pod, target.Container)
+		restarted := status.RestartCount > *restarts
+		if !terminated && (restarted || status.State.Running == nil || podFinished(pod)) {
+			terminated = true
+			writeMarker(w, endedMarkerColor, "-", name, "terminated"+terminationReason(pod, status))
+			if last := lastTermination(status); last != nil && last.Reason == "OOMKilled" && m.LastWords > 0 {
+				// A container that restarted already left its lines to the previous instance
+				m.writeLastWords(ctx, w, target, name, status.State.Terminated == nil)
+			}
+		}
+		switch {
+		case restarted && status.State.Running != nil:
+			*restarts = status.RestartCount
+			writeMarker(w, startedMarkerColor, "+", name, "restarted")
+			return true, nil
+		case podFinished(pod), terminated && !willRestart(pod, status):
+			return false, nil
+		case !terminated && checks > 1:
+			// The container still runs, so the stream ended for another reason
+			return false, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return false, nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// terminationReason describes why the last instance of a container ended,
+// e.g. " (OOMKilled)" or " (Error, exit code 1)", or why its pod finished
+func terminationReason(pod *corev1.Pod, status corev1.ContainerStatus) string {
+	state := lastTermination(status)
+	switch {
+	case state == nil && pod.Status.Reason != "":
+		return fmt.Sprintf(" (%s)", pod.Status.Reason)
+	case state == nil:
+		return ""
+	case state.Reason == "":
+		return fmt.Sprintf(" (exit code %d)", state.ExitCode)
+	case state.Reason == "Error":
+		return fmt.Sprintf(" (Error, exit code %d)", state.ExitCode)
+	}
+	return fmt.Sprintf(" (%s)", state.Reason)
+}
+
+// lastTermination returns how the last instance of a container ended, or nil
+// if none did
+func lastTermination(status corev1.ContainerStatus) *corev1.ContainerStateTerminated {
+	if status.State.Terminated != nil {
+		return status.State.Terminated
+	}
+	return status.LastTerminationState.Terminated
+}
+
+// writeLastWords writes the last lines an OOMKilled container logged in a
+// block, as they are easily missed once the kill cut its stream short
+func (m *MultiLogFetcher) writeLastWords(ctx context.Context, w io.Writer, target Target, name string, previous bool) {
+	tail := m.LastWords
+	req := m.Clientset.CoreV1().Pods(target.Namespace).GetLogs(target.Pod, &corev1.PodLogOptions{
+		Container: target.Container,
+		Previous:  previous,
+		TailLines: &tail,
+	})
+	data, err := req.DoRaw(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			m.event(target, err, "error fetching the last lines of %s: %v", target, err)
+		}
+		return
+	}
+	_, _ = io.WriteString(w, lastWordsBlock(name, strings.Split(strings.TrimRight(string(data), "\n"), "\n")))
+}
+
+// lastWordsBlock frames the last lines of an OOMKilled container
+func lastWordsBlock(name string, lines []string) string {
+	var sb strings.Builder
+	sb.WriteString(endedMarkerColor.Sprintf("%s%s last lines of %s before it was OOMKilled", glyph.BoxTopLeft, glyph.BoxHorizontal, name))
+	sb.WriteString("\n")
+	for _, line := range lines {
+		sb.WriteString(endedMarkerColor.Sprint(glyph.BoxVertical) + " " + line + "\n")
+	}
+	sb.WriteString(endedMarkerColor.Sprint(glyph.BoxBottomLeft.String()+glyph.BoxHorizontal.String()) + "\n")
+	return sb.String()
+}
+
+// willRestart reports whether the kubelet restarts a terminated container
+// under the restart policy of its pod
+func willRestart(pod *corev1.Pod, status corev1.ContainerStatus) bool {
+	switch pod.Spec.RestartPolicy {
+	case corev1.RestartPolicyNever:
+		return false
+	case corev1.RestartPolicyOnFailure:
+		state := status.State.Terminated
+		return state == nil || state.ExitCode != 0
+	}
+	return true
+}
+
+// writeMarker writes a marker line about the pod of a stream, e.g.
+// "+ pod api-xyz started"
+func writeMarker(w io.Writer, c *color.Color, symbol, name, event string) {
+	_, _ = fmt.Fprintln(w, c.Sprintf("%s pod %s %s", symbol, name, event))
+}
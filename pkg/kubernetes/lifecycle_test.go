@@ -0,0 +1,155 @@
+package kubernetes
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fatih/color"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// withContainerState sets the state and restart count of the first container of a pod
+func withContainerState(pod *corev1.Pod, restarts int32, state, last corev1.ContainerState) *corev1.Pod {
+	pod = pod.DeepCopy()
+	pod.Status.ContainerStatuses[0].RestartCount = restarts
+	pod.Status.ContainerStatuses[0].State = state
+	pod.Status.ContainerStatuses[0].LastTerminationState = last
+	return pod
+}
+
+func TestMultiLogFetcher_Lifecycle(t *testing.T) {
+	defer func(noColor bool) { color.NoColor = noColor }(color.NoColor)
+	color.NoColor = true
+	defer func(interval time.Duration) { lifecyclePollInterval = interval }(lifecyclePollInterval)
+	lifecyclePollInterval = time.Millisecond
+
+	pod := newTestPod("api-1", nil, "app")
+	running := corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}
+	oomKilled := corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Reason: "OOMKilled", ExitCode: 137}}
+	backOff := corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}}
+
+	tests := []struct {
+		name string
+		// pods are returned by the successive gets of the pod, the last one
+		// repeatedly; nil when the pod was deleted
+		pods []*corev1.Pod
+		want []string
+	}{
+		{
+			name: "Restart",
+			pods: []*corev1.Pod{
+				withContainerState(pod, 0, running, corev1.ContainerState{}),
+				withContainerState(pod, 0, running, corev1.ContainerState{}),
+				withContainerState(pod, 1, backOff, oomKilled),
+				withContainerState(pod, 1, running, oomKilled),
+				withContainerState(pod, 1, running, oomKilled),
+				nil,
+			},
+			want: []string{
+				"+ pod api-1 started",
+				"[DEBUG] fake logs",
+				"- pod api-1 terminated (OOMKilled)",
+				"┌─ last lines of api-1 before it was OOMKilled",
+				"│ fake logs",
+				"└─",
+				"+ pod api-1 restarted",
+				"[DEBUG] fake logs",
+				"- pod api-1 deleted",
+			},
+		},
+		{
+			name: "Terminated for good",
+			pods: func() []*corev1.Pod {
+				never := withContainerState(pod, 0, oomKilled, corev1.ContainerState{})
+				never.Spec.RestartPolicy = corev1.RestartPolicyNever
+				return []*corev1.Pod{never}
+			}(),
+			want: []string{
+				"+ pod api-1 started",
+				"[DEBUG] fake logs",
+				"- pod api-1 terminated (OOMKilled)",
+				"┌─ last lines of api-1 before it was OOMKilled",
+				"│ fake logs",
+				"└─",
+			},
+		},
+		{
+			name: "Stream ended while running",
+			pods: []*corev1.Pod{withContainerState(pod, 0, running, corev1.ContainerState{})},
+			want: []string{"+ pod api-1 started", "[DEBUG] fake logs"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clientset := fake.NewSimpleClientset(pod)
+			gets := 0
+			clientset.PrependReactor("get", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+				if action.GetSubresource() != "" {
+					return false, nil, nil
+				}
+				p := tt.pods[min(gets, len(tt.pods)-1)]
+				gets++
+				if p == nil {
+					return true, nil, apierrors.NewNotFound(corev1.Resource("pods"), "api-1")
+				}
+				return true, p, nil
+			})
+
+			var buf bytes.Buffer
+			multi := NewMultiLogFetcher(clientset, []Target{{Namespace: "default", Pod: "api-1", Container: "app"}}, WithFollow())
+			multi.Writer = &buf
+			multi.Lifecycle = true
+			multi.LastWords = DefaultLastWords
+
+			if err := multi.GetLogs(context.Background()); err != nil {
+				t.Fatalf("GetLogs() error = %v", err)
+			}
+			if got := strings.Split(strings.TrimSpace(buf.String()), "\n"); strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("GetLogs() wrote\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
+
+func TestTerminationReason(t *testing.T) {
+	tests := []struct {
+		name   string
+		status corev1.ContainerStatus
+		pod    corev1.PodStatus
+		want   string
+	}{
+		{
+			name:   "OOMKilled",
+			status: corev1.ContainerStatus{State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Reason: "OOMKilled", ExitCode: 137}}},
+			want:   " (OOMKilled)",
+		},
+		{
+			name:   "Error of the last instance",
+			status: corev1.ContainerStatus{LastTerminationState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Reason: "Error", ExitCode: 1}}},
+			want:   " (Error, exit code 1)",
+		},
+		{
+			name:   "Exit code only",
+			status: corev1.ContainerStatus{State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 2}}},
+			want:   " (exit code 2)",
+		},
+		{name: "Evicted pod", pod: corev1.PodStatus{Reason: "Evicted"}, want: " (Evicted)"},
+		{name: "Unknown", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := terminationReason(&corev1.Pod{Status: tt.pod}, tt.status); got != tt.want {
+				t.Errorf("terminationReason() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package kubernetes
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/dantech2000/kubelog/pkg/logging"
+	"github.com/fatih/color"
+)
+
+// lineNumberWidth is the width line numbers are padded to, so the lines of
+// sessions up to 99999 entries stay aligned
+const lineNumberWidth = 5
+
+var lineNumberColor = color.New(color.Faint)
+
+// LineNumberWriter numbers the lines written to it, counting from 1 for the
+// whole session, so they can be referred to as e.g. line 482. The lines of a
+// multi-line entry share its number. It is safe for concurrent use.
+type LineNumberWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+	n  int
+	// partial is set when the last write ended within a line
+	partial bool
+}
+
+// NewLineNumberWriter creates a LineNumberWriter writing to w
+func NewLineNumberWriter(w io.Writer) *LineNumberWriter {
+	return &LineNumberWriter{w: w}
+}
+
+// WriteEntry implements the EntryWriter interface
+func (l *LineNumberWriter) WriteEntry(entry logging.LogEntry, line string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	line = l.number(line)
+	if ew, ok := l.w.(EntryWriter); ok {
+		return ew.WriteEntry(entry, line)
+	}
+	_, err := fmt.Fprintln(l.w, line)
+	return err
+}
+
+// Write implements io.Writer interface for lines without an entry, such as
+// the chunks of --raw output. Every line is numbered; a line written in
+// pieces is numbered once.
+func (l *LineNumberWriter) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var sb strings.Builder
+	for _, line := range strings.SplitAfter(string(p), "\n") {
+		if line == "" {
+			continue
+		}
+		if !l.partial {
+			sb.WriteString(lineNumberColor.Sprint(l.prefix()))
+		}
+		sb.WriteString(line)
+		l.partial = !strings.HasSuffix(line, "\n")
+	}
+	if _, err := io.WriteString(l.w, sb.String()); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// number prefixes line with the next number, indenting its continuation
+// lines to keep them aligned; the caller holds l.mu
+func (l *LineNumberWriter) number(line string) string {
+	prefix := l.prefix()
+	indent := strings.Repeat(" ", len(prefix))
+	return lineNumberColor.Sprint(prefix) + strings.ReplaceAll(line, "\n", "\n"+indent)
+}
+
+// prefix returns the padded next number; the caller holds l.mu
+func (l *LineNumberWriter) prefix() string {
+	l.n++
+	return fmt.Sprintf("%*d ", lineNumberWidth, l.n)
+}
@@ -0,0 +1,62 @@
+package kubernetes
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/dantech2000/kubelog/pkg/logging"
+	"github.com/fatih/color"
+)
+
+func TestLineNumberWriter(t *testing.T) {
+	defer func(noColor bool) { color.NoColor = noColor }(color.NoColor)
+	color.NoColor = true
+
+	var buf bytes.Buffer
+	w := NewLineNumberWriter(&buf)
+	_ = w.WriteEntry(logging.LogEntry{Level: logging.INFO}, "first")
+	_ = w.WriteEntry(logging.LogEntry{Level: logging.ERROR}, "Traceback:\n  File \"app.py\"")
+	_, _ = w.Write([]byte("no entry\n"))
+
+	want := "    1 first\n" +
+		"    2 Traceback:\n" +
+		"        File \"app.py\"\n" +
+		"    3 no entry\n"
+	if got := buf.String(); got != want {
+		t.Errorf("LineNumberWriter wrote\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestLineNumberWriter_RawChunks(t *testing.T) {
+	defer func(noColor bool) { color.NoColor = noColor }(color.NoColor)
+	color.NoColor = true
+
+	var buf bytes.Buffer
+	w := NewLineNumberWriter(&buf)
+	// --raw writes chunks of many lines, and lines longer than its buffer in pieces
+	_, _ = w.Write([]byte("first\nsecond\nthird\n"))
+	_, _ = w.Write([]byte("a long "))
+	_, _ = w.Write([]byte("line\nlast\n"))
+
+	want := "    1 first\n" +
+		"    2 second\n" +
+		"    3 third\n" +
+		"    4 a long line\n" +
+		"    5 last\n"
+	if got := buf.String(); got != want {
+		t.Errorf("LineNumberWriter wrote\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestLineNumberWriter_Entries(t *testing.T) {
+	defer func(noColor bool) { color.NoColor = noColor }(color.NoColor)
+	color.NoColor = true
+
+	var rec entryRecorder
+	w := NewLineNumberWriter(&rec)
+	_ = w.WriteEntry(logging.LogEntry{Level: logging.WARN}, "numbered")
+
+	if len(rec.entries) != 1 || rec.entries[0].Level != logging.WARN {
+		t.Errorf("LineNumberWriter wrote entries %v, want the WARN entry", rec.entries)
+	}
+}
@@ -6,10 +6,11 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"os"
 	"strings"
+	"time"
 
-	"github.com/AlecAivazis/survey/v2"
-	"github.com/AlecAivazis/survey/v2/terminal"
+	"github.com/dantech2000/kubelog/pkg/checkpoint"
 	"github.com/dantech2000/kubelog/pkg/logging"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -24,200 +25,401 @@ type LogFetcher struct {
 	Namespace string
 	// PodName is the name of the pod
 	PodName string
-	// ContainerName is the name of the container (optional, will prompt if not provided)
+	// ContainerName is the name of the container (optional, ChooseContainer
+	// picks one of a pod with several containers if not provided)
 	ContainerName string
+	// ChooseContainer picks the container to stream of a pod with several,
+	// e.g. by prompting the user (optional, an error wrapping
+	// ErrContainerRequired is returned otherwise)
+	ChooseContainer func(pod string, containers []ContainerInfo) (string, error)
 	// Follow indicates if the logs should be streamed
 	Follow bool
 	// Previous indicates if logs from a previous container instance should be retrieved
 	Previous bool
+	// Since limits the logs to those newer than this duration (zero means all logs)
+	Since time.Duration
 	// Writer is where the logs will be written
 	Writer io.Writer
+	// Pipeline filters parsed entries before they are written (optional)
+	Pipeline *logging.Pipeline
+	// Prefix is written before every log line (optional)
+	Prefix string
+	// Formatter renders entries for output (optional, defaults to logging.TextFormatter)
+	Formatter logging.Formatter
+	// Labels are the pod labels attached to every entry's source (optional)
+	Labels []string
+	// Checkpoints records the last line seen, and the stream resumes after the
+	// line recorded by a previous session (optional)
+	Checkpoints *checkpoint.Store
+	// Skew estimates the clock skew of the stream, and corrects the order of
+	// its lines if enabled (optional)
+	Skew *ClockSkew
+	// Raw writes the lines as they are, after the prefix, without parsing or
+	// formatting them
+	Raw bool
+	// Events receives the progress of the stream, such as its opening and
+	// the phase of a pod that has finished (optional)
+	Events EventHandler
+	// Monitor tracks the health of the stream; fetchers sharing a monitor
+	// report all their streams to it
+	Monitor *StreamMonitor
 }
 
-// NewLogFetcher creates a new LogFetcher instance
-func NewLogFetcher(clientset kubernetes.Interface, namespace, podName string, follow bool, previous bool, writer io.Writer) *LogFetcher {
-	return &LogFetcher{
+// NewLogFetcher creates a new LogFetcher instance for the given pod.
+// Logs are written to os.Stdout unless WithWriter is used.
+func NewLogFetcher(clientset kubernetes.Interface, namespace, podName string, opts ...Option) *LogFetcher {
+	lf := &LogFetcher{
 		Clientset: clientset,
 		Namespace: namespace,
 		PodName:   podName,
-		Follow:    follow,
-		Previous:  previous,
-		Writer:    writer,
+		Writer:    os.Stdout,
+		Monitor:   NewStreamMonitor(),
 	}
+	for _, opt := range opts {
+		opt(lf)
+	}
+	return lf
 }
 
 // getSingleContainerName returns the name of the container to fetch logs from.
 // If there's only one container, it returns that container's name.
-// If there are multiple containers, it prompts the user to select one.
-func (lf *LogFetcher) getSingleContainerName() (string, error) {
-	ctx := context.Background()
+// If there are multiple containers, ChooseContainer picks one.
+func (lf *LogFetcher) getSingleContainerName(ctx context.Context) (string, error) {
 	pod, err := lf.Clientset.CoreV1().Pods(lf.Namespace).Get(ctx, lf.PodName, metav1.GetOptions{})
 	if err != nil {
-		return "", fmt.Errorf("error fetching pod details: %w", err)
+		return "", fmt.Errorf("error fetching pod details: %w", wrapAPIError(err, lf.Namespace, lf.PodName))
 	}
 
 	containerCount := len(pod.Spec.Containers)
 	if containerCount == 0 {
 		return "", fmt.Errorf("no containers found in pod %s", lf.PodName)
 	} else if containerCount == 1 {
+		lf.event(EventContainerChosen, nil, "using the only container %s of the pod", pod.Spec.Containers[0].Name)
 		return pod.Spec.Containers[0].Name, nil
 	}
 
-	// Create container info list for the prompt
-	containers := make([]ContainerInfo, containerCount)
-	options := make([]string, containerCount)
-
-	for i, c := range pod.Spec.Containers {
-		ready, status := GetContainerStatus(pod, c.Name)
-		info := ContainerInfo{
-			Name:   c.Name,
-			Ready:  ready,
-			Status: status,
-			Image:  c.Image,
+	containers := PodContainers(pod)
+	if lf.ChooseContainer == nil {
+		names := make([]string, len(containers))
+		for i, info := range containers {
+			names[i] = info.Name
 		}
-		containers[i] = info
-		options[i] = FormatContainerInfo(info)
-	}
-
-	// Prepare the survey prompt
-	var selectedIdx int
-	prompt := &survey.Select{
-		Message: "Choose a container:",
-		Options: options,
-		Filter: func(filter string, value string, index int) bool {
-			container := containers[index]
-			filter = strings.ToLower(filter)
-			return strings.Contains(strings.ToLower(container.Name), filter) ||
-				strings.Contains(strings.ToLower(container.Status), filter) ||
-				strings.Contains(strings.ToLower(container.Image), filter)
-		},
+		return "", fmt.Errorf("%w: pod %s has the containers %s", ErrContainerRequired, lf.PodName, strings.Join(names, ", "))
 	}
-
-	// Show the prompt and get user's selection
-	err = survey.AskOne(prompt, &selectedIdx, survey.WithPageSize(10))
+	name, err := lf.ChooseContainer(lf.PodName, containers)
 	if err != nil {
-		if err == terminal.InterruptErr {
-			return "", fmt.Errorf("operation cancelled")
-		}
-		return "", fmt.Errorf("selection failed: %w", err)
+		return "", err
 	}
-
-	return containers[selectedIdx].Name, nil
+	lf.event(EventContainerChosen, nil, "using the chosen container %s of the pod", name)
+	return name, nil
 }
 
 // hasPreviousContainer checks if a container has previous terminated instances
-func (lf *LogFetcher) hasPreviousContainer(containerName string) (bool, error) {
-	ctx := context.Background()
+func (lf *LogFetcher) hasPreviousContainer(ctx context.Context, containerName string) (bool, error) {
 	pod, err := lf.Clientset.CoreV1().Pods(lf.Namespace).Get(ctx, lf.PodName, metav1.GetOptions{})
 	if err != nil {
-		return false, fmt.Errorf("error fetching pod details: %w", err)
+		return false, fmt.Errorf("error fetching pod details: %w", wrapAPIError(err, lf.Namespace, lf.PodName))
 	}
 
-	for _, status := range pod.Status.ContainerStatuses {
-		if status.Name == containerName {
-			return status.RestartCount > 0, nil
-		}
+	if status, ok := findContainerStatus(pod, containerName); ok {
+		return status.RestartCount > 0, nil
 	}
-	return false, fmt.Errorf("container '%s' not found in pod '%s'", containerName, lf.PodName)
+	return false, fmt.Errorf("%w: '%s' in pod '%s'", ErrContainerNotFound, containerName, lf.PodName)
 }
 
 // LogWriter wraps an io.Writer to process logs before writing
 type LogWriter struct {
-	writer io.Writer
+	writer    io.Writer
+	stream    *logging.Stream
+	formatter logging.Formatter
+	prefix    string
+	source    *logging.Source
+	// timestamps is set when every line starts with the time the container
+	// runtime received it, which is stripped and kept in received
+	timestamps bool
+	received   time.Time
+	status     *streamStatus
+	cursor     *streamCursor
+	skew       *ClockSkew
+	target     Target
 }
 
 // Write implements io.Writer interface
 func (w *LogWriter) Write(p []byte) (n int, err error) {
-	// Convert bytes to string and parse log
-	logLine := strings.TrimSpace(string(p))
-	if logLine == "" {
-		return len(p), nil
+	line := string(p)
+	if w.timestamps {
+		var received time.Time
+		line, received = splitTimestamp(line)
+		if w.cursor != nil && !w.cursor.advance(received) {
+			return len(p), nil
+		}
+		w.received = received
+	}
+	if w.status != nil {
+		w.status.linesRead(1, time.Now())
 	}
+	return len(p), w.write(w.stream.Process(line))
+}
 
-	parsedLog := logging.ParseLog(logLine)
-	// Write the parsed log with a newline
-	_, err = fmt.Fprintln(w.writer, parsedLog)
-	return len(p), err
+// Flush writes any multi-line record still being folded; call it once the
+// stream has ended
+func (w *LogWriter) Flush() error {
+	return w.write(w.stream.Flush())
+}
+
+// write formats entries and writes each on its own line
+func (w *LogWriter) write(entries []logging.LogEntry) error {
+	for _, entry := range entries {
+		if w.source != nil {
+			entry.Source = w.source
+		}
+		formatted := w.formatter.Format(entry)
+		if formatted == "" {
+			// Formatters such as the jq one drop entries by rendering nothing
+			continue
+		}
+		if w.prefix != "" {
+			formatted = w.prefix + " " + formatted
+		}
+		if w.status != nil {
+			if logged := w.received; !logged.IsZero() {
+				w.status.setLag(logged, time.Now())
+			} else if !entry.Timestamp.IsZero() {
+				w.status.setLag(entry.Timestamp, time.Now())
+			}
+		}
+		if w.skew != nil && !w.received.IsZero() && !entry.Timestamp.IsZero() {
+			// Only the order of lines is corrected, not the timestamps shown
+			w.skew.Observe(w.target, entry.Timestamp, w.received)
+			entry.Timestamp = w.skew.Adjust(w.target, entry.Timestamp)
+		}
+		if ew, ok := w.writer.(EntryWriter); ok {
+			if err := ew.WriteEntry(entry, formatted); err != nil {
+				return err
+			}
+			continue
+		}
+		if _, err := fmt.Fprintln(w.writer, formatted); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // NewLogWriter creates a new LogWriter
 func NewLogWriter(w io.Writer) *LogWriter {
-	return &LogWriter{writer: w}
+	return NewFilteredLogWriter(w, nil)
+}
+
+// NewFilteredLogWriter creates a LogWriter that only writes entries accepted by the pipeline
+func NewFilteredLogWriter(w io.Writer, pipeline *logging.Pipeline) *LogWriter {
+	return &LogWriter{writer: w, stream: pipeline.NewStream(), formatter: logging.TextFormatter{}}
+}
+
+// source describes the container being streamed, with the selected pod labels
+func (lf *LogFetcher) source(pod *corev1.Pod) *logging.Source {
+	source := &logging.Source{
+		Namespace: lf.Namespace,
+		Pod:       lf.PodName,
+		Container: lf.ContainerName,
+		Node:      pod.Spec.NodeName,
+	}
+	for _, key := range lf.Labels {
+		if value, ok := pod.Labels[key]; ok {
+			if source.Labels == nil {
+				source.Labels = make(map[string]string)
+			}
+			source.Labels[key] = value
+		}
+	}
+	return source
+}
+
+// target identifies the container being streamed
+func (lf *LogFetcher) target() Target {
+	return Target{Namespace: lf.Namespace, Pod: lf.PodName, Container: lf.ContainerName}
+}
+
+// Stats returns the health of the stream. Its state is StreamConnecting
+// until GetLogs is called.
+func (lf *LogFetcher) Stats() StreamStats {
+	stats, _ := lf.Monitor.Stream(lf.target())
+	return stats
+}
+
+// GetLogsBackground retrieves logs like GetLogs using a background context.
+//
+// Deprecated: use GetLogs with a context that can be cancelled.
+func (lf *LogFetcher) GetLogsBackground() error {
+	return lf.GetLogs(context.Background())
 }
 
 // GetLogs retrieves logs from the specified container.
-// If no container is specified, it will prompt the user to select one.
+// If no container is specified, a pod's only container is used, and of a pod
+// with several the ChooseContainer option picks one; without that option,
+// an error wrapping ErrContainerRequired is returned.
 // It handles both current and previous container instances based on the Previous flag.
-func (lf *LogFetcher) GetLogs() error {
+// Cancelling ctx stops all API calls, including an open log stream, and the
+// context's error is returned.
+func (lf *LogFetcher) GetLogs(ctx context.Context) (err error) {
 	// Get container name first if not specified
 	if lf.ContainerName == "" {
-		containerName, err := lf.getSingleContainerName()
+		containerName, err := lf.getSingleContainerName(ctx)
 		if err != nil {
 			return fmt.Errorf("failed to get container name: %w", err)
 		}
 		lf.ContainerName = containerName
 	}
 
+	status := lf.Monitor.status(lf.target())
+	status.begin()
+	defer func() { status.end(ctx, err) }()
+
 	// Validate container exists
-	ctx := context.Background()
 	pod, err := lf.Clientset.CoreV1().Pods(lf.Namespace).Get(ctx, lf.PodName, metav1.GetOptions{})
 	if err != nil {
-		return fmt.Errorf("error fetching pod details: %w", err)
+		return fmt.Errorf("error fetching pod details: %w", wrapAPIError(err, lf.Namespace, lf.PodName))
 	}
 
-	containerExists := false
-	for _, container := range pod.Spec.Containers {
-		if container.Name == lf.ContainerName {
-			containerExists = true
-			break
-		}
-	}
-	if !containerExists {
-		return fmt.Errorf("container '%s' not found in pod '%s'", lf.ContainerName, lf.PodName)
+	if !hasContainer(pod, lf.ContainerName) {
+		return fmt.Errorf("%w: '%s' in pod '%s'", ErrContainerNotFound, lf.ContainerName, lf.PodName)
 	}
 
 	// Check for previous container if -p flag is used
 	if lf.Previous {
-		hasPrevious, err := lf.hasPreviousContainer(lf.ContainerName)
+		hasPrevious, err := lf.hasPreviousContainer(ctx, lf.ContainerName)
 		if err != nil {
 			return fmt.Errorf("failed to check for previous container: %w", err)
 		}
 		if !hasPrevious {
-			return fmt.Errorf("no previous terminated container found for '%s' in pod '%s'\nNote: The -p flag only works for containers that have terminated or restarted",
-				lf.ContainerName, lf.PodName)
+			return fmt.Errorf("%w for '%s' in pod '%s'\nNote: The -p flag only works for containers that have terminated or restarted",
+				ErrNoPreviousInstance, lf.ContainerName, lf.PodName)
 		}
 	}
 
+	// A pod that has finished logs nothing more, so what it left is
+	// fetched without following
+	finished := podFinished(pod)
+	if finished {
+		lf.event(EventPodFinished, nil, "pod %s/%s is %s; showing the logs it left", lf.Namespace, lf.PodName, podPhase(pod))
+	}
+
 	// Now proceed with log fetching
 	podLogOpts := corev1.PodLogOptions{
 		Container: lf.ContainerName,
-		Follow:    lf.Follow,
+		Follow:    lf.Follow && !finished,
 		Previous:  lf.Previous,
 	}
+	if lf.Since > 0 {
+		seconds := int64(lf.Since.Seconds())
+		if seconds < 1 {
+			seconds = 1
+		}
+		podLogOpts.SinceSeconds = &seconds
+	}
+	cursor := lf.resumeCursor(&podLogOpts)
+	if lf.Skew != nil {
+		podLogOpts.Timestamps = true
+	}
 
-	ctx = context.Background()
 	req := lf.Clientset.CoreV1().Pods(lf.Namespace).GetLogs(lf.PodName, &podLogOpts)
 	podLogs, err := req.Stream(ctx)
+	if err != nil && finished && ctx.Err() == nil {
+		// The containers of evicted pods are often removed with their logs
+		lf.event(EventLogsGone, err, "no logs are left for container %s of pod %s/%s: %v", lf.ContainerName, lf.Namespace, lf.PodName, err)
+		return nil
+	}
 	if err != nil {
-		return fmt.Errorf("error opening log stream: %w", err)
+		return fmt.Errorf("error opening log stream: %w", wrapAPIError(err, lf.Namespace, lf.PodName))
 	}
 	defer podLogs.Close()
+	status.setState(StreamConnected, nil)
+	lf.event(EventStreamOpened, nil, "opened log stream (follow=%t, previous=%t)", podLogOpts.Follow, lf.Previous)
+	defer lf.event(EventStreamClosed, nil, "closed log stream")
 
-	// Create a scanner to read logs line by line
-	scanner := bufio.NewScanner(podLogs)
-	logWriter := NewLogWriter(lf.Writer)
-
-	// Process each log line
-	for scanner.Scan() {
-		logLine := scanner.Text()
-		if _, err := logWriter.Write([]byte(logLine)); err != nil {
-			return fmt.Errorf("error writing log line: %w", err)
+	if lf.Raw {
+		if err := copyRaw(&lineCounter{w: lf.Writer, status: status}, podLogs, lf.Prefix); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return err
 		}
+		return nil
 	}
 
-	if err := scanner.Err(); err != nil {
-		return fmt.Errorf("error reading log stream: %w", err)
+	logWriter := NewFilteredLogWriter(lf.Writer, lf.Pipeline)
+	logWriter.prefix = lf.Prefix
+	logWriter.source = lf.source(pod)
+	logWriter.timestamps = podLogOpts.Timestamps
+	logWriter.status = status
+	logWriter.cursor = cursor
+	logWriter.skew = lf.Skew
+	logWriter.target = lf.target()
+	if lf.Formatter != nil {
+		logWriter.formatter = lf.Formatter
 	}
 
+	if err := streamLines(ctx, podLogs, logWriter); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return err
+	}
 	return nil
 }
+
+// foldIdleTimeout is how long a stream may be idle before records still being
+// folded, such as the last stack trace, are written
+const foldIdleTimeout = 500 * time.Millisecond
+
+// streamLines writes each line read from r to w. Records held by folders are
+// flushed when the stream ends or has been idle for foldIdleTimeout.
+func streamLines(ctx context.Context, r io.Reader, w *LogWriter) error {
+	lines := make(chan string)
+	scanErr := make(chan error, 1)
+	done := make(chan struct{})
+	defer close(done)
+
+	go func() {
+		defer close(lines)
+		// Read logs line by line
+		scanner := bufio.NewScanner(r)
+		for scanner.Scan() {
+			select {
+			case lines <- scanner.Text():
+			case <-done:
+				return
+			}
+		}
+		scanErr <- scanner.Err()
+	}()
+
+	ticker := time.NewTicker(foldIdleTimeout)
+	defer ticker.Stop()
+	lastLine := time.Now()
+
+	for {
+		select {
+		case line, ok := <-lines:
+			if !ok {
+				if err := w.Flush(); err != nil {
+					return fmt.Errorf("error writing log line: %w", err)
+				}
+				if err := <-scanErr; err != nil {
+					return fmt.Errorf("error reading log stream: %w", err)
+				}
+				return nil
+			}
+			if _, err := w.Write([]byte(line)); err != nil {
+				return fmt.Errorf("error writing log line: %w", err)
+			}
+			lastLine = time.Now()
+		case <-ticker.C:
+			if time.Since(lastLine) >= foldIdleTimeout {
+				if err := w.Flush(); err != nil {
+					return fmt.Errorf("error writing log line: %w", err)
+				}
+			}
+		}
+	}
+}
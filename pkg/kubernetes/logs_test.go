@@ -3,8 +3,13 @@ package kubernetes
 import (
 	"bytes"
 	"context"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
 	"testing"
 
+	"github.com/dantech2000/kubelog/pkg/logging"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes/fake"
@@ -57,6 +62,7 @@ func TestLogFetcher_GetLogs(t *testing.T) {
 		follow        bool
 		previous      bool
 		wantError     bool
+		wantErrIs     error
 	}{
 		{
 			name:          "Get logs from single container",
@@ -85,19 +91,29 @@ func TestLogFetcher_GetLogs(t *testing.T) {
 			follow:        false,
 			previous:      false,
 			wantError:     true,
+			wantErrIs:     ErrContainerNotFound,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			var buf bytes.Buffer
-			fetcher := NewLogFetcher(clientset, "default", "test-pod", tt.follow, tt.previous, &buf)
-			fetcher.ContainerName = tt.containerName
+			opts := []Option{WithContainer(tt.containerName), WithWriter(&buf)}
+			if tt.follow {
+				opts = append(opts, WithFollow())
+			}
+			if tt.previous {
+				opts = append(opts, WithPrevious())
+			}
+			fetcher := NewLogFetcher(clientset, "default", "test-pod", opts...)
 
-			err := fetcher.GetLogs()
+			err := fetcher.GetLogs(context.Background())
 			if (err != nil) != tt.wantError {
 				t.Errorf("GetLogs() error = %v, wantError %v", err, tt.wantError)
 			}
+			if tt.wantErrIs != nil && !errors.Is(err, tt.wantErrIs) {
+				t.Errorf("GetLogs() error = %v, want errors.Is %v", err, tt.wantErrIs)
+			}
 		})
 	}
 }
@@ -157,8 +173,8 @@ func TestLogFetcher_hasPreviousContainer(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			fetcher := NewLogFetcher(clientset, "default", "test-pod", false, false, nil)
-			got, err := fetcher.hasPreviousContainer(tt.containerName)
+			fetcher := NewLogFetcher(clientset, "default", "test-pod")
+			got, err := fetcher.hasPreviousContainer(context.Background(), tt.containerName)
 			if (err != nil) != tt.wantError {
 				t.Errorf("hasPreviousContainer() error = %v, wantError %v", err, tt.wantError)
 				return
@@ -219,3 +235,122 @@ func TestLogWriter_Write(t *testing.T) {
 		})
 	}
 }
+
+func TestLogWriter_SkipsEmptyOutput(t *testing.T) {
+	var buf bytes.Buffer
+	writer := NewLogWriter(&buf)
+	writer.formatter = logging.FormatterFunc(func(entry logging.LogEntry) string {
+		if strings.Contains(entry.Message, "health") {
+			return ""
+		}
+		return entry.Message
+	})
+
+	for _, line := range []string{"GET /health\n", "GET /api\n"} {
+		if _, err := writer.Write([]byte(line)); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+	if got, want := buf.String(), "GET /api\n"; got != want {
+		t.Errorf("Write() output = %q, want %q", got, want)
+	}
+}
+
+func TestLogFetcher_GetLogsErrors(t *testing.T) {
+	clientset := fake.NewSimpleClientset(&corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "fresh-pod", Namespace: "default"},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "app", Image: "app-image"}},
+		},
+		Status: corev1.PodStatus{
+			ContainerStatuses: []corev1.ContainerStatus{{Name: "app", Ready: true}},
+		},
+	}, newTestPod("meshed-pod", nil, "app", "istio-proxy"))
+
+	tests := []struct {
+		name      string
+		podName   string
+		opts      []Option
+		wantErrIs error
+	}{
+		{
+			name:      "Several containers without a chooser",
+			podName:   "meshed-pod",
+			wantErrIs: ErrContainerRequired,
+		},
+		{
+			name:      "Missing pod",
+			podName:   "missing-pod",
+			opts:      []Option{WithContainer("app")},
+			wantErrIs: ErrPodNotFound,
+		},
+		{
+			name:      "Previous logs without restarts",
+			podName:   "fresh-pod",
+			opts:      []Option{WithContainer("app"), WithPrevious()},
+			wantErrIs: ErrNoPreviousInstance,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			opts := append([]Option{WithWriter(&buf)}, tt.opts...)
+			err := NewLogFetcher(clientset, "default", tt.podName, opts...).GetLogs(context.Background())
+			if !errors.Is(err, tt.wantErrIs) {
+				t.Errorf("GetLogs() error = %v, want errors.Is %v", err, tt.wantErrIs)
+			}
+		})
+	}
+}
+
+func TestLogFetcher_ChooseContainer(t *testing.T) {
+	clientset := fake.NewSimpleClientset(newTestPod("meshed-pod", nil, "app", "istio-proxy"))
+
+	var offered []string
+	var events []Event
+	fetcher := NewLogFetcher(clientset, "default", "meshed-pod",
+		WithWriter(io.Discard),
+		WithContainerChooser(func(pod string, containers []ContainerInfo) (string, error) {
+			for _, c := range containers {
+				offered = append(offered, c.Name)
+			}
+			return "istio-proxy", nil
+		}),
+		WithEvents(func(e Event) { events = append(events, e) }),
+	)
+	if err := fetcher.GetLogs(context.Background()); err != nil {
+		t.Fatalf("GetLogs() error = %v", err)
+	}
+	if want := []string{"app", "istio-proxy"}; !reflect.DeepEqual(offered, want) {
+		t.Errorf("containers offered = %v, want %v", offered, want)
+	}
+	if fetcher.ContainerName != "istio-proxy" {
+		t.Errorf("ContainerName = %q, want the chosen istio-proxy", fetcher.ContainerName)
+	}
+	if len(events) == 0 || events[0].Kind != EventContainerChosen || events[0].Notice() {
+		t.Errorf("events = %+v, want EventContainerChosen first, which is no notice", events)
+	}
+}
+
+func TestLogFetcher_GetLogsSource(t *testing.T) {
+	pod := newTestPod("api-1", map[string]string{"app": "api", "team": "payments"}, "app")
+	pod.Spec.NodeName = "node-1"
+	clientset := fake.NewSimpleClientset(pod)
+
+	var buf bytes.Buffer
+	fetcher := NewLogFetcher(clientset, "default", "api-1",
+		WithContainer("app"),
+		WithWriter(&buf),
+		WithFormatter(logging.LogfmtFormatter{}),
+		WithLabels("team", "missing"),
+	)
+	if err := fetcher.GetLogs(context.Background()); err != nil {
+		t.Fatalf("GetLogs() error = %v", err)
+	}
+
+	want := `level=DEBUG message="fake logs" kubernetes.container=app kubernetes.labels.team=payments kubernetes.namespace=default kubernetes.node=node-1 kubernetes.pod=api-1`
+	if got := strings.TrimSpace(buf.String()); got != want {
+		t.Errorf("GetLogs() = %s, want %s", got, want)
+	}
+}
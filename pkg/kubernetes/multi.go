@@ -0,0 +1,174 @@
+package kubernetes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/dantech2000/kubelog/pkg/logging"
+	"k8s.io/client-go/kubernetes"
+)
+
+// MultiLogFetcher streams logs from several containers concurrently into a single writer
+type MultiLogFetcher struct {
+	// Clientset is the Kubernetes client
+	Clientset kubernetes.Interface
+	// Targets are the container streams to fetch
+	Targets []Target
+	// Options are applied to the LogFetcher of every target
+	Options []Option
+	// Writer is where the logs of all targets will be written
+	Writer io.Writer
+	// Prefix renders the prefix written before every line of a target (optional)
+	Prefix func(Target) string
+	// TargetOptions returns extra options for a single target, applied after Options (optional)
+	TargetOptions func(Target) []Option
+	// Monitor tracks the health of the streams of all targets
+	Monitor *StreamMonitor
+	// IgnoreErrors keeps the other streams going when one fails, reporting
+	// the failure to Events; otherwise the first failure stops all streams
+	IgnoreErrors bool
+	// Events receives the failures ignored with IgnoreErrors, besides the
+	// events of every stream (optional)
+	Events EventHandler
+	// Lifecycle writes marker lines when a pod starts being followed, and
+	// when its container terminates or restarts; the stream of a restarted
+	// container is attached again. It only applies to followed streams.
+	Lifecycle bool
+	// LastWords is how many of the last lines of a container killed for
+	// running out of memory are shown in a block after its termination
+	// marker, with Lifecycle (optional, 0 shows none)
+	LastWords int64
+}
+
+// NewMultiLogFetcher creates a MultiLogFetcher for the given targets.
+// The options are applied to every target; WithContainer is overridden per
+// target and the writer given with WithWriter is shared by all of them, like
+// the monitor given with WithMonitor and the handler given with WithEvents.
+func NewMultiLogFetcher(clientset kubernetes.Interface, targets []Target, opts ...Option) *MultiLogFetcher {
+	shared := &LogFetcher{Writer: os.Stdout, Monitor: NewStreamMonitor()}
+	for _, opt := range opts {
+		opt(shared)
+	}
+	return &MultiLogFetcher{
+		Clientset: clientset,
+		Targets:   targets,
+		Options:   opts,
+		Writer:    shared.Writer,
+		Monitor:   shared.Monitor,
+		Events:    shared.Events,
+	}
+}
+
+// Stats returns the health of the stream of every target that was started,
+// ordered by target
+func (m *MultiLogFetcher) Stats() []StreamStats {
+	if m.Monitor == nil {
+		return nil
+	}
+	return m.Monitor.Stats()
+}
+
+// GetLogs streams the logs of all targets until every stream ends or ctx is cancelled.
+// The first stream to fail stops the others and its error is returned, unless
+// IgnoreErrors is set; then an error is only returned when every stream failed,
+// with the errors of all of them joined together.
+func (m *MultiLogFetcher) GetLogs(parent context.Context) error {
+	writer := &syncWriter{w: m.Writer}
+	ctx, cancel := context.WithCancel(parent)
+	defer cancel()
+
+	containers := make(map[string]int)
+	for _, target := range m.Targets {
+		containers[target.Namespace+"/"+target.Pod]++
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, len(m.Targets))
+	for i, target := range m.Targets {
+		opts := append([]Option{}, m.Options...)
+		if m.Monitor != nil {
+			opts = append(opts, WithMonitor(m.Monitor))
+		}
+		if m.TargetOptions != nil {
+			opts = append(opts, m.TargetOptions(target)...)
+		}
+		opts = append(opts, WithContainer(target.Container), WithWriter(writer))
+		if m.Prefix != nil {
+			opts = append(opts, WithPrefix(m.Prefix(target)))
+		}
+		newFetcher := func() *LogFetcher {
+			return NewLogFetcher(m.Clientset, target.Namespace, target.Pod, opts...)
+		}
+		// Markers name the container when the pod has several streamed
+		name := target.Pod
+		if containers[target.Namespace+"/"+target.Pod] > 1 {
+			name += "/" + target.Container
+		}
+
+		wg.Add(1)
+		go func(i int, target Target) {
+			defer wg.Done()
+			var err error
+			if m.Lifecycle {
+				err = m.followLifecycle(ctx, writer, target, name, newFetcher)
+			} else {
+				err = newFetcher().GetLogs(ctx)
+			}
+			if err == nil || ctx.Err() != nil {
+				return
+			}
+			errs[i] = err
+			if m.IgnoreErrors {
+				m.event(target, err, "error streaming %s, the other streams continue: %v", target, err)
+				return
+			}
+			cancel()
+		}(i, target)
+	}
+	wg.Wait()
+
+	if parent.Err() != nil {
+		return parent.Err()
+	}
+	if m.IgnoreErrors {
+		for _, err := range errs {
+			if err == nil {
+				return nil
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// event reports a failure of the stream of target to m.Events
+func (m *MultiLogFetcher) event(target Target, err error, format string, args ...interface{}) {
+	m.Events.emit(Event{Kind: EventStreamFailed, Target: target, Message: fmt.Sprintf(format, args...), Err: err})
+}
+
+// syncWriter serializes writes so lines from concurrent streams don't interleave
+type syncWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// Write implements io.Writer interface
+func (s *syncWriter) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.w.Write(p)
+}
+
+// WriteEntry implements the EntryWriter interface when the underlying writer does
+func (s *syncWriter) WriteEntry(entry logging.LogEntry, line string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if ew, ok := s.w.(EntryWriter); ok {
+		return ew.WriteEntry(entry, line)
+	}
+	_, err := fmt.Fprintln(s.w, line)
+	return err
+}
@@ -0,0 +1,226 @@
+package kubernetes
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func newTestPod(name string, labels map[string]string, containers ...string) *corev1.Pod {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: labels},
+	}
+	for _, c := range containers {
+		pod.Spec.Containers = append(pod.Spec.Containers, corev1.Container{Name: c, Image: c + "-image"})
+		pod.Status.ContainerStatuses = append(pod.Status.ContainerStatuses, corev1.ContainerStatus{Name: c, Ready: true})
+	}
+	return pod
+}
+
+func TestResolveTargets(t *testing.T) {
+	clientset := fake.NewSimpleClientset(
+		newTestPod("api-1", map[string]string{"app": "api"}, "app", "sidecar"),
+		newTestPod("api-2", map[string]string{"app": "api"}, "app", "sidecar"),
+		newTestPod("web-1", map[string]string{"app": "web"}, "app"),
+	)
+
+	tests := []struct {
+		name      string
+		selector  string
+		container string
+		want      int
+		wantError bool
+	}{
+		{"All containers", "app=api", "", 4, false},
+		{"Single container", "app=api", "sidecar", 2, false},
+		{"No matching pods", "app=db", "", 0, true},
+		{"No matching container", "app=web", "sidecar", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ResolveTargets(context.Background(), clientset, "default", tt.selector, tt.container)
+			if (err != nil) != tt.wantError {
+				t.Fatalf("ResolveTargets() error = %v, wantError %v", err, tt.wantError)
+			}
+			if len(got) != tt.want {
+				t.Errorf("ResolveTargets() returned %d targets, want %d", len(got), tt.want)
+			}
+		})
+	}
+}
+
+func TestMultiLogFetcher_GetLogs(t *testing.T) {
+	clientset := fake.NewSimpleClientset(
+		newTestPod("api-1", nil, "app"),
+		newTestPod("api-2", nil, "app"),
+	)
+	targets := []Target{
+		{Namespace: "default", Pod: "api-1", Container: "app"},
+		{Namespace: "default", Pod: "api-2", Container: "app"},
+	}
+
+	var buf bytes.Buffer
+	multi := NewMultiLogFetcher(clientset, targets)
+	multi.Writer = &buf
+	multi.Prefix = func(t Target) string { return t.Pod }
+
+	if err := multi.GetLogs(context.Background()); err != nil {
+		t.Fatalf("GetLogs() error = %v", err)
+	}
+
+	// The fake clientset returns a single "fake logs" line per stream
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != len(targets) {
+		t.Fatalf("GetLogs() wrote %d lines, want %d: %q", len(lines), len(targets), buf.String())
+	}
+	for _, prefix := range []string{"api-1 ", "api-2 "} {
+		if !strings.Contains(buf.String(), prefix) {
+			t.Errorf("GetLogs() output missing prefix %q: %q", prefix, buf.String())
+		}
+	}
+}
+
+func TestExcludeContainers(t *testing.T) {
+	targets := []Target{
+		{Namespace: "default", Pod: "hello-1", Container: "user-container"},
+		{Namespace: "default", Pod: "hello-1", Container: "queue-proxy"},
+		{Namespace: "default", Pod: "hello-2", Container: "user-container"},
+		{Namespace: "default", Pod: "hello-2", Container: "queue-proxy"},
+	}
+
+	got := ExcludeContainers(targets, "queue-proxy")
+	if len(got) != 2 {
+		t.Fatalf("ExcludeContainers() returned %d targets, want 2", len(got))
+	}
+	for _, target := range got {
+		if target.Container == "queue-proxy" {
+			t.Errorf("ExcludeContainers() kept excluded target %s", target)
+		}
+	}
+
+	if got := ExcludeContainers(targets); len(got) != len(targets) {
+		t.Errorf("ExcludeContainers() without names returned %d targets, want %d", len(got), len(targets))
+	}
+}
+
+func TestCheckMaxStreams(t *testing.T) {
+	var targets []Target
+	for i := 0; i < 30; i++ {
+		targets = append(targets, Target{Namespace: "default", Pod: fmt.Sprintf("api-%d", i), Container: "app"})
+	}
+
+	if err := CheckMaxStreams(targets, 30); err != nil {
+		t.Errorf("CheckMaxStreams() at the limit returned error: %v", err)
+	}
+
+	err := CheckMaxStreams(targets, 25)
+	if !errors.Is(err, ErrTooManyStreams) {
+		t.Fatalf("CheckMaxStreams() over the limit returned %v, want ErrTooManyStreams", err)
+	}
+	for _, skipped := range targets[25:] {
+		if !strings.Contains(err.Error(), skipped.String()) {
+			t.Errorf("CheckMaxStreams() error doesn't name skipped target %s: %v", skipped, err)
+		}
+	}
+	if strings.Contains(err.Error(), targets[24].String()+"\n") {
+		t.Errorf("CheckMaxStreams() error names target %s within the limit", targets[24])
+	}
+
+	err = CheckMaxStreams(targets, 1)
+	if !strings.Contains(err.Error(), "and 9 more") {
+		t.Errorf("CheckMaxStreams() error doesn't shorten the list of skipped targets: %v", err)
+	}
+}
+
+func TestMultiLogFetcher_IgnoreErrors(t *testing.T) {
+	clientset := fake.NewSimpleClientset(newTestPod("api-1", nil, "app"))
+	targets := []Target{
+		{Namespace: "default", Pod: "api-1", Container: "app"},
+		{Namespace: "default", Pod: "api-gone", Container: "app"},
+	}
+
+	tests := []struct {
+		name         string
+		targets      []Target
+		ignoreErrors bool
+		wantError    bool
+		wantNotice   bool
+	}{
+		{name: "failure stops the session", targets: targets, wantError: true},
+		{name: "failure is reported", targets: targets, ignoreErrors: true, wantNotice: true},
+		{name: "every stream failing is an error", targets: targets[1:], ignoreErrors: true, wantError: true, wantNotice: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out, notices bytes.Buffer
+			multi := NewMultiLogFetcher(clientset, tt.targets, WithWriter(&out), WithEvents(NoticeHandler(&notices)))
+			multi.IgnoreErrors = tt.ignoreErrors
+
+			err := multi.GetLogs(context.Background())
+			if (err != nil) != tt.wantError {
+				t.Fatalf("GetLogs() error = %v, wantError %v", err, tt.wantError)
+			}
+			if err != nil && !errors.Is(err, ErrPodNotFound) {
+				t.Errorf("GetLogs() error = %v, want ErrPodNotFound", err)
+			}
+			if got := strings.Contains(notices.String(), "api-gone"); got != tt.wantNotice {
+				t.Errorf("GetLogs() notices = %q, want notice %v", notices.String(), tt.wantNotice)
+			}
+		})
+	}
+}
+
+func TestGroupTargetsByLabel(t *testing.T) {
+	clientset := fake.NewSimpleClientset(
+		newTestPod("api-1", map[string]string{"app": "api", "version": "v2"}, "app", "istio-proxy"),
+		newTestPod("api-2", map[string]string{"app": "api", "version": "v1"}, "app"),
+		newTestPod("api-3", map[string]string{"app": "api", "version": "v1"}, "app"),
+		newTestPod("api-4", map[string]string{"app": "api"}, "app"),
+	)
+
+	tests := []struct {
+		name      string
+		container string
+		label     string
+		want      map[string]int
+		wantError error
+	}{
+		{name: "all containers", label: "version", want: map[string]int{"v1": 2, "v2": 2}},
+		{name: "single container", container: "app", label: "version", want: map[string]int{"v1": 2, "v2": 1}},
+		{name: "missing label", label: "track", wantError: ErrPodNotFound},
+		{name: "missing container", container: "worker", label: "version", wantError: ErrContainerNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			groups, err := GroupTargetsByLabel(context.Background(), clientset, "default", "app=api", tt.container, tt.label)
+			if tt.wantError != nil {
+				if !errors.Is(err, tt.wantError) {
+					t.Fatalf("GroupTargetsByLabel() error = %v, want %v", err, tt.wantError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GroupTargetsByLabel() error = %v", err)
+			}
+			if len(groups) != len(tt.want) {
+				t.Fatalf("GroupTargetsByLabel() returned %d groups, want %d", len(groups), len(tt.want))
+			}
+			for i, group := range groups {
+				if i > 0 && groups[i-1].Value >= group.Value {
+					t.Errorf("GroupTargetsByLabel() groups aren't ordered by value: %q before %q", groups[i-1].Value, group.Value)
+				}
+				if len(group.Targets) != tt.want[group.Value] {
+					t.Errorf("GroupTargetsByLabel() group %q has %d targets, want %d", group.Value, len(group.Targets), tt.want[group.Value])
+				}
+			}
+		})
+	}
+}
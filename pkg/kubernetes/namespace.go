@@ -0,0 +1,104 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// NamespaceInfo holds information about a namespace the user can access
+type NamespaceInfo struct {
+	Name   string
+	Status string
+	Pods   int
+	// Current marks the namespace of the current kubeconfig context
+	Current bool
+}
+
+// ListNamespaces returns the namespaces whose pods the user can list, ordered
+// by name, with their pod counts. Users who may not list namespaces see the
+// current one only.
+func ListNamespaces(ctx context.Context, clientset kubernetes.Interface, current string) ([]NamespaceInfo, error) {
+	namespaces, err := namespaceNames(ctx, clientset, current)
+	if err != nil {
+		return nil, err
+	}
+
+	counts, err := podCounts(ctx, clientset, namespaces)
+	if err != nil {
+		return nil, err
+	}
+
+	var infos []NamespaceInfo
+	for _, ns := range namespaces {
+		count, ok := counts[ns.Name]
+		if !ok {
+			continue
+		}
+		infos = append(infos, NamespaceInfo{
+			Name:    ns.Name,
+			Status:  string(ns.Status.Phase),
+			Pods:    count,
+			Current: ns.Name == current,
+		})
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Name < infos[j].Name
+	})
+	return infos, nil
+}
+
+// namespaceNames lists the namespaces of the cluster, falling back to the
+// current namespace when listing them is forbidden
+func namespaceNames(ctx context.Context, clientset kubernetes.Interface, current string) ([]corev1.Namespace, error) {
+	list, err := clientset.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+	if apierrors.IsForbidden(err) {
+		return []corev1.Namespace{{
+			ObjectMeta: metav1.ObjectMeta{Name: current},
+			Status:     corev1.NamespaceStatus{Phase: corev1.NamespaceActive},
+		}}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error listing namespaces: %w", err)
+	}
+	return list.Items, nil
+}
+
+// podCounts counts the pods of every namespace with a single cluster-wide
+// list, or namespace by namespace when that is forbidden. Namespaces whose
+// pods may not be listed are left out.
+func podCounts(ctx context.Context, clientset kubernetes.Interface, namespaces []corev1.Namespace) (map[string]int, error) {
+	counts := make(map[string]int)
+	pods, err := clientset.CoreV1().Pods("").List(ctx, metav1.ListOptions{})
+	if err == nil {
+		for _, ns := range namespaces {
+			counts[ns.Name] = 0
+		}
+		for _, pod := range pods.Items {
+			if _, ok := counts[pod.Namespace]; ok {
+				counts[pod.Namespace]++
+			}
+		}
+		return counts, nil
+	}
+	if !apierrors.IsForbidden(err) {
+		return nil, fmt.Errorf("error listing pods: %w", err)
+	}
+
+	for _, ns := range namespaces {
+		pods, err := clientset.CoreV1().Pods(ns.Name).List(ctx, metav1.ListOptions{})
+		if apierrors.IsForbidden(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error listing pods in namespace '%s': %w", ns.Name, err)
+		}
+		counts[ns.Name] = len(pods.Items)
+	}
+	return counts, nil
+}
@@ -0,0 +1,206 @@
+package kubernetes
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func newTestNamespace(name string) *corev1.Namespace {
+	return &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status:     corev1.NamespaceStatus{Phase: corev1.NamespaceActive},
+	}
+}
+
+func newNamespacedTestPod(namespace, name string) *corev1.Pod {
+	pod := newTestPod(name, nil, "app")
+	pod.Namespace = namespace
+	return pod
+}
+
+func TestListNamespaces(t *testing.T) {
+	objects := []runtime.Object{
+		newTestNamespace("shop"),
+		newTestNamespace("default"),
+		newTestNamespace("kube-system"),
+		newNamespacedTestPod("shop", "api-1"),
+		newNamespacedTestPod("shop", "api-2"),
+		newNamespacedTestPod("kube-system", "coredns"),
+	}
+
+	tests := []struct {
+		name  string
+		react func(*fake.Clientset)
+		want  []NamespaceInfo
+	}{
+		{
+			name: "All namespaces",
+			want: []NamespaceInfo{
+				{Name: "default", Status: "Active", Pods: 0},
+				{Name: "kube-system", Status: "Active", Pods: 1},
+				{Name: "shop", Status: "Active", Pods: 2, Current: true},
+			},
+		},
+		{
+			name: "Namespaces forbidden",
+			react: func(c *fake.Clientset) {
+				c.PrependReactor("list", "namespaces", func(k8stesting.Action) (bool, runtime.Object, error) {
+					return true, nil, apierrors.NewForbidden(corev1.Resource("namespaces"), "", nil)
+				})
+			},
+			want: []NamespaceInfo{{Name: "shop", Status: "Active", Pods: 2, Current: true}},
+		},
+		{
+			name: "Pods forbidden outside the current namespace",
+			react: func(c *fake.Clientset) {
+				c.PrependReactor("list", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+					if action.GetNamespace() == "shop" {
+						return false, nil, nil
+					}
+					return true, nil, apierrors.NewForbidden(corev1.Resource("pods"), "", nil)
+				})
+			},
+			want: []NamespaceInfo{{Name: "shop", Status: "Active", Pods: 2, Current: true}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clientset := fake.NewSimpleClientset(objects...)
+			if tt.react != nil {
+				tt.react(clientset)
+			}
+			got, err := ListNamespaces(context.Background(), clientset, "shop")
+			if err != nil {
+				t.Fatalf("ListNamespaces() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ListNamespaces() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveAllTargets(t *testing.T) {
+	pod := func(namespace, name string) *corev1.Pod {
+		pod := newTestPod(name, map[string]string{"app": "api"}, "app")
+		pod.Namespace = namespace
+		return pod
+	}
+	objects := []runtime.Object{
+		newTestNamespace("shop"),
+		newTestNamespace("payments"),
+		newTestNamespace("kube-system"),
+		pod("shop", "api-1"),
+		pod("payments", "api-2"),
+		pod("kube-system", "coredns"),
+	}
+	// onlyIn forbids listing pods outside the given namespaces
+	onlyIn := func(namespaces ...string) func(*fake.Clientset) {
+		return func(c *fake.Clientset) {
+			c.PrependReactor("list", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+				for _, ns := range namespaces {
+					if action.GetNamespace() == ns {
+						return false, nil, nil
+					}
+				}
+				return true, nil, apierrors.NewForbidden(corev1.Resource("pods"), "", nil)
+			})
+		}
+	}
+	forbidNamespaces := func(c *fake.Clientset) {
+		c.PrependReactor("list", "namespaces", func(k8stesting.Action) (bool, runtime.Object, error) {
+			return true, nil, apierrors.NewForbidden(corev1.Resource("namespaces"), "", nil)
+		})
+	}
+
+	tests := []struct {
+		name         string
+		react        []func(*fake.Clientset)
+		selector     string
+		fallback     []string
+		wantPods     []string
+		wantSearched []string
+		wantErr      error
+	}{
+		{
+			name:     "Pods listed cluster-wide",
+			selector: "app=api",
+			wantPods: []string{"api-1", "api-2", "coredns"},
+		},
+		{
+			name:         "Namespaces the user may list",
+			react:        []func(*fake.Clientset){onlyIn("shop", "payments")},
+			selector:     "app=api",
+			wantPods:     []string{"api-1", "api-2"},
+			wantSearched: []string{"payments", "shop"},
+		},
+		{
+			name:         "Namespaces of the config file",
+			react:        []func(*fake.Clientset){onlyIn("shop", "payments")},
+			selector:     "app=api",
+			fallback:     []string{"shop", "kube-system"},
+			wantPods:     []string{"api-1"},
+			wantSearched: []string{"shop"},
+		},
+		{
+			name:         "Current namespace when namespaces are forbidden",
+			react:        []func(*fake.Clientset){onlyIn("shop"), forbidNamespaces},
+			selector:     "app=api",
+			wantPods:     []string{"api-1"},
+			wantSearched: []string{"shop"},
+		},
+		{
+			name:     "Pods forbidden everywhere",
+			react:    []func(*fake.Clientset){onlyIn(), forbidNamespaces},
+			selector: "app=api",
+			wantErr:  ErrForbidden,
+		},
+		{
+			name:     "No matching pods",
+			react:    []func(*fake.Clientset){onlyIn("shop", "payments")},
+			selector: "app=db",
+			wantErr:  ErrPodNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clientset := fake.NewSimpleClientset(objects...)
+			for _, react := range tt.react {
+				react(clientset)
+			}
+			targets, searched, err := ResolveAllTargets(context.Background(), clientset, tt.selector, "", tt.fallback, "shop")
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("ResolveAllTargets() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ResolveAllTargets() error = %v", err)
+			}
+
+			var pods []string
+			for _, target := range targets {
+				pods = append(pods, target.Pod)
+			}
+			sort.Strings(pods)
+			sort.Strings(searched)
+			if !reflect.DeepEqual(pods, tt.wantPods) {
+				t.Errorf("ResolveAllTargets() pods = %v, want %v", pods, tt.wantPods)
+			}
+			if !reflect.DeepEqual(searched, tt.wantSearched) {
+				t.Errorf("ResolveAllTargets() searched = %v, want %v", searched, tt.wantSearched)
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package kubernetes
+
+import (
+	"io"
+	"time"
+
+	"github.com/dantech2000/kubelog/pkg/checkpoint"
+	"github.com/dantech2000/kubelog/pkg/logging"
+)
+
+// Option configures a LogFetcher
+type Option func(*LogFetcher)
+
+// WithContainer selects the container to fetch logs from.
+// Without it, WithContainerChooser picks one of a pod with several containers.
+func WithContainer(name string) Option {
+	return func(lf *LogFetcher) {
+		lf.ContainerName = name
+	}
+}
+
+// WithFollow streams new log lines as they are written
+func WithFollow() Option {
+	return func(lf *LogFetcher) {
+		lf.Follow = true
+	}
+}
+
+// WithPrevious fetches logs from the previous terminated container instance
+func WithPrevious() Option {
+	return func(lf *LogFetcher) {
+		lf.Previous = true
+	}
+}
+
+// WithSince only fetches logs newer than the given duration
+func WithSince(d time.Duration) Option {
+	return func(lf *LogFetcher) {
+		lf.Since = d
+	}
+}
+
+// WithWriter sets where formatted log lines are written
+func WithWriter(w io.Writer) Option {
+	return func(lf *LogFetcher) {
+		lf.Writer = w
+	}
+}
+
+// WithPipeline sets the pipeline parsed entries are passed through before writing
+func WithPipeline(p *logging.Pipeline) Option {
+	return func(lf *LogFetcher) {
+		lf.Pipeline = p
+	}
+}
+
+// WithPrefix writes the given prefix before every log line
+func WithPrefix(prefix string) Option {
+	return func(lf *LogFetcher) {
+		lf.Prefix = prefix
+	}
+}
+
+// WithFormatter sets how entries are rendered; the default is logging.TextFormatter
+func WithFormatter(f logging.Formatter) Option {
+	return func(lf *LogFetcher) {
+		lf.Formatter = f
+	}
+}
+
+// WithLabels attaches the values of the given pod labels to every entry's source
+func WithLabels(keys ...string) Option {
+	return func(lf *LogFetcher) {
+		lf.Labels = keys
+	}
+}
+
+// WithCheckpoints resumes the stream after the last line recorded in the store
+// and records the lines seen
+func WithCheckpoints(store *checkpoint.Store) Option {
+	return func(lf *LogFetcher) {
+		lf.Checkpoints = store
+	}
+}
+
+// WithClockSkew estimates the clock skew of the stream in skew, which also
+// corrects the order of lines if enabled
+func WithClockSkew(skew *ClockSkew) Option {
+	return func(lf *LogFetcher) {
+		lf.Skew = skew
+	}
+}
+
+// WithContainerChooser picks the container of a pod with several when
+// WithContainer isn't given, e.g. by prompting the user
+func WithContainerChooser(choose func(pod string, containers []ContainerInfo) (string, error)) Option {
+	return func(lf *LogFetcher) {
+		lf.ChooseContainer = choose
+	}
+}
+
+// WithEvents passes the progress of the stream, such as its opening and the
+// phase of a pod that has finished, to h
+func WithEvents(h EventHandler) Option {
+	return func(lf *LogFetcher) {
+		lf.Events = h
+	}
+}
+
+// WithMonitor reports the health of the stream to m, which can be shared by
+// several fetchers
+func WithMonitor(m *StreamMonitor) Option {
+	return func(lf *LogFetcher) {
+		lf.Monitor = m
+	}
+}
+
+// WithRaw writes the lines as they are, skipping parsing, filtering and formatting
+func WithRaw() Option {
+	return func(lf *LogFetcher) {
+		lf.Raw = true
+	}
+}
@@ -0,0 +1,31 @@
+package kubernetes
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// podFinished reports whether all containers of a pod have terminated for
+// good, because it completed, failed or was evicted, so no more lines follow
+func podFinished(pod *corev1.Pod) bool {
+	return pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed
+}
+
+// podPhase describes the phase of a pod with the reason the kubelet gave,
+// e.g. "Failed (Evicted: The node was low on resource: memory.)"
+func podPhase(pod *corev1.Pod) string {
+	phase := string(pod.Status.Phase)
+	switch {
+	case pod.Status.Reason != "" && pod.Status.Message != "":
+		return fmt.Sprintf("%s (%s: %s)", phase, pod.Status.Reason, pod.Status.Message)
+	case pod.Status.Reason != "":
+		return fmt.Sprintf("%s (%s)", phase, pod.Status.Reason)
+	}
+	return phase
+}
+
+// event reports something about the stream to lf.Events
+func (lf *LogFetcher) event(kind EventKind, err error, format string, args ...interface{}) {
+	lf.Events.emit(Event{Kind: kind, Target: lf.target(), Message: fmt.Sprintf(format, args...), Err: err})
+}
@@ -0,0 +1,76 @@
+package kubernetes
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestPodPhase(t *testing.T) {
+	tests := []struct {
+		name         string
+		status       corev1.PodStatus
+		wantFinished bool
+		wantPhase    string
+	}{
+		{"running", corev1.PodStatus{Phase: corev1.PodRunning}, false, "Running"},
+		{"completed", corev1.PodStatus{Phase: corev1.PodSucceeded}, true, "Succeeded"},
+		{
+			name:         "evicted",
+			status:       corev1.PodStatus{Phase: corev1.PodFailed, Reason: "Evicted", Message: "The node was low on resource: memory."},
+			wantFinished: true,
+			wantPhase:    "Failed (Evicted: The node was low on resource: memory.)",
+		},
+		{"failed without a message", corev1.PodStatus{Phase: corev1.PodFailed, Reason: "DeadlineExceeded"}, true, "Failed (DeadlineExceeded)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := &corev1.Pod{Status: tt.status}
+			if got := podFinished(pod); got != tt.wantFinished {
+				t.Errorf("podFinished() = %v, want %v", got, tt.wantFinished)
+			}
+			if got := podPhase(pod); got != tt.wantPhase {
+				t.Errorf("podPhase() = %q, want %q", got, tt.wantPhase)
+			}
+		})
+	}
+}
+
+func TestLogFetcher_GetLogsFinishedPod(t *testing.T) {
+	pod := newTestPod("job-1", nil, "app")
+	pod.Status.Phase = corev1.PodSucceeded
+	clientset := fake.NewSimpleClientset(pod)
+
+	var logs bytes.Buffer
+	var events []Event
+	fetcher := NewLogFetcher(clientset, "default", "job-1",
+		WithContainer("app"),
+		WithWriter(&logs),
+		WithFollow(),
+		WithEvents(func(e Event) { events = append(events, e) }),
+		WithRaw(),
+	)
+	if err := fetcher.GetLogs(context.Background()); err != nil {
+		t.Fatalf("GetLogs() error = %v", err)
+	}
+	if got, want := logs.String(), "fake logs\n"; got != want {
+		t.Errorf("GetLogs() = %q, want %q", got, want)
+	}
+
+	var kinds []EventKind
+	for _, e := range events {
+		kinds = append(kinds, e.Kind)
+		if e.Kind == EventPodFinished && !strings.Contains(e.Message, "default/job-1 is Succeeded") {
+			t.Errorf("event message = %q, want the pod phase", e.Message)
+		}
+	}
+	if want := []EventKind{EventPodFinished, EventStreamOpened, EventStreamClosed}; !reflect.DeepEqual(kinds, want) {
+		t.Errorf("events = %v, want %v", kinds, want)
+	}
+}
@@ -0,0 +1,98 @@
+package kubernetes
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// rawBufferSize is the size of the chunks raw streams are copied in
+const rawBufferSize = 256 << 10
+
+// rawCopier writes a stream unparsed in chunks of whole lines, so lines of
+// concurrent streams sharing a writer don't interleave, with the prefix
+// before every line
+type rawCopier struct {
+	w      io.Writer
+	prefix string
+	// atStart is set when the next byte written starts a line
+	atStart bool
+	out     []byte
+}
+
+// copyRaw copies r to w, writing prefix before every line when it is not empty
+func copyRaw(w io.Writer, r io.Reader, prefix string) error {
+	c := &rawCopier{w: w, prefix: prefix, atStart: true}
+	buf := make([]byte, rawBufferSize)
+	pending := 0
+	for {
+		n, readErr := r.Read(buf[pending:])
+		pending += n
+
+		if i := bytes.LastIndexByte(buf[:pending], '\n'); i >= 0 {
+			if err := c.write(buf[:i+1]); err != nil {
+				return err
+			}
+			pending = copy(buf, buf[i+1:pending])
+		} else if pending == len(buf) {
+			// A line longer than the buffer is written in pieces
+			if err := c.write(buf); err != nil {
+				return err
+			}
+			pending = 0
+		}
+
+		if errors.Is(readErr, io.EOF) {
+			if pending > 0 {
+				return c.write(append(buf[:pending], '\n'))
+			}
+			return nil
+		}
+		if readErr != nil {
+			return fmt.Errorf("error reading log stream: %w", readErr)
+		}
+	}
+}
+
+// write writes p with the prefix inserted at the start of every line
+func (c *rawCopier) write(p []byte) error {
+	if c.prefix != "" {
+		c.out = c.out[:0]
+		for len(p) > 0 {
+			if c.atStart {
+				c.out = append(c.out, c.prefix...)
+				c.out = append(c.out, ' ')
+			}
+			i := bytes.IndexByte(p, '\n')
+			if i < 0 {
+				c.out = append(c.out, p...)
+				c.atStart = false
+				break
+			}
+			c.out = append(c.out, p[:i+1]...)
+			p = p[i+1:]
+			c.atStart = true
+		}
+		p = c.out
+	}
+	if _, err := c.w.Write(p); err != nil {
+		return fmt.Errorf("error writing log line: %w", err)
+	}
+	return nil
+}
+
+// lineCounter counts the lines written through it as read by a stream
+type lineCounter struct {
+	w      io.Writer
+	status *streamStatus
+}
+
+// Write implements io.Writer
+func (c *lineCounter) Write(p []byte) (int, error) {
+	if n := bytes.Count(p, []byte{'\n'}); n > 0 {
+		c.status.linesRead(uint64(n), time.Now())
+	}
+	return c.w.Write(p)
+}
@@ -0,0 +1,71 @@
+package kubernetes
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// writeRecorder records every Write call
+type writeRecorder struct {
+	writes []string
+}
+
+func (w *writeRecorder) Write(p []byte) (int, error) {
+	w.writes = append(w.writes, string(p))
+	return len(p), nil
+}
+
+func TestCopyRaw(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		prefix string
+		want   string
+	}{
+		{"no prefix", "line 1\n{\"level\":\"error\"}\n", "", "line 1\n{\"level\":\"error\"}\n"},
+		{"prefix", "line 1\nline 2\n", "[api-0]", "[api-0] line 1\n[api-0] line 2\n"},
+		{"missing final newline", "line 1\nline 2", "[api-0]", "[api-0] line 1\n[api-0] line 2\n"},
+		{"empty stream", "", "[api-0]", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &writeRecorder{}
+			// Reading a byte at a time, every write still holds whole lines
+			if err := copyRaw(w, iotest.OneByteReader(strings.NewReader(tt.input)), tt.prefix); err != nil {
+				t.Fatalf("copyRaw() error = %v", err)
+			}
+			for _, write := range w.writes {
+				if !strings.HasSuffix(write, "\n") {
+					t.Errorf("copyRaw() wrote a partial line %q", write)
+				}
+			}
+			if got := strings.Join(w.writes, ""); got != tt.want {
+				t.Errorf("copyRaw() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLogFetcher_GetLogsRaw(t *testing.T) {
+	clientset := fake.NewSimpleClientset(newTestPod("api-1", nil, "app"))
+
+	var buf bytes.Buffer
+	fetcher := NewLogFetcher(clientset, "default", "api-1",
+		WithContainer("app"),
+		WithWriter(&buf),
+		WithPrefix("[api-1]"),
+		WithRaw(),
+	)
+	if err := fetcher.GetLogs(context.Background()); err != nil {
+		t.Fatalf("GetLogs() error = %v", err)
+	}
+	if got, want := buf.String(), "[api-1] fake logs\n"; got != want {
+		t.Errorf("GetLogs() = %q, want %q", got, want)
+	}
+}
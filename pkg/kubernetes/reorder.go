@@ -0,0 +1,158 @@
+package kubernetes
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dantech2000/kubelog/pkg/logging"
+)
+
+// ReorderWriter orders the lines of concurrent streams by timestamp while they
+// are followed. Every line is held for the length of the window and written
+// once it has waited that long, after the buffered lines with an earlier
+// timestamp. A line arriving after a later one was written can't be put back
+// in order; it is written right away and counted as late. Lines without a
+// timestamp keep the position of the line before them. It is safe for
+// concurrent use.
+type ReorderWriter struct {
+	w      io.Writer
+	window time.Duration
+	now    func() time.Time
+	stop   chan struct{}
+	done   chan struct{}
+
+	mu      sync.Mutex
+	lines   []bufferedLine
+	last    time.Time
+	written time.Time
+	late    uint64
+	maxLag  time.Duration
+	err     error
+}
+
+// bufferedLine is a formatted line waiting in a ReorderWriter
+type bufferedLine struct {
+	timedLine
+	arrived time.Time
+}
+
+// NewReorderWriter creates a ReorderWriter writing to w, holding lines for window.
+// Close must be called to write the lines still buffered.
+func NewReorderWriter(w io.Writer, window time.Duration) *ReorderWriter {
+	r := &ReorderWriter{
+		w:      w,
+		window: window,
+		now:    time.Now,
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	go r.run()
+	return r
+}
+
+// run releases the lines that waited for the window until Close is called
+func (r *ReorderWriter) run() {
+	defer close(r.done)
+	ticker := time.NewTicker(max(r.window/4, 10*time.Millisecond))
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.stop:
+			return
+		case <-ticker.C:
+			r.mu.Lock()
+			r.release(r.now())
+			r.mu.Unlock()
+		}
+	}
+}
+
+// WriteEntry implements the EntryWriter interface
+func (r *ReorderWriter) WriteEntry(entry logging.LogEntry, line string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil {
+		return r.err
+	}
+
+	ts := entry.Timestamp
+	if ts.IsZero() {
+		ts = r.last
+	}
+	r.last = ts
+	if ts.Before(r.written) {
+		// A later line was already written, so this one is out of the window
+		r.late++
+		r.maxLag = max(r.maxLag, r.written.Sub(ts))
+		r.writeLine(line)
+		return r.err
+	}
+
+	// Insert after the lines with the same timestamp, so they keep their order
+	i := sort.Search(len(r.lines), func(i int) bool {
+		return r.lines[i].timestamp.After(ts)
+	})
+	r.lines = append(r.lines, bufferedLine{})
+	copy(r.lines[i+1:], r.lines[i:])
+	r.lines[i] = bufferedLine{timedLine: timedLine{timestamp: ts, line: line}, arrived: r.now()}
+	return nil
+}
+
+// Write implements io.Writer interface for lines without an entry
+func (r *ReorderWriter) Write(p []byte) (int, error) {
+	return len(p), r.WriteEntry(logging.LogEntry{}, strings.TrimSuffix(string(p), "\n"))
+}
+
+// release writes the lines that arrived at least a window before now, along
+// with every buffered line with an earlier timestamp; the caller holds r.mu
+func (r *ReorderWriter) release(now time.Time) {
+	n := 0
+	for i, l := range r.lines {
+		if now.Sub(l.arrived) >= r.window {
+			n = i + 1
+		}
+	}
+	r.writeLines(n)
+}
+
+// writeLines writes the first n buffered lines; the caller holds r.mu
+func (r *ReorderWriter) writeLines(n int) {
+	for _, l := range r.lines[:n] {
+		r.writeLine(l.line)
+		r.written = l.timestamp
+	}
+	r.lines = append(r.lines[:0], r.lines[n:]...)
+}
+
+// writeLine writes a line, keeping the first error; the caller holds r.mu
+func (r *ReorderWriter) writeLine(line string) {
+	if r.err != nil {
+		return
+	}
+	if _, err := fmt.Fprintln(r.w, line); err != nil {
+		r.err = err
+	}
+}
+
+// Late returns the number of lines that arrived too late to be put in order,
+// and how far behind the lines already written the latest of them was
+func (r *ReorderWriter) Late() (uint64, time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.late, r.maxLag
+}
+
+// Close writes the lines still buffered and returns the first error writing any line
+func (r *ReorderWriter) Close() error {
+	close(r.stop)
+	<-r.done
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.writeLines(len(r.lines))
+	return r.err
+}
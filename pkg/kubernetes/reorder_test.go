@@ -0,0 +1,61 @@
+package kubernetes
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/dantech2000/kubelog/pkg/logging"
+)
+
+func TestReorderWriter(t *testing.T) {
+	base := time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC)
+	clock := base
+	var buf bytes.Buffer
+	// Without the release goroutine, so the test controls the clock
+	done := make(chan struct{})
+	close(done)
+	w := &ReorderWriter{w: &buf, window: 2 * time.Second, now: func() time.Time { return clock }, stop: make(chan struct{}), done: done}
+
+	write := func(offset time.Duration, line string) {
+		t.Helper()
+		entry := logging.LogEntry{}
+		if offset >= 0 {
+			entry.Timestamp = base.Add(offset)
+		}
+		if err := w.WriteEntry(entry, line); err != nil {
+			t.Fatal(err)
+		}
+	}
+	expect := func(want string) {
+		t.Helper()
+		if got := buf.String(); got != want {
+			t.Errorf("output = %q, want %q", got, want)
+		}
+	}
+
+	write(2*time.Second, "api-1 second")
+	clock = clock.Add(time.Second)
+	write(1*time.Second, "api-2 first")
+	write(-1, "api-2 first, continued")
+	write(3*time.Second, "api-1 third")
+	w.release(clock)
+	expect("")
+
+	// api-1 second has waited for the window, and api-2 first comes before it
+	clock = clock.Add(time.Second)
+	w.release(clock)
+	expect("api-2 first\napi-2 first, continued\napi-1 second\n")
+
+	// Behind the lines already written, so it is written late
+	write(500*time.Millisecond, "api-3 late")
+	expect("api-2 first\napi-2 first, continued\napi-1 second\napi-3 late\n")
+	if late, lag := w.Late(); late != 1 || lag != 1500*time.Millisecond {
+		t.Errorf("Late() = %d, %s, want 1, 1.5s", late, lag)
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	expect("api-2 first\napi-2 first, continued\napi-1 second\napi-3 late\napi-1 third\n")
+}
@@ -0,0 +1,69 @@
+package kubernetes
+
+import (
+	"strings"
+	"time"
+
+	"github.com/dantech2000/kubelog/pkg/checkpoint"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// streamCursor skips the lines of a stream up to the checkpoint of a previous
+// session and records the timestamp of the others
+type streamCursor struct {
+	store *checkpoint.Store
+	key   string
+	after time.Time
+}
+
+// resumeCursor requests the kubelet timestamp of every line when checkpoints
+// are recorded, and only the lines since the recorded cursor. SinceTime has a
+// precision of one second, so the returned cursor skips the lines of that
+// second which were already seen.
+func (lf *LogFetcher) resumeCursor(opts *corev1.PodLogOptions) *streamCursor {
+	if lf.Checkpoints == nil {
+		return nil
+	}
+	key := Target{Namespace: lf.Namespace, Pod: lf.PodName, Container: lf.ContainerName}.String()
+	cursor := &streamCursor{store: lf.Checkpoints, key: key}
+	opts.Timestamps = true
+
+	after, ok := lf.Checkpoints.Cursor(key)
+	if !ok {
+		return cursor
+	}
+	cursor.after = after
+	// A shorter --since already starts after the cursor
+	if lf.Since <= 0 || time.Since(after) < lf.Since {
+		since := metav1.NewTime(after)
+		opts.SinceTime = &since
+		opts.SinceSeconds = nil
+	}
+	lf.event(EventStreamResumed, nil, "resuming log stream after %s", after.Format(time.RFC3339Nano))
+	return cursor
+}
+
+// advance records a line received at t, or returns false when it was seen by
+// a previous session. Lines without a timestamp are passed through.
+func (c *streamCursor) advance(t time.Time) bool {
+	if t.IsZero() {
+		return true
+	}
+	if !t.After(c.after) {
+		return false
+	}
+	c.store.Advance(c.key, t)
+	return true
+}
+
+// splitTimestamp splits the time the container runtime received a line from
+// the line, returning a zero time when it doesn't start with one
+func splitTimestamp(line string) (string, time.Time) {
+	stamp, rest, _ := strings.Cut(line, " ")
+	t, err := time.Parse(time.RFC3339Nano, stamp)
+	if err != nil {
+		return line, time.Time{}
+	}
+	return rest, t
+}
@@ -0,0 +1,54 @@
+package kubernetes
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dantech2000/kubelog/pkg/checkpoint"
+	"github.com/dantech2000/kubelog/pkg/logging"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestLogWriter_Resume(t *testing.T) {
+	store, err := checkpoint.Load(filepath.Join(t.TempDir(), "checkpoints.json"))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	key := "default/api-0/app"
+	store.Advance(key, time.Date(2024, 1, 15, 10, 0, 0, 500000000, time.UTC))
+
+	fetcher := NewLogFetcher(nil, "default", "api-0", WithContainer("app"), WithCheckpoints(store))
+	var opts corev1.PodLogOptions
+	cursor := fetcher.resumeCursor(&opts)
+	if !opts.Timestamps || opts.SinceTime == nil {
+		t.Fatalf("resumeCursor() options = %+v, want timestamps since the cursor", opts)
+	}
+
+	var buf bytes.Buffer
+	writer := NewLogWriter(&buf)
+	writer.timestamps = true
+	writer.cursor = cursor
+	writer.formatter = logging.FormatterFunc(func(entry logging.LogEntry) string {
+		return entry.RawLine
+	})
+	for _, line := range []string{
+		"2024-01-15T10:00:00.250000000Z seen before",
+		"2024-01-15T10:00:00.500000000Z last line of the previous session",
+		"2024-01-15T10:00:00.750000000Z first new line",
+		"2024-01-15T10:00:01.000000000Z second new line",
+		"no timestamp",
+	} {
+		if _, err := writer.Write([]byte(line)); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+
+	if got, want := buf.String(), "first new line\nsecond new line\nno timestamp\n"; got != want {
+		t.Errorf("Write() output = %q, want %q", got, want)
+	}
+	if got, _ := store.Cursor(key); !got.Equal(time.Date(2024, 1, 15, 10, 0, 1, 0, time.UTC)) {
+		t.Errorf("Cursor() = %v, want the timestamp of the last line", got)
+	}
+}
@@ -0,0 +1,71 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// RevisionGroup is the set of pods belonging to one revision of a workload
+type RevisionGroup struct {
+	// Label names the group, "stable" or "canary"
+	Label string
+	// Revision is the revision number of the group's ReplicaSet
+	Revision int64
+	// Selector matches the pods of the group
+	Selector string
+}
+
+// CompareRevisionGroups returns the stable and canary revision groups of a
+// deployment or Argo Rollout. The two most recent ReplicaSets that still run
+// pods are compared; the newest one is considered the canary.
+func CompareRevisionGroups(ctx context.Context, clientset kubernetes.Interface, namespace string, w Workload) ([]RevisionGroup, error) {
+	var replicaSets []appsv1.ReplicaSet
+	var err error
+
+	switch w.Kind {
+	case "deployment":
+		replicaSets, err = DeploymentReplicaSets(ctx, clientset, namespace, w.Name)
+	case "rollout":
+		replicaSets, err = RolloutReplicaSets(ctx, clientset, namespace, w.Name)
+	default:
+		return nil, fmt.Errorf("revisions can only be compared for deployments and rollouts, not %s", w)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var active []appsv1.ReplicaSet
+	for _, rs := range replicaSets {
+		if rs.Status.Replicas > 0 {
+			active = append(active, rs)
+		}
+	}
+	if len(active) < 2 {
+		return nil, fmt.Errorf("%s has %d active revision(s); at least two are needed to compare", w, len(active))
+	}
+
+	sort.Slice(active, func(i, j int) bool {
+		return ReplicaSetRevision(&active[i]) > ReplicaSetRevision(&active[j])
+	})
+
+	groups := make([]RevisionGroup, 0, 2)
+	for i, label := range []string{"canary", "stable"} {
+		selector, err := labelSelectorString(active[i].Spec.Selector)
+		if err != nil {
+			return nil, err
+		}
+		groups = append(groups, RevisionGroup{
+			Label:    label,
+			Revision: ReplicaSetRevision(&active[i]),
+			Selector: selector,
+		})
+	}
+
+	// Report the stable group first
+	groups[0], groups[1] = groups[1], groups[0]
+	return groups, nil
+}
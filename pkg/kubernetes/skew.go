@@ -0,0 +1,100 @@
+package kubernetes
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// skewWindow is the number of recent entries the skew of a stream is estimated from
+	skewWindow = 101
+	// minSkewSamples is the number of entries needed before a skew is estimated
+	minSkewSamples = 5
+)
+
+// ClockSkew estimates the clock skew of every container stream: how far the
+// timestamps an application logs are ahead of the time the container runtime
+// received the lines, which comes from the node's clock. A skew beyond the
+// delivery delay is systematic, e.g. a container with a drifting clock or
+// logging local time without a zone. It is safe for concurrent use.
+type ClockSkew struct {
+	// Correct shifts the timestamps lines are ordered by by the skew of their stream
+	Correct bool
+
+	mu      sync.Mutex
+	streams map[Target]*skewSamples
+}
+
+// skewSamples are the offsets of the most recent entries of a stream
+type skewSamples struct {
+	offsets []time.Duration
+	next    int
+}
+
+// NewClockSkew creates a ClockSkew, correcting the order of lines when correct is set
+func NewClockSkew(correct bool) *ClockSkew {
+	return &ClockSkew{Correct: correct, streams: make(map[Target]*skewSamples)}
+}
+
+// Observe records an entry of the stream logged and received at the given times
+func (c *ClockSkew) Observe(target Target, logged, received time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s, ok := c.streams[target]
+	if !ok {
+		s = &skewSamples{}
+		c.streams[target] = s
+	}
+	offset := logged.Sub(received)
+	if len(s.offsets) < skewWindow {
+		s.offsets = append(s.offsets, offset)
+		return
+	}
+	s.offsets[s.next] = offset
+	s.next = (s.next + 1) % skewWindow
+}
+
+// Skew returns the median offset of the recent entries of the stream, or false
+// when too few entries had a timestamp
+func (c *ClockSkew) Skew(target Target) (time.Duration, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.skew(target)
+}
+
+// skew returns the skew of a stream; the caller holds c.mu
+func (c *ClockSkew) skew(target Target) (time.Duration, bool) {
+	s, ok := c.streams[target]
+	if !ok || len(s.offsets) < minSkewSamples {
+		return 0, false
+	}
+	offsets := append([]time.Duration(nil), s.offsets...)
+	sort.Slice(offsets, func(i, j int) bool { return offsets[i] < offsets[j] })
+	return offsets[len(offsets)/2], true
+}
+
+// Skews returns the skew of every stream with enough entries, keyed by target
+func (c *ClockSkew) Skews() map[Target]time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	skews := make(map[Target]time.Duration, len(c.streams))
+	for target := range c.streams {
+		if skew, ok := c.skew(target); ok {
+			skews[target] = skew
+		}
+	}
+	return skews
+}
+
+// Adjust returns the timestamp the entry of the stream is ordered by: the
+// logged timestamp, shifted by the skew of the stream when correcting
+func (c *ClockSkew) Adjust(target Target, logged time.Time) time.Time {
+	if !c.Correct {
+		return logged
+	}
+	if skew, ok := c.Skew(target); ok {
+		return logged.Add(-skew)
+	}
+	return logged
+}
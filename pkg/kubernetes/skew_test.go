@@ -0,0 +1,95 @@
+package kubernetes
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/dantech2000/kubelog/pkg/logging"
+)
+
+func TestClockSkew(t *testing.T) {
+	base := time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC)
+	target := Target{Namespace: "default", Pod: "api-0", Container: "app"}
+
+	tests := []struct {
+		name     string
+		offsets  []time.Duration
+		wantSkew time.Duration
+		wantOK   bool
+	}{
+		{"too few entries", []time.Duration{3 * time.Second, 3 * time.Second}, 0, false},
+		{"median ignores outliers", []time.Duration{
+			3 * time.Second, 3*time.Second + 10*time.Millisecond, -time.Minute,
+			3*time.Second - 10*time.Millisecond, time.Hour,
+		}, 3 * time.Second, true},
+		{"local time logged as UTC", []time.Duration{
+			-2 * time.Hour, -2 * time.Hour, -2 * time.Hour, -2 * time.Hour, -2 * time.Hour,
+		}, -2 * time.Hour, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			skew := NewClockSkew(true)
+			for i, offset := range tt.offsets {
+				received := base.Add(time.Duration(i) * time.Second)
+				skew.Observe(target, received.Add(offset), received)
+			}
+			got, ok := skew.Skew(target)
+			if got != tt.wantSkew || ok != tt.wantOK {
+				t.Errorf("Skew() = %s, %v, want %s, %v", got, ok, tt.wantSkew, tt.wantOK)
+			}
+			if _, ok := skew.Skews()[target]; ok != tt.wantOK {
+				t.Errorf("Skews() has the target = %v, want %v", ok, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestLogWriter_CorrectsSkew(t *testing.T) {
+	base := time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC)
+	var buf bytes.Buffer
+	sorted := NewSortedWriter(&buf)
+	skew := NewClockSkew(true)
+
+	// api-0 logs 10s ahead of the runtime's clock, api-1 is on time
+	streams := []struct {
+		pod      string
+		offset   time.Duration
+		received []time.Duration
+	}{
+		{"api-0", 10 * time.Second, []time.Duration{0, 2 * time.Second}},
+		{"api-1", 0, []time.Duration{time.Second, 3 * time.Second}},
+	}
+	for _, stream := range streams {
+		target := Target{Namespace: "default", Pod: stream.pod, Container: "app"}
+		for i := 0; i < minSkewSamples; i++ {
+			skew.Observe(target, base.Add(stream.offset), base)
+		}
+
+		writer := NewLogWriter(sorted)
+		writer.timestamps = true
+		writer.skew = skew
+		writer.target = target
+		writer.formatter = logging.FormatterFunc(func(entry logging.LogEntry) string {
+			return stream.pod + " " + entry.Message
+		})
+		for _, offset := range stream.received {
+			received := base.Add(offset)
+			line := fmt.Sprintf(`%s {"ts":"%s","msg":"at %s"}`,
+				received.Format(time.RFC3339Nano), received.Add(stream.offset).Format(time.RFC3339), offset)
+			if _, err := writer.Write([]byte(line)); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err := sorted.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "api-0 at 0s\napi-1 at 1s\napi-0 at 2s\napi-1 at 3s\n"
+	if got := buf.String(); got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+}
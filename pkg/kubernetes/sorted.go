@@ -0,0 +1,89 @@
+package kubernetes
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/dantech2000/kubelog/pkg/logging"
+)
+
+// EntryWriter is implemented by writers that want the parsed entry along with
+// each formatted line, e.g. to order lines by timestamp
+type EntryWriter interface {
+	WriteEntry(entry logging.LogEntry, line string) error
+}
+
+// SortedWriter buffers the lines of one or more streams and writes them
+// ordered by timestamp on Flush. Lines without a timestamp keep the position
+// of the line before them. It is safe for concurrent use.
+type SortedWriter struct {
+	mu    sync.Mutex
+	w     io.Writer
+	lines []timedLine
+}
+
+// timedLine is a formatted line and the timestamp it is ordered by, with
+// its entry when it was written for one
+type timedLine struct {
+	timestamp time.Time
+	line      string
+	entry     *logging.LogEntry
+}
+
+// NewSortedWriter creates a SortedWriter writing to w
+func NewSortedWriter(w io.Writer) *SortedWriter {
+	return &SortedWriter{w: w}
+}
+
+// WriteEntry implements the EntryWriter interface
+func (s *SortedWriter) WriteEntry(entry logging.LogEntry, line string) error {
+	s.add(&entry, line)
+	return nil
+}
+
+// Write implements io.Writer interface for lines without an entry
+func (s *SortedWriter) Write(p []byte) (int, error) {
+	s.add(nil, string(p))
+	return len(p), nil
+}
+
+// add buffers a line, ordered by the timestamp of its entry
+func (s *SortedWriter) add(entry *logging.LogEntry, line string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var ts time.Time
+	if entry != nil {
+		ts = entry.Timestamp
+	}
+	if ts.IsZero() && len(s.lines) > 0 {
+		ts = s.lines[len(s.lines)-1].timestamp
+	}
+	s.lines = append(s.lines, timedLine{timestamp: ts, line: line, entry: entry})
+}
+
+// Flush writes the buffered lines ordered by timestamp, as entries when the
+// writer accepts them
+func (s *SortedWriter) Flush() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sort.SliceStable(s.lines, func(i, j int) bool {
+		return s.lines[i].timestamp.Before(s.lines[j].timestamp)
+	})
+	ew, entries := s.w.(EntryWriter)
+	for _, l := range s.lines {
+		if entries && l.entry != nil {
+			if err := ew.WriteEntry(*l.entry, l.line); err != nil {
+				return err
+			}
+			continue
+		}
+		if _, err := fmt.Fprintln(s.w, l.line); err != nil {
+			return err
+		}
+	}
+	s.lines = nil
+	return nil
+}
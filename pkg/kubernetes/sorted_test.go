@@ -0,0 +1,76 @@
+package kubernetes
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/dantech2000/kubelog/pkg/logging"
+)
+
+func TestSortedWriter(t *testing.T) {
+	base := time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC)
+	var buf bytes.Buffer
+	w := NewSortedWriter(&buf)
+
+	// Two streams interleaved out of order; the untimed line follows its predecessor
+	writes := []struct {
+		offset time.Duration
+		line   string
+	}{
+		{3 * time.Second, "api-1 third"},
+		{1 * time.Second, "api-2 first"},
+		{-1, "api-2 first, continued"},
+		{2 * time.Second, "api-1 second"},
+	}
+	for _, wr := range writes {
+		entry := logging.LogEntry{}
+		if wr.offset >= 0 {
+			entry.Timestamp = base.Add(wr.offset)
+		}
+		if err := w.WriteEntry(entry, wr.line); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if buf.Len() != 0 {
+		t.Fatal("lines were written before Flush")
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "api-2 first\napi-2 first, continued\napi-1 second\napi-1 third\n"
+	if got := buf.String(); got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+}
+
+// entryRecorder records the entries written to it
+type entryRecorder struct {
+	bytes.Buffer
+	entries []logging.LogEntry
+}
+
+func (r *entryRecorder) WriteEntry(entry logging.LogEntry, line string) error {
+	r.entries = append(r.entries, entry)
+	return nil
+}
+
+func TestSortedWriter_Entries(t *testing.T) {
+	base := time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC)
+	var rec entryRecorder
+	w := NewSortedWriter(&rec)
+	_ = w.WriteEntry(logging.LogEntry{Timestamp: base.Add(time.Second), Level: logging.ERROR}, "second")
+	_ = w.WriteEntry(logging.LogEntry{Timestamp: base, Level: logging.INFO}, "first")
+	_, _ = w.Write([]byte("no entry"))
+	if err := w.Flush(); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+
+	if len(rec.entries) != 2 || rec.entries[0].Level != logging.INFO || rec.entries[1].Level != logging.ERROR {
+		t.Errorf("Flush() wrote entries %v, want the INFO then the ERROR entry", rec.entries)
+	}
+	if rec.String() != "no entry\n" {
+		t.Errorf("Flush() wrote %q, want the line without an entry as text", rec.String())
+	}
+}
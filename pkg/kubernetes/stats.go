@@ -0,0 +1,196 @@
+package kubernetes
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// StreamState is the connection state of a container log stream
+type StreamState int
+
+const (
+	// StreamConnecting is the state of a stream being opened
+	StreamConnecting StreamState = iota
+	// StreamConnected is the state of an open stream
+	StreamConnected
+	// StreamReconnecting is the state of a stream that ended and is opened again
+	StreamReconnecting
+	// StreamEnded is the state of a stream that ended without an error
+	StreamEnded
+	// StreamFailed is the state of a stream that could not be opened or broke
+	StreamFailed
+)
+
+// String returns the name of the state, e.g. connected
+func (s StreamState) String() string {
+	switch s {
+	case StreamConnecting:
+		return "connecting"
+	case StreamConnected:
+		return "connected"
+	case StreamReconnecting:
+		return "reconnecting"
+	case StreamEnded:
+		return "ended"
+	case StreamFailed:
+		return "failed"
+	default:
+		return "unknown"
+	}
+}
+
+// StreamStats is a snapshot of the health of a container log stream
+type StreamStats struct {
+	Target Target
+	State  StreamState
+	// Lines is the number of lines read, including those hidden by filters
+	Lines uint64
+	// LastLine is when the last line was read, zero before the first one
+	LastLine time.Time
+	// LastError is the error the stream last failed with, if any
+	LastError error
+	// Lag is how long after it was logged the last line was read: measured
+	// from the container runtime's timestamp when the stream requests them,
+	// otherwise from the timestamp parsed from the line
+	Lag time.Duration
+}
+
+// StreamMonitor tracks the health of log streams. Fetchers sharing a monitor
+// report all their streams to it. It is safe for concurrent use.
+type StreamMonitor struct {
+	// OnChange is called with the new stats whenever the state of a stream
+	// changes (optional). It is called synchronously, so it should be quick.
+	OnChange func(StreamStats)
+
+	mu      sync.Mutex
+	streams map[Target]*streamStatus
+}
+
+// NewStreamMonitor creates an empty StreamMonitor
+func NewStreamMonitor() *StreamMonitor {
+	return &StreamMonitor{streams: make(map[Target]*streamStatus)}
+}
+
+// Stats returns the stats of every stream, ordered by target
+func (m *StreamMonitor) Stats() []StreamStats {
+	m.mu.Lock()
+	stats := make([]StreamStats, 0, len(m.streams))
+	for _, status := range m.streams {
+		stats = append(stats, status.snapshot())
+	}
+	m.mu.Unlock()
+
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].Target.String() < stats[j].Target.String()
+	})
+	return stats
+}
+
+// Stream returns the stats of a single stream
+func (m *StreamMonitor) Stream(target Target) (StreamStats, bool) {
+	m.mu.Lock()
+	status, ok := m.streams[target]
+	m.mu.Unlock()
+	if !ok {
+		return StreamStats{Target: target}, false
+	}
+	return status.snapshot(), true
+}
+
+// status returns the status of a stream, registering it on first use. A nil
+// monitor returns a status that isn't tracked.
+func (m *StreamMonitor) status(target Target) *streamStatus {
+	if m == nil {
+		return &streamStatus{target: target}
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	status, ok := m.streams[target]
+	if !ok {
+		status = &streamStatus{monitor: m, target: target}
+		m.streams[target] = status
+	}
+	return status
+}
+
+// streamStatus holds the stats of a stream. Lines are counted atomically, so
+// reading them costs no lock.
+type streamStatus struct {
+	monitor *StreamMonitor
+	target  Target
+
+	lines    atomic.Uint64
+	lastLine atomic.Int64
+	lag      atomic.Int64
+
+	mu      sync.Mutex
+	state   StreamState
+	lastErr error
+}
+
+// setState changes the state of the stream, keeping err as the last error if
+// not nil, and calls the OnChange callback
+func (s *streamStatus) setState(state StreamState, err error) {
+	s.mu.Lock()
+	s.state = state
+	if err != nil {
+		s.lastErr = err
+	}
+	s.mu.Unlock()
+	if s.monitor != nil && s.monitor.OnChange != nil {
+		s.monitor.OnChange(s.snapshot())
+	}
+}
+
+// begin marks the stream as being opened, or opened again after it ended
+func (s *streamStatus) begin() {
+	s.mu.Lock()
+	state := StreamConnecting
+	if s.state != StreamConnecting {
+		state = StreamReconnecting
+	}
+	s.mu.Unlock()
+	s.setState(state, nil)
+}
+
+// end marks the stream as ended, or failed with a non-nil err that isn't
+// the cancellation of ctx
+func (s *streamStatus) end(ctx context.Context, err error) {
+	if err != nil && ctx.Err() == nil {
+		s.setState(StreamFailed, err)
+		return
+	}
+	s.setState(StreamEnded, nil)
+}
+
+// linesRead counts n lines read at now
+func (s *streamStatus) linesRead(n uint64, now time.Time) {
+	s.lines.Add(n)
+	s.lastLine.Store(now.UnixNano())
+}
+
+// setLag records how long after it was logged at the given time a line was
+// read at now. Clock differences can't make the lag negative.
+func (s *streamStatus) setLag(logged, now time.Time) {
+	lag := now.Sub(logged)
+	if lag < 0 {
+		lag = 0
+	}
+	s.lag.Store(int64(lag))
+}
+
+// snapshot returns the current stats of the stream
+func (s *streamStatus) snapshot() StreamStats {
+	s.mu.Lock()
+	stats := StreamStats{Target: s.target, State: s.state, LastError: s.lastErr}
+	s.mu.Unlock()
+	stats.Lines = s.lines.Load()
+	if last := s.lastLine.Load(); last != 0 {
+		stats.LastLine = time.Unix(0, last)
+	}
+	stats.Lag = time.Duration(s.lag.Load())
+	return stats
+}
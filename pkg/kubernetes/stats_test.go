@@ -0,0 +1,103 @@
+package kubernetes
+
+import (
+	"context"
+	"errors"
+	"io"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestLogFetcher_Stats(t *testing.T) {
+	clientset := fake.NewSimpleClientset(newTestPod("api-1", nil, "app"))
+
+	var mu sync.Mutex
+	var states []StreamState
+	monitor := NewStreamMonitor()
+	monitor.OnChange = func(stats StreamStats) {
+		mu.Lock()
+		defer mu.Unlock()
+		states = append(states, stats.State)
+	}
+
+	for _, raw := range []bool{false, true} {
+		opts := []Option{WithContainer("app"), WithWriter(io.Discard), WithMonitor(monitor)}
+		if raw {
+			opts = append(opts, WithRaw())
+		}
+		fetcher := NewLogFetcher(clientset, "default", "api-1", opts...)
+		before := time.Now()
+		if err := fetcher.GetLogs(context.Background()); err != nil {
+			t.Fatalf("GetLogs() error = %v", err)
+		}
+
+		stats := fetcher.Stats()
+		if stats.State != StreamEnded || stats.LastError != nil {
+			t.Errorf("raw %v: State = %s, LastError = %v, want ended without an error", raw, stats.State, stats.LastError)
+		}
+		if stats.LastLine.Before(before) {
+			t.Errorf("raw %v: LastLine = %v, want after %v", raw, stats.LastLine, before)
+		}
+	}
+
+	stats, ok := monitor.Stream(Target{Namespace: "default", Pod: "api-1", Container: "app"})
+	if !ok || stats.Lines != 2 {
+		t.Errorf("Stream() = %+v, %v, want the lines of both streams", stats, ok)
+	}
+	want := []StreamState{StreamConnecting, StreamConnected, StreamEnded, StreamReconnecting, StreamConnected, StreamEnded}
+	if !reflect.DeepEqual(states, want) {
+		t.Errorf("OnChange states = %v, want %v", states, want)
+	}
+}
+
+func TestLogFetcher_StatsFailed(t *testing.T) {
+	fetcher := NewLogFetcher(fake.NewSimpleClientset(), "default", "api-1", WithContainer("app"))
+	err := fetcher.GetLogs(context.Background())
+	if !errors.Is(err, ErrPodNotFound) {
+		t.Fatalf("GetLogs() error = %v, want %v", err, ErrPodNotFound)
+	}
+	stats := fetcher.Stats()
+	if stats.State != StreamFailed || !errors.Is(stats.LastError, ErrPodNotFound) {
+		t.Errorf("Stats() = %+v, want failed with %v", stats, ErrPodNotFound)
+	}
+}
+
+func TestMultiLogFetcher_Stats(t *testing.T) {
+	clientset := fake.NewSimpleClientset(newTestPod("api-1", nil, "app", "sidecar"))
+	targets := []Target{
+		{Namespace: "default", Pod: "api-1", Container: "sidecar"},
+		{Namespace: "default", Pod: "api-1", Container: "app"},
+	}
+	multi := NewMultiLogFetcher(clientset, targets, WithWriter(io.Discard))
+	if err := multi.GetLogs(context.Background()); err != nil {
+		t.Fatalf("GetLogs() error = %v", err)
+	}
+
+	stats := multi.Stats()
+	if len(stats) != 2 || stats[0].Target != targets[1] || stats[1].Target != targets[0] {
+		t.Fatalf("Stats() = %+v, want both targets ordered", stats)
+	}
+	for _, s := range stats {
+		if s.State != StreamEnded || s.Lines != 1 {
+			t.Errorf("Stats() for %s = %+v, want one line and ended", s.Target, s)
+		}
+	}
+}
+
+func TestStreamStatus_Lag(t *testing.T) {
+	s := NewStreamMonitor().status(Target{Pod: "api-1"})
+	now := time.Now()
+	s.setLag(now.Add(-3*time.Second), now)
+	if got := s.snapshot().Lag; got != 3*time.Second {
+		t.Errorf("Lag = %s, want 3s", got)
+	}
+	// A clock ahead of ours doesn't make the lag negative
+	s.setLag(now.Add(time.Second), now)
+	if got := s.snapshot().Lag; got != 0 {
+		t.Errorf("Lag = %s, want 0", got)
+	}
+}
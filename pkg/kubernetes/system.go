@@ -0,0 +1,84 @@
+package kubernetes
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// SystemNamespace is the namespace of the cluster components
+const SystemNamespace = "kube-system"
+
+// SystemComponent describes where the pods of a cluster component run and
+// how their logs are written
+type SystemComponent struct {
+	// Description is shown when listing the components
+	Description string
+	// Selector matches the pods of the component in SystemNamespace
+	Selector string
+	// Container is the container of the component's pods, when they have several
+	Container string
+	// Parser names the built-in parser of the component's log format, if
+	// it isn't detected automatically
+	Parser string
+}
+
+// SystemComponents are the cluster components known by name. They use the
+// labels of kubeadm and of the standard add-ons; control plane components
+// can't be found on managed clusters that don't run them as pods.
+var SystemComponents = map[string]SystemComponent{
+	"apiserver": {
+		Description: "the Kubernetes API server",
+		Selector:    "component=kube-apiserver",
+		Parser:      "klog",
+	},
+	"controller-manager": {
+		Description: "the Kubernetes controller manager",
+		Selector:    "component=kube-controller-manager",
+		Parser:      "klog",
+	},
+	"scheduler": {
+		Description: "the Kubernetes scheduler",
+		Selector:    "component=kube-scheduler",
+		Parser:      "klog",
+	},
+	"etcd": {
+		Description: "the etcd cluster store",
+		Selector:    "component=etcd",
+	},
+	"kube-proxy": {
+		Description: "the node network proxy",
+		Selector:    "k8s-app=kube-proxy",
+		Parser:      "klog",
+	},
+	"coredns": {
+		Description: "the cluster DNS server",
+		Selector:    "k8s-app=kube-dns",
+		Container:   "coredns",
+	},
+	"metrics-server": {
+		Description: "the resource metrics server",
+		Selector:    "k8s-app=metrics-server",
+		Container:   "metrics-server",
+		Parser:      "klog",
+	},
+}
+
+// SystemComponentNames returns the names of the known components in sorted order
+func SystemComponentNames() []string {
+	names := make([]string, 0, len(SystemComponents))
+	for name := range SystemComponents {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LookupSystemComponent returns the known component of the given name
+func LookupSystemComponent(name string) (SystemComponent, error) {
+	component, ok := SystemComponents[name]
+	if !ok {
+		return SystemComponent{}, fmt.Errorf("unknown system component %q (expected %s)", name, strings.Join(SystemComponentNames(), ", "))
+	}
+	return component, nil
+}
@@ -0,0 +1,28 @@
+package kubernetes
+
+import (
+	"testing"
+
+	"github.com/dantech2000/kubelog/pkg/logging"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+func TestSystemComponents(t *testing.T) {
+	for name, component := range SystemComponents {
+		if _, err := labels.Parse(component.Selector); err != nil {
+			t.Errorf("component %s has an invalid selector %q: %v", name, component.Selector, err)
+		}
+		if component.Parser != "" {
+			if _, err := logging.LookupParser(component.Parser); err != nil {
+				t.Errorf("component %s uses an unknown parser: %v", name, err)
+			}
+		}
+	}
+
+	if _, err := LookupSystemComponent("coredns"); err != nil {
+		t.Errorf("LookupSystemComponent(coredns) error = %v", err)
+	}
+	if _, err := LookupSystemComponent("kubelet"); err == nil {
+		t.Error("LookupSystemComponent(kubelet) returned no error")
+	}
+}
@@ -0,0 +1,223 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Target identifies a single container log stream
+type Target struct {
+	// Namespace is the Kubernetes namespace of the pod
+	Namespace string
+	// Pod is the name of the pod
+	Pod string
+	// Container is the name of the container within the pod
+	Container string
+}
+
+// String returns the target as namespace/pod/container
+func (t Target) String() string {
+	return fmt.Sprintf("%s/%s/%s", t.Namespace, t.Pod, t.Container)
+}
+
+// DefaultSidecarContainers are service mesh sidecars whose logs are mostly noise
+// when streaming an application's pods
+var DefaultSidecarContainers = []string{"istio-proxy"}
+
+// ResolveTargets lists the pods matching the label selector and returns a target
+// for every container, or only for the named container when one is given
+func ResolveTargets(ctx context.Context, clientset kubernetes.Interface, namespace, selector, container string) ([]Target, error) {
+	pods, err := clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, fmt.Errorf("error listing pods for selector '%s': %w", selector, wrapAPIError(err, namespace, ""))
+	}
+	if len(pods.Items) == 0 {
+		return nil, fmt.Errorf("%w: no pods match selector '%s' in namespace '%s'", ErrPodNotFound, selector, namespace)
+	}
+
+	targets := PodTargets(pods.Items, container)
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("%w: '%s' in pods matching selector '%s'", ErrContainerNotFound, container, selector)
+	}
+	return targets, nil
+}
+
+// ResolveAllTargets is like ResolveTargets for the pods of all namespaces.
+// Users who may not list pods cluster-wide get those of the fallback
+// namespaces instead, e.g. from the config file, or without any, of the
+// namespaces they may list, or else of the current one. These are searched
+// one by one, leaving out those whose pods are forbidden too, and returned;
+// they are nil when the pods of all namespaces were listed.
+func ResolveAllTargets(ctx context.Context, clientset kubernetes.Interface, selector, container string, fallback []string, current string) ([]Target, []string, error) {
+	var items []corev1.Pod
+	var searched []string
+	pods, err := clientset.CoreV1().Pods("").List(ctx, metav1.ListOptions{LabelSelector: selector})
+	switch {
+	case apierrors.IsForbidden(err):
+		namespaces := fallback
+		if len(namespaces) == 0 {
+			listed, err := namespaceNames(ctx, clientset, current)
+			if err != nil {
+				return nil, nil, err
+			}
+			for _, ns := range listed {
+				namespaces = append(namespaces, ns.Name)
+			}
+		}
+		items, searched, err = podsInNamespaces(ctx, clientset, namespaces, selector)
+		if err != nil {
+			return nil, nil, err
+		}
+	case err != nil:
+		return nil, nil, fmt.Errorf("error listing pods for selector '%s' in all namespaces: %w", selector, wrapAPIError(err, "", ""))
+	default:
+		items = pods.Items
+	}
+
+	if len(items) == 0 {
+		where := "any namespace"
+		if searched != nil {
+			where = "namespaces " + strings.Join(searched, ", ")
+		}
+		return nil, nil, fmt.Errorf("%w: no pods match selector '%s' in %s", ErrPodNotFound, selector, where)
+	}
+	targets := PodTargets(items, container)
+	if len(targets) == 0 {
+		return nil, nil, fmt.Errorf("%w: '%s' in pods matching selector '%s'", ErrContainerNotFound, container, selector)
+	}
+	return targets, searched, nil
+}
+
+// podsInNamespaces lists the pods matching the label selector namespace by
+// namespace, skipping the namespaces whose pods are forbidden, and returns
+// them with the namespaces searched
+func podsInNamespaces(ctx context.Context, clientset kubernetes.Interface, namespaces []string, selector string) ([]corev1.Pod, []string, error) {
+	var items []corev1.Pod
+	var searched []string
+	for _, namespace := range namespaces {
+		pods, err := clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: selector})
+		if apierrors.IsForbidden(err) {
+			continue
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("error listing pods for selector '%s': %w", selector, wrapAPIError(err, namespace, ""))
+		}
+		items = append(items, pods.Items...)
+		searched = append(searched, namespace)
+	}
+	if len(searched) == 0 {
+		return nil, nil, fmt.Errorf("%w: pods may not be listed in all namespaces, nor in any of %s", ErrForbidden, strings.Join(namespaces, ", "))
+	}
+	return items, searched, nil
+}
+
+// PodTargets returns a target for every container of the given pods,
+// or only for the named container when one is given
+func PodTargets(pods []corev1.Pod, container string) []Target {
+	var targets []Target
+	for _, pod := range pods {
+		for _, c := range pod.Spec.Containers {
+			if container != "" && c.Name != container {
+				continue
+			}
+			targets = append(targets, Target{Namespace: pod.Namespace, Pod: pod.Name, Container: c.Name})
+		}
+	}
+	return targets
+}
+
+// LabelGroup is the set of container streams of the pods sharing a label value
+type LabelGroup struct {
+	// Value is the value of the label
+	Value string
+	// Targets are the containers of the pods with that value
+	Targets []Target
+}
+
+// GroupTargetsByLabel lists the pods matching the label selector and groups
+// their containers, or only the named container when one is given, by the
+// value of the label. Groups are ordered by value; pods without the label
+// are left out.
+func GroupTargetsByLabel(ctx context.Context, clientset kubernetes.Interface, namespace, selector, container, label string) ([]LabelGroup, error) {
+	pods, err := clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, fmt.Errorf("error listing pods for selector '%s': %w", selector, wrapAPIError(err, namespace, ""))
+	}
+
+	byValue := make(map[string][]corev1.Pod)
+	for _, pod := range pods.Items {
+		if value, ok := pod.Labels[label]; ok {
+			byValue[value] = append(byValue[value], pod)
+		}
+	}
+	if len(byValue) == 0 {
+		return nil, fmt.Errorf("%w: no pods matching selector '%s' in namespace '%s' have the label '%s'", ErrPodNotFound, selector, namespace, label)
+	}
+
+	groups := make([]LabelGroup, 0, len(byValue))
+	for value, pods := range byValue {
+		targets := PodTargets(pods, container)
+		if len(targets) == 0 {
+			continue
+		}
+		groups = append(groups, LabelGroup{Value: value, Targets: targets})
+	}
+	if len(groups) == 0 {
+		return nil, fmt.Errorf("%w: '%s' in pods matching selector '%s'", ErrContainerNotFound, container, selector)
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].Value < groups[j].Value })
+	return groups, nil
+}
+
+// DefaultMaxStreams is how many container streams are opened at once unless
+// a higher limit is asked for
+const DefaultMaxStreams = 25
+
+// maxListedTargets is how many of the targets beyond the limit the error of
+// CheckMaxStreams names
+const maxListedTargets = 20
+
+// CheckMaxStreams returns an error wrapping ErrTooManyStreams when there are
+// more than max targets, listing the ones beyond the limit
+func CheckMaxStreams(targets []Target, max int) error {
+	if len(targets) <= max {
+		return nil
+	}
+	skipped := targets[max:]
+	names := make([]string, 0, maxListedTargets+1)
+	for i, target := range skipped {
+		if i == maxListedTargets {
+			names = append(names, fmt.Sprintf("and %d more", len(skipped)-i))
+			break
+		}
+		names = append(names, target.String())
+	}
+	return fmt.Errorf("%w: %d container streams requested, but at most %d are opened at once; these would be skipped:\n  %s",
+		ErrTooManyStreams, len(targets), max, strings.Join(names, "\n  "))
+}
+
+// ExcludeContainers returns the targets whose container is not one of the given names
+func ExcludeContainers(targets []Target, names ...string) []Target {
+	if len(names) == 0 {
+		return targets
+	}
+	excluded := make(map[string]bool, len(names))
+	for _, name := range names {
+		excluded[name] = true
+	}
+
+	var kept []Target
+	for _, target := range targets {
+		if !excluded[target.Container] {
+			kept = append(kept, target)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,82 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// timeoutTransport bounds API requests by a timeout. Followed log streams and
+// watches, which last as long as they are wanted, are left alone, and other
+// log requests are only bounded until their headers arrive, so that reading
+// a long history isn't cut short. Every other request is bounded until its
+// body has been read, like with http.Client.Timeout.
+type timeoutTransport struct {
+	next    http.RoundTripper
+	timeout time.Duration
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *timeoutTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if query := req.URL.Query(); query.Get("follow") == "true" || query.Get("watch") == "true" {
+		return t.next.RoundTrip(req)
+	}
+
+	ctx, cancel := context.WithCancel(req.Context())
+	var timedOut atomic.Bool
+	timer := time.AfterFunc(t.timeout, func() {
+		timedOut.Store(true)
+		cancel()
+	})
+
+	resp, err := t.next.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		timer.Stop()
+		cancel()
+		if timedOut.Load() {
+			return nil, t.timeoutError(err)
+		}
+		return nil, err
+	}
+	if strings.HasSuffix(req.URL.Path, "/log") {
+		timer.Stop()
+	}
+	resp.Body = &timeoutBody{ReadCloser: resp.Body, transport: t, timer: timer, cancel: cancel, timedOut: &timedOut}
+	return resp, nil
+}
+
+// timeoutError names the timeout in the error of a request that ran past it
+func (t *timeoutTransport) timeoutError(err error) error {
+	return fmt.Errorf("request to the API server timed out after %s: %w", t.timeout, err)
+}
+
+// timeoutBody reports reads cut short by the timeout of its request, and
+// releases the timeout when closed
+type timeoutBody struct {
+	io.ReadCloser
+	transport *timeoutTransport
+	timer     *time.Timer
+	cancel    context.CancelFunc
+	timedOut  *atomic.Bool
+}
+
+// Read implements io.Reader
+func (b *timeoutBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err != nil && err != io.EOF && b.timedOut.Load() {
+		err = b.transport.timeoutError(err)
+	}
+	return n, err
+}
+
+// Close implements io.Closer
+func (b *timeoutBody) Close() error {
+	b.timer.Stop()
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
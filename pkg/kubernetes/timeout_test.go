@@ -0,0 +1,74 @@
+package kubernetes
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTimeoutTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		wait := func() {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(200 * time.Millisecond):
+			}
+		}
+		switch r.URL.Query().Get("slow") {
+		case "headers":
+			wait()
+		case "body":
+			_, _ = io.WriteString(w, "o")
+			w.(http.Flusher).Flush()
+			wait()
+		}
+		_, _ = io.WriteString(w, "ok")
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: &timeoutTransport{next: http.DefaultTransport, timeout: 50 * time.Millisecond}}
+	tests := []struct {
+		name    string
+		path    string
+		query   string
+		want    string
+		wantErr bool
+	}{
+		{name: "Quick request", path: "/api/v1/namespaces/default/pods", want: "ok"},
+		{name: "Hung request", path: "/api/v1/namespaces/default/pods", query: "slow=headers", wantErr: true},
+		{name: "Slow body", path: "/api/v1/namespaces/default/pods", query: "slow=body", wantErr: true},
+		{name: "Hung log request", path: "/api/v1/namespaces/default/pods/api/log", query: "slow=headers", wantErr: true},
+		{name: "Slow log body", path: "/api/v1/namespaces/default/pods/api/log", query: "slow=body", want: "ook"},
+		{name: "Followed log stream", path: "/api/v1/namespaces/default/pods/api/log", query: "slow=headers&follow=true", want: "ok"},
+		{name: "Watch", path: "/api/v1/namespaces/default/pods", query: "slow=headers&watch=true", want: "ok"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, err := func() (string, error) {
+				resp, err := client.Get(server.URL + tt.path + "?" + tt.query)
+				if err != nil {
+					return "", err
+				}
+				defer resp.Body.Close()
+				body, err := io.ReadAll(resp.Body)
+				return string(body), err
+			}()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Get() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if !strings.Contains(err.Error(), "timed out after 50ms") {
+					t.Errorf("Get() error = %v, want the timeout named", err)
+				}
+				return
+			}
+			if body != tt.want {
+				t.Errorf("body = %q, want %q", body, tt.want)
+			}
+		})
+	}
+}
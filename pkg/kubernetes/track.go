@@ -0,0 +1,87 @@
+package kubernetes
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/dantech2000/kubelog/pkg/glyph"
+	"github.com/dantech2000/kubelog/pkg/logging"
+	"github.com/fatih/color"
+)
+
+var changeMarkerColor = color.New(color.FgYellow, color.Bold)
+
+// TrackWriter watches fields of the entries written to it and writes a marker
+// line before an entry whose value of one of them differs from the previous
+// entry of the same container, e.g. "~ status 200 → 503 (api-1)", so status
+// flapping or leader changes stand out. Entries without a field keep the last
+// value seen. It is safe for concurrent use.
+type TrackWriter struct {
+	mu     sync.Mutex
+	w      io.Writer
+	fields []string
+	last   map[string]map[string]string
+}
+
+// NewTrackWriter creates a TrackWriter marking changes of fields, given by
+// name or dotted path, and writing to w
+func NewTrackWriter(w io.Writer, fields []string) *TrackWriter {
+	return &TrackWriter{w: w, fields: fields, last: make(map[string]map[string]string)}
+}
+
+// WriteEntry implements the EntryWriter interface
+func (t *TrackWriter) WriteEntry(entry logging.LogEntry, line string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if changes := t.changes(entry); len(changes) > 0 {
+		marker := "~ " + strings.Join(changes, ", ")
+		if s := entry.Source; s != nil {
+			marker += " (" + s.Pod + ")"
+		}
+		if _, err := fmt.Fprintln(t.w, changeMarkerColor.Sprint(marker)); err != nil {
+			return err
+		}
+	}
+	if ew, ok := t.w.(EntryWriter); ok {
+		return ew.WriteEntry(entry, line)
+	}
+	_, err := fmt.Fprintln(t.w, line)
+	return err
+}
+
+// Write implements io.Writer interface for lines without an entry
+func (t *TrackWriter) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.w.Write(p)
+}
+
+// changes records the values of the tracked fields of an entry and describes
+// those that changed, e.g. "status 200 → 503"; the caller holds t.mu
+func (t *TrackWriter) changes(entry logging.LogEntry) []string {
+	key := ""
+	if s := entry.Source; s != nil {
+		key = s.Namespace + "/" + s.Pod + "/" + s.Container
+	}
+	last, ok := t.last[key]
+	if !ok {
+		last = make(map[string]string, len(t.fields))
+		t.last[key] = last
+	}
+
+	var changes []string
+	for _, field := range t.fields {
+		v, ok := logging.FieldValue(entry.Fields, field)
+		if !ok {
+			continue
+		}
+		value := fmt.Sprint(v)
+		if previous, seen := last[field]; seen && previous != value {
+			changes = append(changes, fmt.Sprintf("%s %s %s %s", field, previous, glyph.Arrow, value))
+		}
+		last[field] = value
+	}
+	return changes
+}
@@ -0,0 +1,65 @@
+package kubernetes
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/dantech2000/kubelog/pkg/logging"
+	"github.com/fatih/color"
+)
+
+func TestTrackWriter(t *testing.T) {
+	defer func(noColor bool) { color.NoColor = noColor }(color.NoColor)
+	color.NoColor = true
+
+	api1 := &logging.Source{Namespace: "default", Pod: "api-1", Container: "app"}
+	api2 := &logging.Source{Namespace: "default", Pod: "api-2", Container: "app"}
+	entry := func(source *logging.Source, fields map[string]interface{}) logging.LogEntry {
+		return logging.LogEntry{Source: source, Fields: fields}
+	}
+
+	var buf bytes.Buffer
+	w := NewTrackWriter(&buf, []string{"status", "raft.leader"})
+	_ = w.WriteEntry(entry(api1, map[string]interface{}{"status": float64(200)}), "first")
+	_ = w.WriteEntry(entry(api2, map[string]interface{}{"status": float64(503)}), "other pod")
+	_ = w.WriteEntry(entry(api1, map[string]interface{}{"status": float64(200)}), "same")
+	_ = w.WriteEntry(entry(api1, nil), "no status")
+	_ = w.WriteEntry(entry(api1, map[string]interface{}{"status": float64(503)}), "flapped")
+	_ = w.WriteEntry(entry(api1, map[string]interface{}{
+		"status": float64(200),
+		"raft":   map[string]interface{}{"leader": "node-a"},
+	}), "recovered")
+	_ = w.WriteEntry(entry(api1, map[string]interface{}{
+		"status": float64(200),
+		"raft":   map[string]interface{}{"leader": "node-b"},
+	}), "new leader")
+	_ = w.WriteEntry(entry(nil, map[string]interface{}{"status": "ok"}), "single pod")
+	_ = w.WriteEntry(entry(nil, map[string]interface{}{"status": "degraded"}), "single pod degraded")
+
+	want := "first\n" +
+		"other pod\n" +
+		"same\n" +
+		"no status\n" +
+		"~ status 200 → 503 (api-1)\n" +
+		"flapped\n" +
+		"~ status 503 → 200 (api-1)\n" +
+		"recovered\n" +
+		"~ raft.leader node-a → node-b (api-1)\n" +
+		"new leader\n" +
+		"single pod\n" +
+		"~ status ok → degraded\n" +
+		"single pod degraded\n"
+	if got := buf.String(); got != want {
+		t.Errorf("TrackWriter wrote\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestTrackWriter_Entries(t *testing.T) {
+	var rec entryRecorder
+	w := NewTrackWriter(&rec, []string{"status"})
+	_ = w.WriteEntry(logging.LogEntry{Level: logging.WARN}, "passed on")
+
+	if len(rec.entries) != 1 || rec.entries[0].Level != logging.WARN {
+		t.Errorf("TrackWriter wrote entries %v, want the WARN entry", rec.entries)
+	}
+}
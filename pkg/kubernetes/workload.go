@@ -0,0 +1,238 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Revision annotations set on ReplicaSets by the deployment and Argo Rollouts controllers
+const (
+	revisionAnnotation        = "deployment.kubernetes.io/revision"
+	rolloutRevisionAnnotation = "rollout.argoproj.io/revision"
+)
+
+// rolloutHashLabel is the pod template hash label Argo Rollouts adds to its ReplicaSets
+const rolloutHashLabel = "rollouts-pod-template-hash"
+
+// knativeServiceLabel is set by Knative Serving on every pod of a service's revisions
+const knativeServiceLabel = "serving.knative.dev/service"
+
+// Workload identifies a resource that owns pods, such as deployment/api
+type Workload struct {
+	// Kind is the normalized resource kind, e.g. "deployment"
+	Kind string
+	// Name is the name of the resource
+	Name string
+}
+
+// String returns the workload as kind/name
+func (w Workload) String() string {
+	return w.Kind + "/" + w.Name
+}
+
+// workloadKinds maps accepted resource names and short names to their kind
+var workloadKinds = map[string]string{
+	"deployment":   "deployment",
+	"deployments":  "deployment",
+	"deploy":       "deployment",
+	"statefulset":  "statefulset",
+	"statefulsets": "statefulset",
+	"sts":          "statefulset",
+	"daemonset":    "daemonset",
+	"daemonsets":   "daemonset",
+	"ds":           "daemonset",
+	"replicaset":   "replicaset",
+	"replicasets":  "replicaset",
+	"rs":           "replicaset",
+	"job":          "job",
+	"jobs":         "job",
+	"rollout":      "rollout",
+	"rollouts":     "rollout",
+	"ro":           "rollout",
+	"ksvc":         "ksvc",
+	"kservice":     "ksvc",
+}
+
+// ParseWorkload parses a kind/name reference. It reports false when ref is not
+// of that form, e.g. a plain pod name.
+func ParseWorkload(ref string) (Workload, bool, error) {
+	kind, name, found := strings.Cut(ref, "/")
+	if !found {
+		return Workload{}, false, nil
+	}
+	normalized, ok := workloadKinds[strings.ToLower(kind)]
+	if !ok || name == "" {
+		return Workload{}, true, fmt.Errorf("unsupported resource %q (expected deployment, statefulset, daemonset, replicaset, job, rollout or ksvc)", ref)
+	}
+	return Workload{Kind: normalized, Name: name}, true, nil
+}
+
+// SidecarContainers returns the names of infrastructure containers injected
+// into the workload's pods, which are usually not interesting to stream
+func (w Workload) SidecarContainers() []string {
+	if w.Kind == "ksvc" {
+		return []string{"queue-proxy"}
+	}
+	return nil
+}
+
+// Selector returns the label selector matching the pods of the workload
+func (w Workload) Selector(ctx context.Context, clientset kubernetes.Interface, namespace string) (string, error) {
+	var selector *metav1.LabelSelector
+	var err error
+
+	switch w.Kind {
+	case "deployment":
+		var d *appsv1.Deployment
+		if d, err = clientset.AppsV1().Deployments(namespace).Get(ctx, w.Name, metav1.GetOptions{}); err == nil {
+			selector = d.Spec.Selector
+		}
+	case "statefulset":
+		var s *appsv1.StatefulSet
+		if s, err = clientset.AppsV1().StatefulSets(namespace).Get(ctx, w.Name, metav1.GetOptions{}); err == nil {
+			selector = s.Spec.Selector
+		}
+	case "daemonset":
+		var d *appsv1.DaemonSet
+		if d, err = clientset.AppsV1().DaemonSets(namespace).Get(ctx, w.Name, metav1.GetOptions{}); err == nil {
+			selector = d.Spec.Selector
+		}
+	case "replicaset":
+		var r *appsv1.ReplicaSet
+		if r, err = clientset.AppsV1().ReplicaSets(namespace).Get(ctx, w.Name, metav1.GetOptions{}); err == nil {
+			selector = r.Spec.Selector
+		}
+	case "job":
+		var j *batchv1.Job
+		if j, err = clientset.BatchV1().Jobs(namespace).Get(ctx, w.Name, metav1.GetOptions{}); err == nil {
+			selector = j.Spec.Selector
+		}
+	case "rollout":
+		return rolloutSelector(ctx, clientset, namespace, w.Name)
+	case "ksvc":
+		// Every revision pod carries the service label; revisions scaled to
+		// zero have no pods, so this matches the serving revisions
+		return knativeServiceLabel + "=" + w.Name, nil
+	default:
+		return "", fmt.Errorf("unsupported resource kind %q", w.Kind)
+	}
+	if err != nil {
+		return "", fmt.Errorf("error fetching %s: %w", w, wrapWorkloadError(err, namespace, w))
+	}
+
+	return labelSelectorString(selector)
+}
+
+// RevisionSelector returns the label selector matching only the pods of the
+// deployment's ReplicaSet with the given revision number
+func RevisionSelector(ctx context.Context, clientset kubernetes.Interface, namespace, deployment string, revision int64) (string, error) {
+	rs, err := revisionReplicaSet(ctx, clientset, namespace, deployment, revision)
+	if err != nil {
+		return "", err
+	}
+	return labelSelectorString(rs.Spec.Selector)
+}
+
+// DeploymentReplicaSets returns the ReplicaSets owned by the deployment
+func DeploymentReplicaSets(ctx context.Context, clientset kubernetes.Interface, namespace, deployment string) ([]appsv1.ReplicaSet, error) {
+	d, err := clientset.AppsV1().Deployments(namespace).Get(ctx, deployment, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("error fetching deployment/%s: %w", deployment,
+			wrapWorkloadError(err, namespace, Workload{Kind: "deployment", Name: deployment}))
+	}
+	selector, err := labelSelectorString(d.Spec.Selector)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := clientset.AppsV1().ReplicaSets(namespace).List(ctx, metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, fmt.Errorf("error listing replicasets: %w", wrapAPIError(err, namespace, ""))
+	}
+
+	var owned []appsv1.ReplicaSet
+	for _, rs := range list.Items {
+		if ref := metav1.GetControllerOf(&rs); ref != nil && ref.UID == d.UID {
+			owned = append(owned, rs)
+		}
+	}
+	return owned, nil
+}
+
+// RolloutReplicaSets returns the ReplicaSets owned by an Argo Rollout
+func RolloutReplicaSets(ctx context.Context, clientset kubernetes.Interface, namespace, rollout string) ([]appsv1.ReplicaSet, error) {
+	list, err := clientset.AppsV1().ReplicaSets(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("error listing replicasets: %w", wrapAPIError(err, namespace, ""))
+	}
+
+	var owned []appsv1.ReplicaSet
+	for _, rs := range list.Items {
+		if ref := metav1.GetControllerOf(&rs); ref != nil && ref.Kind == "Rollout" && ref.Name == rollout {
+			owned = append(owned, rs)
+		}
+	}
+	if len(owned) == 0 {
+		return nil, fmt.Errorf("%w: rollout/%s in namespace '%s'", ErrWorkloadNotFound, rollout, namespace)
+	}
+	return owned, nil
+}
+
+// rolloutSelector derives the pod selector of an Argo Rollout from its ReplicaSets,
+// dropping the per-revision hash so pods of every revision match
+func rolloutSelector(ctx context.Context, clientset kubernetes.Interface, namespace, rollout string) (string, error) {
+	replicaSets, err := RolloutReplicaSets(ctx, clientset, namespace, rollout)
+	if err != nil {
+		return "", err
+	}
+
+	selector := replicaSets[0].Spec.Selector.DeepCopy()
+	delete(selector.MatchLabels, rolloutHashLabel)
+	return labelSelectorString(selector)
+}
+
+// ReplicaSetRevision returns the deployment or rollout revision recorded on a ReplicaSet
+func ReplicaSetRevision(rs *appsv1.ReplicaSet) int64 {
+	value, ok := rs.Annotations[revisionAnnotation]
+	if !ok {
+		value = rs.Annotations[rolloutRevisionAnnotation]
+	}
+	revision, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0
+	}
+	return revision
+}
+
+// revisionReplicaSet finds the deployment's ReplicaSet with the given revision
+func revisionReplicaSet(ctx context.Context, clientset kubernetes.Interface, namespace, deployment string, revision int64) (*appsv1.ReplicaSet, error) {
+	replicaSets, err := DeploymentReplicaSets(ctx, clientset, namespace, deployment)
+	if err != nil {
+		return nil, err
+	}
+	for i := range replicaSets {
+		if ReplicaSetRevision(&replicaSets[i]) == revision {
+			return &replicaSets[i], nil
+		}
+	}
+	return nil, fmt.Errorf("%w: deployment/%s has no revision %d", ErrRevisionNotFound, deployment, revision)
+}
+
+// labelSelectorString converts an API label selector into its string form
+func labelSelectorString(selector *metav1.LabelSelector) (string, error) {
+	s, err := metav1.LabelSelectorAsSelector(selector)
+	if err != nil {
+		return "", fmt.Errorf("invalid label selector: %w", err)
+	}
+	if s.Empty() {
+		return "", fmt.Errorf("refusing to use an empty label selector")
+	}
+	return s.String(), nil
+}
@@ -0,0 +1,138 @@
+package kubernetes
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestParseWorkload(t *testing.T) {
+	tests := []struct {
+		name    string
+		ref     string
+		want    Workload
+		wantOK  bool
+		wantErr bool
+	}{
+		{"Plain pod name", "api-7d4b9c8f6-x2k9z", Workload{}, false, false},
+		{"Deployment", "deployment/api", Workload{Kind: "deployment", Name: "api"}, true, false},
+		{"Short kind", "sts/db", Workload{Kind: "statefulset", Name: "db"}, true, false},
+		{"Unknown kind", "cronjob/nightly", Workload{}, true, true},
+		{"Missing name", "deployment/", Workload{}, true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok, err := ParseWorkload(tt.ref)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseWorkload() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if ok != tt.wantOK || got != tt.want {
+				t.Errorf("ParseWorkload() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func newTestDeployment() *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "default", UID: "deploy-uid"},
+		Spec: appsv1.DeploymentSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "api"}},
+		},
+	}
+}
+
+func newTestReplicaSet(name, hash, revision string, replicas int32) *appsv1.ReplicaSet {
+	controller := true
+	return &appsv1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   "default",
+			Labels:      map[string]string{"app": "api", "pod-template-hash": hash},
+			Annotations: map[string]string{"deployment.kubernetes.io/revision": revision},
+			OwnerReferences: []metav1.OwnerReference{
+				{Kind: "Deployment", Name: "api", UID: "deploy-uid", Controller: &controller},
+			},
+		},
+		Spec: appsv1.ReplicaSetSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "api", "pod-template-hash": hash}},
+		},
+		Status: appsv1.ReplicaSetStatus{Replicas: replicas},
+	}
+}
+
+func TestRevisionSelector(t *testing.T) {
+	clientset := fake.NewSimpleClientset(newTestDeployment(),
+		newTestReplicaSet("api-aaa", "aaa", "1", 0),
+		newTestReplicaSet("api-bbb", "bbb", "2", 3),
+	)
+
+	tests := []struct {
+		name      string
+		revision  int64
+		want      string
+		wantErrIs error
+	}{
+		{"Current revision", 2, "app=api,pod-template-hash=bbb", nil},
+		{"Old revision", 1, "app=api,pod-template-hash=aaa", nil},
+		{"Unknown revision", 3, "", ErrRevisionNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RevisionSelector(context.Background(), clientset, "default", "api", tt.revision)
+			if tt.wantErrIs != nil {
+				if !errors.Is(err, tt.wantErrIs) {
+					t.Errorf("RevisionSelector() error = %v, want errors.Is %v", err, tt.wantErrIs)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("RevisionSelector() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("RevisionSelector() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompareRevisionGroups(t *testing.T) {
+	deployment := Workload{Kind: "deployment", Name: "api"}
+
+	t.Run("Two active revisions", func(t *testing.T) {
+		clientset := fake.NewSimpleClientset(newTestDeployment(),
+			newTestReplicaSet("api-aaa", "aaa", "1", 0),
+			newTestReplicaSet("api-bbb", "bbb", "2", 3),
+			newTestReplicaSet("api-ccc", "ccc", "3", 1),
+		)
+		groups, err := CompareRevisionGroups(context.Background(), clientset, "default", deployment)
+		if err != nil {
+			t.Fatalf("CompareRevisionGroups() error = %v", err)
+		}
+		if len(groups) != 2 {
+			t.Fatalf("CompareRevisionGroups() returned %d groups, want 2", len(groups))
+		}
+		if groups[0].Label != "stable" || groups[0].Revision != 2 {
+			t.Errorf("first group = %+v, want stable revision 2", groups[0])
+		}
+		if groups[1].Label != "canary" || groups[1].Revision != 3 {
+			t.Errorf("second group = %+v, want canary revision 3", groups[1])
+		}
+	})
+
+	t.Run("Single active revision", func(t *testing.T) {
+		clientset := fake.NewSimpleClientset(newTestDeployment(),
+			newTestReplicaSet("api-aaa", "aaa", "1", 0),
+			newTestReplicaSet("api-bbb", "bbb", "2", 3),
+		)
+		if _, err := CompareRevisionGroups(context.Background(), clientset, "default", deployment); err == nil {
+			t.Error("CompareRevisionGroups() expected error with a single active revision")
+		}
+	})
+}
@@ -0,0 +1,38 @@
+package logging
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// ActivityTracker records when the last entry was seen. It implements
+// Transform so it can be added to a pipeline, where it sees every entry before
+// filtering. It is safe for concurrent use.
+type ActivityTracker struct {
+	last atomic.Int64
+	now  func() time.Time
+}
+
+// NewActivityTracker creates an ActivityTracker whose idle time starts now
+func NewActivityTracker() *ActivityTracker {
+	t := &ActivityTracker{now: time.Now}
+	t.last.Store(t.now().UnixNano())
+	return t
+}
+
+// Apply implements the Transform interface; it never drops entries
+func (t *ActivityTracker) Apply(entry *LogEntry) bool {
+	t.last.Store(t.now().UnixNano())
+	return true
+}
+
+// LastSeen returns when the last entry was seen, or when the tracker was
+// created if none has been
+func (t *ActivityTracker) LastSeen() time.Time {
+	return time.Unix(0, t.last.Load())
+}
+
+// Idle returns how long ago the last entry was seen
+func (t *ActivityTracker) Idle() time.Duration {
+	return t.now().Sub(t.LastSeen())
+}
@@ -0,0 +1,29 @@
+package logging
+
+import (
+	"testing"
+	"time"
+)
+
+func TestActivityTracker(t *testing.T) {
+	now := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
+	tracker := NewActivityTracker()
+	tracker.now = func() time.Time { return now }
+	tracker.last.Store(now.UnixNano())
+
+	now = now.Add(2 * time.Minute)
+	if got := tracker.Idle(); got != 2*time.Minute {
+		t.Errorf("Idle() = %v, want 2m0s", got)
+	}
+
+	entry := ParseLogEntry(`{"level":"info","msg":"hello"}`)
+	if !tracker.Apply(&entry) {
+		t.Error("Apply() = false, want true")
+	}
+	if got := tracker.Idle(); got != 0 {
+		t.Errorf("Idle() after an entry = %v, want 0s", got)
+	}
+	if !tracker.LastSeen().Equal(now) {
+		t.Errorf("LastSeen() = %v, want %v", tracker.LastSeen(), now)
+	}
+}
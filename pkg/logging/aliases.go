@@ -0,0 +1,20 @@
+package logging
+
+// applyFieldAliases renames aliased fields to the names they stand for. A
+// field already present under that name is kept and the alias left as is.
+func applyFieldAliases(fields map[string]interface{}, aliases map[string]string) {
+	if fields == nil {
+		return
+	}
+	for from, to := range aliases {
+		value, ok := fields[from]
+		if !ok || from == to {
+			continue
+		}
+		if _, exists := fields[to]; exists {
+			continue
+		}
+		fields[to] = value
+		delete(fields, from)
+	}
+}
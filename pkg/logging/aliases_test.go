@@ -0,0 +1,69 @@
+package logging
+
+import (
+	"testing"
+)
+
+func TestPipeline_Alias(t *testing.T) {
+	p := NewPipeline()
+	p.Alias(map[string]string{
+		"lvl":   "level",
+		"@m":    "message",
+		"@t":    "time",
+		"reqId": "trace_id",
+	})
+
+	tests := []struct {
+		name        string
+		input       string
+		wantLevel   LogLevel
+		wantMessage string
+		wantFields  map[string]interface{}
+	}{
+		{
+			name:        "JSON with in-house field names",
+			input:       `{"@t":"2024-03-15T12:19:57Z","lvl":"error","@m":"payment failed","reqId":"abc123"}`,
+			wantLevel:   ERROR,
+			wantMessage: "payment failed",
+			wantFields:  map[string]interface{}{"trace_id": "abc123", "reqId": nil},
+		},
+		{
+			name:        "Standard field wins over its alias",
+			input:       `{"level":"warn","lvl":"debug","msg":"retrying"}`,
+			wantLevel:   WARN,
+			wantMessage: "retrying",
+			wantFields:  map[string]interface{}{"lvl": "debug"},
+		},
+		{
+			name:        "Embedded JSON in plain text",
+			input:       `charge declined {"reqId":"xyz"}`,
+			wantLevel:   DEBUG,
+			wantMessage: `charge declined {"reqId":"xyz"}`,
+			wantFields:  map[string]interface{}{"trace_id": "xyz"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _ := p.Process(tt.input)
+			if got.Level != tt.wantLevel {
+				t.Errorf("Level = %v, want %v", got.Level, tt.wantLevel)
+			}
+			if got.Message != tt.wantMessage {
+				t.Errorf("Message = %q, want %q", got.Message, tt.wantMessage)
+			}
+			for k, v := range tt.wantFields {
+				if got.Fields[k] != v {
+					t.Errorf("Fields[%q] = %v, want %v", k, got.Fields[k], v)
+				}
+			}
+		})
+	}
+
+	// Aliases added to a clone do not leak into the original
+	clone := p.Clone()
+	clone.Alias(map[string]string{"sev": "level"})
+	if got, _ := p.Process(`{"sev":"error","msg":"x"}`); got.Level == ERROR {
+		t.Error("alias added to a clone changed the original pipeline")
+	}
+}
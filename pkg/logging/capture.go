@@ -0,0 +1,116 @@
+package logging
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// HasNamedGroups reports whether a regular expression has named capture groups
+func HasNamedGroups(re *regexp.Regexp) bool {
+	for _, name := range re.SubexpNames() {
+		if name != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// CaptureFields returns a transform adding the named groups of re as fields
+// when it matches the raw line of an entry, e.g. (?P<order>ord-\d+) adds an
+// order field. Fields the entry already has are kept.
+func CaptureFields(re *regexp.Regexp) Transform {
+	return TransformFunc(func(entry *LogEntry) bool {
+		line := entry.RawLine
+		if line == "" {
+			line = entry.Message
+		}
+		match := re.FindStringSubmatch(line)
+		if match == nil {
+			return true
+		}
+		for i, name := range re.SubexpNames() {
+			if name == "" || match[i] == "" {
+				continue
+			}
+			if _, ok := entry.Fields[name]; ok {
+				continue
+			}
+			if entry.Fields == nil {
+				entry.Fields = make(map[string]interface{})
+			}
+			entry.Fields[name] = captureValue(match[i])
+		}
+		return true
+	})
+}
+
+// captureValue converts a captured number to a float64 like JSON numbers, so
+// it compares and aggregates the same way. Numbers with leading zeros, such
+// as IDs, stay strings.
+func captureValue(s string) interface{} {
+	digits := strings.TrimPrefix(s, "-")
+	if len(digits) > 1 && digits[0] == '0' && digits[1] != '.' {
+		return s
+	}
+	if n, err := strconv.ParseFloat(s, 64); err == nil && !strings.ContainsAny(s, "xXeEnN") {
+		return n
+	}
+	return s
+}
+
+// RegexParser parses lines with a regular expression whose named groups
+// become the fields of the entry. The groups level, time and message (or
+// timestamp, ts and msg) fill in the entry's level, timestamp and message.
+type RegexParser struct {
+	regex *regexp.Regexp
+}
+
+// NewRegexParser compiles a pattern with named groups into a RegexParser
+func NewRegexParser(pattern string) (*RegexParser, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid parse regex %q: %w", pattern, err)
+	}
+	if !HasNamedGroups(re) {
+		return nil, fmt.Errorf("parse regex %q has no named groups such as (?P<level>\\w+)", pattern)
+	}
+	return &RegexParser{regex: re}, nil
+}
+
+// Parse implements the LineParser interface
+func (p *RegexParser) Parse(line string) (LogEntry, bool) {
+	match := p.regex.FindStringSubmatch(line)
+	if match == nil {
+		return LogEntry{}, false
+	}
+
+	entry := LogEntry{
+		Format:  FormatPlainText,
+		Message: line,
+		RawLine: line,
+		Fields:  make(map[string]interface{}),
+	}
+	for i, name := range p.regex.SubexpNames() {
+		value := match[i]
+		if name == "" || value == "" {
+			continue
+		}
+		switch name {
+		case "level":
+			if level, err := ParseLogLevel(value); err == nil {
+				entry.Level = level
+			}
+		case "time", "timestamp", "ts":
+			if ts, err := parseTimestamp(value); err == nil {
+				entry.Timestamp = ts
+			}
+		case "message", "msg":
+			entry.Message = value
+		default:
+			entry.Fields[name] = captureValue(value)
+		}
+	}
+	return entry, true
+}
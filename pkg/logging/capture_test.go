@@ -0,0 +1,109 @@
+package logging
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestCaptureFields(t *testing.T) {
+	re := regexp.MustCompile(`order (?P<order>ord-\d+) took (?P<duration_ms>\d+)ms(?: for (?P<customer>\w+))?`)
+
+	tests := []struct {
+		name  string
+		entry LogEntry
+		want  map[string]interface{}
+	}{
+		{
+			name:  "plain text",
+			entry: ParseLogEntry("INFO order ord-42 took 120ms for alice"),
+			want:  map[string]interface{}{"order": "ord-42", "duration_ms": 120.0, "customer": "alice"},
+		},
+		{
+			name:  "optional group not matched",
+			entry: ParseLogEntry("INFO order ord-42 took 120ms"),
+			want:  map[string]interface{}{"order": "ord-42", "duration_ms": 120.0},
+		},
+		{
+			name:  "parsed fields are kept",
+			entry: ParseLogEntry(`{"msg":"order ord-42 took 120ms","order":"ord-42-retry"}`),
+			want:  map[string]interface{}{"msg": "order ord-42 took 120ms", "order": "ord-42-retry", "duration_ms": 120.0},
+		},
+		{
+			name:  "no match",
+			entry: ParseLogEntry("INFO starting"),
+			want:  nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry := tt.entry
+			if !CaptureFields(re).Apply(&entry) {
+				t.Fatal("Apply() dropped the entry")
+			}
+			if !reflect.DeepEqual(entry.Fields, tt.want) {
+				t.Errorf("Fields = %v, want %v", entry.Fields, tt.want)
+			}
+		})
+	}
+}
+
+func TestCaptureValue(t *testing.T) {
+	tests := []struct {
+		in   string
+		want interface{}
+	}{
+		{"120", 120.0},
+		{"-3.5", -3.5},
+		{"0", 0.0},
+		{"0.25", 0.25},
+		{"007", "007"},
+		{"1e5", "1e5"},
+		{"0x1F", "0x1F"},
+		{"NaN", "NaN"},
+		{"alice", "alice"},
+	}
+	for _, tt := range tests {
+		if got := captureValue(tt.in); got != tt.want {
+			t.Errorf("captureValue(%q) = %#v, want %#v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestRegexParser(t *testing.T) {
+	p, err := NewRegexParser(`^(?P<time>\S+) \[(?P<level>\w+)\] (?P<component>[\w-]+): (?P<message>.*?)(?: status=(?P<status>\d+))?$`)
+	if err != nil {
+		t.Fatalf("NewRegexParser() error = %v", err)
+	}
+
+	entry, ok := p.Parse("2024-03-15T12:19:57Z [warn] billing-worker: retrying charge status=503")
+	if !ok {
+		t.Fatal("Parse() did not match")
+	}
+	if entry.Level != WARN {
+		t.Errorf("Level = %s, want WARN", entry.Level)
+	}
+	if want := time.Date(2024, 3, 15, 12, 19, 57, 0, time.UTC); !entry.Timestamp.Equal(want) {
+		t.Errorf("Timestamp = %v, want %v", entry.Timestamp, want)
+	}
+	if entry.Message != "retrying charge" {
+		t.Errorf("Message = %q, want %q", entry.Message, "retrying charge")
+	}
+	if want := map[string]interface{}{"component": "billing-worker", "status": 503.0}; !reflect.DeepEqual(entry.Fields, want) {
+		t.Errorf("Fields = %v, want %v", entry.Fields, want)
+	}
+
+	if _, ok := p.Parse("not in this format"); ok {
+		t.Error("Parse() matched a line in another format")
+	}
+}
+
+func TestNewRegexParser_Invalid(t *testing.T) {
+	for _, pattern := range []string{`(unclosed`, `^\S+ \w+$`} {
+		if _, err := NewRegexParser(pattern); err == nil {
+			t.Errorf("NewRegexParser(%q) succeeded", pattern)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package logging
+
+import (
+	"encoding/csv"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCSVFormatter_Format(t *testing.T) {
+	f := NewCSVFormatter([]string{"ts", "level", "pod", "msg", "status", "label.team"})
+	if got, want := f.Header(), "ts,level,pod,msg,status,label.team"; got != want {
+		t.Errorf("Header() = %q, want %q", got, want)
+	}
+
+	tests := []struct {
+		name string
+		line string
+		want []string
+	}{
+		{
+			name: "JSON log",
+			line: `{"level":"error","time":"2024-01-15T10:30:00.123Z","msg":"upstream said \"no\", giving up","status":502}`,
+			want: []string{"2024-01-15T10:30:00.123Z", "ERROR", "api-1", `upstream said "no", giving up`, "502", "payments"},
+		},
+		{
+			name: "Missing fields",
+			line: "plain text",
+			want: []string{"", "DEBUG", "api-1", "plain text", "", "payments"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry := ParseLogEntry(tt.line)
+			entry.Source = &Source{Pod: "api-1", Labels: map[string]string{"team": "payments"}}
+
+			row := f.Format(entry)
+			got, err := csv.NewReader(strings.NewReader(row)).Read()
+			if err != nil {
+				t.Fatalf("Format() = %q is not valid CSV: %v", row, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Format() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewCSVFormatter_DefaultColumns(t *testing.T) {
+	if got := NewCSVFormatter(nil).Columns; !reflect.DeepEqual(got, DefaultCSVColumns) {
+		t.Errorf("Columns = %v, want %v", got, DefaultCSVColumns)
+	}
+}
@@ -0,0 +1,96 @@
+package logging
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// MaxDecodedLength is the number of bytes of a decoded field kept for display
+const MaxDecodedLength = 1024
+
+// decoders decode field values by encoding name
+var decoders = map[string]func(string) ([]byte, error){
+	"base64": decodeBase64,
+}
+
+// ParseDecodeField parses a name=encoding expression, e.g. payload=base64,
+// into a transform decoding that field
+func ParseDecodeField(expr string) (Transform, error) {
+	name, encoding, found := strings.Cut(expr, "=")
+	if !found || name == "" {
+		return nil, fmt.Errorf("invalid decode expression %q (expected name=encoding)", expr)
+	}
+	return DecodeField(name, encoding)
+}
+
+// DecodeField returns a transform that decodes a field holding an encoded
+// payload. Text is truncated to MaxDecodedLength and binary data is replaced
+// by a summary; values that fail to decode are left unchanged.
+func DecodeField(name, encoding string) (Transform, error) {
+	decode, ok := decoders[strings.ToLower(encoding)]
+	if !ok {
+		return nil, fmt.Errorf("unsupported encoding %q for field %q (expected base64)", encoding, name)
+	}
+	return TransformFunc(func(entry *LogEntry) bool {
+		value, ok := entry.Fields[name].(string)
+		if !ok {
+			return true
+		}
+		data, err := decode(value)
+		if err != nil {
+			return true
+		}
+		decoded := displayDecoded(data)
+		entry.Fields[name] = decoded
+		if entry.Message == value {
+			entry.Message = decoded
+		}
+		return true
+	}), nil
+}
+
+// decodeBase64 decodes standard or URL-safe base64, with or without padding
+func decodeBase64(value string) ([]byte, error) {
+	value = strings.TrimSpace(value)
+	var err error
+	for _, enc := range []*base64.Encoding{base64.StdEncoding, base64.URLEncoding, base64.RawStdEncoding, base64.RawURLEncoding} {
+		var data []byte
+		if data, err = enc.DecodeString(value); err == nil {
+			return data, nil
+		}
+	}
+	return nil, err
+}
+
+// displayDecoded converts decoded bytes to text that is safe to print
+func displayDecoded(data []byte) string {
+	if !isPrintable(data) {
+		return fmt.Sprintf("<%d bytes of binary data>", len(data))
+	}
+	if len(data) <= MaxDecodedLength {
+		return string(data)
+	}
+	// Cut at a rune boundary so the text stays valid UTF-8
+	cut := MaxDecodedLength
+	for cut > 0 && !utf8.RuneStart(data[cut]) {
+		cut--
+	}
+	return fmt.Sprintf("%s... (%d bytes truncated)", data[:cut], len(data)-cut)
+}
+
+// isPrintable reports whether data is UTF-8 text without control characters
+// other than whitespace
+func isPrintable(data []byte) bool {
+	if !utf8.Valid(data) {
+		return false
+	}
+	for _, r := range string(data) {
+		if unicode.IsControl(r) && !unicode.IsSpace(r) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,86 @@
+package logging
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+)
+
+func TestParseDecodeField(t *testing.T) {
+	long := strings.Repeat("é", MaxDecodedLength)
+
+	tests := []struct {
+		name      string
+		expr      string
+		value     interface{}
+		wantErr   bool
+		wantValue interface{}
+	}{
+		{
+			name:      "Standard base64",
+			expr:      "payload=base64",
+			value:     base64.StdEncoding.EncodeToString([]byte(`{"event":"push"}`)),
+			wantValue: `{"event":"push"}`,
+		},
+		{
+			name:      "URL-safe base64 without padding",
+			expr:      "payload=BASE64",
+			value:     base64.RawURLEncoding.EncodeToString([]byte("a?b>c")),
+			wantValue: "a?b>c",
+		},
+		{
+			name:      "Binary data is summarized",
+			expr:      "payload=base64",
+			value:     base64.StdEncoding.EncodeToString([]byte{0x00, 0xff, 0x10}),
+			wantValue: "<3 bytes of binary data>",
+		},
+		{
+			name:      "Long text is truncated at a rune boundary",
+			expr:      "payload=base64",
+			value:     base64.StdEncoding.EncodeToString([]byte(long)),
+			wantValue: long[:MaxDecodedLength] + "... (1024 bytes truncated)",
+		},
+		{
+			name:      "Invalid base64 is left unchanged",
+			expr:      "payload=base64",
+			value:     "not base64!",
+			wantValue: "not base64!",
+		},
+		{
+			name:      "Non-string values are left unchanged",
+			expr:      "payload=base64",
+			value:     float64(42),
+			wantValue: float64(42),
+		},
+		{
+			name:    "Unsupported encoding",
+			expr:    "payload=rot13",
+			wantErr: true,
+		},
+		{
+			name:    "Missing encoding",
+			expr:    "payload",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transform, err := ParseDecodeField(tt.expr)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseDecodeField() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			entry := LogEntry{Fields: map[string]interface{}{"payload": tt.value}}
+			if !transform.Apply(&entry) {
+				t.Fatal("Apply() dropped the entry")
+			}
+			if got := entry.Fields["payload"]; got != tt.wantValue {
+				t.Errorf("payload = %q, want %q", got, tt.wantValue)
+			}
+		})
+	}
+}
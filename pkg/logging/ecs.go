@@ -0,0 +1,152 @@
+package logging
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ECSVersion is the Elastic Common Schema version written by ECSFormatter
+const ECSVersion = "8.11"
+
+// ecsFields maps common field names to their ECS equivalents
+var ecsFields = map[string]string{
+	"status":      "http.response.status_code",
+	"status_code": "http.response.status_code",
+	"method":      "http.request.method",
+	"path":        "url.path",
+	"url":         "url.original",
+	"remote_ip":   "client.ip",
+	"client_ip":   "client.ip",
+	"user_agent":  "user_agent.original",
+	"thread":      "process.thread.name",
+	"pid":         "process.pid",
+	"error":       "error.message",
+	"exception":   "error.type",
+	"stack_trace": "error.stack_trace",
+	"traceback":   "error.stack_trace",
+	"span_id":     "span.id",
+	"spanId":      "span.id",
+}
+
+// ECSFormatter renders entries as Elastic Common Schema JSON documents:
+// @timestamp, log.level, message, log.logger, trace.id and kubernetes.*.
+// Fields without an ECS equivalent are kept under their own names.
+type ECSFormatter struct{}
+
+// Format implements the Formatter interface
+func (ECSFormatter) Format(entry LogEntry) string {
+	data, err := json.Marshal(ecsDocument(entry))
+	if err != nil {
+		return fmt.Sprintf(`{"message": %q, "error.message": %q}`, entry.Message, err.Error())
+	}
+	return string(data)
+}
+
+// ecsDocument builds the nested ECS document for an entry
+func ecsDocument(entry LogEntry) map[string]interface{} {
+	doc := map[string]interface{}{}
+	consumed := map[string]bool{}
+
+	setECSField(doc, "ecs.version", ECSVersion)
+	if !entry.Timestamp.IsZero() {
+		setECSField(doc, "@timestamp", entry.Timestamp.UTC().Format(time.RFC3339Nano))
+	}
+	setECSField(doc, "log.level", strings.ToLower(entry.Level.String()))
+	setECSField(doc, "message", entry.Message)
+	// The fields the level, message and timestamp were parsed from are
+	// replaced by their ECS fields; a numeric status is not a level
+	for _, key := range jsonLevelFields {
+		if v, ok := entry.Fields[key].(string); ok {
+			if _, err := ParseLogLevel(v); err == nil {
+				consumed[key] = true
+			}
+		}
+	}
+	for _, fields := range [][]string{jsonMessageFields, jsonTimeFields} {
+		for _, key := range fields {
+			if _, ok := entry.Fields[key]; ok {
+				consumed[key] = true
+				break
+			}
+		}
+	}
+
+	if name := LoggerName(entry); name != "" {
+		setECSField(doc, "log.logger", name)
+	}
+	for _, key := range LoggerFields {
+		consumed[key] = true
+	}
+	for _, key := range TraceFields {
+		if v, ok := entry.Fields[key]; ok && !consumed[key] {
+			setECSField(doc, "trace.id", fmt.Sprintf("%v", v))
+			consumed[key] = true
+			break
+		}
+	}
+
+	if s := entry.Source; s != nil {
+		for path, value := range map[string]string{
+			"kubernetes.namespace":      s.Namespace,
+			"kubernetes.pod.name":       s.Pod,
+			"kubernetes.container.name": s.Container,
+			"kubernetes.node.name":      s.Node,
+		} {
+			if value != "" {
+				setECSField(doc, path, value)
+			}
+		}
+		for key, value := range s.Labels {
+			setECSField(doc, "kubernetes.labels."+key, value)
+		}
+	}
+
+	// Sorted so that the first of several fields mapping to one path wins
+	keys := make([]string, 0, len(entry.Fields))
+	for key := range entry.Fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		value := entry.Fields[key]
+		if consumed[key] {
+			continue
+		}
+		if path, ok := ecsFields[key]; ok {
+			if lines, ok := value.([]string); ok {
+				value = strings.Join(lines, "\n")
+			}
+			setECSField(doc, path, value)
+			continue
+		}
+		setECSField(doc, key, value)
+	}
+	return doc
+}
+
+// setECSField sets the value at a dotted path, creating nested objects as
+// needed. Values already present, or paths blocked by a non-object value,
+// are left alone.
+func setECSField(doc map[string]interface{}, path string, value interface{}) {
+	parts := strings.Split(path, ".")
+	for _, part := range parts[:len(parts)-1] {
+		next, ok := doc[part]
+		if !ok {
+			child := map[string]interface{}{}
+			doc[part] = child
+			doc = child
+			continue
+		}
+		child, ok := next.(map[string]interface{})
+		if !ok {
+			return
+		}
+		doc = child
+	}
+	if _, ok := doc[parts[len(parts)-1]]; !ok {
+		doc[parts[len(parts)-1]] = value
+	}
+}
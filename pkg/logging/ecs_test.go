@@ -0,0 +1,62 @@
+package logging
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestECSFormatter_Format(t *testing.T) {
+	tests := []struct {
+		name   string
+		line   string
+		source *Source
+		want   map[string]interface{}
+	}{
+		{
+			name:   "zap log with source",
+			line:   `{"level":"error","ts":"2024-01-15T10:30:00.5Z","caller":"api/charge.go:42","logger":"payments","msg":"charge failed","trace_id":"4bf92f35","status":502}`,
+			source: &Source{Namespace: "shop", Pod: "api-1", Container: "app", Node: "node-1", Labels: map[string]string{"team": "payments"}},
+			want: map[string]interface{}{
+				"@timestamp": "2024-01-15T10:30:00.5Z",
+				"ecs":        map[string]interface{}{"version": ECSVersion},
+				"log":        map[string]interface{}{"level": "error", "logger": "payments"},
+				"message":    "charge failed",
+				"trace":      map[string]interface{}{"id": "4bf92f35"},
+				"http":       map[string]interface{}{"response": map[string]interface{}{"status_code": float64(502)}},
+				"caller":     "api/charge.go:42",
+				"kubernetes": map[string]interface{}{
+					"namespace": "shop",
+					"pod":       map[string]interface{}{"name": "api-1"},
+					"container": map[string]interface{}{"name": "app"},
+					"node":      map[string]interface{}{"name": "node-1"},
+					"labels":    map[string]interface{}{"team": "payments"},
+				},
+			},
+		},
+		{
+			name: "Plain text",
+			line: "WARN cache miss",
+			want: map[string]interface{}{
+				"ecs":     map[string]interface{}{"version": ECSVersion},
+				"log":     map[string]interface{}{"level": "warn"},
+				"message": "WARN cache miss",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry := ParseLogEntry(tt.line)
+			entry.Source = tt.source
+
+			var got map[string]interface{}
+			if err := json.Unmarshal([]byte(ECSFormatter{}.Format(entry)), &got); err != nil {
+				t.Fatalf("Format() returned invalid JSON: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Format() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
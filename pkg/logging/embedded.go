@@ -0,0 +1,76 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+)
+
+// maxEmbeddedJSONAttempts bounds how many opening braces of a message are
+// tried as the start of an embedded JSON object
+const maxEmbeddedJSONAttempts = 5
+
+// findEmbeddedJSON locates the first JSON object inside a plain text message,
+// e.g. `Request payload: {"id": 42}`, returning its bounds and decoded value
+func findEmbeddedJSON(message string) (start, end int, object map[string]interface{}, ok bool) {
+	offset := 0
+	for attempt := 0; attempt < maxEmbeddedJSONAttempts; attempt++ {
+		i := strings.IndexByte(message[offset:], '{')
+		if i < 0 {
+			return 0, 0, nil, false
+		}
+		start = offset + i
+
+		decoder := json.NewDecoder(strings.NewReader(message[start:]))
+		var value map[string]interface{}
+		if err := decoder.Decode(&value); err == nil && len(value) > 0 {
+			return start, start + int(decoder.InputOffset()), value, true
+		}
+		offset = start + 1
+	}
+	return 0, 0, nil, false
+}
+
+// extractEmbeddedJSON adds the keys of a JSON object embedded in a plain text
+// message to the entry's fields so they can be filtered on. Fields set by the
+// parser take precedence.
+func extractEmbeddedJSON(entry *LogEntry) {
+	if entry.Format != FormatPlainText || !strings.Contains(entry.Message, "{") {
+		return
+	}
+	_, _, object, ok := findEmbeddedJSON(entry.Message)
+	if !ok {
+		return
+	}
+	if entry.Fields == nil {
+		entry.Fields = make(map[string]interface{}, len(object))
+	}
+	for k, v := range object {
+		if _, exists := entry.Fields[k]; !exists {
+			entry.Fields[k] = v
+		}
+	}
+}
+
+// PrettyEmbeddedJSON is a transform that indents a JSON object embedded in a
+// plain text message onto its own lines
+var PrettyEmbeddedJSON Transform = TransformFunc(func(entry *LogEntry) bool {
+	if entry.Format != FormatPlainText {
+		return true
+	}
+	start, end, _, ok := findEmbeddedJSON(entry.Message)
+	if !ok {
+		return true
+	}
+
+	var pretty bytes.Buffer
+	if err := json.Indent(&pretty, []byte(entry.Message[start:end]), "", "  "); err != nil {
+		return true
+	}
+	message := strings.TrimRight(entry.Message[:start], " ") + "\n" + pretty.String()
+	if rest := strings.TrimSpace(entry.Message[end:]); rest != "" {
+		message += "\n" + rest
+	}
+	entry.Message = message
+	return true
+})
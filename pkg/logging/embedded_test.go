@@ -0,0 +1,71 @@
+package logging
+
+import (
+	"testing"
+)
+
+func TestParseLogEntry_EmbeddedJSON(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      string
+		wantFields map[string]interface{}
+	}{
+		{
+			name:  "Object after a prefix",
+			input: `2024-03-15T12:19:57Z INFO Request payload: {"user_id": 42, "action": "checkout"}`,
+			wantFields: map[string]interface{}{
+				"user_id": float64(42),
+				"action":  "checkout",
+			},
+		},
+		{
+			name:  "Object followed by text",
+			input: `webhook received {"event":"push","nested":{"ref":"main"}} in 3ms`,
+			wantFields: map[string]interface{}{
+				"event": "push",
+			},
+		},
+		{
+			name:  "Braces that are not JSON before the object",
+			input: `template {name} rendered with {"name":"api"}`,
+			wantFields: map[string]interface{}{
+				"name": "api",
+			},
+		},
+		{
+			name:  "Parser fields take precedence",
+			input: `2024-03-15 12:19:57,123 [main] INFO  com.example.App - Sent {"logger":"other","id":"7"}`,
+			wantFields: map[string]interface{}{
+				"logger": "com.example.App",
+				"id":     "7",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParseLogEntry(tt.input)
+			if got.Format != FormatPlainText {
+				t.Fatalf("Format = %v, want plaintext", got.Format)
+			}
+			if got.Message == "" {
+				t.Error("Message is empty")
+			}
+			for k, v := range tt.wantFields {
+				if got.Fields[k] != v {
+					t.Errorf("Fields[%q] = %v, want %v", k, got.Fields[k], v)
+				}
+			}
+		})
+	}
+}
+
+func TestPrettyEmbeddedJSON(t *testing.T) {
+	entry := ParseLogEntry(`Request payload: {"id":42,"tags":["a"]} done`)
+	PrettyEmbeddedJSON.Apply(&entry)
+
+	want := "Request payload:\n{\n  \"id\": 42,\n  \"tags\": [\n    \"a\"\n  ]\n}\ndone"
+	if entry.Message != want {
+		t.Errorf("Message = %q, want %q", entry.Message, want)
+	}
+}
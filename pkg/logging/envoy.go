@@ -0,0 +1,111 @@
+package logging
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// envoyAccessLogRegex matches Envoy's default access log format, which Istio's
+// default text format extends with extra trailing fields:
+//
+//	[%START_TIME%] "%METHOD% %PATH% %PROTOCOL%" %RESPONSE_CODE% %RESPONSE_FLAGS% ... %BYTES_RECEIVED% %BYTES_SENT% %DURATION% ...
+var envoyAccessLogRegex = regexp.MustCompile(
+	`^\[(?P<start_time>[^\]]+)\] "(?P<method>[A-Z]+|-) (?P<path>\S+) (?P<protocol>[^"]+)" ` +
+		`(?P<response_code>\d{1,3}) (?P<response_flags>\S+) (?:(?P<response_code_details>\S+) (?P<connection_termination_details>\S+) )?` +
+		`(?:"(?P<upstream_transport_failure_reason>[^"]*)" )?` +
+		`(?P<bytes_received>\d+) (?P<bytes_sent>\d+) (?P<duration>\d+) (?P<upstream_service_time>\S+) ` +
+		`"(?P<x_forwarded_for>[^"]*)" "(?P<user_agent>[^"]*)" "(?P<request_id>[^"]*)" "(?P<authority>[^"]*)" "(?P<upstream_host>[^"]*)"` +
+		`(?: (?P<upstream_cluster>\S+))?`)
+
+// envoyNumericFields are converted to numbers so they can be compared in filters
+var envoyNumericFields = map[string]bool{
+	"response_code":  true,
+	"bytes_received": true,
+	"bytes_sent":     true,
+	"duration":       true,
+}
+
+// parseEnvoyLog parses an access log line in Envoy's (or Istio's) default text format
+func parseEnvoyLog(line string) (LogEntry, bool) {
+	if !strings.HasPrefix(line, "[") {
+		return LogEntry{}, false
+	}
+	match := envoyAccessLogRegex.FindStringSubmatch(line)
+	if match == nil {
+		return LogEntry{}, false
+	}
+
+	fields := make(map[string]interface{})
+	for i, name := range envoyAccessLogRegex.SubexpNames() {
+		if name == "" || match[i] == "" || match[i] == "-" {
+			continue
+		}
+		if envoyNumericFields[name] {
+			if n, err := strconv.ParseFloat(match[i], 64); err == nil {
+				fields[name] = n
+				continue
+			}
+		}
+		fields[name] = match[i]
+	}
+
+	entry := LogEntry{
+		Format:  FormatPlainText,
+		Logger:  "envoy",
+		Fields:  fields,
+		Message: line,
+		RawLine: line,
+	}
+	if ts, err := parseTimestamp(match[1]); err == nil {
+		entry.Timestamp = ts
+	}
+	entry.Level = accessLogLevel(fields["response_code"])
+	return entry, true
+}
+
+// isEnvoyJSON reports whether a JSON log looks like an Envoy/Istio access log
+func isEnvoyJSON(data map[string]interface{}) bool {
+	_, hasCode := data["response_code"]
+	_, hasUpstream := data["upstream_host"]
+	_, hasCluster := data["upstream_cluster"]
+	return hasCode && (hasUpstream || hasCluster)
+}
+
+// enrichEnvoyJSON derives the level and a short request summary for JSON access logs
+func enrichEnvoyJSON(entry *LogEntry) {
+	entry.Level = accessLogLevel(entry.Fields["response_code"])
+	if entry.Message == entry.RawLine {
+		entry.Message = strings.TrimSpace(fmt.Sprintf("%v %v %v",
+			valueOrDash(entry.Fields["method"]),
+			valueOrDash(entry.Fields["path"]),
+			valueOrDash(entry.Fields["response_code"])))
+	}
+}
+
+// accessLogLevel maps an HTTP status code to a level: 5xx is an error, 4xx a warning
+func accessLogLevel(code interface{}) LogLevel {
+	var status float64
+	switch v := code.(type) {
+	case float64:
+		status = v
+	case string:
+		status, _ = strconv.ParseFloat(v, 64)
+	}
+	switch {
+	case status >= 500 || (status == 0 && code != nil):
+		return ERROR
+	case status >= 400:
+		return WARN
+	default:
+		return INFO
+	}
+}
+
+func valueOrDash(v interface{}) interface{} {
+	if v == nil {
+		return "-"
+	}
+	return v
+}
@@ -0,0 +1,74 @@
+package logging
+
+import (
+	"testing"
+)
+
+func TestParseEnvoyLog(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      string
+		wantLogger string
+		wantLevel  LogLevel
+		wantFields map[string]interface{}
+	}{
+		{
+			name:       "Envoy default format",
+			input:      `[2016-04-15T20:17:00.310Z] "POST /api/v1/locations HTTP/2" 204 - 154 0 226 100 "10.0.35.28" "nsq2http" "cc21d9b0-cf5c-432b-8c7e-98aeb7988cd2" "locations" "tcp://10.0.2.1:80"`,
+			wantLogger: "envoy",
+			wantLevel:  INFO,
+			wantFields: map[string]interface{}{
+				"method":        "POST",
+				"path":          "/api/v1/locations",
+				"response_code": float64(204),
+				"duration":      float64(226),
+				"upstream_host": "tcp://10.0.2.1:80",
+			},
+		},
+		{
+			name:       "Istio default format",
+			input:      `[2024-03-15T12:19:57.123Z] "GET /checkout HTTP/1.1" 503 UF upstream_reset_before_response_started{connection_failure} - "-" 0 91 12 - "-" "curl/8.0" "5b8e2d2c-1f7a-4f4e-9d2b-1c2d3e4f5a6b" "checkout:8080" "10.1.2.3:8080" outbound|8080||checkout.shop.svc.cluster.local 10.1.0.5:41234 10.96.0.10:8080 10.1.0.5:41230 - default`,
+			wantLogger: "envoy",
+			wantLevel:  ERROR,
+			wantFields: map[string]interface{}{
+				"method":           "GET",
+				"response_code":    float64(503),
+				"response_flags":   "UF",
+				"duration":         float64(12),
+				"upstream_host":    "10.1.2.3:8080",
+				"upstream_cluster": "outbound|8080||checkout.shop.svc.cluster.local",
+			},
+		},
+		{
+			name:       "Istio JSON format",
+			input:      `{"start_time":"2024-03-15T12:19:57.123Z","method":"GET","path":"/cart","response_code":404,"duration":3,"upstream_host":"10.1.2.3:8080","upstream_cluster":"outbound|8080||cart"}`,
+			wantLogger: "envoy",
+			wantLevel:  WARN,
+			wantFields: map[string]interface{}{
+				"response_code": float64(404),
+				"duration":      float64(3),
+				"upstream_host": "10.1.2.3:8080",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParseLogEntry(tt.input)
+			if got.Logger != tt.wantLogger {
+				t.Errorf("Logger = %v, want %v", got.Logger, tt.wantLogger)
+			}
+			if got.Level != tt.wantLevel {
+				t.Errorf("Level = %v, want %v", got.Level, tt.wantLevel)
+			}
+			if got.Timestamp.IsZero() {
+				t.Error("Timestamp was not parsed")
+			}
+			for k, v := range tt.wantFields {
+				if got.Fields[k] != v {
+					t.Errorf("Fields[%q] = %v, want %v", k, got.Fields[k], v)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package logging
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// epochUnits are the units of epoch timestamps by name
+var epochUnits = map[string]time.Duration{
+	"s":  time.Second,
+	"ms": time.Millisecond,
+	"us": time.Microsecond,
+	"ns": time.Nanosecond,
+}
+
+// epochNumber matches timestamps logged as a number in a string, e.g. "1705314645.123"
+var epochNumber = regexp.MustCompile(`^-?\d+(\.\d+)?$`)
+
+// ParseEpochUnit parses the unit of epoch timestamps: s, ms, us or ns
+func ParseEpochUnit(name string) (time.Duration, error) {
+	unit, ok := epochUnits[name]
+	if !ok {
+		return 0, fmt.Errorf("invalid epoch unit %q (expected s, ms, us or ns)", name)
+	}
+	return unit, nil
+}
+
+// EpochUnit guesses the unit of an epoch timestamp from its size. In seconds,
+// the times up to the year 5138 are below 1e11, so larger numbers are taken
+// for the milliseconds, microseconds or nanoseconds of times since 1973.
+func EpochUnit(n float64) time.Duration {
+	switch n = math.Abs(n); {
+	case n < 1e11:
+		return time.Second
+	case n < 1e14:
+		return time.Millisecond
+	case n < 1e17:
+		return time.Microsecond
+	}
+	return time.Nanosecond
+}
+
+// EpochTime returns the time of an epoch timestamp counting unit, or the
+// unit guessed by EpochUnit when unit is 0. Fractions, such as those of the
+// float seconds of zap, are kept to the microsecond, the precision of
+// floating point numbers of that size.
+func EpochTime(n float64, unit time.Duration) time.Time {
+	if unit == 0 {
+		unit = EpochUnit(n)
+	}
+	whole, frac := math.Modf(n)
+	t := epochInt(int64(whole), unit)
+	return t.Add(time.Duration(math.Round(frac*float64(unit)/1e3)) * time.Microsecond)
+}
+
+// epochInt returns the time of an integer epoch timestamp counting unit
+func epochInt(n int64, unit time.Duration) time.Time {
+	switch unit {
+	case time.Second:
+		return time.Unix(n, 0)
+	case time.Millisecond:
+		return time.UnixMilli(n)
+	case time.Microsecond:
+		return time.UnixMicro(n)
+	}
+	return time.Unix(0, n*int64(unit))
+}
+
+// parseEpochString parses an epoch timestamp logged as a string. Integers
+// are parsed exactly, so nanoseconds keep every digit.
+func parseEpochString(s string, unit time.Duration) (time.Time, bool) {
+	if !epochNumber.MatchString(s) {
+		return time.Time{}, false
+	}
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		if unit == 0 {
+			unit = EpochUnit(float64(n))
+		}
+		return epochInt(n, unit), true
+	}
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return EpochTime(n, unit), true
+}
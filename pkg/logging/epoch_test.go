@@ -0,0 +1,113 @@
+package logging
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEpochUnit(t *testing.T) {
+	tests := []struct {
+		name string
+		n    float64
+		want time.Duration
+	}{
+		{"seconds", 1705314645, time.Second},
+		{"float seconds", 1705314645.123456, time.Second},
+		{"milliseconds", 1705314645123, time.Millisecond},
+		{"microseconds", 1705314645123456, time.Microsecond},
+		{"nanoseconds", 1705314645123456789, time.Nanosecond},
+		{"milliseconds of 1973", 1e11, time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := EpochUnit(tt.n); got != tt.want {
+				t.Errorf("EpochUnit(%v) = %v, want %v", tt.n, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseEpochUnit(t *testing.T) {
+	if unit, err := ParseEpochUnit("us"); err != nil || unit != time.Microsecond {
+		t.Errorf("ParseEpochUnit(us) = %v, %v, want %v", unit, err, time.Microsecond)
+	}
+	if _, err := ParseEpochUnit("minutes"); err == nil {
+		t.Error("ParseEpochUnit(minutes) returned no error")
+	}
+}
+
+func TestParseLogEntry_EpochTimestamps(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want time.Time
+	}{
+		{
+			name: "zap float seconds",
+			line: `{"level":"info","ts":1705314645.123456,"msg":"zap"}`,
+			want: time.Date(2024, 1, 15, 10, 30, 45, 123456000, time.UTC),
+		},
+		{
+			name: "microseconds",
+			line: `{"level":"info","time":1705314645123456,"msg":"micros"}`,
+			want: time.Date(2024, 1, 15, 10, 30, 45, 123456000, time.UTC),
+		},
+		{
+			name: "nanoseconds keep every digit",
+			line: `{"level":"info","timestamp":1705314645123456789,"msg":"nanos"}`,
+			want: time.Date(2024, 1, 15, 10, 30, 45, 123456789, time.UTC),
+		},
+		{
+			name: "nanoseconds in a string keep every digit",
+			line: `{"level":"info","time":"1705314645123456789","msg":"nanos"}`,
+			want: time.Date(2024, 1, 15, 10, 30, 45, 123456789, time.UTC),
+		},
+		{
+			name: "seconds with an exponent",
+			line: `{"level":"info","ts":1.7053146455e9,"msg":"zap"}`,
+			want: time.Date(2024, 1, 15, 10, 30, 45, 500000000, time.UTC),
+		},
+		{
+			name: "float seconds in a string",
+			line: `{"level":"info","ts":"1705314645.5","msg":"zap"}`,
+			want: time.Date(2024, 1, 15, 10, 30, 45, 500000000, time.UTC),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseLogEntry(tt.line).Timestamp; !got.Equal(tt.want) {
+				t.Errorf("Timestamp = %v, want %v", got.UTC(), tt.want)
+			}
+		})
+	}
+}
+
+func TestPipeline_EpochUnits(t *testing.T) {
+	p := NewPipeline()
+	p.Alias(map[string]string{"@t": "time"})
+	p.EpochUnits(map[string]time.Duration{"ts": time.Millisecond, "time": time.Microsecond})
+
+	tests := []struct {
+		name string
+		line string
+		want time.Time
+	}{
+		{"unit of the field", `{"ts":90061000,"msg":"test clock"}`, time.Date(1970, 1, 2, 1, 1, 1, 0, time.UTC)},
+		{"unit of an aliased field", `{"@t":"90061000000","msg":"test clock"}`, time.Date(1970, 1, 2, 1, 1, 1, 0, time.UTC)},
+		{"guessed without a unit", `{"timestamp":90061,"msg":"test clock"}`, time.Date(1970, 1, 2, 1, 1, 1, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry, ok := p.Process(tt.line)
+			if !ok {
+				t.Fatal("entry was dropped")
+			}
+			if !entry.Timestamp.Equal(tt.want) {
+				t.Errorf("Timestamp = %v, want %v", entry.Timestamp.UTC(), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,509 @@
+package logging
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Expr is a compiled expression computing a value from the fields of an
+// entry, e.g. ceil(duration_ms/100)*100. It supports numbers, quoted strings,
+// field names, the operators + - * / % == != < <= > >= && || ! and the
+// functions listed in exprFuncs.
+type Expr struct {
+	src  string
+	root exprNode
+}
+
+// CompileExpr parses an expression
+func CompileExpr(src string) (*Expr, error) {
+	p := &exprParser{src: src}
+	if err := p.tokenize(); err != nil {
+		return nil, fmt.Errorf("invalid expression %q: %w", src, err)
+	}
+	root, err := p.parseOr()
+	if err == nil && p.pos < len(p.tokens) {
+		err = fmt.Errorf("unexpected %q", p.tokens[p.pos].text)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid expression %q: %w", src, err)
+	}
+	return &Expr{src: src, root: root}, nil
+}
+
+// String returns the source of the expression
+func (e *Expr) String() string {
+	return e.src
+}
+
+// Eval evaluates the expression against an entry. Fields are looked up by
+// name; level and message refer to the entry's level and message.
+func (e *Expr) Eval(entry LogEntry) (interface{}, error) {
+	return e.root.eval(entry)
+}
+
+// DeriveField returns a transform setting a field to the value of expr.
+// Entries the expression cannot be evaluated for, e.g. because a field is
+// missing, are kept without the field.
+func DeriveField(name string, expr *Expr) Transform {
+	return TransformFunc(func(entry *LogEntry) bool {
+		value, err := expr.Eval(*entry)
+		if err != nil {
+			return true
+		}
+		if entry.Fields == nil {
+			entry.Fields = make(map[string]interface{})
+		}
+		entry.Fields[name] = value
+		return true
+	})
+}
+
+// exprFuncs are the functions available in expressions
+var exprFuncs = map[string]func(args []interface{}) (interface{}, error){
+	"ceil":  numericFunc(math.Ceil),
+	"floor": numericFunc(math.Floor),
+	"round": numericFunc(math.Round),
+	"abs":   numericFunc(math.Abs),
+	"min": func(args []interface{}) (interface{}, error) {
+		return foldNumbers(args, math.Min)
+	},
+	"max": func(args []interface{}) (interface{}, error) {
+		return foldNumbers(args, math.Max)
+	},
+	"lower": stringFunc(strings.ToLower),
+	"upper": stringFunc(strings.ToUpper),
+	"len": func(args []interface{}) (interface{}, error) {
+		if len(args) != 1 {
+			return nil, fmt.Errorf("len expects 1 argument")
+		}
+		return float64(len([]rune(exprString(args[0])))), nil
+	},
+	"if": func(args []interface{}) (interface{}, error) {
+		if len(args) != 3 {
+			return nil, fmt.Errorf("if expects 3 arguments")
+		}
+		if exprTruthy(args[0]) {
+			return args[1], nil
+		}
+		return args[2], nil
+	},
+}
+
+func numericFunc(f func(float64) float64) func(args []interface{}) (interface{}, error) {
+	return func(args []interface{}) (interface{}, error) {
+		if len(args) != 1 {
+			return nil, fmt.Errorf("expected 1 argument")
+		}
+		n, err := exprNumber(args[0])
+		if err != nil {
+			return nil, err
+		}
+		return f(n), nil
+	}
+}
+
+func stringFunc(f func(string) string) func(args []interface{}) (interface{}, error) {
+	return func(args []interface{}) (interface{}, error) {
+		if len(args) != 1 {
+			return nil, fmt.Errorf("expected 1 argument")
+		}
+		return f(exprString(args[0])), nil
+	}
+}
+
+func foldNumbers(args []interface{}, f func(a, b float64) float64) (interface{}, error) {
+	if len(args) == 0 {
+		return nil, fmt.Errorf("expected at least 1 argument")
+	}
+	result, err := exprNumber(args[0])
+	if err != nil {
+		return nil, err
+	}
+	for _, arg := range args[1:] {
+		n, err := exprNumber(arg)
+		if err != nil {
+			return nil, err
+		}
+		result = f(result, n)
+	}
+	return result, nil
+}
+
+// exprNumber converts a value to a number
+func exprNumber(v interface{}) (float64, error) {
+	switch val := v.(type) {
+	case bool:
+		if val {
+			return 1, nil
+		}
+		return 0, nil
+	default:
+		if n, ok := numericValue(v); ok {
+			return n, nil
+		}
+	}
+	return 0, fmt.Errorf("%v is not a number", v)
+}
+
+// exprString converts a value to a string
+func exprString(v interface{}) string {
+	if n, ok := v.(float64); ok {
+		return strconv.FormatFloat(n, 'f', -1, 64)
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+// exprTruthy reports whether a value counts as true in a condition
+func exprTruthy(v interface{}) bool {
+	switch val := v.(type) {
+	case bool:
+		return val
+	case float64:
+		return val != 0
+	case string:
+		return val != ""
+	default:
+		return v != nil
+	}
+}
+
+// exprNode is a node of a parsed expression
+type exprNode interface {
+	eval(entry LogEntry) (interface{}, error)
+}
+
+type literalNode struct{ value interface{} }
+
+func (n literalNode) eval(LogEntry) (interface{}, error) { return n.value, nil }
+
+type fieldNode struct{ name string }
+
+func (n fieldNode) eval(entry LogEntry) (interface{}, error) {
+	if v, ok := FieldValue(entry.Fields, n.name); ok {
+		return v, nil
+	}
+	switch n.name {
+	case "level":
+		return entry.Level.String(), nil
+	case "message":
+		return entry.Message, nil
+	}
+	return nil, fmt.Errorf("field %q not found", n.name)
+}
+
+type unaryNode struct {
+	op      string
+	operand exprNode
+}
+
+func (n unaryNode) eval(entry LogEntry) (interface{}, error) {
+	v, err := n.operand.eval(entry)
+	if err != nil {
+		return nil, err
+	}
+	if n.op == "!" {
+		return !exprTruthy(v), nil
+	}
+	num, err := exprNumber(v)
+	if err != nil {
+		return nil, err
+	}
+	return -num, nil
+}
+
+type binaryNode struct {
+	op          string
+	left, right exprNode
+}
+
+func (n binaryNode) eval(entry LogEntry) (interface{}, error) {
+	left, err := n.left.eval(entry)
+	if err != nil {
+		return nil, err
+	}
+	// Logical operators short-circuit
+	switch n.op {
+	case "&&":
+		if !exprTruthy(left) {
+			return false, nil
+		}
+	case "||":
+		if exprTruthy(left) {
+			return true, nil
+		}
+	}
+	right, err := n.right.eval(entry)
+	if err != nil {
+		return nil, err
+	}
+
+	switch n.op {
+	case "&&", "||":
+		return exprTruthy(right), nil
+	case "==", "!=":
+		equal := exprString(left) == exprString(right)
+		if l, err := exprNumber(left); err == nil {
+			if r, err := exprNumber(right); err == nil {
+				equal = l == r
+			}
+		}
+		return equal == (n.op == "=="), nil
+	case "+":
+		// + concatenates when either side is not a number
+		l, lerr := exprNumber(left)
+		r, rerr := exprNumber(right)
+		if lerr != nil || rerr != nil {
+			return exprString(left) + exprString(right), nil
+		}
+		return l + r, nil
+	}
+
+	l, err := exprNumber(left)
+	if err != nil {
+		return nil, err
+	}
+	r, err := exprNumber(right)
+	if err != nil {
+		return nil, err
+	}
+	switch n.op {
+	case "-":
+		return l - r, nil
+	case "*":
+		return l * r, nil
+	case "/":
+		if r == 0 {
+			return nil, fmt.Errorf("division by zero")
+		}
+		return l / r, nil
+	case "%":
+		if r == 0 {
+			return nil, fmt.Errorf("division by zero")
+		}
+		return math.Mod(l, r), nil
+	case "<":
+		return l < r, nil
+	case "<=":
+		return l <= r, nil
+	case ">":
+		return l > r, nil
+	case ">=":
+		return l >= r, nil
+	}
+	return nil, fmt.Errorf("unknown operator %q", n.op)
+}
+
+type callNode struct {
+	name string
+	fn   func(args []interface{}) (interface{}, error)
+	args []exprNode
+}
+
+func (n callNode) eval(entry LogEntry) (interface{}, error) {
+	args := make([]interface{}, len(n.args))
+	for i, arg := range n.args {
+		v, err := arg.eval(entry)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = v
+	}
+	v, err := n.fn(args)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", n.name, err)
+	}
+	return v, nil
+}
+
+// exprToken is a lexical token of an expression
+type exprToken struct {
+	kind rune // 'n' number, 's' string, 'i' identifier, 'o' operator
+	text string
+}
+
+// exprParser is a recursive descent parser for expressions
+type exprParser struct {
+	src    string
+	tokens []exprToken
+	pos    int
+}
+
+// exprOperators are the operator tokens, longest first
+var exprOperators = []string{"&&", "||", "==", "!=", "<=", ">=", "<", ">", "+", "-", "*", "/", "%", "!", "(", ")", ","}
+
+func (p *exprParser) tokenize() error {
+	s := p.src
+	for i := 0; i < len(s); {
+		c := rune(s[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case unicode.IsDigit(c) || c == '.' && i+1 < len(s) && unicode.IsDigit(rune(s[i+1])):
+			start := i
+			for i < len(s) && (unicode.IsDigit(rune(s[i])) || s[i] == '.') {
+				i++
+			}
+			p.tokens = append(p.tokens, exprToken{'n', s[start:i]})
+		case c == '"' || c == '\'':
+			end := strings.IndexRune(s[i+1:], c)
+			if end < 0 {
+				return fmt.Errorf("unterminated string")
+			}
+			p.tokens = append(p.tokens, exprToken{'s', s[i+1 : i+1+end]})
+			i += end + 2
+		case unicode.IsLetter(c) || c == '_' || c == '@':
+			start := i
+			for i < len(s) && (unicode.IsLetter(rune(s[i])) || unicode.IsDigit(rune(s[i])) || strings.ContainsRune("_@.", rune(s[i]))) {
+				i++
+			}
+			p.tokens = append(p.tokens, exprToken{'i', s[start:i]})
+		default:
+			matched := false
+			for _, op := range exprOperators {
+				if strings.HasPrefix(s[i:], op) {
+					p.tokens = append(p.tokens, exprToken{'o', op})
+					i += len(op)
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return fmt.Errorf("unexpected character %q", c)
+			}
+		}
+	}
+	return nil
+}
+
+// accept consumes the next token if it is one of the given operators
+func (p *exprParser) accept(ops ...string) (string, bool) {
+	if p.pos >= len(p.tokens) || p.tokens[p.pos].kind != 'o' {
+		return "", false
+	}
+	for _, op := range ops {
+		if p.tokens[p.pos].text == op {
+			p.pos++
+			return op, true
+		}
+	}
+	return "", false
+}
+
+// parseBinary parses a left-associative chain of the given operators
+func (p *exprParser) parseBinary(next func() (exprNode, error), ops ...string) (exprNode, error) {
+	left, err := next()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := p.accept(ops...)
+		if !ok {
+			return left, nil
+		}
+		right, err := next()
+		if err != nil {
+			return nil, err
+		}
+		left = binaryNode{op: op, left: left, right: right}
+	}
+}
+
+func (p *exprParser) parseOr() (exprNode, error) {
+	return p.parseBinary(p.parseAnd, "||")
+}
+
+func (p *exprParser) parseAnd() (exprNode, error) {
+	return p.parseBinary(p.parseComparison, "&&")
+}
+
+func (p *exprParser) parseComparison() (exprNode, error) {
+	return p.parseBinary(p.parseSum, "==", "!=", "<=", ">=", "<", ">")
+}
+
+func (p *exprParser) parseSum() (exprNode, error) {
+	return p.parseBinary(p.parseProduct, "+", "-")
+}
+
+func (p *exprParser) parseProduct() (exprNode, error) {
+	return p.parseBinary(p.parseUnary, "*", "/", "%")
+}
+
+func (p *exprParser) parseUnary() (exprNode, error) {
+	if op, ok := p.accept("-", "!"); ok {
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return unaryNode{op: op, operand: operand}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *exprParser) parsePrimary() (exprNode, error) {
+	if p.pos >= len(p.tokens) {
+		return nil, fmt.Errorf("unexpected end of expression")
+	}
+	if _, ok := p.accept("("); ok {
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := p.accept(")"); !ok {
+			return nil, fmt.Errorf("missing )")
+		}
+		return node, nil
+	}
+
+	tok := p.tokens[p.pos]
+	p.pos++
+	switch tok.kind {
+	case 'n':
+		n, err := strconv.ParseFloat(tok.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q", tok.text)
+		}
+		return literalNode{n}, nil
+	case 's':
+		return literalNode{tok.text}, nil
+	case 'i':
+		if _, ok := p.accept("("); ok {
+			return p.parseCall(tok.text)
+		}
+		switch tok.text {
+		case "true":
+			return literalNode{true}, nil
+		case "false":
+			return literalNode{false}, nil
+		}
+		return fieldNode{tok.text}, nil
+	}
+	return nil, fmt.Errorf("unexpected %q", tok.text)
+}
+
+// parseCall parses the arguments of a function call after the opening parenthesis
+func (p *exprParser) parseCall(name string) (exprNode, error) {
+	fn, ok := exprFuncs[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown function %q", name)
+	}
+	call := callNode{name: name, fn: fn}
+	if _, ok := p.accept(")"); ok {
+		return call, nil
+	}
+	for {
+		arg, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		call.args = append(call.args, arg)
+		if _, ok := p.accept(")"); ok {
+			return call, nil
+		}
+		if _, ok := p.accept(","); !ok {
+			return nil, fmt.Errorf("expected , or ) in call to %s", name)
+		}
+	}
+}
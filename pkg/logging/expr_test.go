@@ -0,0 +1,88 @@
+package logging
+
+import (
+	"testing"
+)
+
+func TestCompileExpr(t *testing.T) {
+	entry := LogEntry{
+		Level:   WARN,
+		Message: "slow request",
+		Fields: map[string]interface{}{
+			"duration_ms": float64(234),
+			"status":      float64(503),
+			"path":        "/api/Orders",
+			"size":        "2048",
+		},
+	}
+
+	tests := []struct {
+		expr       string
+		want       interface{}
+		wantErr    bool
+		wantEvalEr bool
+	}{
+		{expr: "ceil(duration_ms/100)*100", want: float64(300)},
+		{expr: "duration_ms / 1000", want: 0.234},
+		{expr: "-duration_ms + 4", want: float64(-230)},
+		{expr: "2 + 3 * 4 % 5", want: float64(4)},
+		{expr: "size / 1024", want: float64(2)},
+		{expr: "status >= 500 && status < 600", want: true},
+		{expr: "!(status == 200) || false", want: true},
+		{expr: `if(status >= 500, "server", "client")`, want: "server"},
+		{expr: "lower(path) + '?v=' + 2", want: "/api/orders?v=2"},
+		{expr: "max(duration_ms, 500, 100)", want: float64(500)},
+		{expr: "len(message)", want: float64(12)},
+		{expr: `level == "WARN"`, want: true},
+		{expr: "missing_field * 2", wantEvalEr: true},
+		{expr: "duration_ms / 0", wantEvalEr: true},
+		{expr: "ceil(path)", wantEvalEr: true},
+		{expr: "ceil(duration_ms", wantErr: true},
+		{expr: "sqrt(4)", wantErr: true},
+		{expr: "1 +", wantErr: true},
+		{expr: "1 2", wantErr: true},
+		{expr: `"unterminated`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			expr, err := CompileExpr(tt.expr)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CompileExpr() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			got, err := expr.Eval(entry)
+			if (err != nil) != tt.wantEvalEr {
+				t.Fatalf("Eval() error = %v, wantErr %v", err, tt.wantEvalEr)
+			}
+			if !tt.wantEvalEr && got != tt.want {
+				t.Errorf("Eval() = %v (%T), want %v (%T)", got, got, tt.want, tt.want)
+			}
+		})
+	}
+}
+
+func TestDeriveField(t *testing.T) {
+	expr, err := CompileExpr("ceil(duration_ms/100)*100")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := NewPipeline(mustFieldFilter(t, "latency_bucket>=300"))
+	p.Transform(DeriveField("latency_bucket", expr))
+
+	if _, keep := p.Process(`{"msg":"slow","duration_ms":250}`); !keep {
+		t.Error("derived field was not available to the filter")
+	}
+	if _, keep := p.Process(`{"msg":"fast","duration_ms":20}`); keep {
+		t.Error("entry below the bucket was kept")
+	}
+
+	derive := NewPipeline()
+	derive.Transform(DeriveField("latency_bucket", expr))
+	got, keep := derive.Process(`{"msg":"no duration"}`)
+	if !keep || got.Fields["latency_bucket"] != nil {
+		t.Errorf("entry without the input field: keep = %v, latency_bucket = %v", keep, got.Fields["latency_bucket"])
+	}
+}
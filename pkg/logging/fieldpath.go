@@ -0,0 +1,24 @@
+package logging
+
+// FieldValue returns the value of a field by name, or by a dotted path such
+// as kubernetes.labels.app into nested JSON objects when no field has the
+// name itself. Keys of nested objects may contain dots too, as the names of
+// labels like app.kubernetes.io/name do.
+func FieldValue(fields map[string]interface{}, path string) (interface{}, bool) {
+	if v, ok := fields[path]; ok {
+		return v, true
+	}
+	for i := 0; i < len(path); i++ {
+		if path[i] != '.' {
+			continue
+		}
+		nested, ok := fields[path[:i]].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if v, ok := FieldValue(nested, path[i+1:]); ok {
+			return v, true
+		}
+	}
+	return nil, false
+}
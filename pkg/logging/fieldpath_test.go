@@ -0,0 +1,47 @@
+package logging
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFieldValue(t *testing.T) {
+	fields := map[string]interface{}{
+		"status": float64(200),
+		"kubernetes": map[string]interface{}{
+			"labels": map[string]interface{}{
+				"app":                    "api",
+				"app.kubernetes.io/name": "payments",
+			},
+		},
+		"http.request.method": "GET",
+		"http": map[string]interface{}{
+			"request": map[string]interface{}{"method": "POST", "bytes": float64(512)},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		path   string
+		want   interface{}
+		wantOK bool
+	}{
+		{"top-level field", "status", float64(200), true},
+		{"nested field", "kubernetes.labels.app", "api", true},
+		{"nested key with dots", "kubernetes.labels.app.kubernetes.io/name", "payments", true},
+		{"flat dotted field wins", "http.request.method", "GET", true},
+		{"nested beside a flat dotted field", "http.request.bytes", float64(512), true},
+		{"nested object", "kubernetes.labels", fields["kubernetes"].(map[string]interface{})["labels"], true},
+		{"missing nested field", "kubernetes.labels.team", nil, false},
+		{"path through a value", "status.code", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := FieldValue(fields, tt.path)
+			if ok != tt.wantOK || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FieldValue(%q) = %v, %v, want %v, %v", tt.path, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
@@ -2,15 +2,344 @@ package logging
 
 import (
 	"bufio"
+	"fmt"
 	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync/atomic"
 )
 
+// Filter decides whether a parsed log entry should be passed on to the output
+type Filter interface {
+	// Match reports whether the entry should be kept
+	Match(entry LogEntry) bool
+}
+
+// FilterFunc adapts an ordinary function to the Filter interface
+type FilterFunc func(entry LogEntry) bool
+
+// Match implements the Filter interface
+func (f FilterFunc) Match(entry LogEntry) bool {
+	return f(entry)
+}
+
+// FilterChain is a list of filters that must all match for an entry to be kept.
+// An empty chain keeps every entry.
+type FilterChain []Filter
+
+// Match implements the Filter interface
+func (c FilterChain) Match(entry LogEntry) bool {
+	for _, f := range c {
+		if !f.Match(entry) {
+			return false
+		}
+	}
+	return true
+}
+
+// AnyFilter is a list of filters of which at least one must match for an
+// entry to be kept. An empty list keeps every entry.
+type AnyFilter []Filter
+
+// Match implements the Filter interface
+func (a AnyFilter) Match(entry LogEntry) bool {
+	if len(a) == 0 {
+		return true
+	}
+	for _, f := range a {
+		if f.Match(entry) {
+			return true
+		}
+	}
+	return false
+}
+
+// Not returns a filter keeping the entries f drops
+func Not(f Filter) Filter {
+	return FilterFunc(func(entry LogEntry) bool {
+		return !f.Match(entry)
+	})
+}
+
+// LevelFilter keeps entries at or above a minimum level. Loggers overrides
+// the minimum for a logger and its children, e.g. io.netty: ERROR also
+// applies to io.netty.channel; the most specific logger wins.
+type LevelFilter struct {
+	Min     LogLevel
+	Loggers map[string]LogLevel
+}
+
+// Match implements the Filter interface
+func (f LevelFilter) Match(entry LogEntry) bool {
+	return entry.Level >= f.minLevel(entry)
+}
+
+// minLevel returns the minimum level applying to the entry's logger
+func (f LevelFilter) minLevel(entry LogEntry) LogLevel {
+	if len(f.Loggers) == 0 {
+		return f.Min
+	}
+	name := LoggerName(entry)
+	min, matched := f.Min, ""
+	for logger, level := range f.Loggers {
+		if len(logger) > len(matched) && isLoggerOrChild(name, logger) {
+			min, matched = level, logger
+		}
+	}
+	return min
+}
+
+// isLoggerOrChild reports whether name is the logger parent or one of its children
+func isLoggerOrChild(name, parent string) bool {
+	if !strings.HasPrefix(name, parent) {
+		return false
+	}
+	rest := name[len(parent):]
+	return rest == "" || strings.ContainsAny(rest[:1], "./:")
+}
+
+// GrepFilter keeps entries whose raw line matches a regular expression, or
+// contains a fixed string; inverted, it keeps the other entries
+type GrepFilter struct {
+	Pattern *regexp.Regexp
+	// Invert keeps the entries that don't match instead
+	Invert bool
+
+	// contains matches a fixed string without a regular expression
+	contains func(line string) bool
+}
+
+// GrepOptions change how the pattern of a GrepFilter is matched
+type GrepOptions struct {
+	// IgnoreCase matches letters regardless of their case
+	IgnoreCase bool
+	// Fixed matches the pattern as a literal string instead of a regular expression
+	Fixed bool
+	// Invert keeps the entries that don't match, like grep -v
+	Invert bool
+}
+
+// NewGrepFilter compiles the pattern and returns a GrepFilter
+func NewGrepFilter(pattern string) (*GrepFilter, error) {
+	return NewGrepFilterWithOptions(pattern, GrepOptions{})
+}
+
+// NewGrepFilterWithOptions returns a GrepFilter matching the pattern as the
+// options describe. Fixed strings are searched for directly, which is several
+// times faster than a regular expression on busy streams.
+func NewGrepFilterWithOptions(pattern string, opts GrepOptions) (*GrepFilter, error) {
+	expr := pattern
+	if opts.Fixed {
+		switch {
+		case !opts.IgnoreCase:
+			return &GrepFilter{Invert: opts.Invert, contains: func(line string) bool {
+				return strings.Contains(line, pattern)
+			}}, nil
+		case isASCII(pattern):
+			lower := strings.ToLower(pattern)
+			return &GrepFilter{Invert: opts.Invert, contains: func(line string) bool {
+				return containsFoldASCII(line, lower)
+			}}, nil
+		}
+		// Unicode case folding is left to the regular expression engine
+		expr = regexp.QuoteMeta(pattern)
+	}
+	if opts.IgnoreCase {
+		expr = "(?i)" + expr
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid grep pattern %q: %w", pattern, err)
+	}
+	return &GrepFilter{Pattern: re, Invert: opts.Invert}, nil
+}
+
+// Match implements the Filter interface
+func (f *GrepFilter) Match(entry LogEntry) bool {
+	line := entry.RawLine
+	if line == "" {
+		line = entry.Message
+	}
+	if f.contains != nil {
+		return f.contains(line) != f.Invert
+	}
+	return f.Pattern.MatchString(line) != f.Invert
+}
+
+// isASCII reports whether s only holds ASCII characters
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= 0x80 {
+			return false
+		}
+	}
+	return true
+}
+
+// containsFoldASCII reports whether s contains the lower case ASCII string
+// substr regardless of case, without allocating
+func containsFoldASCII(s, substr string) bool {
+	n := len(substr)
+	if n == 0 {
+		return true
+	}
+	first, upper := substr[0], substr[0]
+	if 'a' <= first && first <= 'z' {
+		upper -= 'a' - 'A'
+	}
+	for i := 0; i+n <= len(s); i++ {
+		if c := s[i]; c != first && c != upper {
+			continue
+		}
+		if equalFoldASCII(s[i:i+n], substr) {
+			return true
+		}
+	}
+	return false
+}
+
+// equalFoldASCII reports whether s equals the lower case ASCII string lower
+// regardless of case
+func equalFoldASCII(s, lower string) bool {
+	for i := 0; i < len(lower); i++ {
+		c := s[i]
+		if 'A' <= c && c <= 'Z' {
+			c += 'a' - 'A'
+		}
+		if c != lower[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// fieldOperators lists the supported comparison operators, longest first so
+// that ">=" is not mistaken for ">"
+var fieldOperators = []string{"!=", ">=", "<=", "=", "~", ">", "<"}
+
+// FieldFilter compares a single entry field against a value.
+// Supported operators are =, !=, ~ (regex match), >, >=, < and <=.
+type FieldFilter struct {
+	Key      string
+	Operator string
+	Value    string
+
+	pattern *regexp.Regexp
+	number  float64
+}
+
+// ParseFieldFilter parses an expression such as "status>=500", "user=alice"
+// or "path~^/api" into a FieldFilter
+func ParseFieldFilter(expr string) (*FieldFilter, error) {
+	op, idx := findFieldOperator(expr)
+	if idx <= 0 {
+		return nil, fmt.Errorf("invalid field expression %q (expected key=value, key!=value, key~regex or a numeric comparison)", expr)
+	}
+
+	f := &FieldFilter{
+		Key:      strings.TrimSpace(expr[:idx]),
+		Operator: op,
+		Value:    strings.TrimSpace(expr[idx+len(op):]),
+	}
+	switch op {
+	case "~":
+		re, err := regexp.Compile(f.Value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern in field expression %q: %w", expr, err)
+		}
+		f.pattern = re
+	case ">", ">=", "<", "<=":
+		n, err := strconv.ParseFloat(f.Value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("field expression %q requires a numeric value", expr)
+		}
+		f.number = n
+	}
+	return f, nil
+}
+
+// findFieldOperator returns the first operator in expr and its position,
+// or -1 if the expression contains no operator
+func findFieldOperator(expr string) (string, int) {
+	for i := range expr {
+		for _, op := range fieldOperators {
+			if strings.HasPrefix(expr[i:], op) {
+				return op, i
+			}
+		}
+	}
+	return "", -1
+}
+
+// Match implements the Filter interface
+func (f *FieldFilter) Match(entry LogEntry) bool {
+	val, ok := FieldValue(entry.Fields, f.Key)
+	if !ok {
+		// A missing field can only satisfy a negative comparison
+		return f.Operator == "!="
+	}
+	str := fmt.Sprintf("%v", val)
+
+	switch f.Operator {
+	case "=":
+		return str == f.Value
+	case "!=":
+		return str != f.Value
+	case "~":
+		return f.pattern.MatchString(str)
+	}
+
+	n, err := strconv.ParseFloat(str, 64)
+	if err != nil {
+		return false
+	}
+	switch f.Operator {
+	case ">":
+		return n > f.number
+	case ">=":
+		return n >= f.number
+	case "<":
+		return n < f.number
+	case "<=":
+		return n <= f.number
+	}
+	return false
+}
+
+// SampleFilter keeps one out of every Rate entries it sees.
+// It is safe for concurrent use.
+type SampleFilter struct {
+	Rate  uint64
+	count uint64
+}
+
+// NewSampleFilter returns a filter that keeps every rate-th entry
+func NewSampleFilter(rate uint64) *SampleFilter {
+	return &SampleFilter{Rate: rate}
+}
+
+// Match implements the Filter interface
+func (f *SampleFilter) Match(entry LogEntry) bool {
+	if f.Rate <= 1 {
+		return true
+	}
+	n := atomic.AddUint64(&f.count, 1)
+	return (n-1)%f.Rate == 0
+}
+
+// FilterAndFormatLogs reads log lines from reader, keeps those at or above
+// filterLevel and writes them formatted to writer.
+//
+// Deprecated: build a Pipeline with the desired filters instead.
 func FilterAndFormatLogs(reader io.Reader, writer io.Writer, filterLevel LogLevel) error {
+	pipeline := NewPipeline(LevelFilter{Min: filterLevel})
 	scanner := bufio.NewScanner(reader)
 	for scanner.Scan() {
-		entry := ParseLogEntry(scanner.Text())
-		if entry.Level >= filterLevel {
-			logLevelColors[entry.Level].Fprintf(writer, "%s\n", entry.Message)
+		if entry, ok := pipeline.Process(scanner.Text()); ok {
+			if _, err := fmt.Fprintln(writer, FormatEntry(entry)); err != nil {
+				return err
+			}
 		}
 	}
 	return scanner.Err()
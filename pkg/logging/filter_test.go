@@ -0,0 +1,254 @@
+package logging
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseFieldFilter(t *testing.T) {
+	tests := []struct {
+		name    string
+		expr    string
+		wantKey string
+		wantOp  string
+		wantVal string
+		wantErr bool
+	}{
+		{"equality", "user=alice", "user", "=", "alice", false},
+		{"inequality", "user!=bob", "user", "!=", "bob", false},
+		{"regex", "path~^/api", "path", "~", "^/api", false},
+		{"greater or equal", "status>=500", "status", ">=", "500", false},
+		{"less than", "duration<10", "duration", "<", "10", false},
+		{"non numeric comparison", "status>abc", "", "", "", true},
+		{"invalid regex", "path~[", "", "", "", true},
+		{"missing operator", "status", "", "", "", true},
+		{"missing key", "=value", "", "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseFieldFilter(tt.expr)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseFieldFilter() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if got.Key != tt.wantKey || got.Operator != tt.wantOp || got.Value != tt.wantVal {
+				t.Errorf("ParseFieldFilter() = %s %s %s, want %s %s %s",
+					got.Key, got.Operator, got.Value, tt.wantKey, tt.wantOp, tt.wantVal)
+			}
+		})
+	}
+}
+
+func TestPipeline_Process(t *testing.T) {
+	grep, err := NewGrepFilter("checkout")
+	if err != nil {
+		t.Fatalf("NewGrepFilter() error = %v", err)
+	}
+	status, err := ParseFieldFilter("status>=500")
+	if err != nil {
+		t.Fatalf("ParseFieldFilter() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		filters []Filter
+		input   string
+		want    bool
+	}{
+		{
+			name:    "No filters keeps everything",
+			filters: nil,
+			input:   "2024-03-15 12:19:57 DEBUG starting",
+			want:    true,
+		},
+		{
+			name:    "Level below minimum is dropped",
+			filters: []Filter{LevelFilter{Min: WARN}},
+			input:   "2024-03-15 12:19:57 INFO starting",
+			want:    false,
+		},
+		{
+			name:    "Level at minimum is kept",
+			filters: []Filter{LevelFilter{Min: WARN}},
+			input:   "2024-03-15 12:19:57 WARN slow request",
+			want:    true,
+		},
+		{
+			name:    "Grep and field filters must both match",
+			filters: []Filter{grep, status},
+			input:   `{"level":"error","msg":"checkout failed","status":503}`,
+			want:    true,
+		},
+		{
+			name:    "Field filter rejects lower status",
+			filters: []Filter{grep, status},
+			input:   `{"level":"info","msg":"checkout ok","status":200}`,
+			want:    false,
+		},
+		{
+			name:    "Field filter rejects missing field",
+			filters: []Filter{status},
+			input:   "plain text without fields",
+			want:    false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, got := NewPipeline(tt.filters...).Process(tt.input)
+			if got != tt.want {
+				t.Errorf("Process() kept = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLevelFilter_Loggers(t *testing.T) {
+	f := LevelFilter{Min: INFO, Loggers: map[string]LogLevel{
+		"io.netty":          ERROR,
+		"myapp.db":          DEBUG,
+		"myapp.db.migrator": WARN,
+	}}
+
+	tests := []struct {
+		name string
+		line string
+		want bool
+	}{
+		{"default minimum", `{"level":"debug","logger":"myapp.api","msg":"hi"}`, false},
+		{"raised for logger", `{"level":"warn","logger":"io.netty","msg":"hi"}`, false},
+		{"raised for child logger", `{"level":"error","logger":"io.netty.channel","msg":"hi"}`, true},
+		{"lowered for logger", `{"level":"debug","logger":"myapp.db.pool","msg":"hi"}`, true},
+		{"most specific logger wins", `{"level":"info","logger":"myapp.db.migrator","msg":"hi"}`, false},
+		{"prefix is not a child", `{"level":"debug","logger":"myapp.dbx","msg":"hi"}`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := f.Match(ParseLogEntry(tt.line)); got != tt.want {
+				t.Errorf("Match() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSampleFilter_Match(t *testing.T) {
+	filter := NewSampleFilter(3)
+	kept := 0
+	for i := 0; i < 9; i++ {
+		if filter.Match(LogEntry{}) {
+			kept++
+		}
+	}
+	if kept != 3 {
+		t.Errorf("SampleFilter kept %d of 9 entries, want 3", kept)
+	}
+}
+
+func TestTraceFilter_Match(t *testing.T) {
+	f := TraceFilter{ID: "4bf92f3577b34da6"}
+
+	tests := []struct {
+		name string
+		line string
+		want bool
+	}{
+		{"trace_id field", `{"msg":"charge","trace_id":"4bf92f3577b34da6"}`, true},
+		{"camel case field", `{"msg":"charge","traceId":"4bf92f3577b34da6"}`, true},
+		{"traceparent header", `{"msg":"in","traceparent":"00-4bf92f3577b34da6-00f067aa0ba902b7-01"}`, true},
+		{"plain text", "INFO handled request 4bf92f3577b34da6 in 12ms", true},
+		{"other trace", `{"msg":"charge","trace_id":"a3ce929d0e0e4736"}`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := f.Match(ParseLogEntry(tt.line)); got != tt.want {
+				t.Errorf("Match() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewGrepFilterWithOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		opts    GrepOptions
+		line    string
+		want    bool
+	}{
+		{"regex is case sensitive", "time(out)?", GrepOptions{}, "upstream Timeout", false},
+		{"regex ignoring case", "time(out)?", GrepOptions{IgnoreCase: true}, "upstream Timeout", true},
+		{"fixed string", "a.b[0]", GrepOptions{Fixed: true}, "key a.b[0] missing", true},
+		{"fixed string is not a regex", "a.b", GrepOptions{Fixed: true}, "key axb missing", false},
+		{"fixed string is case sensitive", "timeout", GrepOptions{Fixed: true}, "upstream TIMEOUT", false},
+		{"fixed string ignoring case", "TimeOut", GrepOptions{Fixed: true, IgnoreCase: true}, "upstream TIMEOUT after 30s", true},
+		{"fixed string ignoring case at the end", "30S", GrepOptions{Fixed: true, IgnoreCase: true}, "timeout after 30s", true},
+		{"fixed string ignoring case not found", "timeouts", GrepOptions{Fixed: true, IgnoreCase: true}, "upstream TIMEOUT", false},
+		{"fixed string ignoring case with symbols", "[WARN]", GrepOptions{Fixed: true, IgnoreCase: true}, "[warn] disk", true},
+		{"fixed unicode string ignoring case", "ÜBER", GrepOptions{Fixed: true, IgnoreCase: true}, "über alles", true},
+		{"inverted regex drops matches", "health", GrepOptions{Invert: true}, "GET /health 200", false},
+		{"inverted regex keeps the rest", "health", GrepOptions{Invert: true}, "GET /api 200", true},
+		{"inverted fixed string", "/health", GrepOptions{Fixed: true, Invert: true}, "GET /health 200", false},
+		{"inverted fixed string ignoring case", "/HEALTH", GrepOptions{Fixed: true, IgnoreCase: true, Invert: true}, "GET /api 200", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := NewGrepFilterWithOptions(tt.pattern, tt.opts)
+			if err != nil {
+				t.Fatalf("NewGrepFilterWithOptions() error = %v", err)
+			}
+			if got := f.Match(LogEntry{RawLine: tt.line}); got != tt.want {
+				t.Errorf("Match(%q) = %v, want %v", tt.line, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewGrepFilterWithOptions_Invalid(t *testing.T) {
+	_, err := NewGrepFilterWithOptions("(unclosed", GrepOptions{IgnoreCase: true})
+	if err == nil || !strings.Contains(err.Error(), `"(unclosed"`) {
+		t.Errorf("NewGrepFilterWithOptions() error = %v, want one quoting the pattern", err)
+	}
+	// Fixed strings are never invalid
+	if _, err := NewGrepFilterWithOptions("(unclosed", GrepOptions{Fixed: true}); err != nil {
+		t.Errorf("NewGrepFilterWithOptions() fixed error = %v", err)
+	}
+}
+
+func TestAnyFilter(t *testing.T) {
+	timeout, err := NewGrepFilter("timeout")
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkout, err := NewGrepFilter("checkout-service")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		filter Filter
+		line   string
+		want   bool
+	}{
+		{"any with one match", AnyFilter{timeout, checkout}, "timeout calling payments", true},
+		{"any without a match", AnyFilter{timeout, checkout}, "GET /api 200", false},
+		{"empty any keeps everything", AnyFilter{}, "GET /api 200", true},
+		{"all with one match", FilterChain{timeout, checkout}, "timeout calling payments", false},
+		{"all with every match", FilterChain{timeout, checkout}, "checkout-service: timeout calling payments", true},
+		{"not any", Not(AnyFilter{timeout, checkout}), "GET /api 200", true},
+		{"not all", Not(FilterChain{timeout, checkout}), "timeout calling payments", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.Match(LogEntry{RawLine: tt.line}); got != tt.want {
+				t.Errorf("Match(%q) = %v, want %v", tt.line, got, tt.want)
+			}
+		})
+	}
+}
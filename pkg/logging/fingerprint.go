@@ -0,0 +1,96 @@
+package logging
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// fingerprintRules replace the variable parts of a message, in order, so
+// messages differing only in IDs, addresses or numbers share a fingerprint
+var fingerprintRules = []struct {
+	pattern     *regexp.Regexp
+	replacement string
+}{
+	{regexp.MustCompile(`(?i)\b[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}\b`), "<uuid>"},
+	{regexp.MustCompile(`\b\d{1,3}(?:\.\d{1,3}){3}(?::\d+)?\b`), "<ip>"},
+	{regexp.MustCompile(`(?i)\b0x[0-9a-f]+\b`), "<hex>"},
+	{regexp.MustCompile(`(?i)\b[0-9a-f]*\d[0-9a-f]*[a-f][0-9a-f]*\b|\b[0-9a-f]*[a-f][0-9a-f]*\d[0-9a-f]*\b`), "<hex>"},
+	{regexp.MustCompile(`"[^"]*"|'[^']*'`), `"<str>"`},
+	{regexp.MustCompile(`\d+(?:\.\d+)?`), "<n>"},
+	{regexp.MustCompile(`\s+`), " "},
+}
+
+// Fingerprint normalizes a message into a pattern shared by messages that differ
+// only in variable parts, e.g. "timeout after 30s to 10.0.0.5:5432" becomes
+// "timeout after <n>s to <ip>"
+func Fingerprint(message string) string {
+	for _, rule := range fingerprintRules {
+		message = rule.pattern.ReplaceAllString(message, rule.replacement)
+	}
+	return strings.TrimSpace(message)
+}
+
+// maxFingerprints bounds the number of distinct fingerprints an ErrorCounter tracks
+const maxFingerprints = 10000
+
+// ErrorCount is the number of errors sharing a fingerprint, with the message
+// of the first one as an example
+type ErrorCount struct {
+	Fingerprint string
+	Example     string
+	Count       uint64
+}
+
+// ErrorCounter counts ERROR entries by the fingerprint of their message. Like
+// LevelCounter it implements Transform and is safe for concurrent use.
+type ErrorCounter struct {
+	mu     sync.Mutex
+	counts map[string]*ErrorCount
+}
+
+// Apply implements the Transform interface; it never drops entries
+func (c *ErrorCounter) Apply(entry *LogEntry) bool {
+	if entry.Level != ERROR {
+		return true
+	}
+	message := entry.Message
+	if message == "" {
+		message = entry.RawLine
+	}
+	fp := Fingerprint(message)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.counts == nil {
+		c.counts = make(map[string]*ErrorCount)
+	}
+	if count, ok := c.counts[fp]; ok {
+		count.Count++
+	} else if len(c.counts) < maxFingerprints {
+		c.counts[fp] = &ErrorCount{Fingerprint: fp, Example: message, Count: 1}
+	}
+	return true
+}
+
+// Top returns the n most frequent fingerprints, most frequent first
+func (c *ErrorCounter) Top(n int) []ErrorCount {
+	c.mu.Lock()
+	counts := make([]ErrorCount, 0, len(c.counts))
+	for _, count := range c.counts {
+		counts = append(counts, *count)
+	}
+	c.mu.Unlock()
+
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Count != counts[j].Count {
+			return counts[i].Count > counts[j].Count
+		}
+		return counts[i].Fingerprint < counts[j].Fingerprint
+	})
+	if len(counts) > n {
+		counts = counts[:n]
+	}
+	return counts
+}
@@ -0,0 +1,60 @@
+package logging
+
+import (
+	"testing"
+)
+
+func TestFingerprint(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		want    string
+	}{
+		{"numbers and address", "timeout after 30s to 10.0.0.5:5432", "timeout after <n>s to <ip>"},
+		{"uuid", "order 3f2504e0-4f89-11d3-9a0c-0305e82c3301 not found", "order <uuid> not found"},
+		{"hex id", "trace 4bf92f3577b34da6 failed", "trace <hex> failed"},
+		{"quoted value", `user "alice" not authorized`, `user "<str>" not authorized`},
+		{"plain words are kept", "connection refused", "connection refused"},
+		{"whitespace collapsed", "  disk   full ", "disk full"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Fingerprint(tt.message); got != tt.want {
+				t.Errorf("Fingerprint(%q) = %q, want %q", tt.message, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestErrorCounter_Top(t *testing.T) {
+	lines := []string{
+		`{"level":"error","msg":"timeout after 30s to 10.0.0.5:5432"}`,
+		`{"level":"error","msg":"timeout after 12s to 10.0.0.7:5432"}`,
+		`{"level":"error","msg":"timeout after 5s to 10.0.0.9:5432"}`,
+		`{"level":"error","msg":"user \"alice\" not authorized"}`,
+		`{"level":"error","msg":"user \"bob\" not authorized"}`,
+		`{"level":"error","msg":"disk full"}`,
+		`{"level":"warn","msg":"disk almost full"}`,
+	}
+
+	c := &ErrorCounter{}
+	for _, line := range lines {
+		entry := ParseLogEntry(line)
+		c.Apply(&entry)
+	}
+
+	got := c.Top(2)
+	want := []ErrorCount{
+		{Fingerprint: "timeout after <n>s to <ip>", Example: "timeout after 30s to 10.0.0.5:5432", Count: 3},
+		{Fingerprint: `user "<str>" not authorized`, Example: `user "alice" not authorized`, Count: 2},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Top(2) = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Top(2)[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
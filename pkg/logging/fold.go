@@ -0,0 +1,85 @@
+package logging
+
+import (
+	"strings"
+)
+
+// Folder joins the continuation lines of a multi-line record, such as a stack
+// trace, into a single entry. A Folder holds state and serves a single stream.
+type Folder interface {
+	// Fold receives the next entry of the stream and returns the entries that
+	// are complete, which may be none while a record is being collected
+	Fold(entry LogEntry) []LogEntry
+	// Flush returns the record still being collected, if any
+	Flush() []LogEntry
+}
+
+// Stream processes the lines of a single log stream through a pipeline.
+// Unlike Pipeline.Process it folds multi-line records, so it must not be
+// shared between streams.
+type Stream struct {
+	pipeline *Pipeline
+	folders  []Folder
+}
+
+// NewStream creates a Stream with fresh instances of the pipeline's folders
+func (p *Pipeline) NewStream() *Stream {
+	s := &Stream{pipeline: p}
+	if p != nil {
+		for _, newFolder := range p.folders {
+			s.folders = append(s.folders, newFolder())
+		}
+	}
+	return s
+}
+
+// Process parses a line and returns the entries that are complete and
+// passed every filter
+func (s *Stream) Process(line string) []LogEntry {
+	trimmed := strings.TrimSpace(line)
+	if trimmed == "" {
+		return nil
+	}
+	entry := s.pipeline.parse(trimmed)
+	// The raw line keeps its indentation so folders can recognize continuation lines
+	entry.RawLine = strings.TrimRight(line, " \t\r\n")
+	return s.fold([]LogEntry{entry})
+}
+
+// Flush returns the records still being folded once the stream has ended
+func (s *Stream) Flush() []LogEntry {
+	var entries []LogEntry
+	for i, f := range s.folders {
+		// Records flushed by earlier folders still pass through later ones
+		entries = append(s.foldEach(i, entries), f.Flush()...)
+	}
+	return s.filter(entries)
+}
+
+// fold passes entries through every folder and then the rest of the pipeline
+func (s *Stream) fold(entries []LogEntry) []LogEntry {
+	for i := range s.folders {
+		entries = s.foldEach(i, entries)
+	}
+	return s.filter(entries)
+}
+
+// foldEach hands each entry to the folder at index i
+func (s *Stream) foldEach(i int, entries []LogEntry) []LogEntry {
+	var folded []LogEntry
+	for _, entry := range entries {
+		folded = append(folded, s.folders[i].Fold(entry)...)
+	}
+	return folded
+}
+
+// filter runs complete entries through the pipeline's transforms and filters
+func (s *Stream) filter(entries []LogEntry) []LogEntry {
+	var kept []LogEntry
+	for _, entry := range entries {
+		if entry, ok := s.pipeline.ProcessEntry(entry); ok {
+			kept = append(kept, entry)
+		}
+	}
+	return kept
+}
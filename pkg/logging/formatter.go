@@ -0,0 +1,45 @@
+package logging
+
+// Formatter renders parsed entries for output. An empty result means the
+// entry is not written.
+type Formatter interface {
+	Format(entry LogEntry) string
+}
+
+// FormatterFunc adapts an ordinary function to the Formatter interface
+type FormatterFunc func(entry LogEntry) string
+
+// Format implements the Formatter interface
+func (f FormatterFunc) Format(entry LogEntry) string {
+	return f(entry)
+}
+
+// TextFormatter renders entries as colored text for terminal display
+type TextFormatter struct {
+	// Humanize renders duration and byte count fields as e.g. 1.2s and 4.3MB.
+	// Only the display changes; filters still see the raw values.
+	Humanize bool
+	// AllFields shows every JSON field, including the level, timestamp and
+	// message fields the default view leaves out
+	AllFields bool
+	// Highlighter decides which messages are shown in the error color
+	// (optional, defaults to DefaultErrorKeywords)
+	Highlighter *Highlighter
+	// Icons starts every entry with the icon of its level from the theme
+	Icons bool
+	// TimeFormat renders timestamps (optional, defaults to DefaultTimeFormat)
+	TimeFormat TimeFormat
+}
+
+// Format implements the Formatter interface
+func (f TextFormatter) Format(entry LogEntry) string {
+	return formatLogEntry(entry, f)
+}
+
+// highlighter returns the formatter's Highlighter or the default one
+func (f TextFormatter) highlighter() *Highlighter {
+	if f.Highlighter != nil {
+		return f.Highlighter
+	}
+	return defaultHighlighter
+}
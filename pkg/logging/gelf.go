@@ -0,0 +1,117 @@
+package logging
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// gelfLevels maps levels to the syslog severities used by GELF
+var gelfLevels = map[LogLevel]int{
+	DEBUG: 7,
+	INFO:  6,
+	WARN:  4,
+	ERROR: 3,
+}
+
+// gelfInvalidKey matches characters not allowed in GELF additional field names
+var gelfInvalidKey = regexp.MustCompile(`[^\w.\-]`)
+
+// GELFFormatter renders entries as GELF 1.1 messages for Graylog. Fields and
+// the entry's source become additional fields such as _status and _pod.
+type GELFFormatter struct {
+	// Host is the host of entries without a pod, "kubelog" when empty
+	Host string
+}
+
+// NewGELFFormatter returns a GELFFormatter whose host is this machine's
+// hostname, or "kubelog" when it can't be determined
+func NewGELFFormatter() GELFFormatter {
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		host = "kubelog"
+	}
+	return GELFFormatter{Host: host}
+}
+
+// Format implements the Formatter interface
+func (f GELFFormatter) Format(entry LogEntry) string {
+	data, err := json.Marshal(gelfMessage(entry, f.Host))
+	if err != nil {
+		return fmt.Sprintf(`{"version":"1.1","host":"kubelog","short_message":%q,"_error":%q}`, entry.Message, err.Error())
+	}
+	return string(data)
+}
+
+// gelfMessage builds the GELF message for an entry, from host unless it
+// has a pod
+func gelfMessage(entry LogEntry, host string) map[string]interface{} {
+	msg := map[string]interface{}{
+		"version": "1.1",
+		"level":   gelfLevels[entry.Level],
+	}
+
+	// Folded tracebacks keep the exception as short_message and the whole record
+	// as full_message
+	short, _, _ := strings.Cut(entry.Message, "\n")
+	if short == "" {
+		// GELF requires a non-empty short_message
+		short = "-"
+	}
+	msg["short_message"] = short
+	if strings.Contains(entry.RawLine, "\n") {
+		msg["full_message"] = entry.RawLine
+	}
+	if !entry.Timestamp.IsZero() {
+		msg["timestamp"] = float64(entry.Timestamp.UnixNano()/int64(1e6)) / 1e3
+	}
+
+	if s := entry.Source; s != nil {
+		if s.Pod != "" {
+			host = s.Pod
+		}
+		for key, value := range map[string]string{
+			"namespace": s.Namespace,
+			"pod":       s.Pod,
+			"container": s.Container,
+			"node":      s.Node,
+		} {
+			if value != "" {
+				msg["_"+key] = value
+			}
+		}
+		for key, value := range s.Labels {
+			msg["_label_"+gelfInvalidKey.ReplaceAllString(key, "_")] = value
+		}
+	}
+	if host == "" {
+		host = "kubelog"
+	}
+	msg["host"] = host
+
+	for key, value := range entry.Fields {
+		name := "_" + gelfInvalidKey.ReplaceAllString(key, "_")
+		// _id is reserved by Graylog
+		if name == "_id" {
+			name = "_field_id"
+		}
+		if _, ok := msg[name]; ok {
+			continue
+		}
+		switch v := value.(type) {
+		case string, float64, int, int64, bool:
+			msg[name] = v
+		case []string:
+			msg[name] = strings.Join(v, "\n")
+		default:
+			data, err := json.Marshal(v)
+			if err != nil {
+				data = []byte(fmt.Sprintf("%v", v))
+			}
+			msg[name] = string(data)
+		}
+	}
+	return msg
+}
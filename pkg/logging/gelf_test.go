@@ -0,0 +1,80 @@
+package logging
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestGELFFormatter_Format(t *testing.T) {
+	entry := ParseLogEntry(`{"level":"warn","msg":"slow query","time":"2024-01-15T10:30:00.25Z","id":"q1","duration_ms":812,"db":{"name":"orders"}}`)
+	entry.Source = &Source{Namespace: "shop", Pod: "api-1", Container: "app", Labels: map[string]string{"app.kubernetes.io/name": "api"}}
+
+	var got map[string]interface{}
+	if err := json.Unmarshal([]byte(GELFFormatter{}.Format(entry)), &got); err != nil {
+		t.Fatalf("Format() returned invalid JSON: %v", err)
+	}
+	want := map[string]interface{}{
+		"version":                       "1.1",
+		"host":                          "api-1",
+		"short_message":                 "slow query",
+		"timestamp":                     1705314600.25,
+		"level":                         float64(4),
+		"_namespace":                    "shop",
+		"_pod":                          "api-1",
+		"_container":                    "app",
+		"_label_app.kubernetes.io_name": "api",
+		"_level":                        "warn",
+		"_msg":                          "slow query",
+		"_time":                         "2024-01-15T10:30:00.25Z",
+		"_field_id":                     "q1",
+		"_duration_ms":                  float64(812),
+		"_db":                           `{"name":"orders"}`,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Format() = %v, want %v", got, want)
+	}
+}
+
+func TestGELFFormatter_FoldedTraceback(t *testing.T) {
+	entry := LogEntry{
+		Level:   ERROR,
+		Message: "ValueError: bad input",
+		RawLine: "Traceback (most recent call last):\n  File \"app.py\", line 3, in <module>\nValueError: bad input",
+	}
+
+	var got map[string]interface{}
+	if err := json.Unmarshal([]byte(GELFFormatter{}.Format(entry)), &got); err != nil {
+		t.Fatalf("Format() returned invalid JSON: %v", err)
+	}
+	if got["short_message"] != entry.Message || got["full_message"] != entry.RawLine || got["level"] != float64(3) {
+		t.Errorf("Format() = %v", got)
+	}
+}
+
+func TestGELFFormatter_Host(t *testing.T) {
+	tests := []struct {
+		name   string
+		host   string
+		source *Source
+		want   string
+	}{
+		{"Pod of the entry", "build-7", &Source{Pod: "api-1"}, "api-1"},
+		{"Formatter host without a source", "build-7", nil, "build-7"},
+		{"Formatter host without a pod", "build-7", &Source{Namespace: "shop"}, "build-7"},
+		{"Fallback without a host", "", nil, "kubelog"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry := LogEntry{Level: INFO, Message: "ready", Source: tt.source}
+			var got map[string]interface{}
+			if err := json.Unmarshal([]byte(GELFFormatter{Host: tt.host}.Format(entry)), &got); err != nil {
+				t.Fatalf("Format() returned invalid JSON: %v", err)
+			}
+			if got["host"] != tt.want {
+				t.Errorf("host = %v, want %q", got["host"], tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package logging
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// DefaultErrorKeywords are the patterns marking the message of an entry
+// without a level of its own as an error. They match whole words, so
+// "0 errors" or "failedOver=false" aren't highlighted.
+var DefaultErrorKeywords = []string{`\berror\b`, `\bfailed\b`}
+
+// defaultHighlighter highlights with the default keywords
+var defaultHighlighter = &Highlighter{patterns: compileKeywords(DefaultErrorKeywords)}
+
+// Highlighter decides which messages are shown in the error color. The parsed
+// level comes first: ERROR entries are always highlighted and entries with any
+// other level logged never are. The messages of entries without a level are
+// highlighted when they match one of the keyword patterns.
+type Highlighter struct {
+	patterns []*regexp.Regexp
+}
+
+// NewHighlighter creates a Highlighter for the given case-insensitive keyword
+// patterns; with none, only ERROR entries are highlighted
+func NewHighlighter(keywords []string) (*Highlighter, error) {
+	h := &Highlighter{}
+	for _, keyword := range keywords {
+		pattern, err := regexp.Compile("(?i)" + keyword)
+		if err != nil {
+			return nil, fmt.Errorf("invalid error keyword %q: %w", keyword, err)
+		}
+		h.patterns = append(h.patterns, pattern)
+	}
+	return h, nil
+}
+
+// compileKeywords compiles built-in keyword patterns
+func compileKeywords(keywords []string) []*regexp.Regexp {
+	patterns := make([]*regexp.Regexp, len(keywords))
+	for i, keyword := range keywords {
+		patterns[i] = regexp.MustCompile("(?i)" + keyword)
+	}
+	return patterns
+}
+
+// Highlight reports whether the message of the entry is shown in the error color
+func (h *Highlighter) Highlight(entry LogEntry) bool {
+	if entry.Level == ERROR {
+		return true
+	}
+	if hasLevel(entry) {
+		return false
+	}
+	for _, pattern := range h.patterns {
+		if pattern.MatchString(entry.Message) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasLevel reports whether the level of an entry was logged rather than defaulted
+func hasLevel(entry LogEntry) bool {
+	if entry.Format == FormatJSON {
+		for _, field := range jsonLevelFields {
+			if _, ok := entry.Fields[field]; ok {
+				return true
+			}
+		}
+		return false
+	}
+	// Recognized text formats always carry a level
+	return entry.Logger != "" || plainTextLevel.MatchString(entry.RawLine)
+}
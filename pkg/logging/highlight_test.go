@@ -0,0 +1,63 @@
+package logging
+
+import "testing"
+
+func TestHighlighter_Highlight(t *testing.T) {
+	custom, err := NewHighlighter([]string{`timed? ?out`, `\bdenied\b`})
+	if err != nil {
+		t.Fatalf("NewHighlighter() error = %v", err)
+	}
+	none, err := NewHighlighter(nil)
+	if err != nil {
+		t.Fatalf("NewHighlighter() error = %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		highlighter *Highlighter
+		line        string
+		want        bool
+	}{
+		{"error level", defaultHighlighter, `{"level":"error","msg":"request done"}`, true},
+		{"parsed level wins over keywords", defaultHighlighter, `{"level":"info","msg":"retry failed, trying again"}`, false},
+		{"plain text level wins over keywords", defaultHighlighter, "INFO cache error rate is 0.1%", false},
+		{"keyword without a level", defaultHighlighter, `{"msg":"connection failed"}`, true},
+		{"plain text keyword without a level", defaultHighlighter, "dial tcp 10.0.0.5:5432: connect failed", true},
+		{"whole words only", defaultHighlighter, "processed 120 items, 0 errors", false},
+		{"custom keywords", custom, "upstream Timed out after 30s", true},
+		{"custom keywords replace the defaults", custom, "connection failed", false},
+		{"keywords disabled", none, "connection failed", false},
+		{"keywords disabled keep error level", none, "ERROR connection failed", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.highlighter.Highlight(ParseLogEntry(tt.line)); got != tt.want {
+				t.Errorf("Highlight(%q) = %v, want %v", tt.line, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewHighlighter_Invalid(t *testing.T) {
+	if _, err := NewHighlighter([]string{"(unclosed"}); err == nil {
+		t.Error("NewHighlighter() with an invalid pattern succeeded")
+	}
+}
+
+func TestParsePlainTextLog_LevelWholeWords(t *testing.T) {
+	tests := []struct {
+		line string
+		want LogLevel
+	}{
+		{"processed 120 items, 0 errors", DEBUG},
+		{"INFORMATIONAL notice", DEBUG},
+		{"[ERROR] disk full", ERROR},
+		{"WARNING: low memory", WARN},
+	}
+	for _, tt := range tests {
+		if got := ParseLogEntry(tt.line).Level; got != tt.want {
+			t.Errorf("ParseLogEntry(%q).Level = %s, want %s", tt.line, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package logging
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// durationUnits maps field name suffixes to the unit of their value
+var durationUnits = []struct {
+	suffix string
+	unit   time.Duration
+}{
+	{"_ns", time.Nanosecond},
+	{"_nanos", time.Nanosecond},
+	{"_us", time.Microsecond},
+	{"_micros", time.Microsecond},
+	{"_ms", time.Millisecond},
+	{"_millis", time.Millisecond},
+	{"ms", time.Millisecond},
+	{"_seconds", time.Second},
+	{"_sec", time.Second},
+	{"_s", time.Second},
+}
+
+// durationFields are field names holding durations without a unit suffix,
+// which are taken to be milliseconds as written by Envoy, Rails and most HTTP loggers
+var durationFields = map[string]bool{
+	"duration":              true,
+	"latency":               true,
+	"elapsed":               true,
+	"took":                  true,
+	"response_time":         true,
+	"upstream_service_time": true,
+	"view_runtime":          true,
+	"db_runtime":            true,
+}
+
+// byteFields are field names holding byte counts
+var byteFields = map[string]bool{
+	"bytes":          true,
+	"size":           true,
+	"bytes_sent":     true,
+	"bytes_received": true,
+	"content_length": true,
+	"response_size":  true,
+	"request_size":   true,
+	"body_size":      true,
+}
+
+// HumanizeField renders a numeric field value as a duration or byte size when
+// its name suggests one, e.g. duration_ms=1234 becomes 1.2s. It reports false
+// for other fields and non-numeric values.
+func HumanizeField(key string, value interface{}) (string, bool) {
+	n, ok := numericValue(value)
+	if !ok || n < 0 {
+		return "", false
+	}
+
+	name := strings.ToLower(key)
+	if byteFields[name] || strings.HasSuffix(name, "_bytes") {
+		return HumanizeBytes(n), true
+	}
+	if unit, ok := durationUnit(key); ok {
+		return HumanizeDuration(time.Duration(n * float64(unit))), true
+	}
+	return "", false
+}
+
+// durationUnit returns the unit of a field holding a duration, judging by its name
+func durationUnit(key string) (time.Duration, bool) {
+	name := strings.ToLower(key)
+	if durationFields[name] {
+		return time.Millisecond, true
+	}
+	for _, u := range durationUnits {
+		// Bare "ms" only counts in camel case, so durationMs matches but items does not
+		if strings.HasSuffix(name, u.suffix) && (u.suffix[0] == '_' || strings.HasSuffix(key, "Ms")) {
+			return u.unit, true
+		}
+	}
+	return 0, false
+}
+
+// Latency returns the duration logged by an entry, read from the first field
+// in key order whose name suggests a duration, e.g. duration_ms or latency
+func Latency(entry LogEntry) (time.Duration, bool) {
+	for _, key := range sortedKeys(entry.Fields) {
+		unit, ok := durationUnit(key)
+		if !ok {
+			continue
+		}
+		if n, ok := numericValue(entry.Fields[key]); ok && n >= 0 {
+			return time.Duration(n * float64(unit)), true
+		}
+	}
+	return 0, false
+}
+
+// HumanizeDuration formats a duration with a precision suited to its size,
+// e.g. 850µs, 12ms, 1.2s or 2m3s
+func HumanizeDuration(d time.Duration) string {
+	switch {
+	case d < time.Millisecond:
+		return d.Round(time.Microsecond).String()
+	case d < 10*time.Millisecond:
+		return strconv.FormatFloat(float64(d)/float64(time.Millisecond), 'f', 1, 64) + "ms"
+	case d < time.Second:
+		return d.Round(time.Millisecond).String()
+	case d < time.Minute:
+		return strconv.FormatFloat(d.Seconds(), 'f', 1, 64) + "s"
+	default:
+		return d.Round(time.Second).String()
+	}
+}
+
+// HumanizeBytes formats a byte count with a binary unit, e.g. 512B or 4.3MB
+func HumanizeBytes(n float64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%dB", int64(n))
+	}
+	exp := int(math.Log(n) / math.Log(unit))
+	if exp > 6 {
+		exp = 6
+	}
+	return fmt.Sprintf("%.1f%cB", n/math.Pow(unit, float64(exp)), "KMGTPE"[exp-1])
+}
+
+// numericValue converts a number or numeric string field value to a float
+func numericValue(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case string:
+		n, err := strconv.ParseFloat(v, 64)
+		return n, err == nil
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,75 @@
+package logging
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHumanizeField(t *testing.T) {
+	tests := []struct {
+		key    string
+		value  interface{}
+		want   string
+		wantOK bool
+	}{
+		{"duration_ms", float64(1234), "1.2s", true},
+		{"latency", float64(12), "12ms", true},
+		{"elapsed_ns", float64(850000), "850µs", true},
+		{"requestDurationMs", "3.5", "3.5ms", true},
+		{"timeout_seconds", float64(185), "3m5s", true},
+		{"bytes_sent", float64(4509715), "4.3MB", true},
+		{"response_bytes", float64(512), "512B", true},
+		{"items", float64(120), "", false},
+		{"duration", "fast", "", false},
+		{"user_id", float64(42), "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			got, ok := HumanizeField(tt.key, tt.value)
+			if ok != tt.wantOK || got != tt.want {
+				t.Errorf("HumanizeField(%q, %v) = %q, %v; want %q, %v", tt.key, tt.value, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestTextFormatter_Humanize(t *testing.T) {
+	entry := ParseLogEntry(`{"level":"info","msg":"done","duration_ms":2500}`)
+
+	if got := (TextFormatter{Humanize: true}).Format(entry); !strings.Contains(got, "2.5s") {
+		t.Errorf("Format() = %q, want it to contain 2.5s", got)
+	}
+	if got := FormatEntry(entry); !strings.Contains(got, "2500") {
+		t.Errorf("FormatEntry() = %q, want the raw value 2500", got)
+	}
+	if got := HumanizeDuration(90 * time.Minute); got != "1h30m0s" {
+		t.Errorf("HumanizeDuration() = %q, want 1h30m0s", got)
+	}
+}
+
+func TestLatency(t *testing.T) {
+	tests := []struct {
+		name   string
+		line   string
+		want   time.Duration
+		wantOK bool
+	}{
+		{"milliseconds suffix", `{"msg":"done","duration_ms":250}`, 250 * time.Millisecond, true},
+		{"seconds suffix", `{"msg":"done","elapsed_seconds":1.5}`, 1500 * time.Millisecond, true},
+		{"bare latency in milliseconds", `{"msg":"done","latency":"12"}`, 12 * time.Millisecond, true},
+		{"rails completed", `Completed 200 OK in 35ms (Views: 10.0ms)`, 35 * time.Millisecond, true},
+		{"not numeric", `{"msg":"done","duration":"-"}`, 0, false},
+		{"no duration field", `{"msg":"done","items":3}`, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := Latency(ParseLogEntry(tt.line))
+			if ok != tt.wantOK || got != tt.want {
+				t.Errorf("Latency() = %v, %v; want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
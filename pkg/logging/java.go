@@ -0,0 +1,180 @@
+package logging
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	// javaFrameRegex matches a stack frame, e.g. "\tat com.example.Api.get(Api.java:42)"
+	javaFrameRegex = regexp.MustCompile(`^\s+at (?P<method>[^\s(]+)\(.*\)$`)
+	// javaOmittedRegex matches the frames Java elides because they repeat the enclosing trace
+	javaOmittedRegex = regexp.MustCompile(`^\s+\.\.\. \d+ (?:more|common frames omitted)$`)
+	// javaCauseRegex matches the start of a nested cause or suppressed exception
+	javaCauseRegex = regexp.MustCompile(`^\s*(?:Caused by|Suppressed): (?P<exception>[\w$.]+)`)
+	// javaExceptionRegex matches the exception line that follows the message it was logged with
+	javaExceptionRegex = regexp.MustCompile(`^(?P<exception>(?:[a-zA-Z_$][\w$]*\.)+[\w$]*(?:Exception|Error|Throwable))(?::\s.*)?$`)
+)
+
+// javaFrameworkPackages are the packages of frames collapsed by JavaStackTraceFolder.Collapse
+var javaFrameworkPackages = []string{
+	"java.", "javax.", "jdk.", "sun.", "com.sun.", "jakarta.",
+	"org.springframework.", "org.apache.catalina.", "org.apache.coyote.", "org.apache.tomcat.",
+	"org.eclipse.jetty.", "io.netty.", "io.undertow.", "reactor.", "kotlin.", "kotlinx.coroutines.",
+	"org.hibernate.", "com.zaxxer.hikari.", "io.micrometer.", "org.glassfish.jersey.",
+}
+
+// JavaStackTraceFolder folds Java stack traces, including "Caused by:" chains,
+// into the entry they were logged with. The exception line is appended to the
+// message, the exception class is stored in the "exception" field, the
+// innermost cause in "root_cause" and the trace lines in "stack_trace".
+type JavaStackTraceFolder struct {
+	// Expand keeps the whole stack trace in the message
+	Expand bool
+	// Collapse replaces runs of framework frames, and frames repeated by
+	// recursion, with a single summary line
+	Collapse bool
+
+	head  *LogEntry
+	trace []string
+}
+
+// NewJavaStackTraceFolder creates a folder for Java stack traces
+func NewJavaStackTraceFolder(expand, collapse bool) Folder {
+	return &JavaStackTraceFolder{Expand: expand, Collapse: collapse}
+}
+
+// Fold implements the Folder interface. Since any entry may be followed by a
+// stack trace, each entry is held until the next one shows it is complete.
+func (f *JavaStackTraceFolder) Fold(entry LogEntry) []LogEntry {
+	if f.head != nil && f.isContinuation(entry.RawLine) {
+		f.trace = append(f.trace, entry.RawLine)
+		if len(f.trace) >= maxFoldedLines {
+			return f.Flush()
+		}
+		return nil
+	}
+	complete := f.Flush()
+	f.head = &entry
+	return complete
+}
+
+// Flush implements the Folder interface
+func (f *JavaStackTraceFolder) Flush() []LogEntry {
+	if f.head == nil {
+		return nil
+	}
+	entry := *f.head
+	if len(f.trace) > 0 {
+		entry = f.build(entry)
+	}
+	f.head, f.trace = nil, nil
+	return []LogEntry{entry}
+}
+
+// isContinuation reports whether a line belongs to the stack trace of the held entry
+func (f *JavaStackTraceFolder) isContinuation(line string) bool {
+	if javaFrameRegex.MatchString(line) || javaOmittedRegex.MatchString(line) || javaCauseRegex.MatchString(line) {
+		return true
+	}
+	// The exception line only directly follows the logged message
+	return len(f.trace) == 0 && javaExceptionRegex.MatchString(line)
+}
+
+// build folds the collected trace into the head entry
+func (f *JavaStackTraceFolder) build(head LogEntry) LogEntry {
+	entry := head
+	entry.Fields = make(map[string]interface{}, len(head.Fields)+3)
+	for k, v := range head.Fields {
+		entry.Fields[k] = v
+	}
+
+	trace := f.trace
+	if f.Collapse {
+		trace = collapseJavaFrames(trace)
+	}
+	entry.Fields["stack_trace"] = trace
+	entry.RawLine = strings.Join(append([]string{head.RawLine}, f.trace...), "\n")
+
+	// The exception is on the first trace line or, for uncaught exceptions
+	// ("Exception in thread ..."), on the head line itself
+	if match := javaExceptionRegex.FindStringSubmatch(strings.TrimSpace(f.trace[0])); match != nil {
+		entry.Fields["exception"] = match[1]
+		entry.Message = head.Message + ": " + strings.TrimSpace(f.trace[0])
+	} else if exception := javaExceptionInLine(head.Message); exception != "" {
+		entry.Fields["exception"] = exception
+		if entry.Level < ERROR {
+			entry.Level = ERROR
+		}
+	}
+	for _, line := range f.trace {
+		if match := javaCauseRegex.FindStringSubmatch(line); match != nil && strings.HasPrefix(strings.TrimSpace(line), "Caused by") {
+			entry.Fields["root_cause"] = match[1]
+		}
+	}
+
+	if f.Expand {
+		entry.Message = strings.Join(append([]string{head.Message}, trace...), "\n")
+	}
+	return entry
+}
+
+// javaExceptionInLine finds the exception class in a line such as
+// `Exception in thread "main" java.lang.IllegalStateException: boom`
+func javaExceptionInLine(line string) string {
+	for _, word := range strings.Fields(line) {
+		if match := javaExceptionRegex.FindStringSubmatch(strings.TrimSuffix(word, ":")); match != nil {
+			return match[1]
+		}
+	}
+	return ""
+}
+
+// collapseJavaFrames replaces consecutive framework frames and repeated frames
+// with summary lines
+func collapseJavaFrames(trace []string) []string {
+	var collapsed []string
+	framework, repeated := 0, 0
+	flush := func() {
+		if framework > 0 {
+			collapsed = append(collapsed, fmt.Sprintf("\t... %d framework frames", framework))
+			framework = 0
+		}
+		if repeated > 0 {
+			collapsed = append(collapsed, fmt.Sprintf("\t... previous frame repeated %d times", repeated))
+			repeated = 0
+		}
+	}
+
+	for i, line := range trace {
+		match := javaFrameRegex.FindStringSubmatch(line)
+		switch {
+		case match == nil:
+			flush()
+			collapsed = append(collapsed, line)
+		case isJavaFrameworkFrame(match[1]):
+			if repeated > 0 {
+				flush()
+			}
+			framework++
+		case i > 0 && line == trace[i-1] && framework == 0:
+			repeated++
+		default:
+			flush()
+			collapsed = append(collapsed, line)
+		}
+	}
+	flush()
+	return collapsed
+}
+
+// isJavaFrameworkFrame reports whether a frame's method belongs to a framework package
+func isJavaFrameworkFrame(method string) bool {
+	for _, pkg := range javaFrameworkPackages {
+		if strings.HasPrefix(method, pkg) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,117 @@
+package logging
+
+import (
+	"reflect"
+	"testing"
+)
+
+// foldLines runs lines through a stream of a pipeline with the given folder
+func foldLines(t *testing.T, newFolder func() Folder, lines []string) []LogEntry {
+	t.Helper()
+	p := NewPipeline()
+	p.Fold(newFolder)
+	stream := p.NewStream()
+
+	var entries []LogEntry
+	for _, line := range lines {
+		entries = append(entries, stream.Process(line)...)
+	}
+	return append(entries, stream.Flush()...)
+}
+
+func TestJavaStackTraceFolder(t *testing.T) {
+	trace := []string{
+		"2024-03-15 12:19:57,123 [http-nio-8080-exec-1] ERROR com.example.Api - Request failed",
+		"java.lang.IllegalStateException: order 42 not found",
+		"\tat com.example.OrderService.find(OrderService.java:87)",
+		"\tat com.example.Api.get(Api.java:42)",
+		"Caused by: java.sql.SQLException: connection reset",
+		"\tat com.example.Db.query(Db.java:12)",
+		"\t... 2 more",
+	}
+
+	tests := []struct {
+		name           string
+		lines          []string
+		wantMessages   []string
+		wantException  interface{}
+		wantRootCause  interface{}
+		wantTraceLines int
+	}{
+		{
+			name:  "Logged exception with cause",
+			lines: append(append([]string{}, trace...), "2024-03-15 12:19:58,000 [main] INFO com.example.Api - Next request"),
+			wantMessages: []string{
+				"Request failed: java.lang.IllegalStateException: order 42 not found",
+				"Next request",
+			},
+			wantException:  "java.lang.IllegalStateException",
+			wantRootCause:  "java.sql.SQLException",
+			wantTraceLines: 6,
+		},
+		{
+			name: "Uncaught exception",
+			lines: []string{
+				`Exception in thread "main" java.lang.NullPointerException: name`,
+				"\tat com.example.Main.main(Main.java:5)",
+			},
+			wantMessages:   []string{`Exception in thread "main" java.lang.NullPointerException: name`},
+			wantException:  "java.lang.NullPointerException",
+			wantTraceLines: 1,
+		},
+		{
+			name:         "Lines without traces are kept",
+			lines:        []string{"first", "second"},
+			wantMessages: []string{"first", "second"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries := foldLines(t, func() Folder { return NewJavaStackTraceFolder(false, false) }, tt.lines)
+
+			var messages []string
+			for _, entry := range entries {
+				messages = append(messages, entry.Message)
+			}
+			if !reflect.DeepEqual(messages, tt.wantMessages) {
+				t.Fatalf("messages = %q, want %q", messages, tt.wantMessages)
+			}
+
+			first := entries[0]
+			if first.Fields["exception"] != tt.wantException {
+				t.Errorf("exception = %v, want %v", first.Fields["exception"], tt.wantException)
+			}
+			if first.Fields["root_cause"] != tt.wantRootCause {
+				t.Errorf("root_cause = %v, want %v", first.Fields["root_cause"], tt.wantRootCause)
+			}
+			if trace, _ := first.Fields["stack_trace"].([]string); len(trace) != tt.wantTraceLines {
+				t.Errorf("stack_trace has %d lines, want %d", len(trace), tt.wantTraceLines)
+			}
+		})
+	}
+}
+
+func TestCollapseJavaFrames(t *testing.T) {
+	trace := []string{
+		"java.lang.StackOverflowError",
+		"\tat com.example.Tree.walk(Tree.java:10)",
+		"\tat com.example.Tree.walk(Tree.java:10)",
+		"\tat com.example.Tree.walk(Tree.java:10)",
+		"\tat org.springframework.web.servlet.FrameworkServlet.service(FrameworkServlet.java:897)",
+		"\tat jakarta.servlet.http.HttpServlet.service(HttpServlet.java:658)",
+		"\tat org.apache.catalina.core.ApplicationFilterChain.doFilter(ApplicationFilterChain.java:205)",
+		"\tat com.example.Filter.doFilter(Filter.java:20)",
+	}
+	want := []string{
+		"java.lang.StackOverflowError",
+		"\tat com.example.Tree.walk(Tree.java:10)",
+		"\t... previous frame repeated 2 times",
+		"\t... 3 framework frames",
+		"\tat com.example.Filter.doFilter(Filter.java:20)",
+	}
+
+	if got := collapseJavaFrames(trace); !reflect.DeepEqual(got, want) {
+		t.Errorf("collapseJavaFrames() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,156 @@
+package logging
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/itchyny/gojq"
+)
+
+// Colors of jq output, following jq's own color scheme
+var (
+	jqNullColor   = color.New(color.FgHiBlack)
+	jqStringColor = color.New(color.FgGreen)
+	jqKeyColor    = color.New(color.FgBlue, color.Bold)
+	jqErrorColor  = color.New(color.FgRed)
+)
+
+// JQFormatter renders the results of a jq query over every entry, one result
+// per line: objects and arrays as colored compact JSON, strings unquoted like
+// jq -r. Entries for which the query yields no result, e.g. through select,
+// are not written.
+//
+// The query sees the entry as {"timestamp", "level", "message", "logger",
+// "fields", "kubernetes"}, so .fields.status is a parsed JSON field.
+type JQFormatter struct {
+	code *gojq.Code
+}
+
+// NewJQFormatter compiles a jq query
+func NewJQFormatter(query string) (*JQFormatter, error) {
+	parsed, err := gojq.Parse(query)
+	if err != nil {
+		return nil, fmt.Errorf("invalid jq query %q: %w", query, err)
+	}
+	code, err := gojq.Compile(parsed)
+	if err != nil {
+		return nil, fmt.Errorf("invalid jq query %q: %w", query, err)
+	}
+	return &JQFormatter{code: code}, nil
+}
+
+// Format implements the Formatter interface
+func (f *JQFormatter) Format(entry LogEntry) string {
+	input, err := jqInput(entry)
+	if err != nil {
+		return jqErrorColor.Sprintf("jq: %v", err)
+	}
+
+	var lines []string
+	iter := f.code.Run(input)
+	for {
+		v, ok := iter.Next()
+		if !ok {
+			break
+		}
+		if err, ok := v.(error); ok {
+			if halt, ok := err.(*gojq.HaltError); ok && halt.Value() == nil {
+				break
+			}
+			lines = append(lines, jqErrorColor.Sprintf("jq: %v", err))
+			break
+		}
+		if s, ok := v.(string); ok {
+			lines = append(lines, s)
+			continue
+		}
+		var sb strings.Builder
+		writeJQValue(&sb, v)
+		lines = append(lines, sb.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// jqInput returns the document a jq query runs on. It is passed through JSON,
+// so field values have the types gojq expects.
+func jqInput(entry LogEntry) (interface{}, error) {
+	doc := map[string]interface{}{
+		"level":   entry.Level.String(),
+		"message": entry.Message,
+		"fields":  entry.Fields,
+	}
+	if doc["fields"] == nil {
+		doc["fields"] = map[string]interface{}{}
+	}
+	if !entry.Timestamp.IsZero() {
+		doc["timestamp"] = entry.Timestamp.Format(time.RFC3339Nano)
+	}
+	if logger := LoggerName(entry); logger != "" {
+		doc["logger"] = logger
+	}
+	if entry.Source != nil {
+		doc["kubernetes"] = entry.Source.record()
+	}
+
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	var input interface{}
+	if err := json.Unmarshal(data, &input); err != nil {
+		return nil, err
+	}
+	return input, nil
+}
+
+// writeJQValue writes a jq result as compact JSON, coloring keys, strings and nulls
+func writeJQValue(sb *strings.Builder, v interface{}) {
+	switch value := v.(type) {
+	case nil:
+		sb.WriteString(jqNullColor.Sprint("null"))
+	case string:
+		sb.WriteString(jqStringColor.Sprint(jqJSON(value)))
+	case map[string]interface{}:
+		keys := make([]string, 0, len(value))
+		for key := range value {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		sb.WriteString("{")
+		for i, key := range keys {
+			if i > 0 {
+				sb.WriteString(",")
+			}
+			sb.WriteString(jqKeyColor.Sprint(jqJSON(key)))
+			sb.WriteString(":")
+			writeJQValue(sb, value[key])
+		}
+		sb.WriteString("}")
+	case []interface{}:
+		sb.WriteString("[")
+		for i, item := range value {
+			if i > 0 {
+				sb.WriteString(",")
+			}
+			writeJQValue(sb, item)
+		}
+		sb.WriteString("]")
+	default:
+		sb.WriteString(jqJSON(value))
+	}
+}
+
+// jqJSON encodes a scalar jq value as JSON, leaving <, > and & unescaped
+func jqJSON(v interface{}) string {
+	var sb strings.Builder
+	enc := json.NewEncoder(&sb)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return fmt.Sprint(v)
+	}
+	return strings.TrimSuffix(sb.String(), "\n")
+}
@@ -0,0 +1,55 @@
+package logging
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+func TestJQFormatter_Format(t *testing.T) {
+	color.NoColor = true
+	defer func() { color.NoColor = false }()
+
+	entry := LogEntry{
+		Level:     ERROR,
+		Message:   "request failed",
+		Timestamp: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+		Fields: map[string]interface{}{
+			"logger":  "api.handler",
+			"request": map[string]interface{}{"path": "/api/cart?a=1&b=<2>", "status": float64(502), "method": "GET"},
+		},
+		Source: &Source{Namespace: "shop", Pod: "api-1", Container: "app"},
+	}
+
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{"Object construction", ".fields.request | {path, status}", `{"path":"/api/cart?a=1&b=<2>","status":502}`},
+		{"Strings are raw", ".message", "request failed"},
+		{"Normalized keys", `"\(.timestamp) \(.level) \(.logger) \(.kubernetes.pod)"`, "2024-05-01T12:00:00Z ERROR api.handler api-1"},
+		{"Several results", ".fields.request.method, .fields.request.status", "GET\n502"},
+		{"Select drops the entry", "select(.fields.request.status < 500)", ""},
+		{"Missing field", ".fields.user", "null"},
+		{"Runtime error", ".message | tonumber", `jq: tonumber cannot be applied to "request failed": invalid number`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := NewJQFormatter(tt.query)
+			if err != nil {
+				t.Fatalf("NewJQFormatter() error = %v", err)
+			}
+			if got := f.Format(entry); got != tt.want {
+				t.Errorf("Format() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewJQFormatter_Invalid(t *testing.T) {
+	if _, err := NewJQFormatter(".fields | {"); err == nil {
+		t.Error("NewJQFormatter() expected an error for an invalid query")
+	}
+}
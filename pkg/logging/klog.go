@@ -0,0 +1,145 @@
+package logging
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// klogRegex matches the header klog writes before every message, as used by
+// the Kubernetes control plane components, e.g.
+//
+//	I0115 10:00:00.123456       1 controller.go:123] Starting controller
+var klogRegex = regexp.MustCompile(`^([IWEF])(\d{4} \d{2}:\d{2}:\d{2}\.\d+)\s+(\d+) ([^ :\]]+):(\d+)\] ?(.*)$`)
+
+// KlogParser parses lines written by klog, the logger of the Kubernetes
+// components. Structured messages, e.g. "Updated lease" node="n1", have
+// their key/value pairs become fields.
+var KlogParser LineParser = LineParserFunc(parseKlogLog)
+
+// Parsers are the built-in parsers for formats that aren't detected
+// automatically, by name
+var Parsers = map[string]LineParser{
+	"klog": KlogParser,
+}
+
+// ParserNames returns the names of the built-in parsers in sorted order
+func ParserNames() []string {
+	names := make([]string, 0, len(Parsers))
+	for name := range Parsers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LookupParser returns the built-in parser of the given name
+func LookupParser(name string) (LineParser, error) {
+	parser, ok := Parsers[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown parser %q (expected %s)", name, strings.Join(ParserNames(), ", "))
+	}
+	return parser, nil
+}
+
+// klogLevels maps the severity letters of klog to log levels
+var klogLevels = map[string]LogLevel{"I": INFO, "W": WARN, "E": ERROR, "F": ERROR}
+
+// parseKlogLog parses a line in the klog format
+func parseKlogLog(line string) (LogEntry, bool) {
+	match := klogRegex.FindStringSubmatch(line)
+	if match == nil {
+		return LogEntry{}, false
+	}
+
+	entry := LogEntry{
+		Format:  FormatPlainText,
+		Level:   klogLevels[match[1]],
+		Logger:  "klog",
+		Message: match[6],
+		RawLine: line,
+		Fields: map[string]interface{}{
+			"source": match[4] + ":" + match[5],
+		},
+	}
+	if ts, ok := parseKlogTime(match[2], time.Now()); ok {
+		entry.Timestamp = ts
+	}
+	if pid, err := strconv.ParseFloat(match[3], 64); err == nil {
+		entry.Fields["pid"] = pid
+	}
+	if match[1] == "F" {
+		entry.Fields["fatal"] = true
+	}
+	if msg, pairs, ok := parseKlogStructured(match[6]); ok {
+		entry.Message = msg
+		for key, value := range pairs {
+			entry.Fields[key] = value
+		}
+	}
+	return entry, true
+}
+
+// parseKlogTime parses the timestamp of a klog header, which has no year. The
+// year of now is assumed, or the previous one for times more than a day
+// ahead, e.g. lines from December read in January.
+func parseKlogTime(s string, now time.Time) (time.Time, bool) {
+	now = now.UTC()
+	ts, err := time.Parse("0102 15:04:05.999999999", s)
+	if err != nil {
+		return time.Time{}, false
+	}
+	ts = ts.AddDate(now.Year()-ts.Year(), 0, 0)
+	if ts.After(now.Add(24 * time.Hour)) {
+		ts = ts.AddDate(-1, 0, 0)
+	}
+	return ts, true
+}
+
+// parseKlogStructured splits a structured klog message, a quoted message
+// followed by key=value pairs, into the message and the pairs. Quoted values
+// are unquoted and numbers become float64 like JSON numbers.
+func parseKlogStructured(msg string) (string, map[string]interface{}, bool) {
+	if !strings.HasPrefix(msg, `"`) {
+		return "", nil, false
+	}
+	quoted, err := strconv.QuotedPrefix(msg)
+	if err != nil {
+		return "", nil, false
+	}
+	message, _ := strconv.Unquote(quoted)
+
+	pairs := make(map[string]interface{})
+	rest := msg[len(quoted):]
+	for {
+		rest = strings.TrimLeft(rest, " ")
+		if rest == "" {
+			return message, pairs, true
+		}
+		eq := strings.IndexByte(rest, '=')
+		if eq <= 0 || strings.ContainsAny(rest[:eq], ` "`) {
+			return "", nil, false
+		}
+		key := rest[:eq]
+		rest = rest[eq+1:]
+		if strings.HasPrefix(rest, `"`) {
+			quoted, err := strconv.QuotedPrefix(rest)
+			if err != nil {
+				return "", nil, false
+			}
+			value, _ := strconv.Unquote(quoted)
+			pairs[key] = value
+			rest = rest[len(quoted):]
+			continue
+		}
+		end := strings.IndexByte(rest, ' ')
+		if end < 0 {
+			end = len(rest)
+		}
+		pairs[key] = captureValue(rest[:end])
+		rest = rest[end:]
+	}
+}
@@ -0,0 +1,123 @@
+package logging
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseKlogLog(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		wantOK      bool
+		wantLevel   LogLevel
+		wantMessage string
+		wantFields  map[string]interface{}
+	}{
+		{
+			name:        "Plain message",
+			input:       "I0115 10:00:00.123456       1 controller.go:123] Starting controller",
+			wantOK:      true,
+			wantLevel:   INFO,
+			wantMessage: "Starting controller",
+			wantFields:  map[string]interface{}{"pid": float64(1), "source": "controller.go:123"},
+		},
+		{
+			name:        "Structured message",
+			input:       `E0115 10:00:01.000001    4242 leaderelection.go:330] "Error retrieving lease" err="context deadline exceeded" lease="kube-system/kube-scheduler" attempt=3`,
+			wantOK:      true,
+			wantLevel:   ERROR,
+			wantMessage: "Error retrieving lease",
+			wantFields: map[string]interface{}{
+				"pid":     float64(4242),
+				"source":  "leaderelection.go:330",
+				"err":     "context deadline exceeded",
+				"lease":   "kube-system/kube-scheduler",
+				"attempt": float64(3),
+			},
+		},
+		{
+			name:        "Unparsable pairs keep the whole message",
+			input:       `W0115 10:00:02.000000       7 reflector.go:535] "Watch failed" obj=&Pod{Name: x}`,
+			wantOK:      true,
+			wantLevel:   WARN,
+			wantMessage: `"Watch failed" obj=&Pod{Name: x}`,
+			wantFields:  map[string]interface{}{"pid": float64(7), "source": "reflector.go:535"},
+		},
+		{
+			name:        "Fatal",
+			input:       "F0115 10:00:03.000000       1 server.go:12] cannot bind",
+			wantOK:      true,
+			wantLevel:   ERROR,
+			wantMessage: "cannot bind",
+			wantFields:  map[string]interface{}{"pid": float64(1), "source": "server.go:12", "fatal": true},
+		},
+		{
+			name:  "Other plain text",
+			input: "Info 2024 something happened",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry, ok := parseKlogLog(tt.input)
+			if ok != tt.wantOK {
+				t.Fatalf("parseKlogLog() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if !ok {
+				return
+			}
+			if entry.Level != tt.wantLevel {
+				t.Errorf("parseKlogLog() level = %v, want %v", entry.Level, tt.wantLevel)
+			}
+			if entry.Message != tt.wantMessage {
+				t.Errorf("parseKlogLog() message = %q, want %q", entry.Message, tt.wantMessage)
+			}
+			if !reflect.DeepEqual(entry.Fields, tt.wantFields) {
+				t.Errorf("parseKlogLog() fields = %v, want %v", entry.Fields, tt.wantFields)
+			}
+			if entry.Timestamp.IsZero() {
+				t.Errorf("parseKlogLog() didn't parse the timestamp")
+			}
+		})
+	}
+}
+
+func TestParseKlogTime(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		now   time.Time
+		want  time.Time
+	}{
+		{
+			name:  "Same year",
+			input: "0115 10:00:00.123456",
+			now:   time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+			want:  time.Date(2024, 1, 15, 10, 0, 0, 123456000, time.UTC),
+		},
+		{
+			name:  "Previous year",
+			input: "1231 23:59:59.000000",
+			now:   time.Date(2024, 1, 1, 0, 5, 0, 0, time.UTC),
+			want:  time.Date(2023, 12, 31, 23, 59, 59, 0, time.UTC),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseKlogTime(tt.input, tt.now)
+			if !ok || !got.Equal(tt.want) {
+				t.Errorf("parseKlogTime() = %v, %v, want %v", got, ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestLookupParser(t *testing.T) {
+	if _, err := LookupParser("klog"); err != nil {
+		t.Errorf("LookupParser(klog) error = %v", err)
+	}
+	if _, err := LookupParser("syslog"); err == nil {
+		t.Error("LookupParser(syslog) returned no error")
+	}
+}
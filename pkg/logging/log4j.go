@@ -0,0 +1,246 @@
+package logging
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// DefaultLog4jPatterns are common Log4j and Log4j2 layouts recognized without configuration
+var DefaultLog4jPatterns = []string{
+	"%d{HH:mm:ss.SSS} [%t] %-5level %logger{36} - %msg%n", // Log4j2 default configuration
+	"%d{ISO8601} [%t] %-5p %c - %m%n",
+	"%d %-5p [%t] %c - %m%n",
+	"%d{ISO8601} %-5p [%t] %c{1}:%L - %m%n",
+}
+
+// log4jConversions maps Log4j conversion words to the field name and regular
+// expression used to capture them. The message is always the "message" field.
+var log4jConversions = map[string]struct {
+	field   string
+	pattern string
+}{
+	"d":        {"time", `\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2}(?:[.,]\d+)?(?:Z|[+-]\d{2}:?\d{2})?`},
+	"date":     {"time", `\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2}(?:[.,]\d+)?(?:Z|[+-]\d{2}:?\d{2})?`},
+	"p":        {"level", `[A-Za-z]+`},
+	"level":    {"level", `[A-Za-z]+`},
+	"c":        {"logger", `\S+`},
+	"logger":   {"logger", `\S+`},
+	"C":        {"class", `\S+`},
+	"class":    {"class", `\S+`},
+	"t":        {"thread", `.*?`},
+	"thread":   {"thread", `.*?`},
+	"m":        {"message", `.*`},
+	"msg":      {"message", `.*`},
+	"message":  {"message", `.*`},
+	"L":        {"line", `\d+`},
+	"line":     {"line", `\d+`},
+	"M":        {"method", `\S+`},
+	"method":   {"method", `\S+`},
+	"F":        {"file", `\S+`},
+	"file":     {"file", `\S+`},
+	"l":        {"location", `\S+`},
+	"location": {"location", `\S+`},
+	"r":        {"relative", `\d+`},
+	"relative": {"relative", `\d+`},
+	"x":        {"ndc", `.*?`},
+	"NDC":      {"ndc", `.*?`},
+	"X":        {"mdc", `.*?`},
+	"MDC":      {"mdc", `.*?`},
+}
+
+// log4jTimeOnlyFormats are date options that print a time without a date
+var log4jTimeOnlyFormats = map[string]string{
+	"ABSOLUTE":     `\d{2}:\d{2}:\d{2}[.,]\d{3}`,
+	"HH:mm:ss.SSS": `\d{2}:\d{2}:\d{2}\.\d{3}`,
+	"HH:mm:ss,SSS": `\d{2}:\d{2}:\d{2},\d{3}`,
+	"HH:mm:ss":     `\d{2}:\d{2}:\d{2}`,
+}
+
+// Log4jParser parses lines written with a Log4j/Log4j2 PatternLayout
+type Log4jParser struct {
+	// Pattern is the conversion pattern the parser was built from
+	Pattern string
+
+	regex  *regexp.Regexp
+	fields []string
+}
+
+// NewLog4jParser compiles a Log4j conversion pattern such as
+// "%d [%t] %-5p %c - %m%n" into a parser. The conversions %d, %p, %c, %C, %t,
+// %m, %L, %M, %F, %l, %r, %x and %X (and their long names) are captured into
+// fields; other conversions are matched but ignored.
+func NewLog4jParser(pattern string) (*Log4jParser, error) {
+	var sb strings.Builder
+	var fields []string
+	sb.WriteString("^")
+
+	for i := 0; i < len(pattern); {
+		c := pattern[i]
+		switch {
+		case c == '%' && i+1 < len(pattern) && pattern[i+1] == '%':
+			sb.WriteString("%")
+			i += 2
+		case c == '%':
+			conv, next, err := parseLog4jConversion(pattern, i+1)
+			if err != nil {
+				return nil, err
+			}
+			i = next
+			if conv.name == "n" {
+				continue
+			}
+
+			spec, ok := log4jConversions[conv.name]
+			expr := `.*?`
+			if ok {
+				expr = spec.pattern
+				if spec.field == "time" {
+					if timeOnly, ok := log4jTimeOnlyFormats[conv.option]; ok {
+						expr = timeOnly
+					}
+				}
+				fields = append(fields, spec.field)
+				sb.WriteString(fmt.Sprintf("(%s)", expr))
+			} else {
+				sb.WriteString(fmt.Sprintf("(?:%s)", expr))
+			}
+			// Padded conversions may be followed by extra spaces
+			if conv.padded {
+				sb.WriteString(`\s*`)
+			}
+		case unicode.IsSpace(rune(c)):
+			sb.WriteString(`\s+`)
+			for i < len(pattern) && unicode.IsSpace(rune(pattern[i])) {
+				i++
+			}
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+			i++
+		}
+	}
+	sb.WriteString("$")
+
+	hasMessage := false
+	for _, f := range fields {
+		hasMessage = hasMessage || f == "message"
+	}
+	if !hasMessage {
+		return nil, fmt.Errorf("log4j pattern %q has no message conversion (%%m)", pattern)
+	}
+
+	re, err := regexp.Compile(sb.String())
+	if err != nil {
+		return nil, fmt.Errorf("invalid log4j pattern %q: %w", pattern, err)
+	}
+	return &Log4jParser{Pattern: pattern, regex: re, fields: fields}, nil
+}
+
+// log4jConversion is a single parsed %-conversion of a pattern
+type log4jConversion struct {
+	name   string
+	option string
+	padded bool
+}
+
+// parseLog4jConversion parses a conversion starting after the % at position start,
+// returning it and the position of the first character after it
+func parseLog4jConversion(pattern string, start int) (log4jConversion, int, error) {
+	var conv log4jConversion
+	i := start
+
+	// Format modifiers: optional left alignment, minimum width and maximum width
+	if i < len(pattern) && pattern[i] == '-' {
+		i++
+	}
+	for i < len(pattern) && (pattern[i] >= '0' && pattern[i] <= '9' || pattern[i] == '.') {
+		conv.padded = true
+		i++
+	}
+
+	nameStart := i
+	for i < len(pattern) && unicode.IsLetter(rune(pattern[i])) {
+		i++
+	}
+	conv.name = pattern[nameStart:i]
+	if conv.name == "" {
+		return conv, i, fmt.Errorf("invalid log4j pattern %q: missing conversion name at position %d", pattern, start)
+	}
+
+	if i < len(pattern) && pattern[i] == '{' {
+		end := strings.IndexByte(pattern[i:], '}')
+		if end < 0 {
+			return conv, i, fmt.Errorf("invalid log4j pattern %q: unterminated option", pattern)
+		}
+		conv.option = pattern[i+1 : i+end]
+		i += end + 1
+	}
+	return conv, i, nil
+}
+
+// Parse implements the LineParser interface
+func (p *Log4jParser) Parse(line string) (LogEntry, bool) {
+	match := p.regex.FindStringSubmatch(line)
+	if match == nil {
+		return LogEntry{}, false
+	}
+
+	entry := LogEntry{
+		Format:  FormatPlainText,
+		Logger:  "log4j",
+		RawLine: line,
+		Fields:  make(map[string]interface{}),
+	}
+	for i, field := range p.fields {
+		value := strings.TrimSpace(match[i+1])
+		switch field {
+		case "message":
+			entry.Message = match[i+1]
+		case "level":
+			level, err := ParseLogLevel(value)
+			if err != nil {
+				// Not a level, so the line was not written with this pattern
+				return LogEntry{}, false
+			}
+			entry.Level = level
+		case "time":
+			if ts, err := parseTimestamp(strings.Replace(value, ",", ".", 1)); err == nil {
+				entry.Timestamp = ts
+			}
+		case "line", "relative":
+			if n, err := strconv.ParseFloat(value, 64); err == nil {
+				entry.Fields[field] = n
+			}
+		default:
+			if value != "" {
+				entry.Fields[field] = value
+			}
+		}
+	}
+	return entry, true
+}
+
+// defaultLog4jParsers are compiled from DefaultLog4jPatterns
+var defaultLog4jParsers = func() []*Log4jParser {
+	parsers := make([]*Log4jParser, len(DefaultLog4jPatterns))
+	for i, pattern := range DefaultLog4jPatterns {
+		p, err := NewLog4jParser(pattern)
+		if err != nil {
+			panic(err)
+		}
+		parsers[i] = p
+	}
+	return parsers
+}()
+
+// parseDefaultLog4jLog tries each of the default Log4j layouts
+func parseDefaultLog4jLog(line string) (LogEntry, bool) {
+	for _, p := range defaultLog4jParsers {
+		if entry, ok := p.Parse(line); ok {
+			return entry, true
+		}
+	}
+	return LogEntry{}, false
+}
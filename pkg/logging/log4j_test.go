@@ -0,0 +1,145 @@
+package logging
+
+import (
+	"testing"
+)
+
+func TestNewLog4jParser(t *testing.T) {
+	tests := []struct {
+		name        string
+		pattern     string
+		input       string
+		wantErr     bool
+		wantMatch   bool
+		wantLevel   LogLevel
+		wantMessage string
+		wantFields  map[string]interface{}
+	}{
+		{
+			name:        "Classic layout",
+			pattern:     "%d [%t] %-5p %c - %m%n",
+			input:       "2024-03-15 12:19:57,123 [main] INFO  com.example.App - Application started",
+			wantMatch:   true,
+			wantLevel:   INFO,
+			wantMessage: "Application started",
+			wantFields: map[string]interface{}{
+				"thread": "main",
+				"logger": "com.example.App",
+			},
+		},
+		{
+			name:        "Long conversion names and line numbers",
+			pattern:     "%date{ISO8601} %level [%thread] %logger{1}:%L - %msg%n",
+			input:       "2024-03-15T12:19:57.123Z ERROR [http-nio-8080-exec-2] OrderService:87 - Payment failed: card declined",
+			wantMatch:   true,
+			wantLevel:   ERROR,
+			wantMessage: "Payment failed: card declined",
+			wantFields: map[string]interface{}{
+				"thread": "http-nio-8080-exec-2",
+				"logger": "OrderService",
+				"line":   float64(87),
+			},
+		},
+		{
+			name:        "Literal percent and ignored conversions",
+			pattern:     "%r %%%X{user} %-5p %m",
+			input:       "1234 %alice WARN  Disk almost full",
+			wantMatch:   true,
+			wantLevel:   WARN,
+			wantMessage: "Disk almost full",
+			wantFields: map[string]interface{}{
+				"relative": float64(1234),
+				"mdc":      "alice",
+			},
+		},
+		{
+			name:      "Line in another format",
+			pattern:   "%d [%t] %-5p %c - %m%n",
+			input:     "just some text",
+			wantMatch: false,
+		},
+		{
+			name:      "Level that is not a log level",
+			pattern:   "%p %m",
+			input:     "hello world",
+			wantMatch: false,
+		},
+		{
+			name:    "Pattern without message",
+			pattern: "%d [%t] %-5p %c",
+			wantErr: true,
+		},
+		{
+			name:    "Unterminated option",
+			pattern: "%d{ISO8601 %m",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser, err := NewLog4jParser(tt.pattern)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewLog4jParser() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			got, ok := parser.Parse(tt.input)
+			if ok != tt.wantMatch {
+				t.Fatalf("Parse() matched = %v, want %v", ok, tt.wantMatch)
+			}
+			if !ok {
+				return
+			}
+			if got.Logger != "log4j" {
+				t.Errorf("Logger = %v, want log4j", got.Logger)
+			}
+			if got.Level != tt.wantLevel {
+				t.Errorf("Level = %v, want %v", got.Level, tt.wantLevel)
+			}
+			if got.Message != tt.wantMessage {
+				t.Errorf("Message = %q, want %q", got.Message, tt.wantMessage)
+			}
+			for k, v := range tt.wantFields {
+				if got.Fields[k] != v {
+					t.Errorf("Fields[%q] = %v, want %v", k, got.Fields[k], v)
+				}
+			}
+		})
+	}
+}
+
+func TestParseLogEntry_DefaultLog4j(t *testing.T) {
+	got := ParseLogEntry("2024-03-15 12:19:57,123 [main] ERROR com.example.App - Connection refused")
+	if got.Logger != "log4j" {
+		t.Fatalf("Logger = %v, want log4j", got.Logger)
+	}
+	if got.Level != ERROR || got.Message != "Connection refused" {
+		t.Errorf("got level %v message %q", got.Level, got.Message)
+	}
+	if got.Timestamp.IsZero() {
+		t.Error("Timestamp was not parsed")
+	}
+}
+
+func TestPipeline_Parse(t *testing.T) {
+	parser, err := NewLog4jParser("%p|%c|%m")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := NewPipeline()
+	p.Parse(parser)
+
+	got, _ := p.Process("WARN|billing|Invoice overdue")
+	if got.Logger != "log4j" || got.Level != WARN || got.Fields["logger"] != "billing" {
+		t.Errorf("custom parser not used: %+v", got)
+	}
+
+	// JSON lines are never handed to custom parsers
+	got, _ = p.Process(`{"level":"error","msg":"boom"}`)
+	if got.Format != FormatJSON {
+		t.Errorf("Format = %v, want JSON", got.Format)
+	}
+}
@@ -0,0 +1,83 @@
+package logging
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// LoggerFields are the field names logger or module names are commonly logged
+// under, in order of preference: zap and Logback use "logger", the Logstash
+// encoder "logger_name" and Python's logging "name"
+var LoggerFields = []string{"logger", "logger_name", "loggerName", "log.logger", "category", "module", "name"}
+
+// LoggerName returns the logger or module name of an entry, or "" if it has none
+func LoggerName(entry LogEntry) string {
+	for _, key := range LoggerFields {
+		if v, ok := entry.Fields[key].(string); ok && v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// LoggerFilter keeps entries by logger name. Patterns match a logger and its
+// children, so "io.netty" or "io.netty.*" also match "io.netty.channel";
+// any other * matches any run of characters.
+type LoggerFilter struct {
+	Include []*regexp.Regexp
+	Exclude []*regexp.Regexp
+}
+
+// NewLoggerFilter builds a LoggerFilter from patterns such as "pkg.foo.*";
+// patterns prefixed with ! exclude the matching loggers instead
+func NewLoggerFilter(patterns []string) (*LoggerFilter, error) {
+	f := &LoggerFilter{}
+	for _, pattern := range patterns {
+		exclude := strings.HasPrefix(pattern, "!")
+		name := strings.TrimPrefix(pattern, "!")
+		if name == "" {
+			return nil, fmt.Errorf("invalid logger pattern %q", pattern)
+		}
+		re := compileLoggerPattern(name)
+		if exclude {
+			f.Exclude = append(f.Exclude, re)
+		} else {
+			f.Include = append(f.Include, re)
+		}
+	}
+	return f, nil
+}
+
+// compileLoggerPattern converts a logger pattern into an anchored regular expression
+func compileLoggerPattern(pattern string) *regexp.Regexp {
+	pattern = strings.TrimSuffix(pattern, ".*")
+	parts := strings.Split(pattern, "*")
+	for i, part := range parts {
+		parts[i] = regexp.QuoteMeta(part)
+	}
+	return regexp.MustCompile(`^` + strings.Join(parts, `.*`) + `([./:]|$)`)
+}
+
+// Match implements the Filter interface. With include patterns, entries
+// without a logger name are dropped.
+func (f *LoggerFilter) Match(entry LogEntry) bool {
+	name := LoggerName(entry)
+	if name == "" {
+		return len(f.Include) == 0
+	}
+	for _, re := range f.Exclude {
+		if re.MatchString(name) {
+			return false
+		}
+	}
+	if len(f.Include) == 0 {
+		return true
+	}
+	for _, re := range f.Include {
+		if re.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
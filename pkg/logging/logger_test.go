@@ -0,0 +1,40 @@
+package logging
+
+import "testing"
+
+func TestLoggerFilter_Match(t *testing.T) {
+	tests := []struct {
+		name     string
+		patterns []string
+		line     string
+		want     bool
+	}{
+		{"zap logger included", []string{"pkg.foo.*"}, `{"level":"info","logger":"pkg.foo.bar","msg":"hi"}`, true},
+		{"parent logger included", []string{"pkg.foo.*"}, `{"level":"info","logger":"pkg.foo","msg":"hi"}`, true},
+		{"sibling prefix not included", []string{"pkg.foo.*"}, `{"level":"info","logger":"pkg.foobar","msg":"hi"}`, false},
+		{"other logger not included", []string{"pkg.foo.*"}, `{"level":"info","logger":"pkg.baz","msg":"hi"}`, false},
+		{"no logger with include", []string{"pkg.foo.*"}, "plain text line", false},
+		{"python name excluded", []string{"!urllib3"}, `{"levelname":"DEBUG","name":"urllib3.connectionpool","message":"GET /"}`, false},
+		{"logback logger kept", []string{"!io.netty"}, "2024-01-15 10:30:00.123  INFO 1 --- [main] com.example.App           : Started", true},
+		{"no logger with exclude", []string{"!io.netty"}, "plain text line", true},
+		{"wildcard inside", []string{"com.*.db"}, `{"level":"info","logger":"com.example.db.pool","msg":"hi"}`, true},
+		{"exclude wins", []string{"com.example", "!com.example.db"}, `{"level":"info","logger":"com.example.db","msg":"hi"}`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := NewLoggerFilter(tt.patterns)
+			if err != nil {
+				t.Fatalf("NewLoggerFilter() error = %v", err)
+			}
+			if got := f.Match(ParseLogEntry(tt.line)); got != tt.want {
+				t.Errorf("Match() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewLoggerFilter_Invalid(t *testing.T) {
+	if _, err := NewLoggerFilter([]string{"!"}); err == nil {
+		t.Error("NewLoggerFilter() expected error for empty pattern")
+	}
+}
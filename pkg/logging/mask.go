@@ -0,0 +1,62 @@
+package logging
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// MaskRule replaces the text matching a regular expression, e.g. account
+// numbers or customer emails that must not leave the cluster
+type MaskRule struct {
+	Pattern     *regexp.Regexp
+	Replacement string
+}
+
+// NewMaskRule compiles a masking rule. The replacement may refer to groups of
+// the pattern like regexp.ReplaceAllString, e.g. ${1}****.
+func NewMaskRule(pattern, replacement string) (MaskRule, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return MaskRule{}, fmt.Errorf("invalid mask pattern %q: %w", pattern, err)
+	}
+	return MaskRule{Pattern: re, Replacement: replacement}, nil
+}
+
+// Mask returns a transform applying the rules, in order, to the message and
+// raw line of every entry and to its string field values, including those
+// nested in objects and arrays. Field names are left as they are.
+func Mask(rules []MaskRule) Transform {
+	return TransformFunc(func(entry *LogEntry) bool {
+		entry.Message = maskString(entry.Message, rules)
+		entry.RawLine = maskString(entry.RawLine, rules)
+		for key, value := range entry.Fields {
+			entry.Fields[key] = maskValue(value, rules)
+		}
+		return true
+	})
+}
+
+// maskValue masks a field value, descending into objects and arrays
+func maskValue(value interface{}, rules []MaskRule) interface{} {
+	switch v := value.(type) {
+	case string:
+		return maskString(v, rules)
+	case map[string]interface{}:
+		for key, nested := range v {
+			v[key] = maskValue(nested, rules)
+		}
+	case []interface{}:
+		for i, nested := range v {
+			v[i] = maskValue(nested, rules)
+		}
+	}
+	return value
+}
+
+// maskString applies the rules to s in order
+func maskString(s string, rules []MaskRule) string {
+	for _, rule := range rules {
+		s = rule.Pattern.ReplaceAllString(s, rule.Replacement)
+	}
+	return s
+}
@@ -0,0 +1,76 @@
+package logging
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMask(t *testing.T) {
+	var rules []MaskRule
+	for _, r := range [][2]string{
+		{`\b\d{4}-\d{4}-\d{4}-(\d{4})\b`, "****-****-****-${1}"},
+		{`[\w.+-]+@[\w-]+\.[\w.]+`, "<email>"},
+	} {
+		rule, err := NewMaskRule(r[0], r[1])
+		if err != nil {
+			t.Fatalf("NewMaskRule(%q) error = %v", r[0], err)
+		}
+		rules = append(rules, rule)
+	}
+
+	tests := []struct {
+		name        string
+		line        string
+		wantMessage string
+		wantFields  map[string]interface{}
+	}{
+		{
+			name:        "plain text",
+			line:        "INFO charged 4111-1111-1111-1234 for jane@example.com",
+			wantMessage: "INFO charged ****-****-****-1234 for <email>",
+		},
+		{
+			name:        "JSON fields",
+			line:        `{"msg":"charge by jane@example.com","card":"4111-1111-1111-1234","amount":12.5,"customer":{"emails":["jane@example.com"]}}`,
+			wantMessage: "charge by <email>",
+			wantFields: map[string]interface{}{
+				"msg":      "charge by <email>",
+				"card":     "****-****-****-1234",
+				"amount":   12.5,
+				"customer": map[string]interface{}{"emails": []interface{}{"<email>"}},
+			},
+		},
+		{
+			name:        "nothing to mask",
+			line:        "INFO ready",
+			wantMessage: "INFO ready",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry := ParseLogEntry(tt.line)
+			if !Mask(rules).Apply(&entry) {
+				t.Fatal("Apply() dropped the entry")
+			}
+			if entry.Message != tt.wantMessage {
+				t.Errorf("Message = %q, want %q", entry.Message, tt.wantMessage)
+			}
+			if tt.wantFields != nil && !reflect.DeepEqual(entry.Fields, tt.wantFields) {
+				t.Errorf("Fields = %v, want %v", entry.Fields, tt.wantFields)
+			}
+			for _, secret := range []string{"4111-1111-1111-1234", "jane@example.com"} {
+				if strings.Contains(entry.RawLine, secret) {
+					t.Errorf("RawLine = %q still holds %q", entry.RawLine, secret)
+				}
+			}
+		})
+	}
+}
+
+func TestNewMaskRule_Invalid(t *testing.T) {
+	if _, err := NewMaskRule("(unclosed", "***"); err == nil {
+		t.Error("NewMaskRule() with an invalid pattern succeeded")
+	}
+}
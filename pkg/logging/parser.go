@@ -4,10 +4,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/dantech2000/kubelog/pkg/glyph"
 	"github.com/fatih/color"
 )
 
@@ -27,26 +29,28 @@ type LogEntry struct {
 	Logger    string
 	Fields    map[string]interface{}
 	Timestamp time.Time
-	RawLine   string // Store the original line
+	RawLine   string  // Store the original line
+	Source    *Source // Container the line was read from (optional)
 }
 
-var logLevelColors = map[LogLevel]*color.Color{
-	DEBUG: color.New(color.FgCyan),
-	INFO:  color.New(color.FgGreen),
-	WARN:  color.New(color.FgYellow),
-	ERROR: color.New(color.FgRed),
-}
-
-// Colors for different log components
+// Colors, level symbols and icons for different log components, set from the theme
+// by SetTheme
 var (
-	timestampColor = color.New(color.FgBlue)
-	loggerColor    = color.New(color.FgMagenta)
-	keyColor       = color.New(color.FgCyan)
-	valueColor     = color.New(color.FgWhite)
-	quoteColor     = color.New(color.FgHiBlack)
-	errorColor     = color.New(color.FgRed, color.Bold)
+	logLevelColors  = make(map[LogLevel]*color.Color)
+	logLevelSymbols = make(map[LogLevel]glyph.Symbol)
+	logLevelIcons   = make(map[LogLevel]glyph.Symbol)
+	timestampColor  *color.Color
+	loggerColor     *color.Color
+	keyColor        *color.Color
+	valueColor      *color.Color
+	quoteColor      *color.Color
+	errorColor      *color.Color
 )
 
+func init() {
+	SetTheme(currentTheme)
+}
+
 // Common field mappings for different JSON log formats
 var (
 	// Level field names across different loggers
@@ -80,6 +84,7 @@ var (
 		"Time",       // AWS CloudWatch
 		"TIME",       // Some uppercase variants
 		"datetime",   // Python logging
+		"start_time", // Envoy/Istio access logs
 	}
 
 	// Time formats to try parsing
@@ -111,6 +116,8 @@ func detectLogFormat(line string) LogFormat {
 // detectLogger tries to determine which logging framework generated the log
 func detectLogger(data map[string]interface{}) string {
 	switch {
+	case isEnvoyJSON(data):
+		return "envoy"
 	case data["caller"] != nil && data["ts"] != nil:
 		return "zap"
 	case data["@level"] != nil && data["@timestamp"] != nil:
@@ -138,17 +145,32 @@ func parseTimestamp(timeStr string) (time.Time, error) {
 	return time.Time{}, fmt.Errorf("unable to parse time: %s", timeStr)
 }
 
-// parseJSONLog attempts to parse a JSON log entry
-func parseJSONLog(line string) LogEntry {
+// parseJSONLog attempts to parse a JSON log entry, renaming aliased fields
+// first; units are those of epoch timestamps by field
+func parseJSONLog(line string, aliases map[string]string, units map[string]time.Duration) LogEntry {
+	// Numbers are decoded exactly, so that nanosecond timestamps keep every
+	// digit, and then turned into float64 like json.Unmarshal gives them
 	var data map[string]interface{}
-	if err := json.Unmarshal([]byte(line), &data); err != nil {
+	dec := json.NewDecoder(strings.NewReader(line))
+	dec.UseNumber()
+	if err := dec.Decode(&data); err != nil || dec.More() {
 		return LogEntry{
 			Level:   DEBUG,
 			Message: line,
 			Format:  FormatJSON,
+			RawLine: line,
 		}
 	}
 
+	data = unwrapJSON(data)
+	applyFieldAliases(data, aliases)
+	epochs := make(map[string]json.Number)
+	for _, field := range jsonTimeFields {
+		if n, ok := data[field].(json.Number); ok {
+			epochs[field] = n
+		}
+	}
+	floatNumbers(data)
 	logger := detectLogger(data)
 	entry := LogEntry{
 		Format:  FormatJSON,
@@ -186,17 +208,23 @@ func parseJSONLog(line string) LogEntry {
 		}
 	}
 
+	if logger == "envoy" {
+		enrichEnvoyJSON(&entry)
+	}
+
 	// Parse timestamp
 	for _, field := range jsonTimeFields {
 		if val, ok := data[field]; ok {
-			// Handle numeric timestamps (milliseconds since epoch)
-			if numTime, ok := val.(float64); ok {
-				msec := int64(numTime)
-				if msec > 1e11 { // Assuming milliseconds if number is large enough
-					entry.Timestamp = time.Unix(0, msec*int64(time.Millisecond))
-				} else {
-					entry.Timestamp = time.Unix(msec, 0)
+			// Handle numeric timestamps since the epoch, in the unit
+			// configured for the field or guessed from their size
+			if n, ok := epochs[field]; ok {
+				if ts, ok := parseEpochString(n.String(), units[field]); ok {
+					entry.Timestamp = ts
+					break
 				}
+			}
+			if numTime, ok := val.(float64); ok {
+				entry.Timestamp = EpochTime(numTime, units[field])
 				break
 			}
 			// Try parsing string timestamps
@@ -205,6 +233,10 @@ func parseJSONLog(line string) LogEntry {
 					entry.Timestamp = ts
 					break
 				}
+				if ts, ok := parseEpochString(timeStr, units[field]); ok {
+					entry.Timestamp = ts
+					break
+				}
 			}
 		}
 	}
@@ -212,6 +244,32 @@ func parseJSONLog(line string) LogEntry {
 	return entry
 }
 
+// floatNumbers replaces the json.Number values of decoded JSON, also in nested
+// objects and arrays, with their float64 value
+func floatNumbers(v interface{}) interface{} {
+	switch v := v.(type) {
+	case json.Number:
+		f, _ := v.Float64()
+		return f
+	case map[string]interface{}:
+		for k, e := range v {
+			v[k] = floatNumbers(e)
+		}
+	case []interface{}:
+		for i, e := range v {
+			v[i] = floatNumbers(e)
+		}
+	}
+	return v
+}
+
+// plainTextLevel finds the level keyword of a plain text line; it matches whole
+// words only, so a line mentioning "0 errors" isn't taken for an error
+var plainTextLevel = regexp.MustCompile(`(?i)\b(DEBUG|INFO|WARN(?:ING)?|ERROR|FATAL|TRACE)\b`)
+
+// plainTextTime finds the timestamp of a plain text line
+var plainTextTime = regexp.MustCompile(`\d{4}[-/]\d{2}[-/]\d{2}[T ]\d{2}:\d{2}:\d{2}(?:[.,]\d+)?(?:Z|[+-]\d{2}:?\d{2})?`)
+
 // parsePlainTextLog parses a plain text log entry
 func parsePlainTextLog(line string) LogEntry {
 	entry := LogEntry{
@@ -221,16 +279,14 @@ func parsePlainTextLog(line string) LogEntry {
 	}
 
 	// First try to extract timestamp
-	timeRegex := regexp.MustCompile(`\d{4}[-/]\d{2}[-/]\d{2}[T ]\d{2}:\d{2}:\d{2}(?:\.\d+)?(?:Z|[+-]\d{2}:?\d{2})?`)
-	if timeStr := timeRegex.FindString(line); timeStr != "" {
+	if timeStr := plainTextTime.FindString(line); timeStr != "" {
 		if ts, err := parseTimestamp(timeStr); err == nil {
 			entry.Timestamp = ts
 		}
 	}
 
 	// Try to extract log level
-	levelRegex := regexp.MustCompile(`(?i)(DEBUG|INFO|WARN(?:ING)?|ERROR|FATAL|TRACE)`)
-	if match := levelRegex.FindString(line); match != "" {
+	if match := plainTextLevel.FindString(line); match != "" {
 		if level, err := ParseLogLevel(match); err == nil {
 			entry.Level = level
 		}
@@ -241,12 +297,54 @@ func parsePlainTextLog(line string) LogEntry {
 	return entry
 }
 
+// lineParsers recognize specific plain text formats. They are tried in order
+// and the generic plain text parser is used when none of them matches.
+var lineParsers = []func(line string) (LogEntry, bool){
+	parseEnvoyLog,
+	parseSpringBootLog,
+	parseDefaultLog4jLog,
+	parseRailsLog,
+	parsePumaLog,
+}
+
+// LineParser parses log lines of a specific plain text format, reporting
+// false when a line is not in that format
+type LineParser interface {
+	Parse(line string) (LogEntry, bool)
+}
+
+// LineParserFunc adapts a function to the LineParser interface
+type LineParserFunc func(line string) (LogEntry, bool)
+
+// Parse implements the LineParser interface
+func (f LineParserFunc) Parse(line string) (LogEntry, bool) {
+	return f(line)
+}
+
+// ParseLogEntry parses a log line, detecting its format
 func ParseLogEntry(line string) LogEntry {
+	return parseLogEntry(line, nil, nil)
+}
+
+// parseLogEntry parses a log line with the given field aliases and units of
+// epoch timestamps
+func parseLogEntry(line string, aliases map[string]string, units map[string]time.Duration) LogEntry {
 	format := detectLogFormat(line)
 	if format == FormatJSON {
-		return parseJSONLog(line)
+		return parseJSONLog(line, aliases, units)
+	}
+	entry, ok := LogEntry{}, false
+	for _, parse := range lineParsers {
+		if entry, ok = parse(line); ok {
+			break
+		}
 	}
-	return parsePlainTextLog(line)
+	if !ok {
+		entry = parsePlainTextLog(line)
+	}
+	extractEmbeddedJSON(&entry)
+	applyFieldAliases(entry.Fields, aliases)
+	return entry
 }
 
 // ParseLogLevel parses both string and numeric log levels
@@ -281,57 +379,68 @@ func ParseLogLevel(level string) (LogLevel, error) {
 	}
 }
 
-func formatLogEntry(entry LogEntry) string {
+func formatLogEntry(entry LogEntry, f TextFormatter) string {
 	var parts []string
 
+	if f.Icons {
+		if icon := logLevelIcons[entry.Level].String(); icon != "" {
+			parts = append(parts, icon)
+		}
+	}
+
 	// Add timestamp if available
 	if !entry.Timestamp.IsZero() {
-		parts = append(parts, timestampColor.Sprintf("[%s]", entry.Timestamp.Format("2006-01-02 15:04:05")))
+		parts = append(parts, timestampColor.Sprintf("[%s]", f.TimeFormat.Format(entry.Timestamp)))
 	}
 
 	// Add level with appropriate color
-	parts = append(parts, logLevelColors[entry.Level].Sprint(fmt.Sprintf("[%s]", entry.Level)))
+	level := fmt.Sprintf("[%s]", entry.Level)
+	if symbol := logLevelSymbols[entry.Level].String(); symbol != "" {
+		level = symbol + " " + level
+	}
+	parts = append(parts, logLevelColors[entry.Level].Sprint(level))
 
-	// Add logger type for JSON logs
-	if entry.Format == FormatJSON && entry.Logger != "" {
+	// Add logger type for JSON logs and recognized text formats
+	if entry.Logger != "" {
 		parts = append(parts, loggerColor.Sprintf("[%s]", entry.Logger))
 	}
 
-	// For JSON logs, parse and format the content
+	// For JSON logs, format the fields; transforms may have changed them, so
+	// the entry is used rather than the raw line
 	if entry.Format == FormatJSON {
-		// Format the JSON fields with colors
-		var data map[string]interface{}
-		if err := json.Unmarshal([]byte(entry.RawLine), &data); err == nil {
-			excludeFields := map[string]bool{
-				"level": true, "severity": true, "log_level": true,
-				"time": true, "timestamp": true, "@timestamp": true,
-			}
-
-			// Format message field specially
-			msg := ""
-			for _, field := range jsonMessageFields {
-				if val, ok := data[field]; ok {
-					msg = fmt.Sprintf("%v", val)
-					break
-				}
+		if entry.Fields != nil {
+			// A message equal to the raw line is the fallback for entries
+			// without a message field and is not repeated
+			msg := entry.Message
+			if msg == entry.RawLine {
+				msg = ""
 			}
 
-			// Build the formatted JSON output
+			// Build the formatted JSON output in key order so lines line up
 			var fields []string
-			for k, v := range data {
-				if !excludeFields[k] && k != "msg" && k != "message" {
-					formattedValue := formatValue(v)
-					fields = append(fields, fmt.Sprintf("%s=%s",
-						keyColor.Sprint(k),
-						formattedValue))
+			for _, k := range sortedKeys(entry.Fields) {
+				v := entry.Fields[k]
+				if !f.AllFields && curatedOut(k, v, msg) {
+					continue
 				}
+				// The message field is shown first and is not repeated
+				if (k == "msg" || k == "message") && msg != "" && fmt.Sprintf("%v", v) == msg {
+					continue
+				}
+				formattedValue := formatValue(v)
+				if f.Humanize {
+					if humanized, ok := HumanizeField(k, v); ok {
+						formattedValue = valueColor.Sprint(humanized)
+					}
+				}
+				fields = append(fields, fmt.Sprintf("%s=%s",
+					keyColor.Sprint(k),
+					formattedValue))
 			}
 
 			// If we found a message, put it first
 			if msg != "" {
-				if entry.Level == ERROR || strings.Contains(strings.ToLower(msg), "error") ||
-					strings.Contains(strings.ToLower(msg), "warn") ||
-					strings.Contains(strings.ToLower(msg), "failed") {
+				if f.highlighter().Highlight(entry) {
 					msg = errorColor.Sprint(msg)
 				}
 				fields = append([]string{msg}, fields...)
@@ -339,22 +448,52 @@ func formatLogEntry(entry LogEntry) string {
 
 			parts = append(parts, strings.Join(fields, " "))
 		} else {
-			// If JSON parsing fails, use the raw line
+			// Without parsed fields, use the raw line
 			parts = append(parts, entry.RawLine)
 		}
 	} else {
-		// For plain text, check if it contains error-related text
-		if entry.Level == ERROR || strings.Contains(strings.ToLower(entry.RawLine), "error") ||
-			strings.Contains(strings.ToLower(entry.RawLine), "failed") {
-			parts = append(parts, errorColor.Sprint(entry.RawLine))
+		// Recognized text formats keep the logger name apart from the message
+		if name, ok := entry.Fields["logger"]; ok {
+			parts = append(parts, loggerColor.Sprintf("%v:", name))
+		}
+
+		if f.highlighter().Highlight(entry) {
+			parts = append(parts, errorColor.Sprint(entry.Message))
 		} else {
-			parts = append(parts, entry.RawLine)
+			parts = append(parts, entry.Message)
 		}
 	}
 
 	return strings.Join(parts, " ")
 }
 
+// curatedFields are JSON fields the default text view leaves out because the
+// timestamp, level and message are already shown ahead of the fields
+var curatedFields = map[string]bool{
+	"level": true, "severity": true, "log_level": true,
+	"time": true, "timestamp": true, "@timestamp": true,
+	"msg": true, "message": true,
+}
+
+// curatedOut reports whether the default text view leaves out a field
+func curatedOut(key string, value interface{}, msg string) bool {
+	if curatedFields[key] {
+		return true
+	}
+	// The error field doubles as the message when there is none
+	return key == "error" && fmt.Sprintf("%v", value) == msg
+}
+
+// sortedKeys returns the keys of a map in sorted order
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
 // formatValue formats a value with appropriate coloring based on its type
 func formatValue(v interface{}) string {
 	switch val := v.(type) {
@@ -384,10 +523,10 @@ func formatValue(v interface{}) string {
 		return valueColor.Sprintf("%.2f", val)
 	case map[string]interface{}:
 		parts := make([]string, 0, len(val))
-		for k, v := range val {
+		for _, k := range sortedKeys(val) {
 			parts = append(parts, fmt.Sprintf("%s=%s",
 				keyColor.Sprint(k),
-				formatValue(v)))
+				formatValue(val[k])))
 		}
 		return fmt.Sprintf("{%s}", strings.Join(parts, " "))
 	default:
@@ -395,7 +534,12 @@ func formatValue(v interface{}) string {
 	}
 }
 
+// FormatEntry renders a parsed entry for terminal display
+func FormatEntry(entry LogEntry) string {
+	return TextFormatter{}.Format(entry)
+}
+
 func ParseLog(log string) string {
 	entry := ParseLogEntry(log)
-	return formatLogEntry(entry)
+	return formatLogEntry(entry, TextFormatter{})
 }
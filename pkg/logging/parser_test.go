@@ -3,6 +3,8 @@ package logging
 import (
 	"testing"
 	"time"
+
+	"github.com/fatih/color"
 )
 
 func TestParseLogEntry(t *testing.T) {
@@ -305,3 +307,35 @@ func TestParseTimestamp(t *testing.T) {
 		})
 	}
 }
+
+func TestTextFormatter_Fields(t *testing.T) {
+	defer func(noColor bool) { color.NoColor = noColor }(color.NoColor)
+	color.NoColor = true
+
+	entry := ParseLogEntry(`{"time":"2024-01-15T10:30:00Z","level":"info","msg":"done","zone":"b","status":200,"app":"api"}`)
+
+	tests := []struct {
+		name      string
+		formatter TextFormatter
+		want      string
+	}{
+		{
+			name:      "curated fields in key order",
+			formatter: TextFormatter{},
+			want:      "[2024-01-15 10:30:00] [INFO] [logrus] done app=api status=200 zone=b",
+		},
+		{
+			name:      "all fields",
+			formatter: TextFormatter{AllFields: true},
+			want:      "[2024-01-15 10:30:00] [INFO] [logrus] done app=api level=info status=200 time=2024-01-15T10:30:00Z zone=b",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.formatter.Format(entry); got != tt.want {
+				t.Errorf("Format() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
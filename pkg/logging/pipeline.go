@@ -0,0 +1,118 @@
+package logging
+
+import "time"
+
+// Pipeline parses raw log lines, applies transforms to the resulting entries
+// and runs them through a chain of filters before they are handed to an output
+type Pipeline struct {
+	parsers    []LineParser
+	aliases    map[string]string
+	epochUnits map[string]time.Duration
+	folders    []func() Folder
+	transforms []Transform
+	filters    FilterChain
+}
+
+// NewPipeline creates a Pipeline applying the given filters in order
+func NewPipeline(filters ...Filter) *Pipeline {
+	return &Pipeline{filters: filters}
+}
+
+// Use appends filters to the end of the pipeline
+func (p *Pipeline) Use(filters ...Filter) {
+	p.filters = append(p.filters, filters...)
+}
+
+// Parse adds parsers for custom plain text formats. They are tried in order
+// before the built-in formats are detected.
+func (p *Pipeline) Parse(parsers ...LineParser) {
+	p.parsers = append(p.parsers, parsers...)
+}
+
+// Alias renames fields when entries are parsed, e.g. lvl to level or @m to
+// message, so in-house field names are recognized like the standard ones
+func (p *Pipeline) Alias(aliases map[string]string) {
+	if p.aliases == nil {
+		p.aliases = make(map[string]string, len(aliases))
+	}
+	for from, to := range aliases {
+		p.aliases[from] = to
+	}
+}
+
+// EpochUnits sets the units of the epoch timestamps of fields, e.g. ts in
+// ms, so numbers whose size doesn't tell their unit are read right. Fields
+// are named as they are after aliasing.
+func (p *Pipeline) EpochUnits(units map[string]time.Duration) {
+	if p.epochUnits == nil {
+		p.epochUnits = make(map[string]time.Duration, len(units))
+	}
+	for field, unit := range units {
+		p.epochUnits[field] = unit
+	}
+}
+
+// Fold adds folders joining multi-line records. Folders hold per-stream state,
+// so the pipeline stores constructors and each Stream creates its own.
+// Pipeline.Process handles single lines and does not fold.
+func (p *Pipeline) Fold(newFolders ...func() Folder) {
+	p.folders = append(p.folders, newFolders...)
+}
+
+// Transform appends transforms to the pipeline. Transforms run in the order
+// they were added, before any filter sees the entry.
+func (p *Pipeline) Transform(transforms ...Transform) {
+	p.transforms = append(p.transforms, transforms...)
+}
+
+// Clone returns a copy of the pipeline that can be extended independently.
+// The transforms and filters themselves are shared with the original.
+func (p *Pipeline) Clone() *Pipeline {
+	if p == nil {
+		return NewPipeline()
+	}
+	clone := &Pipeline{
+		parsers:    append([]LineParser{}, p.parsers...),
+		folders:    append([]func() Folder{}, p.folders...),
+		transforms: append([]Transform{}, p.transforms...),
+		filters:    append(FilterChain{}, p.filters...),
+	}
+	clone.Alias(p.aliases)
+	clone.EpochUnits(p.epochUnits)
+	return clone
+}
+
+// Process parses a single line and reports whether it passed every filter
+func (p *Pipeline) Process(line string) (LogEntry, bool) {
+	return p.ProcessEntry(p.parse(line))
+}
+
+// parse tries the pipeline's custom parsers before detecting the format
+func (p *Pipeline) parse(line string) LogEntry {
+	if p != nil && detectLogFormat(line) != FormatJSON {
+		for _, parser := range p.parsers {
+			if entry, ok := parser.Parse(line); ok {
+				extractEmbeddedJSON(&entry)
+				applyFieldAliases(entry.Fields, p.aliases)
+				return entry
+			}
+		}
+	}
+	if p == nil {
+		return ParseLogEntry(line)
+	}
+	return parseLogEntry(line, p.aliases, p.epochUnits)
+}
+
+// ProcessEntry runs an already parsed entry through the pipeline
+func (p *Pipeline) ProcessEntry(entry LogEntry) (LogEntry, bool) {
+	if p == nil {
+		return entry, true
+	}
+	for _, t := range p.transforms {
+		if !t.Apply(&entry) {
+			return entry, false
+		}
+	}
+	return entry, p.filters.Match(entry)
+}
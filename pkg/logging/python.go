@@ -0,0 +1,106 @@
+package logging
+
+import (
+	"strings"
+)
+
+// pythonTracebackHeader starts every Python traceback
+const pythonTracebackHeader = "Traceback (most recent call last):"
+
+// pythonChainMarkers introduce a chained traceback after an exception line
+var pythonChainMarkers = []string{
+	"During handling of the above exception, another exception occurred:",
+	"The above exception was the direct cause of the following exception:",
+}
+
+// maxFoldedLines bounds the number of lines a folder collects into one entry
+const maxFoldedLines = 1000
+
+// PythonTracebackFolder folds Python tracebacks into a single ERROR entry.
+// The final exception line becomes the message, its type the "exception"
+// field and the frames are stored in the "traceback" field.
+type PythonTracebackFolder struct {
+	// Expand keeps the whole traceback as the message instead of the exception line
+	Expand bool
+
+	lines []string
+}
+
+// NewPythonTracebackFolder creates a folder for Python tracebacks
+func NewPythonTracebackFolder(expand bool) Folder {
+	return &PythonTracebackFolder{Expand: expand}
+}
+
+// Fold implements the Folder interface
+func (f *PythonTracebackFolder) Fold(entry LogEntry) []LogEntry {
+	line := entry.RawLine
+	if f.lines == nil {
+		if isPythonTracebackStart(line) {
+			f.lines = []string{line}
+			return nil
+		}
+		return []LogEntry{entry}
+	}
+
+	// Frames are indented; anything else ends the traceback with the exception
+	if strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t") || isPythonTracebackStart(line) {
+		f.lines = append(f.lines, line)
+		if len(f.lines) >= maxFoldedLines {
+			return f.Flush()
+		}
+		return nil
+	}
+	folded := f.build(line)
+	f.lines = nil
+	return []LogEntry{folded}
+}
+
+// Flush implements the Folder interface
+func (f *PythonTracebackFolder) Flush() []LogEntry {
+	if f.lines == nil {
+		return nil
+	}
+	folded := f.build("")
+	f.lines = nil
+	return []LogEntry{folded}
+}
+
+// build creates the folded entry from the collected frames and the exception line
+func (f *PythonTracebackFolder) build(exception string) LogEntry {
+	raw := append(append([]string{}, f.lines...), exception)
+	entry := LogEntry{
+		Level:   ERROR,
+		Format:  FormatPlainText,
+		Logger:  "python",
+		Message: exception,
+		RawLine: strings.TrimRight(strings.Join(raw, "\n"), "\n"),
+		Fields: map[string]interface{}{
+			"traceback": append([]string{}, f.lines...),
+		},
+	}
+	if exception == "" {
+		// The stream ended before the exception line
+		entry.Message = f.lines[0]
+	} else {
+		name, _, _ := strings.Cut(exception, ":")
+		entry.Fields["exception"] = name
+	}
+	if f.Expand {
+		entry.Message = entry.RawLine
+	}
+	return entry
+}
+
+// isPythonTracebackStart reports whether a line starts a traceback or a chained one
+func isPythonTracebackStart(line string) bool {
+	line = strings.TrimSpace(line)
+	if line == pythonTracebackHeader {
+		return true
+	}
+	for _, marker := range pythonChainMarkers {
+		if line == marker {
+			return true
+		}
+	}
+	return false
+}
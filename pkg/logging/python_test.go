@@ -0,0 +1,110 @@
+package logging
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPythonTracebackFolder(t *testing.T) {
+	traceback := []string{
+		"Traceback (most recent call last):",
+		`  File "/app/main.py", line 12, in <module>`,
+		"    main()",
+		`  File "/app/main.py", line 8, in main`,
+		"    raise ValueError(\"bad config\")",
+		"ValueError: bad config",
+	}
+
+	tests := []struct {
+		name         string
+		lines        []string
+		expand       bool
+		wantMessages []string
+	}{
+		{
+			name:         "Traceback between regular lines",
+			lines:        append(append([]string{"starting worker"}, traceback...), "worker stopped"),
+			wantMessages: []string{"starting worker", "ValueError: bad config", "worker stopped"},
+		},
+		{
+			name:   "Expanded traceback",
+			lines:  traceback,
+			expand: true,
+			wantMessages: []string{
+				"Traceback (most recent call last):\n" +
+					"  File \"/app/main.py\", line 12, in <module>\n" +
+					"    main()\n" +
+					"  File \"/app/main.py\", line 8, in main\n" +
+					"    raise ValueError(\"bad config\")\n" +
+					"ValueError: bad config",
+			},
+		},
+		{
+			name: "Chained traceback",
+			lines: append(append(append([]string{}, traceback...),
+				"During handling of the above exception, another exception occurred:",
+				"Traceback (most recent call last):",
+				`  File "/app/main.py", line 14, in <module>`,
+				"    sys.exit(1)"),
+				"SystemExit: 1"),
+			wantMessages: []string{"ValueError: bad config", "SystemExit: 1"},
+		},
+		{
+			name:         "Stream ends inside a traceback",
+			lines:        traceback[:3],
+			wantMessages: []string{"Traceback (most recent call last):"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewPipeline()
+			p.Fold(func() Folder { return NewPythonTracebackFolder(tt.expand) })
+			stream := p.NewStream()
+
+			var entries []LogEntry
+			for _, line := range tt.lines {
+				entries = append(entries, stream.Process(line)...)
+			}
+			entries = append(entries, stream.Flush()...)
+
+			var messages []string
+			for _, entry := range entries {
+				messages = append(messages, entry.Message)
+			}
+			if !reflect.DeepEqual(messages, tt.wantMessages) {
+				t.Errorf("messages = %q, want %q", messages, tt.wantMessages)
+			}
+		})
+	}
+}
+
+func TestPythonTracebackFolder_Fields(t *testing.T) {
+	p := NewPipeline(LevelFilter{Min: ERROR})
+	p.Fold(func() Folder { return NewPythonTracebackFolder(false) })
+	stream := p.NewStream()
+
+	var entries []LogEntry
+	for _, line := range []string{
+		"Traceback (most recent call last):",
+		`  File "/app/db.py", line 3, in connect`,
+		"KeyError: 'host'",
+	} {
+		entries = append(entries, stream.Process(line)...)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("got %d entries, want 1", len(entries))
+	}
+
+	got := entries[0]
+	if got.Level != ERROR || got.Logger != "python" {
+		t.Errorf("Level = %v, Logger = %v, want ERROR python", got.Level, got.Logger)
+	}
+	if got.Fields["exception"] != "KeyError" {
+		t.Errorf("exception = %v, want KeyError", got.Fields["exception"])
+	}
+	wantFrames := []string{"Traceback (most recent call last):", `  File "/app/db.py", line 3, in connect`}
+	if !reflect.DeepEqual(got.Fields["traceback"], wantFrames) {
+		t.Errorf("traceback = %q, want %q", got.Fields["traceback"], wantFrames)
+	}
+}
@@ -0,0 +1,186 @@
+package logging
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// rubyLoggerRegex matches the prefix written by Ruby's Logger, which Rails uses, e.g.
+	//
+	//	I, [2024-03-15T12:19:57.123456 #1]  INFO -- : [8f3c...] Started GET "/" for 10.0.0.1 at ...
+	rubyLoggerRegex = regexp.MustCompile(
+		`^[DIWEFA], \[(?P<time>\S+) #(?P<pid>\d+)\]\s+(?P<level>[A-Z]+) -- (?P<progname>[^:]*): (?P<message>.*)$`)
+
+	// railsTagRegex matches a leading tag added by config.log_tags, usually the request ID
+	railsTagRegex = regexp.MustCompile(`^\[(?P<tag>[^\]]+)\] `)
+
+	// railsRequestIDRegex matches the UUIDs Rails assigns as request IDs
+	railsRequestIDRegex = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`)
+
+	railsStartedRegex = regexp.MustCompile(
+		`^Started (?P<method>[A-Z]+) "(?P<path>[^"]*)" for (?P<remote_ip>\S+) at (?P<time>.+)$`)
+	railsProcessingRegex = regexp.MustCompile(
+		`^Processing by (?P<controller>[\w:]+)#(?P<action>\w+) as (?P<format>\S+)`)
+	railsCompletedRegex = regexp.MustCompile(
+		`^Completed (?P<status>\d{3}) (?P<status_text>.*?) in (?P<duration>\d+(?:\.\d+)?)ms(?: \((?P<details>.*)\))?$`)
+
+	// railsRuntimeRegex matches the runtimes in a Completed line, e.g. "Views: 5.1ms"
+	railsRuntimeRegex = regexp.MustCompile(`(Views|ActiveRecord): (\d+(?:\.\d+)?)ms`)
+
+	// pumaRegex matches Puma's startup and lifecycle lines, e.g.
+	//
+	//	[1] * Listening on http://0.0.0.0:3000
+	pumaRegex = regexp.MustCompile(
+		`^(?:\[(?P<pid>\d+)\] )?(?:(?:\* |- )(?P<setting>.+)|(?P<message>Puma starting .*|Use Ctrl-C to stop|Gracefully stopping.*|Restarting\.\.\.))$`)
+)
+
+// railsRuntimeFields maps the runtimes of a Completed line to field names
+var railsRuntimeFields = map[string]string{
+	"Views":        "view_runtime",
+	"ActiveRecord": "db_runtime",
+}
+
+// pumaSettings are the prefixes of Puma startup lines and the field they set
+var pumaSettings = map[string]string{
+	"Puma version: ":   "puma_version",
+	"Listening on ":    "listen",
+	"Environment: ":    "environment",
+	"Min threads: ":    "",
+	"Max threads: ":    "",
+	"Master PID: ":     "",
+	"Workers: ":        "",
+	"Ruby version: ":   "",
+	"Restart command:": "",
+	"Worker ":          "",
+	"Goodbye!":         "",
+}
+
+// parseRailsLog parses Rails request lines, optionally prefixed by Ruby's Logger
+func parseRailsLog(line string) (LogEntry, bool) {
+	entry := LogEntry{
+		Format:  FormatPlainText,
+		Logger:  "rails",
+		Level:   INFO,
+		RawLine: line,
+		Fields:  make(map[string]interface{}),
+	}
+
+	body, prefixed := line, false
+	if match := rubyLoggerRegex.FindStringSubmatch(line); match != nil {
+		prefixed = true
+		if ts, err := parseTimestamp(match[1]); err == nil {
+			entry.Timestamp = ts
+		}
+		if pid, err := strconv.ParseFloat(match[2], 64); err == nil {
+			entry.Fields["pid"] = pid
+		}
+		if level, err := ParseLogLevel(match[3]); err == nil {
+			entry.Level = level
+		}
+		if progname := strings.TrimSpace(match[4]); progname != "" {
+			entry.Fields["progname"] = progname
+		}
+		body = match[5]
+	}
+
+	for {
+		match := railsTagRegex.FindStringSubmatch(body)
+		if match == nil {
+			break
+		}
+		if railsRequestIDRegex.MatchString(match[1]) {
+			entry.Fields["request_id"] = match[1]
+		}
+		body = body[len(match[0]):]
+	}
+	entry.Message = body
+
+	// Without the Logger prefix only request lines identify a Rails log
+	if !parseRailsRequestLine(body, &entry) && !prefixed {
+		return LogEntry{}, false
+	}
+	return entry, true
+}
+
+// parseRailsRequestLine extracts the request fields of Started, Processing and
+// Completed lines into the entry, reporting whether the line was one of them
+func parseRailsRequestLine(body string, entry *LogEntry) bool {
+	if match := railsStartedRegex.FindStringSubmatch(body); match != nil {
+		entry.Fields["method"] = match[1]
+		entry.Fields["path"] = match[2]
+		entry.Fields["remote_ip"] = match[3]
+		if entry.Timestamp.IsZero() {
+			if ts, err := time.Parse("2006-01-02 15:04:05 -0700", match[4]); err == nil {
+				entry.Timestamp = ts
+			}
+		}
+		return true
+	}
+	if match := railsProcessingRegex.FindStringSubmatch(body); match != nil {
+		entry.Fields["controller"] = match[1]
+		entry.Fields["action"] = match[2]
+		entry.Fields["format"] = match[3]
+		return true
+	}
+	if match := railsCompletedRegex.FindStringSubmatch(body); match != nil {
+		status, _ := strconv.ParseFloat(match[1], 64)
+		duration, _ := strconv.ParseFloat(match[3], 64)
+		entry.Fields["status"] = status
+		entry.Fields["duration"] = duration
+		for _, runtime := range railsRuntimeRegex.FindAllStringSubmatch(match[4], -1) {
+			if value, err := strconv.ParseFloat(runtime[2], 64); err == nil {
+				entry.Fields[railsRuntimeFields[runtime[1]]] = value
+			}
+		}
+		if level := accessLogLevel(status); level > entry.Level {
+			entry.Level = level
+		}
+		return true
+	}
+	return false
+}
+
+// parsePumaLog parses Puma's startup and lifecycle lines
+func parsePumaLog(line string) (LogEntry, bool) {
+	match := pumaRegex.FindStringSubmatch(line)
+	if match == nil {
+		return LogEntry{}, false
+	}
+
+	entry := LogEntry{
+		Format:  FormatPlainText,
+		Logger:  "puma",
+		Level:   INFO,
+		RawLine: line,
+		Message: match[3],
+		Fields:  make(map[string]interface{}),
+	}
+	if setting := match[2]; setting != "" {
+		if !parsePumaSetting(setting, &entry) {
+			return LogEntry{}, false
+		}
+		entry.Message = setting
+	}
+	if pid, err := strconv.ParseFloat(match[1], 64); err == nil {
+		entry.Fields["pid"] = pid
+	}
+	return entry, true
+}
+
+// parsePumaSetting extracts the value of a known Puma startup setting into the
+// entry, reporting whether the setting was recognized
+func parsePumaSetting(setting string, entry *LogEntry) bool {
+	for prefix, field := range pumaSettings {
+		if !strings.HasPrefix(setting, prefix) {
+			continue
+		}
+		if value := strings.Fields(strings.TrimPrefix(setting, prefix)); field != "" && len(value) > 0 {
+			entry.Fields[field] = value[0]
+		}
+		return true
+	}
+	return false
+}
@@ -0,0 +1,138 @@
+package logging
+
+import (
+	"testing"
+)
+
+func TestParseRailsLog(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		wantLogger  string
+		wantLevel   LogLevel
+		wantMessage string
+		wantFields  map[string]interface{}
+	}{
+		{
+			name:        "Started request",
+			input:       `Started GET "/users/42?tab=posts" for 10.0.0.7 at 2024-03-15 12:19:57 +0000`,
+			wantLogger:  "rails",
+			wantLevel:   INFO,
+			wantMessage: `Started GET "/users/42?tab=posts" for 10.0.0.7 at 2024-03-15 12:19:57 +0000`,
+			wantFields: map[string]interface{}{
+				"method":    "GET",
+				"path":      "/users/42?tab=posts",
+				"remote_ip": "10.0.0.7",
+			},
+		},
+		{
+			name:        "Completed with Logger prefix and request ID tag",
+			input:       "E, [2024-03-15T12:19:57.123456 #1] ERROR -- : [8f3c2a1e-6b7d-4c2f-9a0e-1d2b3c4d5e6f] Completed 500 Internal Server Error in 87ms (Views: 5.1ms | ActiveRecord: 1.2ms | Allocations: 1234)",
+			wantLogger:  "rails",
+			wantLevel:   ERROR,
+			wantMessage: "Completed 500 Internal Server Error in 87ms (Views: 5.1ms | ActiveRecord: 1.2ms | Allocations: 1234)",
+			wantFields: map[string]interface{}{
+				"status":       float64(500),
+				"duration":     float64(87),
+				"view_runtime": 5.1,
+				"db_runtime":   1.2,
+				"pid":          float64(1),
+				"request_id":   "8f3c2a1e-6b7d-4c2f-9a0e-1d2b3c4d5e6f",
+			},
+		},
+		{
+			name:        "Client error is a warning",
+			input:       "Completed 404 Not Found in 3ms (ActiveRecord: 0.4ms)",
+			wantLogger:  "rails",
+			wantLevel:   WARN,
+			wantMessage: "Completed 404 Not Found in 3ms (ActiveRecord: 0.4ms)",
+			wantFields: map[string]interface{}{
+				"status":   float64(404),
+				"duration": float64(3),
+			},
+		},
+		{
+			name:        "Processing line",
+			input:       "Processing by Admin::UsersController#show as JSON",
+			wantLogger:  "rails",
+			wantLevel:   INFO,
+			wantMessage: "Processing by Admin::UsersController#show as JSON",
+			wantFields: map[string]interface{}{
+				"controller": "Admin::UsersController",
+				"action":     "show",
+				"format":     "JSON",
+			},
+		},
+		{
+			name:        "Other line with Logger prefix",
+			input:       "W, [2024-03-15T12:19:57.123456 #12]  WARN -- sidekiq: Job retried",
+			wantLogger:  "rails",
+			wantLevel:   WARN,
+			wantMessage: "Job retried",
+			wantFields: map[string]interface{}{
+				"progname": "sidekiq",
+				"pid":      float64(12),
+			},
+		},
+		{
+			name:        "Puma listening",
+			input:       "[1] * Listening on http://0.0.0.0:3000",
+			wantLogger:  "puma",
+			wantLevel:   INFO,
+			wantMessage: "Listening on http://0.0.0.0:3000",
+			wantFields: map[string]interface{}{
+				"listen": "http://0.0.0.0:3000",
+				"pid":    float64(1),
+			},
+		},
+		{
+			name:        "Puma version",
+			input:       `* Puma version: 6.4.0 (ruby 3.2.2-p53) ("The Eagle of Durango")`,
+			wantLogger:  "puma",
+			wantLevel:   INFO,
+			wantMessage: `Puma version: 6.4.0 (ruby 3.2.2-p53) ("The Eagle of Durango")`,
+			wantFields: map[string]interface{}{
+				"puma_version": "6.4.0",
+			},
+		},
+		{
+			name:        "Puma starting",
+			input:       "[1] Puma starting in cluster mode...",
+			wantLogger:  "puma",
+			wantLevel:   INFO,
+			wantMessage: "Puma starting in cluster mode...",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParseLogEntry(tt.input)
+			if got.Logger != tt.wantLogger {
+				t.Fatalf("Logger = %v, want %v", got.Logger, tt.wantLogger)
+			}
+			if got.Level != tt.wantLevel {
+				t.Errorf("Level = %v, want %v", got.Level, tt.wantLevel)
+			}
+			if got.Message != tt.wantMessage {
+				t.Errorf("Message = %q, want %q", got.Message, tt.wantMessage)
+			}
+			for k, v := range tt.wantFields {
+				if got.Fields[k] != v {
+					t.Errorf("Fields[%q] = %v, want %v", k, got.Fields[k], v)
+				}
+			}
+		})
+	}
+}
+
+func TestParseRailsLog_Unrelated(t *testing.T) {
+	for _, line := range []string{
+		"* a markdown bullet",
+		"Started the worker",
+		"- item",
+	} {
+		if got := ParseLogEntry(line); got.Logger == "rails" || got.Logger == "puma" {
+			t.Errorf("ParseLogEntry(%q).Logger = %v, want generic plain text", line, got.Logger)
+		}
+	}
+}
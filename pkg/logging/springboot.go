@@ -0,0 +1,60 @@
+package logging
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// springBootRegex matches Spring Boot's default console pattern, e.g.
+//
+//	2024-03-15T12:19:57.123Z  INFO 12345 --- [myapp] [           main] c.e.demo.DemoApplication : Started DemoApplication
+//
+// The application name in brackets is only printed by Spring Boot 3.2 and later.
+var springBootRegex = regexp.MustCompile(
+	`^(?P<time>\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2}(?:[.,]\d+)?(?:Z|[+-]\d{2}:?\d{2})?)\s+` +
+		`(?P<level>TRACE|DEBUG|INFO|WARN|ERROR|FATAL)\s+(?P<pid>\d+)\s+---\s+` +
+		`(?:\[(?P<app>[^\]]*)\]\s+)?\[\s*(?P<thread>[^\]]*?)\s*\]\s+` +
+		`(?P<logger>\S+)\s*:\s(?P<message>.*)$`)
+
+// parseSpringBootLog parses a line in Spring Boot's default Logback console format
+func parseSpringBootLog(line string) (LogEntry, bool) {
+	if !strings.Contains(line, " --- ") {
+		return LogEntry{}, false
+	}
+	match := springBootRegex.FindStringSubmatch(line)
+	if match == nil {
+		return LogEntry{}, false
+	}
+	groups := make(map[string]string)
+	for i, name := range springBootRegex.SubexpNames() {
+		if name != "" {
+			groups[name] = match[i]
+		}
+	}
+
+	entry := LogEntry{
+		Format:  FormatPlainText,
+		Logger:  "logback",
+		Message: groups["message"],
+		RawLine: line,
+		Fields: map[string]interface{}{
+			"thread": groups["thread"],
+			"logger": groups["logger"],
+		},
+	}
+	if pid, err := strconv.ParseFloat(groups["pid"], 64); err == nil {
+		entry.Fields["pid"] = pid
+	}
+	if groups["app"] != "" {
+		entry.Fields["app"] = strings.TrimSpace(groups["app"])
+	}
+	if level, err := ParseLogLevel(groups["level"]); err == nil {
+		entry.Level = level
+	}
+	// Logback separates milliseconds with a comma in some locales
+	if ts, err := parseTimestamp(strings.Replace(groups["time"], ",", ".", 1)); err == nil {
+		entry.Timestamp = ts
+	}
+	return entry, true
+}
@@ -0,0 +1,72 @@
+package logging
+
+import (
+	"testing"
+)
+
+func TestParseSpringBootLog(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		wantLevel   LogLevel
+		wantMessage string
+		wantFields  map[string]interface{}
+	}{
+		{
+			name:        "Spring Boot 2 format",
+			input:       "2024-03-15 12:19:57.123  INFO 12345 --- [           main] c.e.demo.DemoApplication                 : Started DemoApplication in 1.234 seconds",
+			wantLevel:   INFO,
+			wantMessage: "Started DemoApplication in 1.234 seconds",
+			wantFields: map[string]interface{}{
+				"pid":    float64(12345),
+				"thread": "main",
+				"logger": "c.e.demo.DemoApplication",
+			},
+		},
+		{
+			name:        "Spring Boot 3.2 format with application name",
+			input:       "2024-03-15T12:19:57.123+01:00 ERROR 1 --- [checkout] [nio-8080-exec-1] o.a.c.c.C.[.[.[/].[dispatcherServlet]    : Servlet.service() threw exception",
+			wantLevel:   ERROR,
+			wantMessage: "Servlet.service() threw exception",
+			wantFields: map[string]interface{}{
+				"pid":    float64(1),
+				"app":    "checkout",
+				"thread": "nio-8080-exec-1",
+				"logger": "o.a.c.c.C.[.[.[/].[dispatcherServlet]",
+			},
+		},
+		{
+			name:        "Comma millisecond separator",
+			input:       "2024-03-15 12:19:57,123  WARN 7 --- [pool-1-thread-3] com.example.Worker : Retrying job",
+			wantLevel:   WARN,
+			wantMessage: "Retrying job",
+			wantFields: map[string]interface{}{
+				"thread": "pool-1-thread-3",
+				"logger": "com.example.Worker",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParseLogEntry(tt.input)
+			if got.Logger != "logback" {
+				t.Fatalf("Logger = %v, want logback", got.Logger)
+			}
+			if got.Level != tt.wantLevel {
+				t.Errorf("Level = %v, want %v", got.Level, tt.wantLevel)
+			}
+			if got.Message != tt.wantMessage {
+				t.Errorf("Message = %q, want %q", got.Message, tt.wantMessage)
+			}
+			if got.Timestamp.IsZero() {
+				t.Error("Timestamp was not parsed")
+			}
+			for k, v := range tt.wantFields {
+				if got.Fields[k] != v {
+					t.Errorf("Fields[%q] = %v, want %v", k, got.Fields[k], v)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,156 @@
+package logging
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// LevelCounter counts entries per log level. It implements Transform so it can
+// be added to a pipeline, where it sees every entry before filtering.
+// It is safe for concurrent use.
+type LevelCounter struct {
+	counts [ERROR + 1]uint64
+}
+
+// Apply implements the Transform interface; it never drops entries
+func (c *LevelCounter) Apply(entry *LogEntry) bool {
+	if entry.Level >= DEBUG && entry.Level <= ERROR {
+		atomic.AddUint64(&c.counts[entry.Level], 1)
+	}
+	return true
+}
+
+// Count returns the number of entries seen at the given level
+func (c *LevelCounter) Count(level LogLevel) uint64 {
+	return atomic.LoadUint64(&c.counts[level])
+}
+
+// Total returns the number of entries seen at any level
+func (c *LevelCounter) Total() uint64 {
+	var total uint64
+	for level := DEBUG; level <= ERROR; level++ {
+		total += c.Count(level)
+	}
+	return total
+}
+
+// ErrorRate returns the fraction of entries at ERROR level, between 0 and 1
+func (c *LevelCounter) ErrorRate() float64 {
+	total := c.Total()
+	if total == 0 {
+		return 0
+	}
+	return float64(c.Count(ERROR)) / float64(total)
+}
+
+// String summarizes the counts, e.g. "120 lines, 3 errors (2.5%)"
+func (c *LevelCounter) String() string {
+	return fmt.Sprintf("%d lines, %d errors (%.1f%%)", c.Total(), c.Count(ERROR), c.ErrorRate()*100)
+}
+
+// StatusCounter counts access log entries per HTTP status class (2xx, 3xx,
+// 4xx and 5xx). Like LevelCounter it implements Transform and is safe for
+// concurrent use.
+type StatusCounter struct {
+	counts [6]uint64
+}
+
+// Apply implements the Transform interface; it never drops entries
+func (c *StatusCounter) Apply(entry *LogEntry) bool {
+	if code, ok := StatusCode(*entry); ok {
+		atomic.AddUint64(&c.counts[code/100], 1)
+	}
+	return true
+}
+
+// Count returns the number of entries seen with a status in the given class,
+// e.g. 5 for 5xx
+func (c *StatusCounter) Count(class int) uint64 {
+	if class < 1 || class >= len(c.counts) {
+		return 0
+	}
+	return atomic.LoadUint64(&c.counts[class])
+}
+
+// Total returns the number of entries seen with any status code
+func (c *StatusCounter) Total() uint64 {
+	var total uint64
+	for class := 1; class < len(c.counts); class++ {
+		total += c.Count(class)
+	}
+	return total
+}
+
+// String summarizes the counts, e.g. "2xx 120, 3xx 0, 4xx 3, 5xx 1"
+func (c *StatusCounter) String() string {
+	return fmt.Sprintf("2xx %d, 3xx %d, 4xx %d, 5xx %d", c.Count(2), c.Count(3), c.Count(4), c.Count(5))
+}
+
+// LatencyWindow is the number of recent durations a LatencyRecorder keeps
+const LatencyWindow = 1000
+
+// LatencyRecorder keeps the durations of the most recent LatencyWindow entries
+// logging one, found with Latency, to compute rolling percentiles. Like
+// LevelCounter it implements Transform and is safe for concurrent use.
+type LatencyRecorder struct {
+	mu      sync.Mutex
+	samples []time.Duration
+	next    int
+}
+
+// Apply implements the Transform interface; it never drops entries
+func (r *LatencyRecorder) Apply(entry *LogEntry) bool {
+	d, ok := Latency(*entry)
+	if !ok {
+		return true
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.samples) < LatencyWindow {
+		r.samples = append(r.samples, d)
+	} else {
+		r.samples[r.next] = d
+		r.next = (r.next + 1) % LatencyWindow
+	}
+	return true
+}
+
+// Percentile returns the duration below which the given percentage of the
+// recent durations fall, e.g. 95 for p95, or false if none were recorded
+func (r *LatencyRecorder) Percentile(p float64) (time.Duration, bool) {
+	r.mu.Lock()
+	sorted := append([]time.Duration{}, r.samples...)
+	r.mu.Unlock()
+	if len(sorted) == 0 {
+		return 0, false
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	// Nearest-rank method
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	if rank > len(sorted) {
+		rank = len(sorted)
+	}
+	return sorted[rank-1], true
+}
+
+// String summarizes the percentiles, e.g. "p50 12ms, p95 250ms, p99 1.2s"
+func (r *LatencyRecorder) String() string {
+	parts := make([]string, 0, 3)
+	for _, p := range []float64{50, 95, 99} {
+		d, ok := r.Percentile(p)
+		if !ok {
+			return "no durations"
+		}
+		parts = append(parts, fmt.Sprintf("p%.0f %s", p, HumanizeDuration(d)))
+	}
+	return strings.Join(parts, ", ")
+}
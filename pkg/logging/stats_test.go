@@ -0,0 +1,85 @@
+package logging
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestStatusCounter(t *testing.T) {
+	lines := []string{
+		`{"msg":"request","status":200}`,
+		`{"msg":"request","status":"204"}`,
+		`{"msg":"request","status_code":404}`,
+		`[2024-01-15T10:30:00.000Z] "GET /api HTTP/1.1" 503 UF 0 91 30 - "-" "curl/8.0" "abc" "api" "10.0.0.5:8080"`,
+		`{"level":"info","msg":"no status here"}`,
+	}
+
+	c := &StatusCounter{}
+	for _, line := range lines {
+		entry := ParseLogEntry(line)
+		if !c.Apply(&entry) {
+			t.Fatalf("Apply(%q) = false, want true", line)
+		}
+	}
+
+	for class, want := range map[int]uint64{1: 0, 2: 2, 3: 0, 4: 1, 5: 1, 9: 0} {
+		if got := c.Count(class); got != want {
+			t.Errorf("Count(%d) = %d, want %d", class, got, want)
+		}
+	}
+	if got := c.Total(); got != 4 {
+		t.Errorf("Total() = %d, want 4", got)
+	}
+	if got, want := c.String(), "2xx 2, 3xx 0, 4xx 1, 5xx 1"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+func TestLatencyRecorder(t *testing.T) {
+	r := &LatencyRecorder{}
+	if _, ok := r.Percentile(50); ok {
+		t.Error("Percentile() ok = true before any duration was recorded")
+	}
+
+	for i := 1; i <= 100; i++ {
+		entry := ParseLogEntry(fmt.Sprintf(`{"msg":"request","duration_ms":%d}`, i))
+		r.Apply(&entry)
+	}
+	entry := ParseLogEntry(`{"msg":"no duration"}`)
+	r.Apply(&entry)
+
+	tests := []struct {
+		p    float64
+		want time.Duration
+	}{
+		{50, 50 * time.Millisecond},
+		{95, 95 * time.Millisecond},
+		{99, 99 * time.Millisecond},
+		{100, 100 * time.Millisecond},
+		{0, time.Millisecond},
+	}
+	for _, tt := range tests {
+		if got, ok := r.Percentile(tt.p); !ok || got != tt.want {
+			t.Errorf("Percentile(%v) = %v, %v; want %v", tt.p, got, ok, tt.want)
+		}
+	}
+	if got, want := r.String(), "p50 50ms, p95 95ms, p99 99ms"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+func TestLatencyRecorder_Window(t *testing.T) {
+	r := &LatencyRecorder{}
+	for i := 0; i < LatencyWindow; i++ {
+		entry := ParseLogEntry(`{"latency":5000}`)
+		r.Apply(&entry)
+	}
+	for i := 0; i < LatencyWindow; i++ {
+		entry := ParseLogEntry(`{"latency":10}`)
+		r.Apply(&entry)
+	}
+	if got, _ := r.Percentile(99); got != 10*time.Millisecond {
+		t.Errorf("Percentile(99) = %v, want 10ms once older durations leave the window", got)
+	}
+}
@@ -0,0 +1,104 @@
+package logging
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// StatusFields are the field names HTTP status codes are commonly logged
+// under: NGINX and Rails use "status", Envoy "response_code" and ECS
+// "http.response.status_code"
+var StatusFields = []string{
+	"status", "status_code", "statusCode", "response_code", "http_status",
+	"http.response.status_code", "http.status_code",
+}
+
+// StatusCode returns the HTTP status code of an access log entry, or false if
+// it has none
+func StatusCode(entry LogEntry) (int, bool) {
+	for _, key := range StatusFields {
+		n, ok := numericValue(entry.Fields[key])
+		if ok && n >= 100 && n < 600 && n == float64(int(n)) {
+			return int(n), true
+		}
+	}
+	return 0, false
+}
+
+// StatusRange is an inclusive range of HTTP status codes
+type StatusRange struct {
+	Min, Max int
+}
+
+// StatusFilter keeps access log entries whose status code is in one of its
+// ranges; entries without a status code are dropped
+type StatusFilter struct {
+	Ranges []StatusRange
+}
+
+// NewStatusFilter builds a StatusFilter from specs such as "5xx", "404" or
+// "400-499"; a spec may list several separated by commas
+func NewStatusFilter(specs []string) (*StatusFilter, error) {
+	f := &StatusFilter{}
+	for _, spec := range specs {
+		for _, part := range strings.Split(spec, ",") {
+			r, err := parseStatusRange(strings.TrimSpace(part))
+			if err != nil {
+				return nil, err
+			}
+			f.Ranges = append(f.Ranges, r)
+		}
+	}
+	return f, nil
+}
+
+// parseStatusRange parses a single status spec
+func parseStatusRange(spec string) (StatusRange, error) {
+	invalid := fmt.Errorf("invalid status %q (expected e.g. 5xx, 404 or 400-499)", spec)
+
+	if class, ok := strings.CutSuffix(strings.ToLower(spec), "xx"); ok {
+		n, err := strconv.Atoi(class)
+		if err != nil || n < 1 || n > 5 {
+			return StatusRange{}, invalid
+		}
+		return StatusRange{Min: n * 100, Max: n*100 + 99}, nil
+	}
+
+	lo, hi, isRange := strings.Cut(spec, "-")
+	if !isRange {
+		hi = lo
+	}
+	min, err := parseStatusCode(lo)
+	if err != nil {
+		return StatusRange{}, invalid
+	}
+	max, err := parseStatusCode(hi)
+	if err != nil || max < min {
+		return StatusRange{}, invalid
+	}
+	return StatusRange{Min: min, Max: max}, nil
+}
+
+// parseStatusCode parses a three-digit HTTP status code
+func parseStatusCode(s string) (int, error) {
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 100 || n > 599 {
+		return 0, fmt.Errorf("invalid status code %q", s)
+	}
+	return n, nil
+}
+
+// Match implements the Filter interface
+func (f *StatusFilter) Match(entry LogEntry) bool {
+	code, ok := StatusCode(entry)
+	if !ok {
+		return false
+	}
+	for _, r := range f.Ranges {
+		if code >= r.Min && code <= r.Max {
+			return true
+		}
+	}
+	return false
+}
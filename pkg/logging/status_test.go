@@ -0,0 +1,71 @@
+package logging
+
+import (
+	"testing"
+)
+
+func TestNewStatusFilter(t *testing.T) {
+	tests := []struct {
+		name    string
+		specs   []string
+		want    []StatusRange
+		wantErr bool
+	}{
+		{"class", []string{"5xx"}, []StatusRange{{500, 599}}, false},
+		{"upper case class", []string{"4XX"}, []StatusRange{{400, 499}}, false},
+		{"single code", []string{"404"}, []StatusRange{{404, 404}}, false},
+		{"range", []string{"400-499"}, []StatusRange{{400, 499}}, false},
+		{"comma separated", []string{"429,5xx"}, []StatusRange{{429, 429}, {500, 599}}, false},
+		{"invalid class", []string{"6xx"}, nil, true},
+		{"reversed range", []string{"499-400"}, nil, true},
+		{"not a code", []string{"ok"}, nil, true},
+		{"out of range", []string{"1000"}, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewStatusFilter(tt.specs)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewStatusFilter() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(got.Ranges) != len(tt.want) {
+				t.Fatalf("NewStatusFilter() = %v, want %v", got.Ranges, tt.want)
+			}
+			for i := range tt.want {
+				if got.Ranges[i] != tt.want[i] {
+					t.Errorf("NewStatusFilter() = %v, want %v", got.Ranges, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestStatusFilter_Match(t *testing.T) {
+	f, err := NewStatusFilter([]string{"5xx"})
+	if err != nil {
+		t.Fatalf("NewStatusFilter() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		line string
+		want bool
+	}{
+		{"nginx JSON 502", `{"remote_addr":"10.0.0.1","request":"GET / HTTP/1.1","status":"502"}`, true},
+		{"JSON 200", `{"level":"info","msg":"request","status_code":200}`, false},
+		{"envoy access log", `[2024-01-15T10:30:00.000Z] "GET /api HTTP/1.1" 503 UF 0 91 30 - "-" "curl/8.0" "abc" "api" "10.0.0.5:8080"`, true},
+		{"rails completed", `Completed 500 Internal Server Error in 12ms`, true},
+		{"no status code", `{"level":"error","msg":"connection lost"}`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := f.Match(ParseLogEntry(tt.line)); got != tt.want {
+				t.Errorf("Match(%q) = %v, want %v", tt.line, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,156 @@
+package logging
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync/atomic"
+)
+
+// ParseIssue is a kind of problem parsing a line, after which kubelog falls
+// back to a default such as the DEBUG level
+type ParseIssue int
+
+const (
+	// MalformedJSON is a line that looks like JSON but doesn't decode
+	MalformedJSON ParseIssue = iota
+	// UnknownLevel is a level field whose value isn't a known level
+	UnknownLevel
+	// MissingLevel is a line without any level
+	MissingLevel
+	// BadTimestamp is a timestamp that none of the known formats match
+	BadTimestamp
+
+	parseIssueCount
+)
+
+// String returns the description of the issue, e.g. "malformed JSON"
+func (i ParseIssue) String() string {
+	switch i {
+	case MalformedJSON:
+		return "malformed JSON"
+	case UnknownLevel:
+		return "unknown level"
+	case MissingLevel:
+		return "no level"
+	case BadTimestamp:
+		return "unparsable timestamp"
+	}
+	return fmt.Sprintf("ParseIssue(%d)", int(i))
+}
+
+// ParseProblem is an issue found parsing a line, with what caused it
+type ParseProblem struct {
+	Issue ParseIssue
+	// Detail is e.g. the value of the level field, or the JSON error
+	Detail string
+}
+
+// String describes the problem, e.g. `unknown level "verbose"`
+func (p ParseProblem) String() string {
+	if p.Detail == "" {
+		return p.Issue.String()
+	}
+	return p.Issue.String() + ": " + p.Detail
+}
+
+// DiagnoseEntry returns the problems parsing an entry as it was parsed,
+// before any transform changed it
+func DiagnoseEntry(entry LogEntry) []ParseProblem {
+	if entry.Format != FormatJSON && strings.HasPrefix(strings.TrimSpace(entry.RawLine), "{") {
+		var data map[string]interface{}
+		if err := json.Unmarshal([]byte(strings.TrimSpace(entry.RawLine)), &data); err != nil {
+			return []ParseProblem{{Issue: MalformedJSON, Detail: err.Error()}}
+		}
+	}
+
+	var problems []ParseProblem
+	if entry.Format == FormatJSON {
+		if value, ok := firstField(entry.Fields, jsonLevelFields); ok {
+			if _, err := ParseLogLevel(fmt.Sprintf("%v", value)); err != nil {
+				problems = append(problems, ParseProblem{Issue: UnknownLevel, Detail: fmt.Sprintf("%q", fmt.Sprint(value))})
+			}
+		}
+		if value, ok := firstField(entry.Fields, jsonTimeFields); ok && entry.Timestamp.IsZero() {
+			problems = append(problems, ParseProblem{Issue: BadTimestamp, Detail: fmt.Sprintf("%q", fmt.Sprint(value))})
+		}
+	} else if ts := plainTextTime.FindString(entry.RawLine); ts != "" && entry.Timestamp.IsZero() {
+		problems = append(problems, ParseProblem{Issue: BadTimestamp, Detail: fmt.Sprintf("%q", ts)})
+	}
+	// Levels set by a parser, such as those of Envoy access logs, count as logged
+	if !hasLevel(entry) && entry.Level == DEBUG {
+		problems = append(problems, ParseProblem{Issue: MissingLevel})
+	}
+	return problems
+}
+
+// firstField returns the value of the first of the fields present
+func firstField(fields map[string]interface{}, names []string) (interface{}, bool) {
+	for _, name := range names {
+		if value, ok := fields[name]; ok {
+			return value, true
+		}
+	}
+	return nil, false
+}
+
+// ParseChecker counts the entries that didn't parse cleanly, by issue. It
+// implements Transform, and is added to a pipeline before any other transform
+// to see the entries as they were parsed. It is safe for concurrent use.
+type ParseChecker struct {
+	// OnProblem is called with every entry that has problems (optional)
+	OnProblem func(entry LogEntry, problems []ParseProblem)
+
+	lines  uint64
+	failed uint64
+	counts [parseIssueCount]uint64
+}
+
+// Apply implements the Transform interface; it never drops entries
+func (c *ParseChecker) Apply(entry *LogEntry) bool {
+	atomic.AddUint64(&c.lines, 1)
+	problems := DiagnoseEntry(*entry)
+	if len(problems) == 0 {
+		return true
+	}
+	atomic.AddUint64(&c.failed, 1)
+	for _, p := range problems {
+		atomic.AddUint64(&c.counts[p.Issue], 1)
+	}
+	if c.OnProblem != nil {
+		c.OnProblem(*entry, problems)
+	}
+	return true
+}
+
+// Lines returns the number of entries checked
+func (c *ParseChecker) Lines() uint64 {
+	return atomic.LoadUint64(&c.lines)
+}
+
+// Failed returns the number of entries with at least one problem
+func (c *ParseChecker) Failed() uint64 {
+	return atomic.LoadUint64(&c.failed)
+}
+
+// Count returns the number of entries with the given issue
+func (c *ParseChecker) Count(issue ParseIssue) uint64 {
+	return atomic.LoadUint64(&c.counts[issue])
+}
+
+// String summarizes the counts, e.g. "3 of 120 lines didn't parse cleanly
+// (2.5%): unknown level 2, no level 1"
+func (c *ParseChecker) String() string {
+	lines, failed := c.Lines(), c.Failed()
+	if failed == 0 {
+		return fmt.Sprintf("all %d lines parsed cleanly", lines)
+	}
+	var issues []string
+	for issue := ParseIssue(0); issue < parseIssueCount; issue++ {
+		if n := c.Count(issue); n > 0 {
+			issues = append(issues, fmt.Sprintf("%s %d", issue, n))
+		}
+	}
+	return fmt.Sprintf("%d of %d lines didn't parse cleanly (%.1f%%): %s",
+		failed, lines, float64(failed)/float64(lines)*100, strings.Join(issues, ", "))
+}
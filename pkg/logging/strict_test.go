@@ -0,0 +1,65 @@
+package logging
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiagnoseEntry(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want []ParseIssue
+	}{
+		{"clean JSON", `{"level":"info","msg":"ok","time":"2024-01-15T10:00:00Z"}`, nil},
+		{"truncated JSON", `{"level":"info","msg":"o`, []ParseIssue{MalformedJSON}},
+		{"unknown level", `{"level":"verbose","msg":"ok"}`, []ParseIssue{UnknownLevel}},
+		{"JSON without level", `{"msg":"ok"}`, []ParseIssue{MissingLevel}},
+		{"numeric level", `{"level":30,"msg":"ok"}`, nil},
+		{"unparsable timestamp", `{"level":"info","msg":"ok","time":"15/Jan/2024:10:00:00"}`, []ParseIssue{BadTimestamp}},
+		{"epoch timestamp", `{"level":"info","msg":"ok","ts":1705312800}`, nil},
+		{"plain text with level", "2024-01-15 10:00:00 INFO started", nil},
+		{"plain text without level", "listening on :8080", []ParseIssue{MissingLevel}},
+		{"recognized format", "2024-03-15 12:19:57.123  INFO 12345 --- [           main] c.e.demo.DemoApplication                 : Started", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []ParseIssue
+			for _, p := range DiagnoseEntry(ParseLogEntry(tt.line)) {
+				got = append(got, p.Issue)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DiagnoseEntry(%q) = %v, want %v", tt.line, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseChecker(t *testing.T) {
+	var reported []string
+	checker := &ParseChecker{OnProblem: func(entry LogEntry, problems []ParseProblem) {
+		reported = append(reported, problems[0].String())
+	}}
+	pipeline := NewPipeline()
+	pipeline.Transform(checker)
+	for _, line := range []string{
+		`{"level":"info","msg":"ok"}`,
+		`{"level":"verbose","msg":"ok"}`,
+		`{"level":"info"`,
+		"listening on :8080",
+	} {
+		pipeline.Process(line)
+	}
+
+	if checker.Lines() != 4 || checker.Failed() != 3 || checker.Count(UnknownLevel) != 1 {
+		t.Errorf("lines %d, failed %d, unknown levels %d; want 4, 3, 1", checker.Lines(), checker.Failed(), checker.Count(UnknownLevel))
+	}
+	want := []string{`unknown level: "verbose"`, "malformed JSON: unexpected end of JSON input", "no level"}
+	if !reflect.DeepEqual(reported, want) {
+		t.Errorf("reported %q, want %q", reported, want)
+	}
+	if got, want := checker.String(), "3 of 4 lines didn't parse cleanly (75.0%): malformed JSON 1, unknown level 1, no level 1"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,168 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Source identifies the container an entry was read from
+type Source struct {
+	Namespace string
+	Pod       string
+	Container string
+	Node      string
+	// Labels holds the pod labels selected for output, e.g. with --add-labels
+	Labels map[string]string
+}
+
+// record returns the source as a map for structured output, omitting empty values
+func (s *Source) record() map[string]interface{} {
+	record := make(map[string]interface{})
+	for key, value := range map[string]string{
+		"namespace": s.Namespace,
+		"pod":       s.Pod,
+		"container": s.Container,
+		"node":      s.Node,
+	} {
+		if value != "" {
+			record[key] = value
+		}
+	}
+	if len(s.Labels) > 0 {
+		record["labels"] = s.Labels
+	}
+	return record
+}
+
+// entryRecord returns the fields of an entry together with its normalized
+// timestamp, level and message, and its source under "kubernetes"
+func entryRecord(entry LogEntry) map[string]interface{} {
+	record := make(map[string]interface{}, len(entry.Fields)+4)
+	for key, value := range entry.Fields {
+		record[key] = value
+	}
+	if !entry.Timestamp.IsZero() {
+		record["timestamp"] = entry.Timestamp.Format(time.RFC3339Nano)
+	}
+	record["level"] = entry.Level.String()
+	record["message"] = entry.Message
+	if entry.Source != nil {
+		record["kubernetes"] = entry.Source.record()
+	}
+	return record
+}
+
+// JSONFormatter renders entries as indented JSON objects, or with Compact as
+// JSON Lines: one object per line for jq and other tools. Keys are sorted, so
+// the output of the same entry is always identical.
+type JSONFormatter struct {
+	Compact bool
+}
+
+// Format implements the Formatter interface
+func (f JSONFormatter) Format(entry LogEntry) string {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if !f.Compact {
+		enc.SetIndent("", "  ")
+	}
+	if err := enc.Encode(entryRecord(entry)); err != nil {
+		return fmt.Sprintf(`{"message": %q, "error": %q}`, entry.Message, err.Error())
+	}
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// logfmtLeadingKeys are written first, in this order; other keys follow sorted
+var logfmtLeadingKeys = []string{"timestamp", "level", "message"}
+
+// LogfmtFormatter renders entries as logfmt key=value pairs. The source is
+// flattened into kubernetes.pod=..., kubernetes.labels.team=... keys.
+type LogfmtFormatter struct{}
+
+// Format implements the Formatter interface
+func (LogfmtFormatter) Format(entry LogEntry) string {
+	record := entryRecord(entry)
+	flat := make(map[string]interface{}, len(record))
+	flattenRecord("", record, flat)
+
+	var keys []string
+	for _, key := range logfmtLeadingKeys {
+		if _, ok := flat[key]; ok {
+			keys = append(keys, key)
+			delete(flat, key)
+		}
+	}
+	rest := make([]string, 0, len(flat))
+	for key := range flat {
+		rest = append(rest, key)
+	}
+	sort.Strings(rest)
+
+	pairs := make([]string, 0, len(keys)+len(rest))
+	for _, key := range keys {
+		pairs = append(pairs, key+"="+logfmtValue(record[key]))
+	}
+	for _, key := range rest {
+		pairs = append(pairs, logfmtKey(key)+"="+logfmtValue(flat[key]))
+	}
+	return strings.Join(pairs, " ")
+}
+
+// flattenRecord copies record into flat, joining the keys of nested string
+// maps with dots
+func flattenRecord(prefix string, record map[string]interface{}, flat map[string]interface{}) {
+	for key, value := range record {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			flattenRecord(prefix+key+".", v, flat)
+		case map[string]string:
+			for k, s := range v {
+				flat[prefix+key+"."+k] = s
+			}
+		default:
+			flat[prefix+key] = value
+		}
+	}
+}
+
+// logfmtKey replaces characters that are not allowed in logfmt keys
+func logfmtKey(key string) string {
+	return strings.Map(func(r rune) rune {
+		if r <= ' ' || r == '=' || r == '"' {
+			return '_'
+		}
+		return r
+	}, key)
+}
+
+// logfmtValue renders a value, quoting it when it is empty or contains
+// spaces, quotes or equals signs
+func logfmtValue(value interface{}) string {
+	var s string
+	switch v := value.(type) {
+	case string:
+		s = v
+	case float64:
+		s = strconv.FormatFloat(v, 'f', -1, 64)
+	case nil:
+		return ""
+	case bool, int, int64:
+		s = fmt.Sprintf("%v", v)
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			data = []byte(fmt.Sprintf("%v", v))
+		}
+		s = string(data)
+	}
+	if s == "" || strings.ContainsAny(s, " =\"\t\r\n") {
+		return strconv.Quote(s)
+	}
+	return s
+}
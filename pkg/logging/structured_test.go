@@ -0,0 +1,81 @@
+package logging
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestJSONFormatter_Format(t *testing.T) {
+	entry := ParseLogEntry(`{"level":"error","msg":"charge failed","time":"2024-01-15T10:30:00Z","status":502}`)
+	entry.Source = &Source{
+		Namespace: "shop",
+		Pod:       "api-7d4b9c8f6-x2k9z",
+		Container: "app",
+		Node:      "node-1",
+		Labels:    map[string]string{"team": "payments"},
+	}
+
+	var got map[string]interface{}
+	if err := json.Unmarshal([]byte(JSONFormatter{}.Format(entry)), &got); err != nil {
+		t.Fatalf("Format() returned invalid JSON: %v", err)
+	}
+	want := map[string]interface{}{
+		"level":     "ERROR",
+		"message":   "charge failed",
+		"msg":       "charge failed",
+		"time":      "2024-01-15T10:30:00Z",
+		"timestamp": "2024-01-15T10:30:00Z",
+		"status":    float64(502),
+		"kubernetes": map[string]interface{}{
+			"namespace": "shop",
+			"pod":       "api-7d4b9c8f6-x2k9z",
+			"container": "app",
+			"node":      "node-1",
+			"labels":    map[string]interface{}{"team": "payments"},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Format() = %v, want %v", got, want)
+	}
+}
+
+func TestJSONFormatter_Compact(t *testing.T) {
+	entry := ParseLogEntry(`{"msg":"GET /a?b=1&c=2","level":"info","status":200}`)
+	entry.Source = &Source{Namespace: "shop", Pod: "api-1"}
+
+	want := `{"kubernetes":{"namespace":"shop","pod":"api-1"},"level":"INFO","message":"GET /a?b=1&c=2","msg":"GET /a?b=1&c=2","status":200}`
+	for i := 0; i < 3; i++ {
+		if got := (JSONFormatter{Compact: true}).Format(entry); got != want {
+			t.Fatalf("Format() = %s, want %s", got, want)
+		}
+	}
+}
+
+func TestLogfmtFormatter_Format(t *testing.T) {
+	tests := []struct {
+		name  string
+		entry LogEntry
+		want  string
+	}{
+		{
+			name:  "JSON log with source",
+			entry: ParseLogEntry(`{"level":"info","msg":"GET /users","status":200,"user":{"id":"u 1"}}`),
+			want:  `level=INFO message="GET /users" kubernetes.container=app kubernetes.labels.team=payments kubernetes.namespace=shop kubernetes.pod=api-1 msg="GET /users" status=200 user.id="u 1"`,
+		},
+		{
+			name:  "Plain text without source",
+			entry: LogEntry{Level: WARN, Message: `retrying "db"`},
+			want:  `level=WARN message="retrying \"db\""`,
+		},
+	}
+	tests[0].entry.Source = &Source{Namespace: "shop", Pod: "api-1", Container: "app", Labels: map[string]string{"team": "payments"}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := (LogfmtFormatter{}).Format(tt.entry); got != tt.want {
+				t.Errorf("Format() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
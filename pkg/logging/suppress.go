@@ -0,0 +1,74 @@
+package logging
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// SuppressPreset is a built-in class of noisy lines that can be hidden by name
+type SuppressPreset struct {
+	Description string
+	pattern     *regexp.Regexp
+}
+
+// SuppressPresets are the presets accepted by NewSuppressFilter
+var SuppressPresets = map[string]SuppressPreset{
+	"probes": {
+		Description: "requests from kubelet liveness and readiness probes (kube-probe user agent)",
+		pattern:     regexp.MustCompile(`kube-probe/`),
+	},
+	"health-checks": {
+		Description: "requests to health and readiness endpoints such as /healthz, /readyz and /actuator/health",
+		pattern: regexp.MustCompile(`(?:^|[\s"'=:])/(?:healthz?|readyz|livez|ready|readiness|liveness|ping|actuator/health)` +
+			`(?:/[\w.-]*)*(?:[\s"'?]|$)`),
+	},
+	"elb": {
+		Description: "AWS load balancer health checks (ELB-HealthChecker user agent)",
+		pattern:     regexp.MustCompile(`ELB-HealthChecker/`),
+	},
+	"istio-heartbeat": {
+		Description: "istio-proxy readiness checks, Prometheus scrapes and ready messages",
+		pattern:     regexp.MustCompile(`/healthz/ready|/stats/prometheus|/app-health/|Envoy proxy is ready`),
+	},
+}
+
+// SuppressPresetNames returns the names of the built-in presets in sorted order
+func SuppressPresetNames() []string {
+	names := make([]string, 0, len(SuppressPresets))
+	for name := range SuppressPresets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SuppressFilter drops the entries matched by any of its presets
+type SuppressFilter struct {
+	Presets []SuppressPreset
+}
+
+// NewSuppressFilter builds a SuppressFilter from preset names such as
+// "health-checks" or "probes"
+func NewSuppressFilter(names []string) (*SuppressFilter, error) {
+	f := &SuppressFilter{}
+	for _, name := range names {
+		preset, ok := SuppressPresets[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown suppress preset %q (expected %s)", name, strings.Join(SuppressPresetNames(), ", "))
+		}
+		f.Presets = append(f.Presets, preset)
+	}
+	return f, nil
+}
+
+// Match implements the Filter interface
+func (f *SuppressFilter) Match(entry LogEntry) bool {
+	for _, preset := range f.Presets {
+		if preset.pattern.MatchString(entry.RawLine) {
+			return false
+		}
+	}
+	return true
+}
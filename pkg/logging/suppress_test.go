@@ -0,0 +1,41 @@
+package logging
+
+import (
+	"testing"
+)
+
+func TestSuppressFilter(t *testing.T) {
+	tests := []struct {
+		name    string
+		presets []string
+		line    string
+		want    bool
+	}{
+		{"kube-probe user agent", []string{"probes"}, `10.0.0.1 - - "GET /live HTTP/1.1" 200 2 "-" "kube-probe/1.29"`, false},
+		{"healthz in a text line", []string{"health-checks"}, `GET /healthz 200 1ms`, false},
+		{"readiness path in JSON", []string{"health-checks"}, `{"level":"info","path":"/actuator/health/readiness","status":200}`, false},
+		{"similar path is kept", []string{"health-checks"}, `{"level":"info","path":"/api/health-report","status":200}`, true},
+		{"ELB health checker", []string{"elb"}, `"GET / HTTP/1.1" 200 "ELB-HealthChecker/2.0"`, false},
+		{"istio readiness", []string{"istio-heartbeat"}, `[2024-01-15T10:30:00.000Z] "GET /healthz/ready HTTP/1.1" 200`, false},
+		{"preset not enabled", []string{"elb"}, `GET /healthz 200 1ms`, true},
+		{"ordinary request", []string{"probes", "health-checks", "elb", "istio-heartbeat"}, `GET /api/orders 200 12ms`, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := NewSuppressFilter(tt.presets)
+			if err != nil {
+				t.Fatalf("NewSuppressFilter() error = %v", err)
+			}
+			if got := f.Match(ParseLogEntry(tt.line)); got != tt.want {
+				t.Errorf("Match(%q) = %v, want %v", tt.line, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewSuppressFilter_UnknownPreset(t *testing.T) {
+	if _, err := NewSuppressFilter([]string{"probes", "chatty"}); err == nil {
+		t.Error("NewSuppressFilter() error = nil, want an error for an unknown preset")
+	}
+}
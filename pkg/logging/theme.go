@@ -0,0 +1,243 @@
+package logging
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dantech2000/kubelog/pkg/glyph"
+	"github.com/fatih/color"
+)
+
+// ThemeAuto picks the theme matching the terminal's background
+const ThemeAuto = "auto"
+
+// Theme is a palette for the colors of the formatted output
+type Theme struct {
+	// Levels color the level of the entries
+	Levels [ERROR + 1]*color.Color
+	// Symbols, if set, mark the level of the entries so it can be told
+	// without the colors
+	Symbols [ERROR + 1]glyph.Symbol
+	// Icons are shown in front of the entries with TextFormatter.Icons
+	Icons [ERROR + 1]glyph.Symbol
+	// Timestamp colors the timestamp of the entries
+	Timestamp *color.Color
+	// Logger colors the logger of the entries
+	Logger *color.Color
+	// Key and Value color the fields of the entries
+	Key   *color.Color
+	Value *color.Color
+	// Quote colors the quotes around string values, and null
+	Quote *color.Color
+	// Error colors the messages of errors
+	Error *color.Color
+	// Prefixes are cycled through to give every pod a color of its own
+	Prefixes []*color.Color
+}
+
+// defaultIcons tell the levels apart by shape as well as color. The ASCII
+// equivalents are their initials.
+var defaultIcons = [ERROR + 1]glyph.Symbol{
+	DEBUG: {Unicode: "🐛", ASCII: "D"},
+	INFO:  {Unicode: "💬", ASCII: "I"},
+	WARN:  {Unicode: "🔶", ASCII: "W"},
+	ERROR: {Unicode: "🔥", ASCII: "E"},
+}
+
+// Themes are the built-in themes, by name. The dark theme is the default.
+var Themes = map[string]Theme{
+	"dark": {
+		Levels: [ERROR + 1]*color.Color{
+			DEBUG: color.New(color.FgCyan),
+			INFO:  color.New(color.FgGreen),
+			WARN:  color.New(color.FgYellow),
+			ERROR: color.New(color.FgRed),
+		},
+		Icons:     defaultIcons,
+		Timestamp: color.New(color.FgBlue),
+		Logger:    color.New(color.FgMagenta),
+		Key:       color.New(color.FgCyan),
+		Value:     color.New(color.FgWhite),
+		Quote:     color.New(color.FgHiBlack),
+		Error:     color.New(color.FgRed, color.Bold),
+		Prefixes: []*color.Color{
+			color.New(color.FgCyan),
+			color.New(color.FgGreen),
+			color.New(color.FgMagenta),
+			color.New(color.FgYellow),
+			color.New(color.FgBlue),
+			color.New(color.FgHiCyan),
+			color.New(color.FgHiGreen),
+			color.New(color.FgHiMagenta),
+		},
+	},
+	// Light backgrounds wash out white, gray, yellow and the bright colors,
+	// so values keep the terminal's own foreground
+	"light": {
+		Levels: [ERROR + 1]*color.Color{
+			DEBUG: color.New(color.FgBlue),
+			INFO:  color.New(color.FgGreen),
+			WARN:  color.New(color.FgMagenta, color.Bold),
+			ERROR: color.New(color.FgRed),
+		},
+		Icons:     defaultIcons,
+		Timestamp: color.New(color.FgBlue),
+		Logger:    color.New(color.FgMagenta),
+		Key:       color.New(color.FgBlue),
+		Value:     color.New(color.Reset),
+		Quote:     color.New(color.Faint),
+		Error:     color.New(color.FgRed, color.Bold),
+		Prefixes: []*color.Color{
+			color.New(color.FgBlue),
+			color.New(color.FgGreen),
+			color.New(color.FgMagenta),
+			color.New(color.FgRed),
+			color.New(color.FgCyan),
+			color.New(color.FgBlue, color.Bold),
+			color.New(color.FgGreen, color.Bold),
+			color.New(color.FgMagenta, color.Bold),
+		},
+	},
+	// Red and green look alike with deuteranopia and protanopia, so levels
+	// differ in brightness and symbols, and blue and yellow, which stay apart
+	"colorblind": {
+		Levels: [ERROR + 1]*color.Color{
+			DEBUG: color.New(color.Faint),
+			INFO:  color.New(color.FgBlue),
+			WARN:  color.New(color.FgYellow, color.Bold),
+			ERROR: color.New(color.Bold, color.ReverseVideo),
+		},
+		Symbols: [ERROR + 1]glyph.Symbol{
+			DEBUG: {Unicode: "·", ASCII: "."},
+			INFO:  {Unicode: "●", ASCII: "*"},
+			WARN:  {Unicode: "▲", ASCII: "!"},
+			ERROR: {Unicode: "✖", ASCII: "X"},
+		},
+		Icons:     defaultIcons,
+		Timestamp: color.New(color.FgBlue),
+		Logger:    color.New(color.FgCyan),
+		Key:       color.New(color.FgCyan),
+		Value:     color.New(color.Reset),
+		Quote:     color.New(color.Faint),
+		Error:     color.New(color.Bold, color.Underline),
+		Prefixes: []*color.Color{
+			color.New(color.FgBlue),
+			color.New(color.FgYellow),
+			color.New(color.FgCyan),
+			color.New(color.FgMagenta),
+			color.New(color.FgBlue, color.Bold),
+			color.New(color.FgYellow, color.Bold),
+			color.New(color.FgCyan, color.Bold),
+			color.New(color.FgMagenta, color.Bold),
+		},
+	},
+}
+
+// ThemeNames returns the names of the built-in themes in sorted order
+func ThemeNames() []string {
+	names := make([]string, 0, len(Themes))
+	for name := range Themes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LookupTheme returns the built-in theme of the given name
+func LookupTheme(name string) (Theme, error) {
+	theme, ok := Themes[name]
+	if !ok {
+		return Theme{}, fmt.Errorf("unknown theme %q (expected %s or %s)", name, strings.Join(ThemeNames(), ", "), ThemeAuto)
+	}
+	return theme, nil
+}
+
+// currentTheme is the theme the output is formatted with
+var currentTheme = Themes["dark"]
+
+// CurrentTheme returns the theme the output is formatted with
+func CurrentTheme() Theme {
+	return currentTheme
+}
+
+// SetTheme changes the colors of the formatted output. It isn't safe to call
+// while entries are formatted, so it is meant to be called at startup.
+func SetTheme(theme Theme) {
+	currentTheme = theme
+	for level, c := range theme.Levels {
+		logLevelColors[LogLevel(level)] = c
+	}
+	for level, symbol := range theme.Symbols {
+		logLevelSymbols[LogLevel(level)] = symbol
+	}
+	for level, icon := range theme.Icons {
+		logLevelIcons[LogLevel(level)] = icon
+	}
+	timestampColor = theme.Timestamp
+	loggerColor = theme.Logger
+	keyColor = theme.Key
+	valueColor = theme.Value
+	quoteColor = theme.Quote
+	errorColor = theme.Error
+	jqNullColor = theme.Quote
+}
+
+// ThemeForBackground returns the name of the theme readable on a background
+// color, given as red, green and blue between 0 and 1
+func ThemeForBackground(r, g, b float64) string {
+	// Relative luminance as in ITU-R BT.709
+	if 0.2126*r+0.7152*g+0.0722*b > 0.5 {
+		return "light"
+	}
+	return "dark"
+}
+
+// ParseBackgroundReply parses the background color a terminal reports when
+// queried with OSC 11, e.g. "\x1b]11;rgb:ffff/ffff/ffff\x1b\\", and returns
+// the theme readable on it
+func ParseBackgroundReply(reply string) (string, bool) {
+	_, spec, ok := strings.Cut(reply, "rgb:")
+	if !ok {
+		return "", false
+	}
+	// The reply ends with BEL or ST, possibly followed by other replies
+	if i := strings.IndexAny(spec, "\x07\x1b"); i >= 0 {
+		spec = spec[:i]
+	}
+	parts := strings.Split(spec, "/")
+	if len(parts) != 3 {
+		return "", false
+	}
+	var rgb [3]float64
+	for i, part := range parts {
+		if len(part) == 0 || len(part) > 4 {
+			return "", false
+		}
+		n, err := strconv.ParseUint(part, 16, 16)
+		if err != nil {
+			return "", false
+		}
+		rgb[i] = float64(n) / float64(uint64(1)<<(4*len(part))-1)
+	}
+	return ThemeForBackground(rgb[0], rgb[1], rgb[2]), true
+}
+
+// ParseColorFGBG returns the theme readable on the background described by
+// the COLORFGBG variable some terminals set, e.g. "15;0" for white on black
+func ParseColorFGBG(value string) (string, bool) {
+	i := strings.LastIndex(value, ";")
+	if i < 0 {
+		return "", false
+	}
+	bg, err := strconv.Atoi(value[i+1:])
+	if err != nil || bg < 0 || bg > 15 {
+		return "", false
+	}
+	// Of the 16 ANSI colors, white and light gray are the light backgrounds
+	if bg == 7 || bg == 15 {
+		return "light", true
+	}
+	return "dark", true
+}
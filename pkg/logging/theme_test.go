@@ -0,0 +1,165 @@
+package logging
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dantech2000/kubelog/pkg/glyph"
+	"github.com/fatih/color"
+)
+
+func TestParseBackgroundReply(t *testing.T) {
+	tests := []struct {
+		name   string
+		reply  string
+		want   string
+		wantOK bool
+	}{
+		{name: "White, ST terminated", reply: "\x1b]11;rgb:ffff/ffff/ffff\x1b\\", want: "light", wantOK: true},
+		{name: "Black, BEL terminated", reply: "\x1b]11;rgb:0000/0000/0000\x07", want: "dark", wantOK: true},
+		{name: "Solarized light", reply: "\x1b]11;rgb:fdfd/f6f6/e3e3\x1b\\", want: "light", wantOK: true},
+		{name: "Solarized dark", reply: "\x1b]11;rgb:0000/2b2b/3636\x1b\\", want: "dark", wantOK: true},
+		{name: "Two digit components", reply: "\x1b]11;rgb:ee/ee/ee\x07", want: "light", wantOK: true},
+		{name: "Followed by the DA1 reply", reply: "\x1b]11;rgb:1e1e/1e1e/1e1e\x1b\\\x1b[?62;22c", want: "dark", wantOK: true},
+		{name: "Only the DA1 reply", reply: "\x1b[?62;22c", wantOK: false},
+		{name: "Malformed color", reply: "\x1b]11;rgb:ffff/ffff\x07", wantOK: false},
+		{name: "Not hexadecimal", reply: "\x1b]11;rgb:zzzz/ffff/ffff\x07", wantOK: false},
+		{name: "Empty", reply: "", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ParseBackgroundReply(tt.reply)
+			if ok != tt.wantOK || got != tt.want {
+				t.Errorf("ParseBackgroundReply(%q) = %q, %v, want %q, %v", tt.reply, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestParseColorFGBG(t *testing.T) {
+	tests := []struct {
+		value  string
+		want   string
+		wantOK bool
+	}{
+		{value: "15;0", want: "dark", wantOK: true},
+		{value: "0;15", want: "light", wantOK: true},
+		{value: "0;7", want: "light", wantOK: true},
+		{value: "0;default;15", want: "light", wantOK: true},
+		{value: "7;default", wantOK: false},
+		{value: "0;16", wantOK: false},
+		{value: "", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, ok := ParseColorFGBG(tt.value)
+			if ok != tt.wantOK || got != tt.want {
+				t.Errorf("ParseColorFGBG(%q) = %q, %v, want %q, %v", tt.value, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestLookupTheme(t *testing.T) {
+	for _, name := range ThemeNames() {
+		theme, err := LookupTheme(name)
+		if err != nil {
+			t.Fatalf("LookupTheme(%q) returned error: %v", name, err)
+		}
+		for level, c := range theme.Levels {
+			if c == nil {
+				t.Errorf("theme %q has no color for level %s", name, LogLevel(level))
+			}
+		}
+		if len(theme.Prefixes) == 0 {
+			t.Errorf("theme %q has no prefix colors", name)
+		}
+	}
+
+	if _, err := LookupTheme("solarized"); err == nil {
+		t.Error("LookupTheme(\"solarized\") returned no error")
+	}
+}
+
+func TestSetTheme(t *testing.T) {
+	defer func(noColor bool) { color.NoColor = noColor }(color.NoColor)
+	color.NoColor = false
+	defer SetTheme(CurrentTheme())
+
+	entry := LogEntry{Level: WARN, Message: "disk almost full", Fields: map[string]interface{}{"used": "95%"}}
+
+	SetTheme(Themes["dark"])
+	dark := FormatEntry(entry)
+	SetTheme(Themes["light"])
+	light := FormatEntry(entry)
+
+	if dark == light {
+		t.Fatalf("light and dark themes format the same: %q", dark)
+	}
+	// White values are unreadable on a light background
+	if white := color.New(color.FgWhite).Sprint("95%"); strings.Contains(light, white) {
+		t.Errorf("light theme formats values white: %q", light)
+	}
+}
+
+func TestColorblindTheme(t *testing.T) {
+	defer func(noColor bool) { color.NoColor = noColor }(color.NoColor)
+	color.NoColor = true
+	defer SetTheme(CurrentTheme())
+
+	theme, err := LookupTheme("colorblind")
+	if err != nil {
+		t.Fatalf("LookupTheme returned error: %v", err)
+	}
+	SetTheme(theme)
+
+	// Levels are told apart by their symbols without any color
+	seen := make(map[string]LogLevel)
+	for _, level := range []LogLevel{DEBUG, INFO, WARN, ERROR} {
+		got := FormatEntry(LogEntry{Level: level, Message: "message"})
+		symbol := theme.Symbols[level].String()
+		if want := symbol + " [" + level.String() + "]"; !strings.HasPrefix(got, want) {
+			t.Errorf("FormatEntry() at %s = %q, want prefix %q", level, got, want)
+		}
+		if other, ok := seen[symbol]; ok {
+			t.Errorf("levels %s and %s share the symbol %q", other, level, symbol)
+		}
+		seen[symbol] = level
+	}
+
+	SetTheme(Themes["dark"])
+	if got := FormatEntry(LogEntry{Level: ERROR, Message: "message"}); !strings.HasPrefix(got, "[ERROR]") {
+		t.Errorf("FormatEntry() with the dark theme = %q, want no symbol", got)
+	}
+}
+
+func TestTextFormatter_Icons(t *testing.T) {
+	defer func(noColor bool) { color.NoColor = noColor }(color.NoColor)
+	color.NoColor = true
+	defer SetTheme(CurrentTheme())
+	SetTheme(Themes["dark"])
+
+	entry := LogEntry{Level: ERROR, Message: "connection refused"}
+	tests := []struct {
+		name  string
+		icons bool
+		ascii bool
+		want  string
+	}{
+		{name: "Off by default", want: "[ERROR] connection refused"},
+		{name: "Icon", icons: true, want: "🔥 [ERROR] connection refused"},
+		{name: "ASCII icon", icons: true, ascii: true, want: "E [ERROR] connection refused"},
+	}
+	defer func() { glyph.ASCII = false }()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			glyph.ASCII = tt.ascii
+			if got := (TextFormatter{Icons: tt.icons}).Format(entry); got != tt.want {
+				t.Errorf("Format() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
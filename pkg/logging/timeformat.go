@@ -0,0 +1,113 @@
+package logging
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultTimeFormat is the layout timestamps are shown in by default
+const DefaultTimeFormat = "2006-01-02 15:04:05"
+
+// timeFormatPresets are the named layouts of --time-format
+var timeFormatPresets = map[string]TimeFormat{
+	"rfc3339":     {layout: time.RFC3339},
+	"rfc3339nano": {layout: time.RFC3339Nano},
+	"short":       {layout: "15:04:05"},
+	"kitchen":     {layout: time.Kitchen},
+	"epoch":       {unit: time.Second},
+	"epoch-ms":    {unit: time.Millisecond},
+}
+
+// TimeFormat renders the timestamps of text output, with a Go layout or as
+// an epoch number. The zero value uses DefaultTimeFormat.
+type TimeFormat struct {
+	layout string
+	// unit is the unit of epoch numbers, used instead of the layout
+	unit time.Duration
+}
+
+// ParseTimeFormat parses a preset name such as rfc3339, short or epoch-ms, or
+// a Go layout such as "15:04:05.000"
+func ParseTimeFormat(s string) (TimeFormat, error) {
+	if preset, ok := timeFormatPresets[strings.ToLower(s)]; ok {
+		return preset, nil
+	}
+	// A layout without any element renders every timestamp as itself
+	reference := time.Date(2001, 2, 3, 4, 5, 6, 0, time.UTC)
+	if s == "" || reference.Format(s) == s {
+		return TimeFormat{}, fmt.Errorf("invalid time format %q (expected %s or a Go layout such as 15:04:05.000)", s, strings.Join(TimeFormatPresets(), ", "))
+	}
+	return TimeFormat{layout: s}, nil
+}
+
+// TimeFormatPresets returns the names of the time format presets, sorted
+func TimeFormatPresets() []string {
+	names := make([]string, 0, len(timeFormatPresets))
+	for name := range timeFormatPresets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// timePrecisions are the units of --time-precision
+var timePrecisions = []struct {
+	name string
+	unit time.Duration
+}{
+	{"s", time.Second},
+	{"ms", time.Millisecond},
+	{"us", time.Microsecond},
+	{"ns", time.Nanosecond},
+}
+
+// fractionalSeconds matches the fractional seconds of a Go layout
+var fractionalSeconds = regexp.MustCompile(`05([.,](0+|9+))?`)
+
+// WithPrecision returns the format showing timestamps to the given unit: s,
+// ms, us or ns. Layouts show as many fractional digits of their seconds, and
+// epoch numbers count the unit, e.g. epoch with ms is epoch-ms.
+func (f TimeFormat) WithPrecision(precision string) (TimeFormat, error) {
+	var unit time.Duration
+	for _, p := range timePrecisions {
+		if p.name == precision {
+			unit = p.unit
+		}
+	}
+	if unit == 0 {
+		return f, fmt.Errorf("invalid time precision %q (expected s, ms, us or ns)", precision)
+	}
+	if f.unit != 0 {
+		return TimeFormat{unit: unit}, nil
+	}
+
+	layout := f.layout
+	if layout == "" {
+		layout = DefaultTimeFormat
+	}
+	if !strings.Contains(layout, "05") {
+		return f, fmt.Errorf("time format %q has no seconds to show to a precision", layout)
+	}
+	fraction := ""
+	if digits := len(strconv.FormatInt(int64(time.Second/unit), 10)) - 1; digits > 0 {
+		fraction = "." + strings.Repeat("0", digits)
+	}
+	return TimeFormat{layout: fractionalSeconds.ReplaceAllLiteralString(layout, "05"+fraction)}, nil
+}
+
+// Format renders t
+func (f TimeFormat) Format(t time.Time) string {
+	switch {
+	case f.unit == time.Second:
+		return strconv.FormatInt(t.Unix(), 10)
+	case f.unit != 0:
+		return strconv.FormatInt(t.UnixNano()/int64(f.unit), 10)
+	case f.layout == "":
+		return t.Format(DefaultTimeFormat)
+	}
+	return t.Format(f.layout)
+}
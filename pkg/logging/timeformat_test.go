@@ -0,0 +1,116 @@
+package logging
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseTimeFormat(t *testing.T) {
+	ts := time.Date(2024, 1, 15, 10, 30, 45, 123456789, time.UTC)
+	tests := []struct {
+		format  string
+		want    string
+		wantErr bool
+	}{
+		{"kitchen", "10:30AM", false},
+		{"rfc3339", "2024-01-15T10:30:45Z", false},
+		{"RFC3339Nano", "2024-01-15T10:30:45.123456789Z", false},
+		{"short", "10:30:45", false},
+		{"epoch", "1705314645", false},
+		{"epoch-ms", "1705314645123", false},
+		{"15:04:05.000", "10:30:45.123", false},
+		{"Jan _2 15:04", "Jan 15 10:30", false},
+		{"iso", "", true},
+		{"", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			f, err := ParseTimeFormat(tt.format)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseTimeFormat(%q) error = %v, wantErr %v", tt.format, err, tt.wantErr)
+			}
+			if err == nil && f.Format(ts) != tt.want {
+				t.Errorf("Format() = %q, want %q", f.Format(ts), tt.want)
+			}
+		})
+	}
+}
+
+func TestTextFormatter_TimeFormat(t *testing.T) {
+	entry := ParseLogEntry(`{"level":"info","msg":"done","time":"2024-01-15T10:30:45.123Z"}`)
+
+	got := TextFormatter{}.Format(entry)
+	if !strings.Contains(got, "[2024-01-15 10:30:45]") {
+		t.Errorf("default format: got %q", got)
+	}
+
+	f, err := ParseTimeFormat("short")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got = TextFormatter{TimeFormat: f}.Format(entry)
+	if !strings.Contains(got, "[10:30:45]") {
+		t.Errorf("short format: got %q", got)
+	}
+}
+
+func TestTimeFormat_WithPrecision(t *testing.T) {
+	ts := time.Date(2024, 1, 15, 10, 30, 45, 123456789, time.UTC)
+	tests := []struct {
+		format    string
+		precision string
+		want      string
+		wantErr   bool
+	}{
+		{"", "s", "2024-01-15 10:30:45", false},
+		{"", "ms", "2024-01-15 10:30:45.123", false},
+		{"", "us", "2024-01-15 10:30:45.123456", false},
+		{"short", "ns", "10:30:45.123456789", false},
+		{"rfc3339nano", "ms", "2024-01-15T10:30:45.123Z", false},
+		{"15:04:05.000", "s", "10:30:45", false},
+		{"epoch", "ms", "1705314645123", false},
+		{"epoch-ms", "us", "1705314645123456", false},
+		{"kitchen", "ms", "", true},
+		{"", "min", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format+"/"+tt.precision, func(t *testing.T) {
+			var f TimeFormat
+			if tt.format != "" {
+				var err error
+				if f, err = ParseTimeFormat(tt.format); err != nil {
+					t.Fatal(err)
+				}
+			}
+			f, err := f.WithPrecision(tt.precision)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("WithPrecision(%q) error = %v, wantErr %v", tt.precision, err, tt.wantErr)
+			}
+			if err == nil && f.Format(ts) != tt.want {
+				t.Errorf("Format() = %q, want %q", f.Format(ts), tt.want)
+			}
+		})
+	}
+}
+
+func TestParseLogEntry_SubSecondTimestamps(t *testing.T) {
+	tests := []struct {
+		line string
+		want time.Time
+	}{
+		{`{"level":"info","ts":1705314645.123456,"msg":"zap"}`, time.Date(2024, 1, 15, 10, 30, 45, 123456000, time.UTC)},
+		{`{"level":"info","time":1705314645123,"msg":"millis"}`, time.Date(2024, 1, 15, 10, 30, 45, 123000000, time.UTC)},
+		{"2024-01-15 10:30:45,123 INFO log4j", time.Date(2024, 1, 15, 10, 30, 45, 123000000, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			if got := ParseLogEntry(tt.line).Timestamp; !got.Equal(tt.want) {
+				t.Errorf("Timestamp = %v, want %v", got.UTC(), tt.want)
+			}
+		})
+	}
+}
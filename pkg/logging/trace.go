@@ -0,0 +1,29 @@
+package logging
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TraceFields are the field names trace and request IDs are commonly logged under
+var TraceFields = []string{
+	"trace_id", "traceId", "traceID", "trace.id", "trace",
+	"request_id", "requestId", "requestID", "x_request_id", "x-request-id",
+	"correlation_id", "correlationId", "dd.trace_id", "traceparent",
+}
+
+// TraceFilter keeps the entries of a single trace or request, identified by
+// the ID in one of the TraceFields or anywhere in the raw line
+type TraceFilter struct {
+	ID string
+}
+
+// Match implements the Filter interface
+func (f TraceFilter) Match(entry LogEntry) bool {
+	for _, name := range TraceFields {
+		if v, ok := entry.Fields[name]; ok && strings.Contains(fmt.Sprintf("%v", v), f.ID) {
+			return true
+		}
+	}
+	return strings.Contains(entry.RawLine, f.ID)
+}
@@ -0,0 +1,78 @@
+package logging
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Transform mutates a parsed log entry before it is filtered and formatted.
+// Returning false drops the entry entirely.
+type Transform interface {
+	Apply(entry *LogEntry) bool
+}
+
+// TransformFunc adapts an ordinary function to the Transform interface
+type TransformFunc func(entry *LogEntry) bool
+
+// Apply implements the Transform interface
+func (f TransformFunc) Apply(entry *LogEntry) bool {
+	return f(entry)
+}
+
+var (
+	transformsMu sync.RWMutex
+	transforms   = make(map[string]Transform)
+)
+
+// RegisterTransform makes a transform available by name, so it can be enabled
+// from the command line or configuration. It panics if the name is empty or
+// already registered.
+func RegisterTransform(name string, t Transform) {
+	transformsMu.Lock()
+	defer transformsMu.Unlock()
+
+	if name == "" || t == nil {
+		panic("logging: RegisterTransform requires a name and a transform")
+	}
+	if _, dup := transforms[name]; dup {
+		panic(fmt.Sprintf("logging: RegisterTransform called twice for %q", name))
+	}
+	transforms[name] = t
+}
+
+// LookupTransform returns the transform registered under name
+func LookupTransform(name string) (Transform, error) {
+	transformsMu.RLock()
+	defer transformsMu.RUnlock()
+
+	t, ok := transforms[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown transform %q", name)
+	}
+	return t, nil
+}
+
+// RegisteredTransforms returns the sorted names of all registered transforms
+func RegisteredTransforms() []string {
+	transformsMu.RLock()
+	defer transformsMu.RUnlock()
+
+	names := make([]string, 0, len(transforms))
+	for name := range transforms {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SetField returns a transform that sets a field to a fixed value on every entry
+func SetField(key string, value interface{}) Transform {
+	return TransformFunc(func(entry *LogEntry) bool {
+		if entry.Fields == nil {
+			entry.Fields = make(map[string]interface{})
+		}
+		entry.Fields[key] = value
+		return true
+	})
+}
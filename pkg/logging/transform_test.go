@@ -0,0 +1,100 @@
+package logging
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPipeline_Transform(t *testing.T) {
+	rewrite := TransformFunc(func(entry *LogEntry) bool {
+		entry.Message = strings.ToUpper(entry.Message)
+		return true
+	})
+	dropHealth := TransformFunc(func(entry *LogEntry) bool {
+		return !strings.Contains(entry.Message, "/healthz")
+	})
+
+	tests := []struct {
+		name        string
+		transforms  []Transform
+		filters     []Filter
+		input       string
+		wantKeep    bool
+		wantMessage string
+		wantField   string
+	}{
+		{
+			name:        "Rewrite message",
+			transforms:  []Transform{rewrite},
+			input:       `{"level":"info","msg":"server started"}`,
+			wantKeep:    true,
+			wantMessage: "SERVER STARTED",
+		},
+		{
+			name:       "Drop entry",
+			transforms: []Transform{dropHealth},
+			input:      "GET /healthz 200",
+			wantKeep:   false,
+		},
+		{
+			name:        "Added field is visible to filters",
+			transforms:  []Transform{SetField("env", "prod")},
+			filters:     []Filter{mustFieldFilter(t, "env=prod")},
+			input:       "plain text line",
+			wantKeep:    true,
+			wantMessage: "plain text line",
+			wantField:   "prod",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewPipeline(tt.filters...)
+			p.Transform(tt.transforms...)
+
+			got, keep := p.Process(tt.input)
+			if keep != tt.wantKeep {
+				t.Fatalf("Process() kept = %v, want %v", keep, tt.wantKeep)
+			}
+			if !keep {
+				return
+			}
+			if got.Message != tt.wantMessage {
+				t.Errorf("Message = %q, want %q", got.Message, tt.wantMessage)
+			}
+			if tt.wantField != "" && got.Fields["env"] != tt.wantField {
+				t.Errorf("Fields[env] = %v, want %v", got.Fields["env"], tt.wantField)
+			}
+			if !strings.Contains(FormatEntry(got), tt.wantMessage) {
+				t.Errorf("FormatEntry() = %q, want it to contain %q", FormatEntry(got), tt.wantMessage)
+			}
+		})
+	}
+}
+
+func TestRegisterTransform(t *testing.T) {
+	RegisterTransform("test-noop", TransformFunc(func(entry *LogEntry) bool { return true }))
+
+	if _, err := LookupTransform("test-noop"); err != nil {
+		t.Errorf("LookupTransform() error = %v", err)
+	}
+	if _, err := LookupTransform("does-not-exist"); err == nil {
+		t.Error("LookupTransform() expected error for unknown transform")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("RegisterTransform() expected panic on duplicate name")
+		}
+	}()
+	RegisterTransform("test-noop", TransformFunc(func(entry *LogEntry) bool { return true }))
+}
+
+func mustFieldFilter(t *testing.T, expr string) Filter {
+	t.Helper()
+	f, err := ParseFieldFilter(expr)
+	if err != nil {
+		t.Fatalf("ParseFieldFilter(%q) error = %v", expr, err)
+	}
+	return f
+}
@@ -0,0 +1,89 @@
+package logging
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// jsonWrapperFields hold the original log line when a log shipper or runtime
+// wraps it in JSON of its own, e.g. Docker's json-file driver uses "log"
+var jsonWrapperFields = []string{"log", "message"}
+
+// maxUnwrapDepth bounds how many layers of wrapping are decoded
+const maxUnwrapDepth = 3
+
+// unwrapJSON decodes escaped JSON logs nested in wrapper fields, such as
+//
+//	{"log":"{\"level\":\"info\",\"msg\":\"ready\"}\n","stream":"stdout"}
+//
+// The inner fields replace the wrapper field and take precedence over the
+// outer ones. Wrapped plain text has its escaped newlines and quotes decoded.
+func unwrapJSON(data map[string]interface{}) map[string]interface{} {
+	for depth := 0; depth < maxUnwrapDepth; depth++ {
+		field, inner, text, ok := unwrapField(data)
+		if !ok {
+			return data
+		}
+		if inner == nil {
+			data[field] = text
+			return data
+		}
+		for k, v := range data {
+			if _, exists := inner[k]; !exists && k != field {
+				inner[k] = v
+			}
+		}
+		data = inner
+	}
+	return data
+}
+
+// unwrapField finds the first wrapper field holding an escaped value and
+// returns its decoded JSON object or, for wrapped plain text, the decoded text
+func unwrapField(data map[string]interface{}) (field string, inner map[string]interface{}, text string, ok bool) {
+	for _, field := range jsonWrapperFields {
+		value, isString := data[field].(string)
+		if !isString {
+			continue
+		}
+		if inner, text, ok := decodeEscaped(value); ok {
+			return field, inner, text, true
+		}
+	}
+	return "", nil, "", false
+}
+
+// decodeEscaped decodes a string holding a JSON object, possibly encoded as a
+// JSON string itself, or plain text with escaped newlines and quotes. It
+// reports false when there is nothing to decode.
+func decodeEscaped(value string) (map[string]interface{}, string, bool) {
+	s := strings.TrimRight(value, "\r\n")
+	changed := s != value
+	for i := 0; i < maxUnwrapDepth; i++ {
+		trimmed := strings.TrimSpace(s)
+		if strings.HasPrefix(trimmed, "{") {
+			var object map[string]interface{}
+			if json.Unmarshal([]byte(trimmed), &object) == nil {
+				return object, "", true
+			}
+		}
+
+		var decoded string
+		switch {
+		case strings.HasPrefix(trimmed, `"`) && strings.HasSuffix(trimmed, `"`) && len(trimmed) > 1:
+			// A JSON string encoded once more
+			if json.Unmarshal([]byte(trimmed), &decoded) != nil {
+				return nil, s, changed
+			}
+		case strings.Contains(s, `\"`) || strings.Contains(s, `\n`):
+			// Escape sequences without the surrounding quotes
+			if json.Unmarshal([]byte(`"`+s+`"`), &decoded) != nil {
+				return nil, s, changed
+			}
+		default:
+			return nil, s, changed
+		}
+		s, changed = strings.TrimRight(decoded, "\r\n"), true
+	}
+	return nil, s, changed
+}
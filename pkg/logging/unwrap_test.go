@@ -0,0 +1,78 @@
+package logging
+
+import (
+	"testing"
+)
+
+func TestParseLogEntry_Unwrap(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		wantLevel   LogLevel
+		wantMessage string
+		wantLogger  string
+		wantFields  map[string]interface{}
+	}{
+		{
+			name:        "Docker json-file wrapping a JSON log",
+			input:       `{"log":"{\"level\":\"error\",\"msg\":\"db down\",\"retry\":3}\n","stream":"stderr","time":"2024-03-15T12:19:57.123Z"}`,
+			wantLevel:   ERROR,
+			wantMessage: "db down",
+			wantLogger:  "logrus",
+			wantFields: map[string]interface{}{
+				"retry":  float64(3),
+				"stream": "stderr",
+			},
+		},
+		{
+			name:        "Double encoded message",
+			input:       `{"message":"\"{\\\"level\\\":\\\"warn\\\",\\\"msg\\\":\\\"slow query\\\"}\"","host":"node-1"}`,
+			wantLevel:   WARN,
+			wantMessage: "slow query",
+			wantFields: map[string]interface{}{
+				"host": "node-1",
+			},
+		},
+		{
+			name:        "Inner fields take precedence",
+			input:       `{"log":"{\"level\":\"info\",\"msg\":\"ready\",\"stream\":\"app\"}","stream":"stdout"}`,
+			wantLevel:   INFO,
+			wantMessage: "ready",
+			wantFields: map[string]interface{}{
+				"stream": "app",
+			},
+		},
+		{
+			name:        "Wrapped plain text with escaped newlines",
+			input:       `{"level":"info","message":"first line\\nsecond line"}`,
+			wantLevel:   INFO,
+			wantMessage: "first line\nsecond line",
+		},
+		{
+			name:        "Unwrapped message is left alone",
+			input:       `{"level":"info","message":"C:\\temp is full"}`,
+			wantLevel:   INFO,
+			wantMessage: `C:\temp is full`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParseLogEntry(tt.input)
+			if got.Level != tt.wantLevel {
+				t.Errorf("Level = %v, want %v", got.Level, tt.wantLevel)
+			}
+			if got.Message != tt.wantMessage {
+				t.Errorf("Message = %q, want %q", got.Message, tt.wantMessage)
+			}
+			if tt.wantLogger != "" && got.Logger != tt.wantLogger {
+				t.Errorf("Logger = %v, want %v", got.Logger, tt.wantLogger)
+			}
+			for k, v := range tt.wantFields {
+				if got.Fields[k] != v {
+					t.Errorf("Fields[%q] = %v, want %v", k, got.Fields[k], v)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package pager
+
+import "unicode/utf8"
+
+// KeyCode identifies a key
+type KeyCode int
+
+const (
+	// KeyRune is a printable character, in Key.Rune
+	KeyRune KeyCode = iota
+	KeyEnter
+	KeyBackspace
+	KeyEscape
+	KeyInterrupt
+	KeyUp
+	KeyDown
+	KeyPageUp
+	KeyPageDown
+	KeyHome
+	KeyEnd
+	// KeyUnknown is an escape sequence that isn't handled
+	KeyUnknown
+)
+
+// Key is a key typed on the terminal
+type Key struct {
+	Code KeyCode
+	Rune rune
+}
+
+// escapeKeys are the escape sequences terminals send for special keys
+var escapeKeys = map[string]KeyCode{
+	"\x1b[A":  KeyUp,
+	"\x1bOA":  KeyUp,
+	"\x1b[B":  KeyDown,
+	"\x1bOB":  KeyDown,
+	"\x1b[5~": KeyPageUp,
+	"\x1b[6~": KeyPageDown,
+	"\x1b[H":  KeyHome,
+	"\x1bOH":  KeyHome,
+	"\x1b[1~": KeyHome,
+	"\x1b[F":  KeyEnd,
+	"\x1bOF":  KeyEnd,
+	"\x1b[4~": KeyEnd,
+}
+
+// ParseKeys decodes the bytes read from a terminal in raw mode into keys
+func ParseKeys(data []byte) []Key {
+	var keys []Key
+	for len(data) > 0 {
+		switch b := data[0]; {
+		case b == 0x1b:
+			n := escapeKeyLen(data)
+			if n == 1 {
+				keys = append(keys, Key{Code: KeyEscape})
+			} else if code, ok := escapeKeys[string(data[:n])]; ok {
+				keys = append(keys, Key{Code: code})
+			} else {
+				keys = append(keys, Key{Code: KeyUnknown})
+			}
+			data = data[n:]
+			continue
+		case b == '\r' || b == '\n':
+			keys = append(keys, Key{Code: KeyEnter})
+		case b == 0x7f || b == 0x08:
+			keys = append(keys, Key{Code: KeyBackspace})
+		case b == 0x03:
+			keys = append(keys, Key{Code: KeyInterrupt})
+		case b == 0x06:
+			keys = append(keys, Key{Code: KeyPageDown})
+		case b == 0x02:
+			keys = append(keys, Key{Code: KeyPageUp})
+		case b < 0x20:
+			keys = append(keys, Key{Code: KeyUnknown})
+		default:
+			r, size := utf8.DecodeRune(data)
+			keys = append(keys, Key{Code: KeyRune, Rune: r})
+			data = data[size:]
+			continue
+		}
+		data = data[1:]
+	}
+	return keys
+}
+
+// escapeKeyLen returns the length of the escape sequence data starts with:
+// ESC [ parameters final, ESC O final, or a lone ESC
+func escapeKeyLen(data []byte) int {
+	if len(data) < 2 {
+		return 1
+	}
+	switch data[1] {
+	case 'O':
+		if len(data) < 3 {
+			return 2
+		}
+		return 3
+	case '[':
+		for i := 2; i < len(data); i++ {
+			if data[i] >= 0x40 && data[i] <= 0x7e {
+				return i + 1
+			}
+		}
+		return len(data)
+	}
+	return 1
+}
@@ -0,0 +1,510 @@
+// Package pager implements the built-in pager of kubelog, which shows
+// historical logs one screen at a time with search, level toggling and
+// jumping to a timestamp.
+//
+// The Pager holds the lines and the state of the view; reading keys from the
+// terminal and switching it to raw mode is left to the caller.
+package pager
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/dantech2000/kubelog/pkg/logging"
+)
+
+// Line is a line of output, with the level and timestamp of its entry when
+// it was written for one
+type Line struct {
+	// Text is the formatted line, which may contain color codes and span
+	// several rows, e.g. a folded stack trace
+	Text string
+	// Leveled is set for the lines of entries; other lines, such as table
+	// headers, are always shown
+	Leveled bool
+	Level   logging.LogLevel
+	// Time is the timestamp of the entry, zero if it has none
+	Time time.Time
+}
+
+// mode is what the keys typed are for
+type mode int
+
+const (
+	modeNormal mode = iota
+	modeSearch
+	modeTime
+)
+
+// row is a screen row of a line
+type row struct {
+	line int
+	text string
+}
+
+// Pager shows lines one screen at a time
+type Pager struct {
+	lines  []Line
+	hidden [logging.ERROR + 1]bool
+
+	width, height int
+	rows          []row
+	top           int
+
+	mode    mode
+	input   string
+	query   string
+	message string
+	// matched is the line the last search found, -1 before any
+	matched int
+}
+
+// New creates an empty pager for a screen of the given size
+func New(width, height int) *Pager {
+	p := &Pager{matched: -1}
+	p.Resize(width, height)
+	return p
+}
+
+// Add appends a line
+func (p *Pager) Add(line Line) {
+	p.lines = append(p.lines, line)
+	if p.visible(line) {
+		for _, text := range wrap(line.Text, p.width) {
+			p.rows = append(p.rows, row{line: len(p.lines) - 1, text: text})
+		}
+	}
+}
+
+// Fits reports whether all lines fit on the screen without paging. The last
+// row is left for the prompt of the shell.
+func (p *Pager) Fits() bool {
+	return len(p.rows) < p.height
+}
+
+// Lines returns the text of all lines, to print them when they fit
+func (p *Pager) Lines() []string {
+	texts := make([]string, len(p.lines))
+	for i, line := range p.lines {
+		texts[i] = line.Text
+	}
+	return texts
+}
+
+// Resize changes the size of the screen, keeping the first line shown
+func (p *Pager) Resize(width, height int) {
+	if width < 1 {
+		width = 1
+	}
+	if height < 2 {
+		height = 2
+	}
+	if width == p.width && height == p.height {
+		return
+	}
+	p.width, p.height = width, height
+	p.layout()
+}
+
+// visible reports whether a line is shown with the levels hidden
+func (p *Pager) visible(line Line) bool {
+	return !line.Leveled || line.Level < 0 || line.Level > logging.ERROR || !p.hidden[line.Level]
+}
+
+// layout wraps the visible lines into rows, keeping the first line shown at
+// the top when it is still visible, or the line after it
+func (p *Pager) layout() {
+	first := p.topLine()
+	p.rows = p.rows[:0]
+	for i, line := range p.lines {
+		if !p.visible(line) {
+			continue
+		}
+		for _, text := range wrap(line.Text, p.width) {
+			p.rows = append(p.rows, row{line: i, text: text})
+		}
+	}
+	p.top = p.firstRowFrom(first)
+	p.clamp()
+}
+
+// topLine returns the index of the line at the top of the screen
+func (p *Pager) topLine() int {
+	if p.top < len(p.rows) {
+		return p.rows[p.top].line
+	}
+	return 0
+}
+
+// firstRowFrom returns the first row of the first visible line at or after
+// the given one
+func (p *Pager) firstRowFrom(line int) int {
+	for i, r := range p.rows {
+		if r.line >= line {
+			return i
+		}
+	}
+	return len(p.rows)
+}
+
+// pageHeight is the number of rows for lines, above the status line
+func (p *Pager) pageHeight() int {
+	return p.height - 1
+}
+
+// clamp keeps the last page full
+func (p *Pager) clamp() {
+	if max := len(p.rows) - p.pageHeight(); p.top > max {
+		p.top = max
+	}
+	if p.top < 0 {
+		p.top = 0
+	}
+}
+
+// scroll moves the view by n rows
+func (p *Pager) scroll(n int) {
+	p.top += n
+	p.clamp()
+}
+
+// Key handles a key and reports whether the user quit
+func (p *Pager) Key(key Key) bool {
+	p.message = ""
+	if p.mode != modeNormal {
+		p.prompt(key)
+		return false
+	}
+
+	switch key.Code {
+	case KeyInterrupt, KeyEscape:
+		return true
+	case KeyDown, KeyEnter:
+		p.scroll(1)
+	case KeyUp:
+		p.scroll(-1)
+	case KeyPageDown:
+		p.scroll(p.pageHeight())
+	case KeyPageUp:
+		p.scroll(-p.pageHeight())
+	case KeyHome:
+		p.top = 0
+	case KeyEnd:
+		p.top = len(p.rows)
+		p.clamp()
+	case KeyRune:
+		return p.command(key.Rune)
+	}
+	return false
+}
+
+// command handles a key typed in normal mode and reports whether the user quit
+func (p *Pager) command(r rune) bool {
+	switch r {
+	case 'q', 'Q':
+		return true
+	case 'j':
+		p.scroll(1)
+	case 'k':
+		p.scroll(-1)
+	case ' ', 'f':
+		p.scroll(p.pageHeight())
+	case 'b':
+		p.scroll(-p.pageHeight())
+	case 'g', '<':
+		p.top = 0
+	case 'G', '>':
+		p.top = len(p.rows)
+		p.clamp()
+	case '/':
+		p.mode, p.input = modeSearch, ""
+	case 't':
+		p.mode, p.input = modeTime, ""
+	case 'n':
+		p.search(true)
+	case 'N':
+		p.search(false)
+	case 'd':
+		p.toggle(logging.DEBUG)
+	case 'i':
+		p.toggle(logging.INFO)
+	case 'w':
+		p.toggle(logging.WARN)
+	case 'e':
+		p.toggle(logging.ERROR)
+	case '?', 'h':
+		p.message = "/ search  n/N next/previous  t jump to time  d/i/w/e toggle levels  g/G top/bottom  q quit"
+	}
+	return false
+}
+
+// prompt handles a key typed at the search or time prompt
+func (p *Pager) prompt(key Key) {
+	switch key.Code {
+	case KeyInterrupt, KeyEscape:
+		p.mode = modeNormal
+	case KeyBackspace:
+		if p.input == "" {
+			p.mode = modeNormal
+			return
+		}
+		_, size := utf8.DecodeLastRuneInString(p.input)
+		p.input = p.input[:len(p.input)-size]
+	case KeyEnter:
+		mode, input := p.mode, p.input
+		p.mode = modeNormal
+		if mode == modeSearch {
+			if input != "" {
+				p.query = input
+			}
+			p.search(true)
+			return
+		}
+		p.jumpTo(input)
+	case KeyRune:
+		p.input += string(key.Rune)
+	}
+}
+
+// toggle hides or shows the lines of a level
+func (p *Pager) toggle(level logging.LogLevel) {
+	p.hidden[level] = !p.hidden[level]
+	p.layout()
+	if p.hidden[level] {
+		p.message = level.String() + " lines hidden"
+	} else {
+		p.message = level.String() + " lines shown"
+	}
+}
+
+// search moves the view to the next (or previous) visible line containing
+// the query, ignoring case
+func (p *Pager) search(forward bool) {
+	if p.query == "" {
+		p.message = "no search pattern; type / to search"
+		return
+	}
+	query := strings.ToLower(p.query)
+	matches := func(r row) bool {
+		return strings.Contains(strings.ToLower(stripANSI(p.lines[r.line].Text)), query)
+	}
+
+	// Searches continue from the last match while it is on the screen
+	current := p.topLine()
+	if p.matched >= 0 && p.onScreen(p.matched) {
+		current = p.matched
+	}
+	if forward {
+		for i := p.top; i < len(p.rows); i++ {
+			if p.rows[i].line > current && matches(p.rows[i]) {
+				p.top, p.matched = i, p.rows[i].line
+				p.clamp()
+				return
+			}
+		}
+	} else {
+		for i := p.firstRowFrom(current) - 1; i >= 0; i-- {
+			if p.rows[i].line < current && matches(p.rows[i]) {
+				p.matched = p.rows[i].line
+				p.top = p.firstRowFrom(p.matched)
+				return
+			}
+		}
+	}
+	p.message = fmt.Sprintf("pattern not found: %s", p.query)
+}
+
+// onScreen reports whether a row of the line is on the screen
+func (p *Pager) onScreen(line int) bool {
+	for i := p.top; i < p.top+p.pageHeight() && i < len(p.rows); i++ {
+		if p.rows[i].line == line {
+			return true
+		}
+	}
+	return false
+}
+
+// jumpFormats are the accepted formats of the time to jump to
+var jumpFormats = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04",
+}
+
+// timeOfDayFormats are the formats of times without a date, which are taken
+// on the day of the first line with a timestamp
+var timeOfDayFormats = []string{"15:04:05", "15:04"}
+
+// jumpTo moves the view to the first visible line logged at or after the
+// given time
+func (p *Pager) jumpTo(input string) {
+	target, ok := p.parseJumpTime(strings.TrimSpace(input))
+	if !ok {
+		p.message = fmt.Sprintf("invalid time %q (expected e.g. 14:05, 14:05:30 or 2024-01-15T14:05:00Z)", input)
+		return
+	}
+	for i, r := range p.rows {
+		ts := p.lines[r.line].Time
+		if !ts.IsZero() && !ts.Before(target) {
+			p.top = i
+			p.clamp()
+			return
+		}
+	}
+	p.message = "no lines at or after " + input
+}
+
+// parseJumpTime parses the time to jump to
+func (p *Pager) parseJumpTime(input string) (time.Time, bool) {
+	for _, layout := range jumpFormats {
+		if t, err := time.Parse(layout, input); err == nil {
+			return t, true
+		}
+	}
+	var ref time.Time
+	for _, line := range p.lines {
+		if !line.Time.IsZero() {
+			ref = line.Time
+			break
+		}
+	}
+	if ref.IsZero() {
+		return time.Time{}, false
+	}
+	for _, layout := range timeOfDayFormats {
+		if t, err := time.Parse(layout, input); err == nil {
+			y, m, d := ref.Date()
+			return time.Date(y, m, d, t.Hour(), t.Minute(), t.Second(), 0, ref.Location()), true
+		}
+	}
+	return time.Time{}, false
+}
+
+// Render draws the screen: a page of rows and the status line
+func (p *Pager) Render(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("\x1b[H")
+	for i := 0; i < p.pageHeight(); i++ {
+		if n := p.top + i; n < len(p.rows) {
+			b.WriteString(p.rows[n].text)
+		} else {
+			b.WriteString("~")
+		}
+		b.WriteString("\x1b[0m\x1b[K\r\n")
+	}
+	b.WriteString("\x1b[7m")
+	b.WriteString(truncate(p.status(), p.width))
+	b.WriteString("\x1b[0m\x1b[K")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// status returns the text of the status line
+func (p *Pager) status() string {
+	switch p.mode {
+	case modeSearch:
+		return "/" + p.input
+	case modeTime:
+		return "jump to time: " + p.input
+	}
+	if p.message != "" {
+		return p.message
+	}
+
+	levels := ""
+	for level := logging.DEBUG; level <= logging.ERROR; level++ {
+		if p.hidden[level] {
+			levels += "-"
+		} else {
+			levels += level.String()[:1]
+		}
+	}
+	first, last := 0, 0
+	if len(p.rows) > 0 {
+		first = p.rows[p.top].line + 1
+		end := p.top + p.pageHeight() - 1
+		if end >= len(p.rows) {
+			end = len(p.rows) - 1
+		}
+		last = p.rows[end].line + 1
+	}
+	position := "END"
+	if p.top+p.pageHeight() < len(p.rows) {
+		position = fmt.Sprintf("%d%%", (p.top+p.pageHeight())*100/len(p.rows))
+	}
+	return fmt.Sprintf("lines %d-%d of %d  %s  levels %s  (? for help)", first, last, len(p.lines), position, levels)
+}
+
+// ansiRegex matches terminal escape sequences
+var ansiRegex = regexp.MustCompile(`\x1b\[[0-9;?]*[ -/]*[@-~]`)
+
+// stripANSI removes the escape sequences of a text
+func stripANSI(s string) string {
+	return ansiRegex.ReplaceAllString(s, "")
+}
+
+// escapeLen returns the length of the escape sequence s starts with, or 0
+func escapeLen(s string) int {
+	if len(s) < 2 || s[0] != 0x1b || s[1] != '[' {
+		return 0
+	}
+	for i := 2; i < len(s); i++ {
+		if s[i] >= 0x40 && s[i] <= 0x7e {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// truncate shortens a plain text to width runes
+func truncate(s string, width int) string {
+	if utf8.RuneCountInString(s) <= width {
+		return s
+	}
+	return string([]rune(s)[:width])
+}
+
+// wrap splits a text into rows of at most width columns, at its newlines and
+// wherever it is too wide. Escape sequences take no room, and the colors set
+// at the end of a row are set again at the start of the next one.
+func wrap(text string, width int) []string {
+	var rows []string
+	for _, part := range strings.Split(strings.ReplaceAll(text, "\t", "    "), "\n") {
+		part = strings.TrimSuffix(part, "\r")
+		var b strings.Builder
+		colors, cols := "", 0
+		for i := 0; i < len(part); {
+			if n := escapeLen(part[i:]); n > 0 {
+				seq := part[i : i+n]
+				b.WriteString(seq)
+				if strings.HasSuffix(seq, "m") {
+					if seq == "\x1b[0m" || seq == "\x1b[m" {
+						colors = ""
+					} else {
+						colors += seq
+					}
+				}
+				i += n
+				continue
+			}
+			if cols == width {
+				rows = append(rows, b.String())
+				b.Reset()
+				b.WriteString(colors)
+				cols = 0
+			}
+			_, size := utf8.DecodeRuneInString(part[i:])
+			b.WriteString(part[i : i+size])
+			cols++
+			i += size
+		}
+		rows = append(rows, b.String())
+	}
+	return rows
+}
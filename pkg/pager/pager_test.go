@@ -0,0 +1,161 @@
+package pager
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dantech2000/kubelog/pkg/logging"
+)
+
+// newTestPager returns a pager 80x5 with twenty lines, logged a minute apart
+// from 10:00, whose level cycles through DEBUG, INFO, WARN and ERROR
+func newTestPager() *Pager {
+	p := New(80, 5)
+	start := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	for i := 0; i < 20; i++ {
+		level := logging.LogLevel(i % 4)
+		p.Add(Line{
+			Text:    fmt.Sprintf("line %d %s", i, level),
+			Leveled: true,
+			Level:   level,
+			Time:    start.Add(time.Duration(i) * time.Minute),
+		})
+	}
+	return p
+}
+
+// typeKeys sends the keys of a string, e.g. "/line 5\r"
+func typeKeys(p *Pager, s string) bool {
+	quit := false
+	for _, key := range ParseKeys([]byte(s)) {
+		quit = p.Key(key)
+	}
+	return quit
+}
+
+func TestPagerNavigation(t *testing.T) {
+	tests := []struct {
+		name    string
+		keys    string
+		wantTop int
+	}{
+		{name: "down", keys: "jj", wantTop: 2},
+		{name: "up stops at the top", keys: "jkkk", wantTop: 0},
+		{name: "page down", keys: " ", wantTop: 4},
+		{name: "end keeps the last page full", keys: "G", wantTop: 16},
+		{name: "page down stops at the end", keys: "      ", wantTop: 16},
+		{name: "home", keys: "G\x1b[H", wantTop: 0},
+		{name: "arrow keys", keys: "\x1b[B\x1b[B\x1b[A", wantTop: 1},
+		{name: "search", keys: "/line 1\r", wantTop: 1},
+		{name: "next match", keys: "/line 1\rn", wantTop: 10},
+		{name: "previous match", keys: "/line 1\rnnN", wantTop: 10},
+		{name: "search ignores case", keys: "/LINE 6 W\r", wantTop: 6},
+		{name: "jump to time of day", keys: "t10:12\r", wantTop: 12},
+		{name: "jump to timestamp", keys: "t2024-01-15T10:03:00Z\r", wantTop: 3},
+		{name: "cancelled prompt", keys: "/line 9\x1b", wantTop: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newTestPager()
+			if typeKeys(p, tt.keys) {
+				t.Fatal("Key() quit")
+			}
+			if p.top != tt.wantTop {
+				t.Errorf("top = %d, want %d", p.top, tt.wantTop)
+			}
+		})
+	}
+}
+
+func TestPagerToggleLevels(t *testing.T) {
+	p := newTestPager()
+	typeKeys(p, "di")
+	if len(p.rows) != 10 {
+		t.Fatalf("rows = %d with DEBUG and INFO hidden, want 10", len(p.rows))
+	}
+	for _, r := range p.rows {
+		if level := p.lines[r.line].Level; level == logging.DEBUG || level == logging.INFO {
+			t.Errorf("row %q of a hidden level is shown", r.text)
+		}
+	}
+	p.message = ""
+	if status := p.status(); !strings.Contains(status, "levels --WE") {
+		t.Errorf("status() = %q, want hidden levels shown as -", status)
+	}
+
+	// The line at the top stays there when levels are shown again
+	typeKeys(p, "jj")
+	top := p.topLine()
+	typeKeys(p, "d")
+	if got := p.topLine(); got != top {
+		t.Errorf("top line = %d after showing a level, want %d", got, top)
+	}
+}
+
+func TestPagerQuit(t *testing.T) {
+	for _, keys := range []string{"q", "\x03", "jq"} {
+		if !typeKeys(newTestPager(), keys) {
+			t.Errorf("keys %q didn't quit", keys)
+		}
+	}
+	if typeKeys(newTestPager(), "/q\r") {
+		t.Error("typing q at the prompt quit")
+	}
+}
+
+func TestPagerFits(t *testing.T) {
+	p := New(80, 5)
+	p.Add(Line{Text: "header"})
+	p.Add(Line{Text: "a\nb"})
+	if !p.Fits() {
+		t.Error("Fits() = false for 3 rows on a screen of 5")
+	}
+	p.Add(Line{Text: strings.Repeat("x", 100)})
+	if p.Fits() {
+		t.Error("Fits() = true for 5 rows on a screen of 5")
+	}
+}
+
+func TestWrap(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		width int
+		want  []string
+	}{
+		{name: "short", text: "hello", width: 10, want: []string{"hello"}},
+		{name: "long", text: "abcdefgh", width: 3, want: []string{"abc", "def", "gh"}},
+		{name: "newlines", text: "ab\ncd", width: 10, want: []string{"ab", "cd"}},
+		{
+			name:  "colors don't take room and carry over",
+			text:  "\x1b[31mabcd\x1b[0mef",
+			width: 3,
+			want:  []string{"\x1b[31mabc", "\x1b[31md\x1b[0mef"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := wrap(tt.text, tt.width); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("wrap() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseKeys(t *testing.T) {
+	got := ParseKeys([]byte("a\x1b[6~\r\x7f\x1b\x1b[Z"))
+	want := []Key{
+		{Code: KeyRune, Rune: 'a'},
+		{Code: KeyPageDown},
+		{Code: KeyEnter},
+		{Code: KeyBackspace},
+		{Code: KeyEscape},
+		{Code: KeyUnknown},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseKeys() = %v, want %v", got, want)
+	}
+}
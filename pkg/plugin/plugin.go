@@ -0,0 +1,111 @@
+// Package plugin finds and runs external kubelog subcommands: like kubectl
+// plugins, an executable named kubelog-foo on the PATH runs as kubelog foo
+package plugin
+
+import (
+	"errors"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// Prefix starts the file name of every plugin
+const Prefix = "kubelog-"
+
+// Plugin is an executable found on the PATH
+type Plugin struct {
+	// Name is the subcommand, e.g. "foo bar" for kubelog-foo-bar
+	Name string
+	Path string
+}
+
+// Lookup finds the plugin handling args, preferring the longest match:
+// kubelog foo bar baz tries kubelog-foo-bar-baz, then kubelog-foo-bar, then
+// kubelog-foo. It returns the plugin's path and the arguments left to pass to
+// it. lookPath finds executables, like exec.LookPath.
+func Lookup(args []string, lookPath func(string) (string, error)) (string, []string, bool) {
+	var names []string
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "-") || strings.ContainsAny(arg, `/\`) {
+			break
+		}
+		names = append(names, arg)
+	}
+
+	for n := len(names); n > 0; n-- {
+		path, err := lookPath(Prefix + strings.Join(names[:n], "-"))
+		if err == nil {
+			return path, args[n:], true
+		}
+	}
+	return "", nil, false
+}
+
+// List returns the plugins in the directories of the PATH, ordered by name.
+// When several directories hold a plugin with the same name, the first one
+// wins, as it does when running it.
+func List() []Plugin {
+	seen := make(map[string]bool)
+	var plugins []Plugin
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			file := entry.Name()
+			if !strings.HasPrefix(file, Prefix) || entry.IsDir() || seen[file] {
+				continue
+			}
+			path := filepath.Join(dir, file)
+			if !isExecutable(path) {
+				continue
+			}
+			seen[file] = true
+			name := strings.TrimSuffix(strings.TrimPrefix(file, Prefix), filepath.Ext(file))
+			plugins = append(plugins, Plugin{Name: strings.ReplaceAll(name, "-", " "), Path: path})
+		}
+	}
+	sort.Slice(plugins, func(i, j int) bool {
+		return plugins[i].Name < plugins[j].Name
+	})
+	return plugins
+}
+
+// isExecutable reports whether the file at path can be run
+func isExecutable(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() {
+		return false
+	}
+	if runtime.GOOS == "windows" {
+		ext := strings.ToLower(filepath.Ext(path))
+		return ext == ".exe" || ext == ".bat" || ext == ".cmd"
+	}
+	return info.Mode()&0o111 != 0
+}
+
+// Run runs the plugin with the given arguments, connected to the standard
+// streams, and returns its exit code
+func Run(path string, args []string) (int, error) {
+	slog.Debug("running plugin", "path", path, "args", args)
+	cmd := exec.Command(path, args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Env = os.Environ()
+
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode(), nil
+	}
+	if err != nil {
+		return 1, err
+	}
+	return 0, nil
+}
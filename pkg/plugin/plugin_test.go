@@ -0,0 +1,74 @@
+package plugin
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+func TestLookup(t *testing.T) {
+	installed := map[string]bool{"kubelog-foo": true, "kubelog-foo-bar": true}
+	lookPath := func(name string) (string, error) {
+		if installed[name] {
+			return "/usr/local/bin/" + name, nil
+		}
+		return "", errors.New("not found")
+	}
+
+	tests := []struct {
+		name     string
+		args     []string
+		wantPath string
+		wantArgs []string
+		wantOK   bool
+	}{
+		{"Single word", []string{"foo", "-x"}, "/usr/local/bin/kubelog-foo", []string{"-x"}, true},
+		{"Longest match", []string{"foo", "bar", "baz"}, "/usr/local/bin/kubelog-foo-bar", []string{"baz"}, true},
+		{"Flags stop the name", []string{"foo", "--bar"}, "/usr/local/bin/kubelog-foo", []string{"--bar"}, true},
+		{"Unknown", []string{"qux"}, "", nil, false},
+		{"Flag first", []string{"--help"}, "", nil, false},
+		{"Path", []string{"../foo"}, "", nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path, args, ok := Lookup(tt.args, lookPath)
+			if ok != tt.wantOK || path != tt.wantPath || !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("Lookup() = %q, %v, %v, want %q, %v, %v", path, args, ok, tt.wantPath, tt.wantArgs, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestListAndRun(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("plugins are shell scripts")
+	}
+	first, second := t.TempDir(), t.TempDir()
+	write := func(dir, name string, mode os.FileMode) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\nexit 3\n"), mode); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(first, "kubelog-export-s3", 0o755)
+	write(first, "kubelog-notes.txt", 0o644)
+	write(second, "kubelog-export-s3", 0o755)
+	write(second, "kubelog-audit", 0o755)
+	write(second, "kubectl-foo", 0o755)
+	t.Setenv("PATH", first+string(os.PathListSeparator)+second)
+
+	want := []Plugin{
+		{Name: "audit", Path: filepath.Join(second, "kubelog-audit")},
+		{Name: "export s3", Path: filepath.Join(first, "kubelog-export-s3")},
+	}
+	if got := List(); !reflect.DeepEqual(got, want) {
+		t.Errorf("List() = %+v, want %+v", got, want)
+	}
+
+	code, err := Run(want[0].Path, nil)
+	if err != nil || code != 3 {
+		t.Errorf("Run() = %d, %v, want exit code 3", code, err)
+	}
+}
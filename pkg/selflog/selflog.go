@@ -0,0 +1,41 @@
+// Package selflog sets up the logger kubelog uses for its own diagnostics,
+// which are written to stderr so they never mix with the log output
+package selflog
+
+import (
+	"io"
+	"log/slog"
+)
+
+// Level returns the minimum level logged at a verbosity set by repeating -v:
+// warnings and errors by default, info with -v and debug with -vv
+func Level(verbosity int) slog.Level {
+	switch {
+	case verbosity <= 0:
+		return slog.LevelWarn
+	case verbosity == 1:
+		return slog.LevelInfo
+	default:
+		return slog.LevelDebug
+	}
+}
+
+// New returns a logger writing lines such as
+// "level=INFO msg="opened log stream" pod=api-0" to w at the given verbosity
+func New(w io.Writer, verbosity int) *slog.Logger {
+	return slog.New(slog.NewTextHandler(w, &slog.HandlerOptions{
+		Level: Level(verbosity),
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			// The time is noise next to the log output it accompanies
+			if a.Key == slog.TimeKey && len(groups) == 0 {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+}
+
+// Setup makes a logger from New the default used by the slog functions
+func Setup(w io.Writer, verbosity int) {
+	slog.SetDefault(New(w, verbosity))
+}
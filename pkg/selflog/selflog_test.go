@@ -0,0 +1,41 @@
+package selflog
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name      string
+		verbosity int
+		want      string
+	}{
+		{"default shows warnings", 0, "level=WARN msg=\"stream ended\" pod=api-0\n"},
+		{"-v shows info", 1, "level=INFO msg=\"opened log stream\" pod=api-0\nlevel=WARN msg=\"stream ended\" pod=api-0\n"},
+		{"-vv shows debug", 2, "level=DEBUG msg=\"resolved targets\" count=2\nlevel=INFO msg=\"opened log stream\" pod=api-0\nlevel=WARN msg=\"stream ended\" pod=api-0\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			logger := New(&buf, tt.verbosity)
+			logger.Debug("resolved targets", "count", 2)
+			logger.Info("opened log stream", "pod", "api-0")
+			logger.Warn("stream ended", "pod", "api-0")
+			if got := buf.String(); got != tt.want {
+				t.Errorf("output = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLevel(t *testing.T) {
+	if got := Level(-1); got != slog.LevelWarn {
+		t.Errorf("Level(-1) = %v, want %v", got, slog.LevelWarn)
+	}
+	if got := Level(5); got != slog.LevelDebug {
+		t.Errorf("Level(5) = %v, want %v", got, slog.LevelDebug)
+	}
+}
@@ -0,0 +1,216 @@
+// Package session reads and writes recordings of kubelog sessions in the
+// .klog format.
+//
+// A .klog file is JSON Lines: the first line is the session Header and every
+// other line an Entry, in the order the entries were shown. Entries keep the
+// line as the container logged it, so a recording can be parsed, filtered and
+// rendered again like a live stream.
+package session
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/dantech2000/kubelog/pkg/logging"
+)
+
+// Extension is the file name extension of session recordings
+const Extension = ".klog"
+
+// Format identifies .klog files in the header
+const Format = "kubelog-session"
+
+// Version is the version of the format written; readers reject newer ones
+const Version = 1
+
+// Header describes a recorded session
+type Header struct {
+	Format  string    `json:"format"`
+	Version int       `json:"version"`
+	Kubelog string    `json:"kubelog,omitempty"`
+	Started time.Time `json:"started"`
+	// Context is the kubeconfig context the session used
+	Context string `json:"context,omitempty"`
+	// Namespace is the namespace the session streamed from
+	Namespace string `json:"namespace,omitempty"`
+	// Args are the command line arguments of the session
+	Args []string `json:"args,omitempty"`
+}
+
+// Entry is a recorded log line with the container it was read from
+type Entry struct {
+	// Time is the timestamp logged with the line, if one was parsed
+	Time      *time.Time `json:"ts,omitempty"`
+	Level     string     `json:"level,omitempty"`
+	Namespace string     `json:"namespace,omitempty"`
+	Pod       string     `json:"pod,omitempty"`
+	Container string     `json:"container,omitempty"`
+	Node      string     `json:"node,omitempty"`
+	// Line is the line as the container logged it
+	Line string `json:"line"`
+}
+
+// NewEntry records a parsed entry
+func NewEntry(entry logging.LogEntry) Entry {
+	e := Entry{Level: entry.Level.String(), Line: entry.RawLine}
+	if !entry.Timestamp.IsZero() {
+		ts := entry.Timestamp
+		e.Time = &ts
+	}
+	if e.Line == "" {
+		e.Line = entry.Message
+	}
+	if s := entry.Source; s != nil {
+		e.Namespace, e.Pod, e.Container, e.Node = s.Namespace, s.Pod, s.Container, s.Node
+	}
+	return e
+}
+
+// LogEntry parses the recorded line again with the built-in formats and
+// restores its source and timestamp. Entries recorded with custom parsers can
+// be parsed with Pipeline.Process instead, setting the Source afterwards.
+func (e Entry) LogEntry() logging.LogEntry {
+	entry := logging.ParseLogEntry(e.Line)
+	if entry.Timestamp.IsZero() && e.Time != nil {
+		entry.Timestamp = *e.Time
+	}
+	entry.Source = e.Source()
+	return entry
+}
+
+// Source returns the container the line was read from, or nil if unknown
+func (e Entry) Source() *logging.Source {
+	if e.Namespace == "" && e.Pod == "" && e.Container == "" {
+		return nil
+	}
+	return &logging.Source{Namespace: e.Namespace, Pod: e.Pod, Container: e.Container, Node: e.Node}
+}
+
+// Writer records entries to a .klog file. It is safe for concurrent use.
+type Writer struct {
+	mu  sync.Mutex
+	w   *bufio.Writer
+	enc *json.Encoder
+}
+
+// NewWriter writes the header to w and returns a Writer for the entries.
+// The format and version of the header are filled in.
+func NewWriter(w io.Writer, header Header) (*Writer, error) {
+	header.Format, header.Version = Format, Version
+	buf := bufio.NewWriter(w)
+	sw := &Writer{w: buf, enc: json.NewEncoder(buf)}
+	if err := sw.enc.Encode(header); err != nil {
+		return nil, fmt.Errorf("error writing session header: %w", err)
+	}
+	return sw, nil
+}
+
+// Write records an entry
+func (w *Writer) Write(entry Entry) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.enc.Encode(entry); err != nil {
+		return fmt.Errorf("error recording entry: %w", err)
+	}
+	return nil
+}
+
+// Flush writes buffered entries to the underlying writer
+func (w *Writer) Flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.w.Flush(); err != nil {
+		return fmt.Errorf("error writing session recording: %w", err)
+	}
+	return nil
+}
+
+// ErrNotSession is returned when reading a file that isn't a session recording
+var ErrNotSession = errors.New("not a kubelog session recording")
+
+// Reader reads the entries of a .klog file
+type Reader struct {
+	// Header describes the recorded session
+	Header Header
+
+	scanner *bufio.Scanner
+	line    int
+}
+
+// NewReader reads the header of a recording from r
+func NewReader(r io.Reader) (*Reader, error) {
+	scanner := bufio.NewScanner(r)
+	// Folded stack traces make for long lines
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	sr := &Reader{scanner: scanner}
+	if !scanner.Scan() {
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("error reading session header: %w", err)
+		}
+		return nil, fmt.Errorf("%w: the file is empty", ErrNotSession)
+	}
+	sr.line = 1
+	if err := json.Unmarshal(scanner.Bytes(), &sr.Header); err != nil || sr.Header.Format != Format {
+		return nil, ErrNotSession
+	}
+	if sr.Header.Version > Version {
+		return nil, fmt.Errorf("session recording version %d is newer than the supported version %d", sr.Header.Version, Version)
+	}
+	return sr, nil
+}
+
+// Next returns the next entry, or io.EOF after the last one
+func (r *Reader) Next() (Entry, error) {
+	for r.scanner.Scan() {
+		r.line++
+		if len(r.scanner.Bytes()) == 0 {
+			continue
+		}
+		var entry Entry
+		if err := json.Unmarshal(r.scanner.Bytes(), &entry); err != nil {
+			return Entry{}, fmt.Errorf("invalid entry on line %d of session recording: %w", r.line, err)
+		}
+		return entry, nil
+	}
+	if err := r.scanner.Err(); err != nil {
+		return Entry{}, fmt.Errorf("error reading session recording: %w", err)
+	}
+	return Entry{}, io.EOF
+}
+
+// Recorder records the entries written through it to a session recording
+// before passing them on to the next writer
+type Recorder struct {
+	session *Writer
+	next    io.Writer
+}
+
+// NewRecorder creates a Recorder recording to session and writing to next
+func NewRecorder(session *Writer, next io.Writer) *Recorder {
+	return &Recorder{session: session, next: next}
+}
+
+// Write passes output that isn't an entry, such as header rows, on unrecorded
+func (r *Recorder) Write(p []byte) (int, error) {
+	return r.next.Write(p)
+}
+
+// WriteEntry records an entry and writes its formatted line to the next
+// writer, as an entry when it accepts them
+func (r *Recorder) WriteEntry(entry logging.LogEntry, line string) error {
+	if err := r.session.Write(NewEntry(entry)); err != nil {
+		return err
+	}
+	if ew, ok := r.next.(interface {
+		WriteEntry(entry logging.LogEntry, line string) error
+	}); ok {
+		return ew.WriteEntry(entry, line)
+	}
+	_, err := fmt.Fprintln(r.next, line)
+	return err
+}
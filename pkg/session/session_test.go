@@ -0,0 +1,123 @@
+package session
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dantech2000/kubelog/pkg/logging"
+)
+
+func TestWriterReader(t *testing.T) {
+	started := time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC)
+	source := &logging.Source{Namespace: "shop", Pod: "api-1", Container: "app", Node: "node-a"}
+	lines := []string{
+		`{"level":"error","msg":"charge failed","ts":"2024-03-15T12:00:01Z"}`,
+		"INFO starting",
+	}
+
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, Header{Kubelog: "1.2.3", Started: started, Namespace: "shop", Args: []string{"logs", "-f"}})
+	if err != nil {
+		t.Fatalf("NewWriter() error = %v", err)
+	}
+	for _, line := range lines {
+		entry := logging.ParseLogEntry(line)
+		entry.Source = source
+		if err := w.Write(NewEntry(entry)); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+
+	r, err := NewReader(&buf)
+	if err != nil {
+		t.Fatalf("NewReader() error = %v", err)
+	}
+	if r.Header.Format != Format || r.Header.Version != Version || !r.Header.Started.Equal(started) || r.Header.Namespace != "shop" {
+		t.Errorf("Header = %+v", r.Header)
+	}
+
+	for i, line := range lines {
+		e, err := r.Next()
+		if err != nil {
+			t.Fatalf("Next() error = %v", err)
+		}
+		if e.Line != line {
+			t.Errorf("entry %d Line = %q, want %q", i, e.Line, line)
+		}
+		entry := e.LogEntry()
+		if want := logging.ParseLogEntry(line); entry.Level != want.Level || entry.Message != want.Message || !entry.Timestamp.Equal(want.Timestamp) {
+			t.Errorf("entry %d LogEntry() = %+v, want %+v", i, entry, want)
+		}
+		if !reflect.DeepEqual(entry.Source, source) {
+			t.Errorf("entry %d Source = %+v, want %+v", i, entry.Source, source)
+		}
+	}
+	if _, err := r.Next(); err != io.EOF {
+		t.Errorf("Next() after the last entry error = %v, want io.EOF", err)
+	}
+}
+
+func TestNewReader_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantNot bool
+	}{
+		{"empty", "", true},
+		{"plain log", "INFO starting\n", true},
+		{"other JSON", `{"level":"info"}` + "\n", true},
+		{"newer version", `{"format":"kubelog-session","version":99}` + "\n", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewReader(strings.NewReader(tt.input))
+			if err == nil {
+				t.Fatal("NewReader() succeeded")
+			}
+			if got := errors.Is(err, ErrNotSession); got != tt.wantNot {
+				t.Errorf("NewReader() error = %v, ErrNotSession %v, want %v", err, got, tt.wantNot)
+			}
+		})
+	}
+}
+
+func TestRecorder(t *testing.T) {
+	var recording, out bytes.Buffer
+	w, err := NewWriter(&recording, Header{})
+	if err != nil {
+		t.Fatalf("NewWriter() error = %v", err)
+	}
+	rec := NewRecorder(w, &out)
+	if _, err := rec.Write([]byte("TIME LEVEL MESSAGE\n")); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if err := rec.WriteEntry(logging.ParseLogEntry("WARN disk almost full"), "formatted"); err != nil {
+		t.Fatalf("WriteEntry() error = %v", err)
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+
+	if got, want := out.String(), "TIME LEVEL MESSAGE\nformatted\n"; got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+	r, err := NewReader(&recording)
+	if err != nil {
+		t.Fatalf("NewReader() error = %v", err)
+	}
+	e, err := r.Next()
+	if err != nil || e.Line != "WARN disk almost full" || e.Level != "WARN" || e.Time != nil {
+		t.Errorf("Next() = %+v, %v", e, err)
+	}
+	if _, err := r.Next(); err != io.EOF {
+		t.Errorf("recorded the header row: Next() error = %v", err)
+	}
+}
@@ -0,0 +1,360 @@
+package sink
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// rotationTimeFormat is the timestamp in the names of rotated files
+const rotationTimeFormat = "20060102-150405"
+
+// Compression algorithms for rotated files
+const (
+	CompressNone = "none"
+	CompressGzip = "gzip"
+	CompressZstd = "zstd"
+)
+
+// compressExtensions are the extensions added to compressed files
+var compressExtensions = map[string]string{
+	CompressGzip: ".gz",
+	CompressZstd: ".zst",
+}
+
+// FileWriter writes to a capture file. With Every or MaxSize set, the capture
+// is split into files named after the time they start, e.g.
+// capture-20240115-100000.log for capture.log: a new file is started for every
+// time window of length Every, and whenever the current one would grow beyond
+// MaxSize. Files that are done can be compressed, and only the newest Keep
+// of them kept. It is safe for concurrent use.
+type FileWriter struct {
+	// Path is the capture file, or the base name of the rotated files
+	Path string
+	// Every is the length of a time window; zero disables time rotation
+	Every time.Duration
+	// MaxSize is the largest size of a file in bytes; zero disables size rotation
+	MaxSize int64
+	// Compress is the algorithm used for files that are done: CompressNone,
+	// CompressGzip or CompressZstd
+	Compress string
+	// Keep is the number of files that are done to keep; zero keeps all of them
+	Keep int
+	// Header is written at the top of every file, e.g. the header row of CSV output
+	Header string
+
+	mu     sync.Mutex
+	file   *os.File
+	size   int64
+	window time.Time
+	now    func() time.Time
+	// finished is closed once the last file that is done has been compressed
+	// and old files removed
+	finished chan struct{}
+
+	errMu sync.Mutex
+	errs  []error
+}
+
+// NewFileWriter creates a FileWriter for path, starting a new file every
+// window of the given length, or never when every is zero. Files are created
+// when first written to.
+func NewFileWriter(path string, every time.Duration) (*FileWriter, error) {
+	if every < 0 {
+		return nil, fmt.Errorf("invalid rotation interval %s", every)
+	}
+	if every > 0 && every < time.Second {
+		return nil, fmt.Errorf("rotation interval %s is shorter than the 1s resolution of file names", every)
+	}
+	return &FileWriter{Path: path, Every: every, Compress: CompressNone, now: time.Now}, nil
+}
+
+// rotating reports whether the capture is split into several files
+func (w *FileWriter) rotating() bool {
+	return w.Every > 0 || w.MaxSize > 0
+}
+
+// Write implements io.Writer. Each call is written to a single file, so
+// lines written whole are never split across files.
+func (w *FileWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	now := w.now()
+	window := time.Time{}
+	if w.Every > 0 {
+		window = now.Truncate(w.Every)
+	}
+	switch {
+	case w.file == nil:
+		if err := w.open(window, now); err != nil {
+			return 0, err
+		}
+	case !window.Equal(w.window), w.MaxSize > 0 && w.size > 0 && w.size+int64(len(p)) > w.MaxSize:
+		if err := w.rotate(window, now); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// open opens the file for the given window, named after the window's start
+// for the first file of a window and after now for later ones
+func (w *FileWriter) open(window, now time.Time) error {
+	path := w.Path
+	if w.rotating() {
+		start := now
+		if w.Every > 0 && !window.Equal(w.window) {
+			start = window
+		}
+		path = w.freePath(start)
+	}
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("error creating capture directory: %w", err)
+		}
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("error opening capture file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("error opening capture file: %w", err)
+	}
+	w.file, w.size, w.window = file, info.Size(), window
+
+	if w.Header != "" && w.size == 0 {
+		n, err := fmt.Fprintln(file, w.Header)
+		if err != nil {
+			return fmt.Errorf("error writing capture file header: %w", err)
+		}
+		w.size += int64(n)
+	}
+	return nil
+}
+
+// freePath returns the name for a file starting at t. A file of that name is
+// appended to, e.g. after a restart, unless it is full or was compressed, in
+// which case a counter is added: capture-20240115-100000-2.log.
+func (w *FileWriter) freePath(t time.Time) string {
+	path := RotatedPath(w.Path, t)
+	ext := filepath.Ext(path)
+	for i := 2; ; i++ {
+		if !w.used(path) {
+			return path
+		}
+		path = RotatedPath(w.Path, t)
+		path = strings.TrimSuffix(path, ext) + "-" + strconv.Itoa(i) + ext
+	}
+}
+
+// used reports whether a file of the given name can't be continued
+func (w *FileWriter) used(path string) bool {
+	if w.file != nil && w.file.Name() == path {
+		return true
+	}
+	if ext, ok := compressExtensions[w.Compress]; ok {
+		if _, err := os.Stat(path + ext); err == nil {
+			return true
+		}
+	}
+	info, err := os.Stat(path)
+	return err == nil && w.MaxSize > 0 && info.Size() >= w.MaxSize
+}
+
+// rotate closes the current file, opens the next one and then compresses the
+// closed file and removes old files in the background
+func (w *FileWriter) rotate(window, now time.Time) error {
+	done := w.file
+	if err := w.open(window, now); err != nil {
+		return err
+	}
+	if err := done.Close(); err != nil {
+		return fmt.Errorf("error closing capture file: %w", err)
+	}
+
+	// Files are compressed one at a time, in the order they were closed
+	prev, next := w.finished, make(chan struct{})
+	w.finished = next
+	go func(path, current string) {
+		defer close(next)
+		if prev != nil {
+			<-prev
+		}
+		if err := w.finish(path, current); err != nil {
+			w.errMu.Lock()
+			w.errs = append(w.errs, err)
+			w.errMu.Unlock()
+		}
+	}(done.Name(), w.file.Name())
+	return nil
+}
+
+// finish compresses a file that is done and applies the retention count to
+// the files other than current
+func (w *FileWriter) finish(path, current string) error {
+	if ext, ok := compressExtensions[w.Compress]; ok {
+		if err := compressFile(path, path+ext, w.Compress); err != nil {
+			return err
+		}
+	}
+	return w.prune(current)
+}
+
+// prune removes all but the newest Keep files other than current
+func (w *FileWriter) prune(current string) error {
+	if w.Keep <= 0 {
+		return nil
+	}
+	ext := filepath.Ext(w.Path)
+	pattern := strings.TrimSuffix(w.Path, ext) + "-*" + ext + "*"
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return fmt.Errorf("error listing capture files: %w", err)
+	}
+
+	rotated := rotatedName(w.Path)
+	var done []string
+	for _, path := range matches {
+		if path != current && rotated.MatchString(filepath.Base(path)) {
+			done = append(done, path)
+		}
+	}
+	// The timestamps in the names sort in the order the files were started
+	sort.Strings(done)
+	var errs []error
+	for len(done) > w.Keep {
+		if err := os.Remove(done[0]); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			errs = append(errs, fmt.Errorf("error removing old capture file: %w", err))
+		}
+		done = done[1:]
+	}
+	return errors.Join(errs...)
+}
+
+// rotatedName matches the base names freePath gives the files rotated from
+// path, e.g. capture-20240115-100000-2.log.gz, and not other files sharing
+// its prefix such as capture-server.log
+func rotatedName(path string) *regexp.Regexp {
+	base := filepath.Base(path)
+	ext := filepath.Ext(base)
+	return regexp.MustCompile(`^` + regexp.QuoteMeta(strings.TrimSuffix(base, ext)) +
+		`-\d{8}-\d{6}(-\d+)?` + regexp.QuoteMeta(ext) + `(\.gz|\.zst)?$`)
+}
+
+// compressFile writes a compressed copy of src to dst and removes src
+func compressFile(src, dst, algorithm string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("error compressing capture file: %w", err)
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return fmt.Errorf("error compressing capture file: %w", err)
+	}
+	defer out.Close()
+
+	var enc io.WriteCloser
+	switch algorithm {
+	case CompressGzip:
+		enc = gzip.NewWriter(out)
+	case CompressZstd:
+		if enc, err = zstd.NewWriter(out); err != nil {
+			return fmt.Errorf("error compressing capture file: %w", err)
+		}
+	default:
+		return fmt.Errorf("unsupported compression %q", algorithm)
+	}
+	if _, err := io.Copy(enc, in); err != nil {
+		enc.Close()
+		return fmt.Errorf("error compressing capture file: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return fmt.Errorf("error compressing capture file: %w", err)
+	}
+	if err := out.Close(); err != nil {
+		return fmt.Errorf("error compressing capture file: %w", err)
+	}
+	in.Close()
+	if err := os.Remove(src); err != nil {
+		return fmt.Errorf("error removing compressed capture file: %w", err)
+	}
+	return nil
+}
+
+// Close closes the current file and waits for files that are done to be
+// compressed. It returns the errors of compressing and removing files.
+func (w *FileWriter) Close() error {
+	w.mu.Lock()
+	var errs []error
+	if w.file != nil {
+		errs = append(errs, w.file.Close())
+		w.file = nil
+	}
+	finished := w.finished
+	w.mu.Unlock()
+
+	if finished != nil {
+		<-finished
+	}
+	w.errMu.Lock()
+	defer w.errMu.Unlock()
+	errs = append(errs, w.errs...)
+	w.errs = nil
+	return errors.Join(errs...)
+}
+
+// RotatedPath returns the name of the file starting at t, inserting the
+// timestamp before the extension of path
+func RotatedPath(path string, t time.Time) string {
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + "-" + t.Format(rotationTimeFormat) + ext
+}
+
+// sizeUnits are the suffixes accepted by ParseSize, longest first
+var sizeUnits = []struct {
+	suffix string
+	bytes  int64
+}{
+	{"KIB", 1 << 10}, {"MIB", 1 << 20}, {"GIB", 1 << 30}, {"TIB", 1 << 40},
+	{"KB", 1 << 10}, {"MB", 1 << 20}, {"GB", 1 << 30}, {"TB", 1 << 40},
+	{"K", 1 << 10}, {"M", 1 << 20}, {"G", 1 << 30}, {"T", 1 << 40},
+	{"B", 1},
+}
+
+// ParseSize parses a size such as 100MB, 1.5G or 512KiB into bytes. Units are
+// binary, like the sizes kubelog displays, and a plain number is in bytes.
+func ParseSize(s string) (int64, error) {
+	number, unit := strings.ToUpper(strings.TrimSpace(s)), int64(1)
+	for _, u := range sizeUnits {
+		if strings.HasSuffix(number, u.suffix) {
+			number, unit = strings.TrimSpace(strings.TrimSuffix(number, u.suffix)), u.bytes
+			break
+		}
+	}
+	n, err := strconv.ParseFloat(number, 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid size %q (expected e.g. 100MB or 1GB)", s)
+	}
+	return int64(n * float64(unit)), nil
+}
@@ -0,0 +1,306 @@
+package sink
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+func TestFileWriter_RotateEvery(t *testing.T) {
+	dir := t.TempDir()
+	w, err := NewFileWriter(filepath.Join(dir, "capture.csv"), time.Hour)
+	if err != nil {
+		t.Fatalf("NewFileWriter() error = %v", err)
+	}
+	w.Header = "ts,msg"
+	now := time.Date(2024, 1, 15, 10, 59, 0, 0, time.UTC)
+	w.now = func() time.Time { return now }
+
+	for _, line := range []string{"a", "b"} {
+		if _, err := w.Write([]byte(line + "\n")); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+	now = now.Add(2 * time.Minute)
+	if _, err := w.Write([]byte("c\n")); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	files := map[string]string{
+		"capture-20240115-100000.csv": "ts,msg\na\nb\n",
+		"capture-20240115-110000.csv": "ts,msg\nc\n",
+	}
+	for name, want := range files {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("ReadFile() error = %v", err)
+		}
+		if string(data) != want {
+			t.Errorf("%s = %q, want %q", name, data, want)
+		}
+	}
+}
+
+func TestFileWriter_NoRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "capture.log")
+	w, err := NewFileWriter(path, 0)
+	if err != nil {
+		t.Fatalf("NewFileWriter() error = %v", err)
+	}
+	if _, err := w.Write([]byte("a\n")); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	w.Close()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if string(data) != "a\n" {
+		t.Errorf("capture.log = %q, want %q", data, "a\n")
+	}
+}
+
+func TestNewFileWriter_InvalidInterval(t *testing.T) {
+	for _, every := range []time.Duration{-time.Minute, 10 * time.Millisecond} {
+		if _, err := NewFileWriter("capture.log", every); err == nil {
+			t.Errorf("NewFileWriter(%s) error = nil, want an error", every)
+		}
+	}
+}
+
+func TestRotatedPath(t *testing.T) {
+	ts := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	tests := map[string]string{
+		"capture.log":          "capture-20240115-100000.log",
+		"/var/log/api":         "/var/log/api-20240115-100000",
+		"out/api.capture.json": "out/api.capture-20240115-100000.json",
+	}
+	for path, want := range tests {
+		if got := RotatedPath(path, ts); got != want {
+			t.Errorf("RotatedPath(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestFileWriter_MaxSize(t *testing.T) {
+	tests := []struct {
+		name     string
+		compress string
+		keep     int
+		want     map[string]string
+	}{
+		{
+			name:     "uncompressed",
+			compress: CompressNone,
+			want: map[string]string{
+				"capture-20240115-100000.log": "aaaa\nbbbb\n",
+				"capture-20240115-100001.log": "cccc\ndddd\n",
+				"capture-20240115-100002.log": "eeee\n",
+			},
+		},
+		{
+			name:     "gzip keeping one",
+			compress: CompressGzip,
+			keep:     1,
+			want: map[string]string{
+				"capture-20240115-100001.log.gz": "cccc\ndddd\n",
+				"capture-20240115-100002.log":    "eeee\n",
+			},
+		},
+		{
+			name:     "zstd",
+			compress: CompressZstd,
+			want: map[string]string{
+				"capture-20240115-100000.log.zst": "aaaa\nbbbb\n",
+				"capture-20240115-100001.log.zst": "cccc\ndddd\n",
+				"capture-20240115-100002.log":     "eeee\n",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			w, err := NewFileWriter(filepath.Join(dir, "capture.log"), 0)
+			if err != nil {
+				t.Fatalf("NewFileWriter() error = %v", err)
+			}
+			w.MaxSize, w.Compress, w.Keep = 10, tt.compress, tt.keep
+			now := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+			w.now = func() time.Time { return now }
+
+			for _, line := range []string{"aaaa", "bbbb", "cccc", "dddd", "eeee"} {
+				if _, err := w.Write([]byte(line + "\n")); err != nil {
+					t.Fatalf("Write() error = %v", err)
+				}
+				now = now.Add(500 * time.Millisecond)
+			}
+			if err := w.Close(); err != nil {
+				t.Fatalf("Close() error = %v", err)
+			}
+
+			entries, err := os.ReadDir(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(entries) != len(tt.want) {
+				var names []string
+				for _, e := range entries {
+					names = append(names, e.Name())
+				}
+				t.Fatalf("files = %v, want %d files", names, len(tt.want))
+			}
+			for name, want := range tt.want {
+				if got := readCapture(t, filepath.Join(dir, name)); got != want {
+					t.Errorf("%s = %q, want %q", name, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestFileWriter_PruneKeepsForeignFiles(t *testing.T) {
+	dir := t.TempDir()
+	foreign := []string{"capture-server.log", "capture-old.log.gz", "capture-metrics-20240115-090000.log"}
+	for _, name := range foreign {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("keep\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	w, err := NewFileWriter(filepath.Join(dir, "capture.log"), 0)
+	if err != nil {
+		t.Fatalf("NewFileWriter() error = %v", err)
+	}
+	w.MaxSize, w.Keep = 10, 1
+	now := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	w.now = func() time.Time { return now }
+	for _, line := range []string{"aaaa", "bbbb", "cccc", "dddd", "eeee"} {
+		if _, err := w.Write([]byte(line + "\n")); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+		now = now.Add(500 * time.Millisecond)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	for _, name := range foreign {
+		if data, err := os.ReadFile(filepath.Join(dir, name)); err != nil || string(data) != "keep\n" {
+			t.Errorf("%s = %q, %v, want it left alone", name, data, err)
+		}
+	}
+	for _, name := range []string{"capture-20240115-100001.log", "capture-20240115-100002.log"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("rotated file %s missing: %v", name, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "capture-20240115-100000.log")); err == nil {
+		t.Error("oldest rotated file was not pruned")
+	}
+}
+
+func TestFileWriter_PruneContainersSharingPrefix(t *testing.T) {
+	// kubelog capture writes every container of a pod to one directory
+	dir := t.TempDir()
+	now := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	writers := make(map[string]*FileWriter)
+	for _, container := range []string{"app", "app-metrics"} {
+		w, err := NewFileWriter(filepath.Join(dir, container+".log"), 0)
+		if err != nil {
+			t.Fatalf("NewFileWriter() error = %v", err)
+		}
+		w.MaxSize, w.Keep = 10, 2
+		w.now = func() time.Time { return now }
+		writers[container] = w
+	}
+
+	for _, line := range []string{"aaaa", "bbbb", "cccc", "dddd", "eeee", "ffff", "gggg"} {
+		for _, container := range []string{"app", "app-metrics"} {
+			if _, err := writers[container].Write([]byte(line + "\n")); err != nil {
+				t.Fatalf("Write() error = %v", err)
+			}
+		}
+		now = now.Add(500 * time.Millisecond)
+	}
+	for _, w := range writers {
+		if err := w.Close(); err != nil {
+			t.Fatalf("Close() error = %v", err)
+		}
+	}
+
+	for _, container := range []string{"app", "app-metrics"} {
+		matches, err := filepath.Glob(filepath.Join(dir, container+"-2024*.log"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		// The file being written and the two newest that are done
+		if len(matches) != 3 {
+			t.Errorf("%s files = %v, want 3", container, matches)
+		}
+	}
+}
+
+// readCapture returns the contents of a capture file, decompressing it if needed
+func readCapture(t *testing.T, path string) string {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	switch filepath.Ext(path) {
+	case ".gz":
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			t.Fatalf("gzip.NewReader() error = %v", err)
+		}
+		r = gz
+	case ".zst":
+		zr, err := zstd.NewReader(f)
+		if err != nil {
+			t.Fatalf("zstd.NewReader() error = %v", err)
+		}
+		defer zr.Close()
+		r = zr
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("ReadAll() error = %v", err)
+	}
+	return string(data)
+}
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    int64
+		wantErr bool
+	}{
+		{"100MB", 100 << 20, false},
+		{"1.5G", 3 << 29, false},
+		{"512KiB", 512 << 10, false},
+		{"64 kb", 64 << 10, false},
+		{"4096", 4096, false},
+		{"0", 0, true},
+		{"lots", 0, true},
+		{"-1MB", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseSize(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseSize(%q) = %d, %v; want %d, error %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
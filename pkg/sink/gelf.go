@@ -0,0 +1,128 @@
+// Package sink sends log entries to external systems
+package sink
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// gelfChunkSize is the largest UDP datagram sent, as recommended by Graylog
+	gelfChunkSize = 8192
+	// gelfChunkHeader is the size of the magic bytes, message ID, sequence number and count
+	gelfChunkHeader = 12
+	// gelfMaxChunks is the most chunks a GELF message may be split into
+	gelfMaxChunks = 128
+	// dialTimeout bounds connecting to a TCP sink
+	dialTimeout = 10 * time.Second
+)
+
+// ErrMessageTooLarge is returned for GELF messages that do not fit in gelfMaxChunks UDP chunks
+var ErrMessageTooLarge = errors.New("GELF message too large")
+
+// GELFWriter sends GELF messages, one per line written, to a Graylog input
+// over UDP (chunked when needed) or TCP (null-byte delimited).
+// It is safe for concurrent use.
+type GELFWriter struct {
+	mu      sync.Mutex
+	conn    net.Conn
+	network string
+}
+
+// NewGELFWriter connects to a Graylog GELF input at an address such as
+// udp://graylog:12201 or tcp://graylog:12201; without a scheme UDP is used
+func NewGELFWriter(address string) (*GELFWriter, error) {
+	network, hostport := "udp", address
+	if scheme, rest, ok := strings.Cut(address, "://"); ok {
+		network, hostport = scheme, rest
+	}
+	if network != "udp" && network != "tcp" {
+		return nil, fmt.Errorf("unsupported GELF transport %q (expected udp or tcp)", network)
+	}
+
+	conn, err := net.DialTimeout(network, hostport, dialTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("error connecting to GELF input %s: %w", address, err)
+	}
+	return &GELFWriter{conn: conn, network: network}, nil
+}
+
+// Write implements io.Writer, sending every non-empty line of p as a message
+func (w *GELFWriter) Write(p []byte) (int, error) {
+	for _, line := range bytes.Split(p, []byte("\n")) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		if err := w.send(line); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// Close closes the connection to the GELF input
+func (w *GELFWriter) Close() error {
+	return w.conn.Close()
+}
+
+// send writes a single message using the framing of the transport
+func (w *GELFWriter) send(msg []byte) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.network == "tcp" {
+		if _, err := w.conn.Write(append(msg, 0)); err != nil {
+			return fmt.Errorf("error sending GELF message: %w", err)
+		}
+		return nil
+	}
+
+	datagrams, err := chunkGELF(msg)
+	if err != nil {
+		return err
+	}
+	for _, datagram := range datagrams {
+		if _, err := w.conn.Write(datagram); err != nil {
+			return fmt.Errorf("error sending GELF message: %w", err)
+		}
+	}
+	return nil
+}
+
+// chunkGELF splits a message into UDP datagrams. Messages that fit are sent
+// as is; larger ones are chunked with a random message ID
+func chunkGELF(msg []byte) ([][]byte, error) {
+	if len(msg) <= gelfChunkSize {
+		return [][]byte{msg}, nil
+	}
+
+	payload := gelfChunkSize - gelfChunkHeader
+	count := (len(msg) + payload - 1) / payload
+	if count > gelfMaxChunks {
+		return nil, fmt.Errorf("%w: %d bytes", ErrMessageTooLarge, len(msg))
+	}
+
+	id := make([]byte, 8)
+	_, _ = rand.Read(id)
+
+	chunks := make([][]byte, 0, count)
+	for i := 0; i < count; i++ {
+		end := (i + 1) * payload
+		if end > len(msg) {
+			end = len(msg)
+		}
+		chunk := make([]byte, 0, gelfChunkHeader+end-i*payload)
+		chunk = append(chunk, 0x1e, 0x0f)
+		chunk = append(chunk, id...)
+		chunk = append(chunk, byte(i), byte(count))
+		chunk = append(chunk, msg[i*payload:end]...)
+		chunks = append(chunks, chunk)
+	}
+	return chunks, nil
+}
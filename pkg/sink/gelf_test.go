@@ -0,0 +1,118 @@
+package sink
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestGELFWriter_UDP(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	w, err := NewGELFWriter("udp://" + conn.LocalAddr().String())
+	if err != nil {
+		t.Fatalf("NewGELFWriter() error = %v", err)
+	}
+	defer w.Close()
+
+	if _, err := w.Write([]byte(`{"version":"1.1","short_message":"hi"}` + "\n")); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	buf := make([]byte, gelfChunkSize)
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, _, err := conn.ReadFrom(buf)
+	if err != nil {
+		t.Fatalf("ReadFrom() error = %v", err)
+	}
+	if got, want := string(buf[:n]), `{"version":"1.1","short_message":"hi"}`; got != want {
+		t.Errorf("received %q, want %q", got, want)
+	}
+}
+
+func TestGELFWriter_TCP(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	received := make(chan []string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			received <- nil
+			return
+		}
+		defer conn.Close()
+		var msgs []string
+		r := bufio.NewReader(conn)
+		for len(msgs) < 2 {
+			msg, err := r.ReadBytes(0)
+			if err != nil {
+				break
+			}
+			msgs = append(msgs, string(bytes.TrimSuffix(msg, []byte{0})))
+		}
+		received <- msgs
+	}()
+
+	w, err := NewGELFWriter("tcp://" + ln.Addr().String())
+	if err != nil {
+		t.Fatalf("NewGELFWriter() error = %v", err)
+	}
+	if _, err := w.Write([]byte("{\"short_message\":\"a\"}\n{\"short_message\":\"b\"}\n")); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	w.Close()
+
+	msgs := <-received
+	if len(msgs) != 2 || msgs[0] != `{"short_message":"a"}` || msgs[1] != `{"short_message":"b"}` {
+		t.Errorf("received %q", msgs)
+	}
+}
+
+func TestChunkGELF(t *testing.T) {
+	msg := bytes.Repeat([]byte("x"), 2*gelfChunkSize)
+	chunks, err := chunkGELF(msg)
+	if err != nil {
+		t.Fatalf("chunkGELF() error = %v", err)
+	}
+	if len(chunks) != 3 {
+		t.Fatalf("chunkGELF() returned %d chunks, want 3", len(chunks))
+	}
+
+	var joined []byte
+	for i, chunk := range chunks {
+		if len(chunk) > gelfChunkSize {
+			t.Errorf("chunk %d has %d bytes, want at most %d", i, len(chunk), gelfChunkSize)
+		}
+		if chunk[0] != 0x1e || chunk[1] != 0x0f || int(chunk[10]) != i || int(chunk[11]) != len(chunks) {
+			t.Errorf("chunk %d has header % x", i, chunk[:gelfChunkHeader])
+		}
+		if !bytes.Equal(chunk[2:10], chunks[0][2:10]) {
+			t.Errorf("chunk %d has a different message ID", i)
+		}
+		joined = append(joined, chunk[gelfChunkHeader:]...)
+	}
+	if !bytes.Equal(joined, msg) {
+		t.Error("chunks do not reassemble into the message")
+	}
+
+	if _, err := chunkGELF(bytes.Repeat([]byte("x"), gelfMaxChunks*gelfChunkSize)); !errors.Is(err, ErrMessageTooLarge) {
+		t.Errorf("chunkGELF() error = %v, want ErrMessageTooLarge", err)
+	}
+}
+
+func TestNewGELFWriter_InvalidTransport(t *testing.T) {
+	if _, err := NewGELFWriter("http://graylog:12201"); err == nil {
+		t.Error("NewGELFWriter() expected error for unsupported transport")
+	}
+}
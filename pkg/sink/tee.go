@@ -0,0 +1,85 @@
+package sink
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sync"
+
+	"github.com/dantech2000/kubelog/pkg/logging"
+)
+
+// ansiEscape matches the color escape sequences of formatted lines
+var ansiEscape = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+
+// Sink is an output of a Tee with the formatter of its lines
+type Sink struct {
+	// Name describes the sink in errors, e.g. json=app.jsonl
+	Name string
+	// Formatter formats the entries written to the sink
+	Formatter logging.Formatter
+	// Writer receives a formatted entry per line, and is closed with the Tee
+	Writer io.WriteCloser
+}
+
+// Tee writes the entries written to it to the next writer, and to each of
+// its sinks formatted with the formatter of the sink, so that one session can
+// e.g. be shown on the terminal, saved as JSON and sent to Graylog at once.
+// Colors are left out of the lines of the sinks, and output that isn't an
+// entry, such as header rows, only goes to the next writer. It is safe for
+// concurrent use.
+type Tee struct {
+	mu    sync.Mutex
+	next  io.Writer
+	sinks []Sink
+}
+
+// NewTee creates a Tee writing to next and the sinks
+func NewTee(next io.Writer, sinks ...Sink) *Tee {
+	return &Tee{next: next, sinks: sinks}
+}
+
+// Write passes output that isn't an entry on to the next writer
+func (t *Tee) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.next.Write(p)
+}
+
+// WriteEntry writes an entry to every sink, and its formatted line to the
+// next writer, as an entry when it accepts them
+func (t *Tee) WriteEntry(entry logging.LogEntry, line string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, s := range t.sinks {
+		formatted := s.Formatter.Format(entry)
+		if formatted == "" {
+			// Formatters such as the jq one drop entries by rendering nothing
+			continue
+		}
+		if _, err := fmt.Fprintln(s.Writer, ansiEscape.ReplaceAllString(formatted, "")); err != nil {
+			return fmt.Errorf("error writing to %s: %w", s.Name, err)
+		}
+	}
+	if ew, ok := t.next.(interface {
+		WriteEntry(entry logging.LogEntry, line string) error
+	}); ok {
+		return ew.WriteEntry(entry, line)
+	}
+	_, err := fmt.Fprintln(t.next, line)
+	return err
+}
+
+// Close closes the writers of all sinks
+func (t *Tee) Close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var errs []error
+	for _, s := range t.sinks {
+		if err := s.Writer.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("error closing %s: %w", s.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
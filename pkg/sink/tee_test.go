@@ -0,0 +1,77 @@
+package sink
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/dantech2000/kubelog/pkg/logging"
+	"github.com/fatih/color"
+)
+
+// bufferSink is a sink writer keeping what was written to it
+type bufferSink struct {
+	bytes.Buffer
+	closed bool
+	err    error
+}
+
+func (b *bufferSink) Write(p []byte) (int, error) {
+	if b.err != nil {
+		return 0, b.err
+	}
+	return b.Buffer.Write(p)
+}
+
+func (b *bufferSink) Close() error {
+	b.closed = true
+	return nil
+}
+
+func TestTee(t *testing.T) {
+	defer func(noColor bool) { color.NoColor = noColor }(color.NoColor)
+	color.NoColor = false
+
+	var terminal bytes.Buffer
+	var text, logfmt bufferSink
+	tee := NewTee(&terminal,
+		Sink{Name: "text=session.log", Formatter: logging.TextFormatter{}, Writer: &text},
+		Sink{Name: "logfmt=session.logfmt", Formatter: logging.LogfmtFormatter{}, Writer: &logfmt},
+	)
+
+	entry := logging.LogEntry{Level: logging.ERROR, Message: "connection refused"}
+	if err := tee.WriteEntry(entry, "shown line"); err != nil {
+		t.Fatalf("WriteEntry() error = %v", err)
+	}
+	if _, err := tee.Write([]byte("header\n")); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if err := tee.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	if got, want := terminal.String(), "shown line\nheader\n"; got != want {
+		t.Errorf("next writer got %q, want %q", got, want)
+	}
+	if got, want := text.String(), "[ERROR] connection refused\n"; got != want {
+		t.Errorf("text sink got %q, want %q without colors", got, want)
+	}
+	wantLogfmt := logging.LogfmtFormatter{}.Format(entry) + "\n"
+	if got := logfmt.String(); got != wantLogfmt {
+		t.Errorf("logfmt sink got %q, want %q", got, wantLogfmt)
+	}
+	if !text.closed || !logfmt.closed {
+		t.Error("Close() didn't close every sink")
+	}
+}
+
+func TestTee_SinkError(t *testing.T) {
+	var terminal bytes.Buffer
+	broken := &bufferSink{err: errors.New("disk full")}
+	tee := NewTee(&terminal, Sink{Name: "json=app.json", Formatter: logging.JSONFormatter{}, Writer: broken})
+
+	err := tee.WriteEntry(logging.LogEntry{Message: "hello"}, "hello")
+	if err == nil || !errors.Is(err, broken.err) {
+		t.Fatalf("WriteEntry() error = %v, want the sink's error", err)
+	}
+}
@@ -0,0 +1,146 @@
+package version
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ReleasesURL is the GitHub API endpoint returning the latest kubelog release
+const ReleasesURL = "https://api.github.com/repos/dantech2000/kubelog/releases/latest"
+
+// Release is a published release as returned by the GitHub releases API
+type Release struct {
+	TagName string `json:"tag_name"`
+	HTMLURL string `json:"html_url"`
+}
+
+// UpdateInfo is the result of comparing the current version with the latest release
+type UpdateInfo struct {
+	Latest Version
+	// URL is the release page of the latest version
+	URL string
+	// Available reports whether the latest release is newer than the current version
+	Available bool
+}
+
+// ParseVersion parses a semantic version such as v1.2.3 or 1.2.3-rc.1.
+// Build metadata after a + is ignored.
+func ParseVersion(s string) (Version, error) {
+	core, _, _ := strings.Cut(strings.TrimPrefix(strings.TrimSpace(s), "v"), "+")
+	core, prerelease, _ := strings.Cut(core, "-")
+
+	parts := strings.Split(core, ".")
+	if len(parts) != 3 {
+		return Version{}, fmt.Errorf("invalid version %q (expected MAJOR.MINOR.PATCH)", s)
+	}
+	numbers := make([]int, 3)
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return Version{}, fmt.Errorf("invalid version %q (expected MAJOR.MINOR.PATCH)", s)
+		}
+		numbers[i] = n
+	}
+	return Version{Major: numbers[0], Minor: numbers[1], Patch: numbers[2], Prerelease: prerelease}, nil
+}
+
+// Compare returns -1, 0 or 1 when v is older than, equal to or newer than
+// other. A pre-release is older than the release it precedes.
+func (v Version) Compare(other Version) int {
+	for _, d := range []int{v.Major - other.Major, v.Minor - other.Minor, v.Patch - other.Patch} {
+		if d != 0 {
+			return sign(d)
+		}
+	}
+	switch {
+	case v.Prerelease == other.Prerelease:
+		return 0
+	case v.Prerelease == "":
+		return 1
+	case other.Prerelease == "":
+		return -1
+	default:
+		return comparePrerelease(v.Prerelease, other.Prerelease)
+	}
+}
+
+// comparePrerelease compares dot-separated pre-release identifiers: numeric
+// ones numerically, others lexically, and a shorter list is older
+func comparePrerelease(a, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		an, aErr := strconv.Atoi(as[i])
+		bn, bErr := strconv.Atoi(bs[i])
+		switch {
+		case aErr == nil && bErr == nil:
+			if an != bn {
+				return sign(an - bn)
+			}
+		case aErr == nil:
+			return -1
+		case bErr == nil:
+			return 1
+		default:
+			if c := strings.Compare(as[i], bs[i]); c != 0 {
+				return c
+			}
+		}
+	}
+	return sign(len(as) - len(bs))
+}
+
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// LatestRelease fetches the latest release from the GitHub releases API at url
+func LatestRelease(ctx context.Context, client *http.Client, url string) (Release, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return Release{}, fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return Release{}, fmt.Errorf("error fetching the latest release: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return Release{}, fmt.Errorf("error fetching the latest release: %s", resp.Status)
+	}
+
+	var release Release
+	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
+		return Release{}, fmt.Errorf("error decoding the latest release: %w", err)
+	}
+	return release, nil
+}
+
+// CheckForUpdate compares current with the latest release published at url
+func CheckForUpdate(ctx context.Context, client *http.Client, url string, current Version) (UpdateInfo, error) {
+	release, err := LatestRelease(ctx, client, url)
+	if err != nil {
+		return UpdateInfo{}, err
+	}
+	latest, err := ParseVersion(release.TagName)
+	if err != nil {
+		return UpdateInfo{}, fmt.Errorf("error parsing the latest release: %w", err)
+	}
+	return UpdateInfo{
+		Latest:    latest,
+		URL:       release.HTMLURL,
+		Available: latest.Compare(current) > 0,
+	}, nil
+}
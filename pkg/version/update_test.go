@@ -0,0 +1,100 @@
+package version
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Version
+		wantErr bool
+	}{
+		{"v1.2.3", Version{Major: 1, Minor: 2, Patch: 3}, false},
+		{"0.1.4", Version{Major: 0, Minor: 1, Patch: 4}, false},
+		{"v2.0.0-rc.1+build.5", Version{Major: 2, Prerelease: "rc.1"}, false},
+		{"v1.2", Version{}, true},
+		{"latest", Version{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseVersion(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseVersion() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseVersion() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVersion_Compare(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"0.1.4", "0.1.4", 0},
+		{"0.1.4", "0.1.10", -1},
+		{"0.2.0", "0.1.9", 1},
+		{"1.0.0", "0.9.9", 1},
+		{"1.0.0-rc.1", "1.0.0", -1},
+		{"1.0.0-rc.2", "1.0.0-rc.10", -1},
+		{"1.0.0-beta", "1.0.0-alpha", 1},
+		{"1.0.0-rc", "1.0.0-rc.1", -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.a+" vs "+tt.b, func(t *testing.T) {
+			a, _ := ParseVersion(tt.a)
+			b, _ := ParseVersion(tt.b)
+			if got := a.Compare(b); got != tt.want {
+				t.Errorf("Compare() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckForUpdate(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/latest":
+			_, _ = w.Write([]byte(`{"tag_name": "v0.2.0", "html_url": "https://github.com/dantech2000/kubelog/releases/tag/v0.2.0"}`))
+		case "/slow":
+			time.Sleep(200 * time.Millisecond)
+		default:
+			http.Error(w, "rate limited", http.StatusForbidden)
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name    string
+		path    string
+		current Version
+		want    bool
+		wantErr bool
+	}{
+		{name: "Update available", path: "/latest", current: Version{Minor: 1, Patch: 4}, want: true},
+		{name: "Up to date", path: "/latest", current: Version{Minor: 2}, want: false},
+		{name: "Newer than the latest release", path: "/latest", current: Version{Minor: 3}, want: false},
+		{name: "API error", path: "/limited", wantErr: true},
+		{name: "Timeout", path: "/slow", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+			got, err := CheckForUpdate(ctx, server.Client(), server.URL+tt.path, tt.current)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CheckForUpdate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got.Available != tt.want {
+				t.Errorf("CheckForUpdate() = %+v, want Available %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -10,6 +10,7 @@ type Version struct {
 	Major      int
 	Minor      int
 	Patch      int
+	Prerelease string
 	CommitHash string
 	BuildDate  string
 }
@@ -31,6 +32,9 @@ var CurrentVersion = Version{
 
 // String returns a string representation of the version
 func (v Version) String() string {
+	if v.Prerelease != "" {
+		return fmt.Sprintf("%d.%d.%d-%s", v.Major, v.Minor, v.Patch, v.Prerelease)
+	}
 	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
 }
 